@@ -0,0 +1,174 @@
+package adherence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/thatnerdjosh/mindfulness/internal/domain/adherence"
+	"github.com/thatnerdjosh/mindfulness/internal/domain/journal"
+)
+
+// renewalNote is the note logged when Renew flips a precept back.
+const renewalNote = "Beginning Anew"
+
+var errNoBeginningAnew = errors.New("beginning anew is not available")
+
+// OpenBreak is a break that has not been renewed yet, with the Beginning
+// Anew record started for it, if any.
+type OpenBreak struct {
+	Event  adherence.AdherenceLogEntry
+	Record *adherence.BeginningAnew
+}
+
+// BeginningAnewEnabled reports whether the service can store Beginning
+// Anew records.
+func (s *Service) BeginningAnewEnabled() bool {
+	return s.renewal != nil
+}
+
+// BeginAnew records the first steps of Beginning Anew for the break event,
+// keeping whatever an earlier record for the same break already holds.
+func (s *Service) BeginAnew(ctx context.Context, event adherence.AdherenceLogEntry, reflection adherence.BeginningAnew) (adherence.BeginningAnew, error) {
+	if s.renewal == nil {
+		return adherence.BeginningAnew{}, errNoBeginningAnew
+	}
+	if !adherence.IsBreak(event) {
+		return adherence.BeginningAnew{}, fmt.Errorf("%s was not broken by this change", event.Precept)
+	}
+
+	var record adherence.BeginningAnew
+	err := s.uow.Do(ctx, func(_ journal.Repository, _ adherence.Repository, renewals adherence.BeginningAnewRepository) error {
+		if renewals == nil {
+			return errNoBeginningAnew
+		}
+		existing, err := recordFor(ctx, renewals, event, s.now())
+		if err != nil {
+			return err
+		}
+		record = withReflection(existing, reflection)
+		return renewals.Save(ctx, record)
+	})
+	if err != nil {
+		return adherence.BeginningAnew{}, err
+	}
+	return record, nil
+}
+
+// Renew completes Beginning Anew for the latest open break of precept: it
+// marks the record renewed and, if the precept is still recorded as not
+// kept, flips it back, storing both as one unit of work. The flip is part of
+// the renewal rather than a change of its own, so the listeners do not see
+// it. The returned changes are the ones logged.
+func (s *Service) Renew(ctx context.Context, precept journal.Precept, reflection adherence.BeginningAnew) (adherence.BeginningAnew, []adherence.AdherenceLogEntry, error) {
+	if s.renewal == nil {
+		return adherence.BeginningAnew{}, nil, errNoBeginningAnew
+	}
+	if !journal.IsKnownPrecept(precept) {
+		return adherence.BeginningAnew{}, nil, fmt.Errorf("%w: %s", journal.ErrUnknownPrecept, precept)
+	}
+
+	var record adherence.BeginningAnew
+	var changes []adherence.AdherenceLogEntry
+	err := s.uow.Do(ctx, func(_ journal.Repository, repo adherence.Repository, renewals adherence.BeginningAnewRepository) error {
+		if renewals == nil {
+			return errNoBeginningAnew
+		}
+		within := s.Within(repo, renewals)
+		open, err := within.OpenBreaks(ctx)
+		if err != nil {
+			return err
+		}
+		var latest *adherence.AdherenceLogEntry
+		for i := range open {
+			if open[i].Event.Precept == precept {
+				latest = &open[i].Event
+			}
+		}
+		if latest == nil {
+			return fmt.Errorf("%w for %s", adherence.ErrNoOpenBreak, precept)
+		}
+
+		current, err := repo.Get(ctx)
+		if err != nil {
+			return err
+		}
+		if !current[precept] {
+			changes, err = within.Set(ctx, adherence.Adherence{precept: true}, map[journal.Precept]string{precept: renewalNote})
+			if err != nil {
+				return err
+			}
+		}
+
+		if record, err = recordFor(ctx, renewals, *latest, s.now()); err != nil {
+			return err
+		}
+		record = withReflection(record, reflection)
+		record.RenewedAt = s.now().UTC()
+		return renewals.Save(ctx, record)
+	})
+	if err != nil {
+		return adherence.BeginningAnew{}, nil, err
+	}
+	return record, changes, nil
+}
+
+// OpenBreaks lists the breaks that have not been renewed, oldest first.
+func (s *Service) OpenBreaks(ctx context.Context) ([]OpenBreak, error) {
+	if s.renewal == nil {
+		return nil, errNoBeginningAnew
+	}
+	log, err := s.Log(ctx)
+	if err != nil {
+		return nil, err
+	}
+	records, err := s.renewal.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byBreak := make(map[string]adherence.BeginningAnew, len(records))
+	for _, record := range records {
+		byBreak[record.Break] = record
+	}
+
+	var open []OpenBreak
+	for _, event := range adherence.OpenBreaks(log, records) {
+		item := OpenBreak{Event: event}
+		if record, ok := byBreak[event.Fingerprint()]; ok {
+			item.Record = &record
+		}
+		open = append(open, item)
+	}
+	return open, nil
+}
+
+// recordFor returns the record stored in renewals for the break event, or a
+// new one started at now.
+func recordFor(ctx context.Context, renewals adherence.BeginningAnewRepository, event adherence.AdherenceLogEntry, now time.Time) (adherence.BeginningAnew, error) {
+	records, err := renewals.List(ctx)
+	if err != nil {
+		return adherence.BeginningAnew{}, err
+	}
+	fingerprint := event.Fingerprint()
+	for _, record := range records {
+		if record.Break == fingerprint {
+			return record, nil
+		}
+	}
+	return adherence.BeginningAnew{Break: fingerprint, Precept: event.Precept, At: now.UTC()}, nil
+}
+
+// withReflection writes the non-empty reflection fields over record.
+func withReflection(record adherence.BeginningAnew, reflection adherence.BeginningAnew) adherence.BeginningAnew {
+	merged := adherence.BeginningAnew{
+		Acknowledgement: strings.TrimSpace(reflection.Acknowledgement),
+		Regret:          strings.TrimSpace(reflection.Regret),
+		Intention:       strings.TrimSpace(reflection.Intention),
+	}.Merge(record)
+	record.Acknowledgement = merged.Acknowledgement
+	record.Regret = merged.Regret
+	record.Intention = merged.Intention
+	return record
+}
@@ -0,0 +1,127 @@
+package adherence
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	journalapp "github.com/thatnerdjosh/mindfulness/internal/application/journal"
+	"github.com/thatnerdjosh/mindfulness/internal/application/unitofwork"
+	"github.com/thatnerdjosh/mindfulness/internal/domain/adherence"
+	"github.com/thatnerdjosh/mindfulness/internal/domain/journal"
+	"github.com/thatnerdjosh/mindfulness/internal/infrastructure/persistence/memory"
+)
+
+func TestServiceBeginAnewAndRenew(t *testing.T) {
+	at := time.Date(2024, 4, 1, 9, 0, 0, 0, time.UTC)
+	records := memory.NewBeginningAnewRepository()
+	svc := NewService(memory.NewAdherenceRepository(), WithBeginningAnew(records))
+	svc.now = func() time.Time { return at }
+
+	changes, err := svc.Set(context.Background(), adherence.Adherence{journal.TrueLove: false}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := svc.BeginAnew(context.Background(), changes[0], adherence.BeginningAnew{
+		Acknowledgement: "I was dismissive",
+		Intention:       "listen fully",
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	open, err := svc.OpenBreaks(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(open) != 1 || open[0].Record == nil || open[0].Record.Intention != "listen fully" {
+		t.Fatalf("expected the break to be open with its record, got %+v", open)
+	}
+
+	at = at.Add(time.Hour)
+	record, renewal, err := svc.Renew(context.Background(), journal.TrueLove, adherence.BeginningAnew{Regret: "it hurt them"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !record.RenewedAt.Equal(at) || record.Acknowledgement != "I was dismissive" || record.Regret != "it hurt them" {
+		t.Fatalf("unexpected renewal record: %+v", record)
+	}
+	if len(renewal) != 1 || !renewal[0].To || renewal[0].Note != renewalNote {
+		t.Fatalf("expected the precept to be flipped back, got %+v", renewal)
+	}
+	if state, _ := svc.Current(context.Background()); !state[journal.TrueLove] {
+		t.Fatal("expected the precept to be kept again")
+	}
+	if open, _ := svc.OpenBreaks(context.Background()); len(open) != 0 {
+		t.Fatalf("expected no open breaks, got %+v", open)
+	}
+
+	if _, _, err := svc.Renew(context.Background(), journal.TrueLove, adherence.BeginningAnew{}); !errors.Is(err, adherence.ErrNoOpenBreak) {
+		t.Fatalf("expected ErrNoOpenBreak, got %v", err)
+	}
+}
+
+func TestServiceRenewKeptPrecept(t *testing.T) {
+	svc := NewService(memory.NewAdherenceRepository(), WithBeginningAnew(memory.NewBeginningAnewRepository()))
+	if _, err := svc.Set(context.Background(), adherence.Adherence{journal.TrueHappiness: false}, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := svc.Set(context.Background(), adherence.Adherence{journal.TrueHappiness: true}, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Flipping the precept back by hand does not renew the commitment.
+	if open, _ := svc.OpenBreaks(context.Background()); len(open) != 1 {
+		t.Fatalf("expected the break to stay open, got %+v", open)
+	}
+	_, changes, err := svc.Renew(context.Background(), journal.TrueHappiness, adherence.BeginningAnew{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(changes) != 0 {
+		t.Fatalf("expected nothing to flip, got %+v", changes)
+	}
+}
+
+func TestServiceBeginAnewRejectsNonBreak(t *testing.T) {
+	svc := NewService(memory.NewAdherenceRepository(), WithBeginningAnew(memory.NewBeginningAnewRepository()))
+	kept := adherence.AdherenceLogEntry{At: time.Now(), Precept: journal.TrueLove, From: false, To: true}
+	if _, err := svc.BeginAnew(context.Background(), kept, adherence.BeginningAnew{}); err == nil {
+		t.Fatal("expected an error for a change that is not a break")
+	}
+
+	if _, err := NewService(memory.NewAdherenceRepository()).OpenBreaks(context.Background()); err == nil {
+		t.Fatal("expected an error without a Beginning Anew store")
+	}
+}
+
+func TestServiceRenewDoesNotJournalTheFlip(t *testing.T) {
+	ctx := context.Background()
+	journalRepo := memory.NewJournalRepository()
+	adherenceRepo := memory.NewAdherenceRepository()
+	renewals := memory.NewBeginningAnewRepository()
+	svc := NewService(adherenceRepo,
+		WithUnitOfWork(unitofwork.Direct(journalRepo, adherenceRepo, renewals)),
+		WithBeginningAnew(renewals),
+		WithListener(journalapp.NewService(journalRepo).JournalAdherenceChanges),
+	)
+
+	if _, err := svc.Set(ctx, adherence.Adherence{journal.TrueLove: false}, map[journal.Precept]string{journal.TrueLove: "snapped at a friend"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, changes, err := svc.Renew(ctx, journal.TrueLove, adherence.BeginningAnew{Intention: "listen fully"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(changes) != 1 {
+		t.Fatalf("expected the precept to be flipped back, got %+v", changes)
+	}
+
+	entries, err := journalRepo.List(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Reflections[journal.TrueLove] != "snapped at a friend" {
+		t.Fatalf("expected only the break to be journaled, got %+v", entries)
+	}
+}
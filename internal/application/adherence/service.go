@@ -3,46 +3,170 @@ package adherence
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/thatnerdjosh/mindfulness/internal/application/unitofwork"
 	"github.com/thatnerdjosh/mindfulness/internal/domain/adherence"
 	"github.com/thatnerdjosh/mindfulness/internal/domain/journal"
 )
 
 // Service coordinates adherence use cases.
 type Service struct {
-	repo adherence.Repository
-	now  func() time.Time
+	repo      adherence.Repository
+	uow       unitofwork.UnitOfWork
+	renewal   adherence.BeginningAnewRepository
+	listeners []Listener
+	now       func() time.Time
 }
 
-func NewService(repo adherence.Repository) *Service {
-	return &Service{
+// Option configures a Service.
+type Option func(*Service)
+
+// Listener is called by Set with the changes it logged. It runs inside the
+// same unit of work, and journalRepo is that unit's view of the journal, so
+// what the listener writes is stored together with the changes.
+type Listener func(ctx context.Context, journalRepo journal.Repository, changes []adherence.AdherenceLogEntry) error
+
+// WithListener adds a listener to the changes made by Set.
+func WithListener(listener Listener) Option {
+	return func(s *Service) {
+		s.listeners = append(s.listeners, listener)
+	}
+}
+
+// WithUnitOfWork makes Set store the new state and its log entries as one
+// unit of work, and Renew the flip back with its record. Without it they are
+// written one after the other. With WithBeginningAnew, uow must cover the
+// same Beginning Anew records.
+func WithUnitOfWork(uow unitofwork.UnitOfWork) Option {
+	return func(s *Service) {
+		s.uow = uow
+	}
+}
+
+// WithBeginningAnew stores Beginning Anew records in repo, enabling
+// BeginAnew, Renew and OpenBreaks.
+func WithBeginningAnew(repo adherence.BeginningAnewRepository) Option {
+	return func(s *Service) {
+		s.renewal = repo
+	}
+}
+
+func NewService(repo adherence.Repository, opts ...Option) *Service {
+	s := &Service{
 		repo: repo,
 		now:  time.Now,
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.uow == nil {
+		s.uow = unitofwork.Direct(nil, repo, s.renewal)
+	}
+	return s
+}
+
+// Within returns a copy of the service that writes straight to repo and
+// renewals, the stores as seen by a unit of work the caller is running. Its
+// Set skips the listeners, since the caller owns the rest of the unit.
+func (s *Service) Within(repo adherence.Repository, renewals adherence.BeginningAnewRepository) *Service {
+	within := *s
+	within.repo = repo
+	within.renewal = renewals
+	within.uow = unitofwork.Direct(nil, repo, renewals)
+	within.listeners = nil
+	return &within
 }
 
 func (s *Service) Current(ctx context.Context) (adherence.Adherence, error) {
 	return s.repo.Get(ctx)
 }
 
-func (s *Service) Set(ctx context.Context, next adherence.Adherence, notes map[journal.Precept]string) error {
-	current, err := s.repo.Get(ctx)
+// Log returns the logged changes, leaving out the events that record where
+// the history starts.
+func (s *Service) Log(ctx context.Context) ([]adherence.AdherenceLogEntry, error) {
+	log, err := s.repo.Log(ctx)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	return adherence.Changes(log), nil
+}
+
+// ArchiveLog moves the current log into an archive segment. It fails when
+// the repository cannot archive.
+func (s *Service) ArchiveLog(ctx context.Context) (adherence.LogSegment, bool, error) {
+	archiver, ok := s.repo.(adherence.Archiver)
+	if !ok {
+		return adherence.LogSegment{}, false, fmt.Errorf("adherence log cannot be archived")
 	}
+	return archiver.ArchiveLog(ctx)
+}
 
-	updated, err := s.computeUpdatedAdherence(current, next)
+// Rebuild regenerates the stored state from the log. It fails when the
+// repository does not derive its state from the log.
+func (s *Service) Rebuild(ctx context.Context) (adherence.Adherence, int, error) {
+	rebuilder, ok := s.repo.(adherence.Rebuilder)
+	if !ok {
+		return nil, 0, fmt.Errorf("adherence state is not derived from the log; nothing to rebuild")
+	}
+	return rebuilder.Rebuild(ctx)
+}
+
+// LogOn returns the adherence changes logged on the given UTC day.
+func (s *Service) LogOn(ctx context.Context, date time.Time) ([]adherence.AdherenceLogEntry, error) {
+	entries, err := s.Log(ctx)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	if err := s.repo.Save(ctx, updated); err != nil {
-		return err
+	day := date.UTC().Format("2006-01-02")
+	var matched []adherence.AdherenceLogEntry
+	for _, entry := range entries {
+		if entry.At.UTC().Format("2006-01-02") == day {
+			matched = append(matched, entry)
+		}
 	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		return matched[i].At.Before(matched[j].At)
+	})
+	return matched, nil
+}
+
+// Set applies next on top of the current adherence and returns the logged changes.
+func (s *Service) Set(ctx context.Context, next adherence.Adherence, notes map[journal.Precept]string) ([]adherence.AdherenceLogEntry, error) {
+	var changes []adherence.AdherenceLogEntry
+	err := s.uow.Do(ctx, func(journalRepo journal.Repository, repo adherence.Repository, _ adherence.BeginningAnewRepository) error {
+		current, err := repo.Get(ctx)
+		if err != nil {
+			return err
+		}
+
+		updated, err := s.computeUpdatedAdherence(current, next)
+		if err != nil {
+			return err
+		}
+
+		if err := repo.Save(ctx, updated); err != nil {
+			return err
+		}
 
-	return s.logChanges(ctx, current, updated, notes)
+		changes, err = s.logChanges(ctx, repo, current, updated, notes)
+		if err != nil || len(changes) == 0 {
+			return err
+		}
+		for _, listener := range s.listeners {
+			if err := listener(ctx, journalRepo, changes); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return changes, nil
 }
 
 func (s *Service) computeUpdatedAdherence(current, next adherence.Adherence) (adherence.Adherence, error) {
@@ -53,7 +177,7 @@ func (s *Service) computeUpdatedAdherence(current, next adherence.Adherence) (ad
 
 	for precept, value := range next {
 		if !journal.IsKnownPrecept(precept) {
-			return nil, fmt.Errorf("unknown precept: %s", precept)
+			return nil, fmt.Errorf("%w: %s", journal.ErrUnknownPrecept, precept)
 		}
 		updated[precept] = value
 	}
@@ -61,24 +185,30 @@ func (s *Service) computeUpdatedAdherence(current, next adherence.Adherence) (ad
 	return updated, nil
 }
 
-func (s *Service) logChanges(ctx context.Context, current, updated adherence.Adherence, notes map[journal.Precept]string) error {
+func (s *Service) logChanges(ctx context.Context, repo adherence.Repository, current, updated adherence.Adherence, notes map[journal.Precept]string) ([]adherence.AdherenceLogEntry, error) {
 	now := s.now().UTC()
-	for precept, from := range current {
-		to := updated[precept]
+	changes := []adherence.AdherenceLogEntry{}
+	for _, info := range journal.AllPrecepts() {
+		from, ok := current[info.ID]
+		if !ok {
+			continue
+		}
+		to := updated[info.ID]
 		if from == to {
 			continue
 		}
-		note := strings.TrimSpace(notes[precept])
+		note := strings.TrimSpace(notes[info.ID])
 		entry := adherence.AdherenceLogEntry{
 			At:      now,
-			Precept: precept,
+			Precept: info.ID,
 			From:    from,
 			To:      to,
 			Note:    note,
 		}
-		if err := s.repo.AppendLog(ctx, entry); err != nil {
-			return err
+		if err := repo.AppendLog(ctx, entry); err != nil {
+			return nil, err
 		}
+		changes = append(changes, entry)
 	}
-	return nil
+	return changes, nil
 }
@@ -7,8 +7,10 @@ import (
 	"testing"
 	"time"
 
+	"github.com/thatnerdjosh/mindfulness/internal/application/unitofwork"
 	"github.com/thatnerdjosh/mindfulness/internal/domain/adherence"
 	"github.com/thatnerdjosh/mindfulness/internal/domain/journal"
+	"github.com/thatnerdjosh/mindfulness/internal/infrastructure/persistence/memory"
 )
 
 type fakeAdherenceRepo struct {
@@ -40,6 +42,21 @@ func (f *fakeAdherenceRepo) AppendLog(_ context.Context, entry adherence.Adheren
 	return nil
 }
 
+func (f *fakeAdherenceRepo) Log(_ context.Context) ([]adherence.AdherenceLogEntry, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return append([]adherence.AdherenceLogEntry{}, f.log...), nil
+}
+
+func (f *fakeAdherenceRepo) ReplaceLog(_ context.Context, log []adherence.AdherenceLogEntry) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.log = append([]adherence.AdherenceLogEntry{}, log...)
+	return nil
+}
+
 func TestServiceCurrent(t *testing.T) {
 	repo := &fakeAdherenceRepo{adherence: adherence.DefaultAdherence()}
 	svc := NewService(repo)
@@ -131,9 +148,10 @@ func TestServiceSet(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			repo := &fakeAdherenceRepo{adherence: tt.current, err: tt.repoErr}
 			now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
-			svc := &Service{repo: repo, now: func() time.Time { return now }}
+			svc := NewService(repo)
+			svc.now = func() time.Time { return now }
 
-			err := svc.Set(context.Background(), tt.next, tt.notes)
+			changes, err := svc.Set(context.Background(), tt.next, tt.notes)
 			if tt.wantErr != "" {
 				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
 					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
@@ -143,9 +161,163 @@ func TestServiceSet(t *testing.T) {
 			if err != nil {
 				t.Fatalf("unexpected error: %v", err)
 			}
+			if len(changes) != len(repo.log) {
+				t.Fatalf("expected %d returned changes, got %d", len(repo.log), len(changes))
+			}
 			if tt.check != nil {
 				tt.check(t, repo, now)
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestServiceLogOn(t *testing.T) {
+	day := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	repo := &fakeAdherenceRepo{log: []adherence.AdherenceLogEntry{
+		{At: day.Add(20 * time.Hour), Precept: journal.TrueLove},
+		{At: day.Add(-time.Hour), Precept: journal.TrueHappiness},
+		{At: day.Add(8 * time.Hour), Precept: journal.ReverenceForLife},
+	}}
+	svc := NewService(repo)
+
+	entries, err := svc.LogOn(context.Background(), day.Add(15*time.Hour))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	if entries[0].Precept != journal.ReverenceForLife || entries[1].Precept != journal.TrueLove {
+		t.Fatalf("expected time order, got %+v", entries)
+	}
+
+	repo.err = errors.New("read failed")
+	if _, err := svc.LogOn(context.Background(), day); err == nil {
+		t.Fatalf("expected error")
+	}
+}
+
+type recordingUnitOfWork struct {
+	repo  adherence.Repository
+	calls int
+	err   error
+}
+
+func (u *recordingUnitOfWork) Do(_ context.Context, fn func(journal.Repository, adherence.Repository, adherence.BeginningAnewRepository) error) error {
+	u.calls++
+	if err := fn(nil, u.repo, nil); err != nil {
+		return err
+	}
+	return u.err
+}
+
+func TestServiceSetUsesUnitOfWork(t *testing.T) {
+	repo := &fakeAdherenceRepo{adherence: adherence.DefaultAdherence()}
+	uow := &recordingUnitOfWork{repo: repo}
+	svc := NewService(repo, WithUnitOfWork(uow))
+
+	changes, err := svc.Set(context.Background(), adherence.Adherence{journal.TrueLove: false}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if uow.calls != 1 || len(changes) != 1 || len(repo.log) != 1 {
+		t.Fatalf("expected one unit of work with one change, got %d calls and %d changes", uow.calls, len(changes))
+	}
+
+	uow.err = errors.New("commit failed")
+	if _, err := svc.Set(context.Background(), adherence.Adherence{journal.TrueLove: true}, nil); err == nil || err.Error() != "commit failed" {
+		t.Fatalf("expected the commit error, got %v", err)
+	}
+}
+
+func TestServiceLogLeavesOutInitialEvents(t *testing.T) {
+	at := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	repo := &fakeAdherenceRepo{
+		adherence: adherence.DefaultAdherence(),
+		log: append(adherence.InitialEvents(at, adherence.DefaultAdherence()),
+			adherence.AdherenceLogEntry{At: at.Add(time.Hour), Precept: journal.TrueLove, From: true, To: false}),
+	}
+	svc := NewService(repo)
+
+	log, err := svc.Log(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(log) != 1 || log[0].Precept != journal.TrueLove {
+		t.Fatalf("expected only the change, got %+v", log)
+	}
+	day, err := svc.LogOn(context.Background(), at)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(day) != 1 {
+		t.Fatalf("expected only the change on the day, got %+v", day)
+	}
+
+	if _, _, err := svc.Rebuild(context.Background()); err == nil {
+		t.Fatal("expected an error for a repository that cannot rebuild")
+	}
+}
+
+type archivingAdherenceRepo struct {
+	*fakeAdherenceRepo
+	archived int
+}
+
+func (r *archivingAdherenceRepo) ArchiveLog(_ context.Context) (adherence.LogSegment, bool, error) {
+	r.archived++
+	return adherence.LogSegment{Name: "adherence.log.1.jsonl.gz", Events: len(r.log)}, true, nil
+}
+
+func TestServiceArchiveLog(t *testing.T) {
+	svc := NewService(&fakeAdherenceRepo{adherence: adherence.DefaultAdherence()})
+	if _, _, err := svc.ArchiveLog(context.Background()); err == nil {
+		t.Fatal("expected an error for a repository that cannot archive")
+	}
+
+	repo := &archivingAdherenceRepo{fakeAdherenceRepo: &fakeAdherenceRepo{adherence: adherence.DefaultAdherence()}}
+	svc = NewService(repo)
+	segment, archived, err := svc.ArchiveLog(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !archived || segment.Name == "" || repo.archived != 1 {
+		t.Fatalf("expected the repository to archive, got %+v (%d calls)", segment, repo.archived)
+	}
+}
+
+func TestServiceSetNotifiesListeners(t *testing.T) {
+	repo := memory.NewAdherenceRepository()
+	journalRepo := memory.NewJournalRepository()
+	var heard []adherence.AdherenceLogEntry
+	var heardRepo journal.Repository
+	listener := func(_ context.Context, tx journal.Repository, changes []adherence.AdherenceLogEntry) error {
+		heardRepo = tx
+		heard = append(heard, changes...)
+		return nil
+	}
+	svc := NewService(repo, WithUnitOfWork(unitofwork.Direct(journalRepo, repo, nil)), WithListener(listener))
+
+	changes, err := svc.Set(context.Background(), adherence.Adherence{journal.TrueLove: false}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(heard) != 1 || heard[0] != changes[0] || heardRepo != journal.Repository(journalRepo) {
+		t.Fatalf("expected the listener to hear the change in the unit of work, got %+v", heard)
+	}
+
+	// Saving an unchanged state logs nothing and tells nobody.
+	if _, err := svc.Set(context.Background(), adherence.Adherence{journal.TrueLove: false}, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(heard) != 1 {
+		t.Fatalf("expected no call without changes, got %+v", heard)
+	}
+
+	failing := NewService(repo, WithListener(func(context.Context, journal.Repository, []adherence.AdherenceLogEntry) error {
+		return errors.New("journal full")
+	}))
+	if _, err := failing.Set(context.Background(), adherence.Adherence{journal.TrueLove: true}, nil); err == nil || err.Error() != "journal full" {
+		t.Fatalf("expected the listener error, got %v", err)
+	}
+}
@@ -0,0 +1,98 @@
+package analytics
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Period is the length of the buckets a trend is grouped into.
+type Period string
+
+const (
+	Daily  Period = "day"
+	Weekly Period = "week"
+)
+
+// MoodPoint is the average mood over one period. Entries counts the entries
+// with a mood and Rated those whose mood has a scale; Valence and Energy
+// average the rated ones and are zero when none was.
+type MoodPoint struct {
+	Start   time.Time
+	Entries int
+	Rated   int
+	Valence float64
+	Energy  float64
+}
+
+// MoodTrend averages the valence and energy of the moods between since and
+// until, inclusive, per period. Every period in the range has a point, so
+// gaps show. Moods are read with the service's vocabulary, so entries
+// written before normalization still count.
+func (s *Service) MoodTrend(ctx context.Context, since time.Time, until time.Time, period Period) ([]MoodPoint, error) {
+	if err := period.validate(); err != nil {
+		return nil, err
+	}
+	entries, since, until, err := s.window(ctx, since, until)
+	if err != nil || since.IsZero() {
+		return nil, err
+	}
+
+	var points []MoodPoint
+	index := make(map[time.Time]int)
+	for _, start := range period.starts(since, until) {
+		index[start] = len(points)
+		points = append(points, MoodPoint{Start: start})
+	}
+
+	for _, entry := range entries {
+		if entry.Mood == "" {
+			continue
+		}
+		point := &points[index[period.start(entry.Date)]]
+		point.Entries++
+		term, ok := s.moods.Lookup(entry.Mood)
+		if !ok || term.Scale == nil {
+			continue
+		}
+		point.Rated++
+		point.Valence += float64(term.Scale.Valence)
+		point.Energy += float64(term.Scale.Energy)
+	}
+	for i := range points {
+		if points[i].Rated > 0 {
+			points[i].Valence /= float64(points[i].Rated)
+			points[i].Energy /= float64(points[i].Rated)
+		}
+	}
+	return points, nil
+}
+
+func (p Period) validate() error {
+	if p != Daily && p != Weekly {
+		return fmt.Errorf("unknown period %q: expected day or week", p)
+	}
+	return nil
+}
+
+// start returns the first day of the period date falls in.
+func (p Period) start(date time.Time) time.Time {
+	day := startOfDay(date)
+	if p == Weekly {
+		return weekStart(day)
+	}
+	return day
+}
+
+// starts lists the first day of every period from since through until.
+func (p Period) starts(since time.Time, until time.Time) []time.Time {
+	step := 1
+	if p == Weekly {
+		step = 7
+	}
+	var starts []time.Time
+	for start := p.start(since); !start.After(until); start = start.AddDate(0, 0, step) {
+		starts = append(starts, start)
+	}
+	return starts
+}
@@ -0,0 +1,47 @@
+package analytics
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/thatnerdjosh/mindfulness/internal/domain/journal"
+	"github.com/thatnerdjosh/mindfulness/internal/infrastructure/persistence/memory"
+)
+
+func TestServiceMoodTrend(t *testing.T) {
+	repo := memory.NewJournalRepository()
+	// Written before moods were normalized, so the words vary.
+	mustSave(t, repo, "2024-04-29", nil, "a", "Calm.", journal.FoundationDhamma)
+	mustSave(t, repo, "2024-04-30", nil, "b", "excited", journal.FoundationDhamma)
+	mustSave(t, repo, "2024-05-01", nil, "c", "wistful", journal.FoundationDhamma)
+	mustSave(t, repo, "2024-05-14", nil, "d", "exhausted", journal.FoundationDhamma)
+
+	svc := NewService(repo)
+	weeks, err := svc.MoodTrend(context.Background(), time.Time{}, day("2024-05-15"), Weekly)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(weeks) != 3 {
+		t.Fatalf("expected three weeks, got %+v", weeks)
+	}
+	first := weeks[0]
+	if first.Entries != 3 || first.Rated != 2 || first.Valence != 1.5 || first.Energy != 0.5 {
+		t.Fatalf("unexpected first week: %+v", first)
+	}
+	if weeks[1].Entries != 0 || weeks[2].Valence != -1 || weeks[2].Energy != -2 {
+		t.Fatalf("unexpected later weeks: %+v", weeks[1:])
+	}
+
+	days, err := svc.MoodTrend(context.Background(), day("2024-05-13"), day("2024-05-15"), Daily)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(days) != 3 || days[1].Rated != 1 || !days[1].Start.Equal(day("2024-05-14")) {
+		t.Fatalf("unexpected days: %+v", days)
+	}
+
+	if _, err := svc.MoodTrend(context.Background(), time.Time{}, time.Time{}, "month"); err == nil {
+		t.Fatal("expected an error for an unknown period")
+	}
+}
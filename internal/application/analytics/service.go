@@ -0,0 +1,282 @@
+package analytics
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/thatnerdjosh/mindfulness/internal/domain/journal"
+)
+
+// topMoods is how many of the most frequent moods Stats reports.
+const topMoods = 5
+
+// Stats summarizes the journal over an inclusive range of UTC days.
+type Stats struct {
+	Since time.Time
+	Until time.Time
+
+	Entries     int
+	Quicknotes  int
+	FullEntries int
+	Weeks       []WeekCount
+
+	DaysJournaled int
+	DaysSkipped   int
+	CurrentStreak int
+	LongestStreak int
+	// CurrentStreakEnd is the last day of the current streak, or zero when
+	// there is none.
+	CurrentStreakEnd time.Time
+
+	Precepts     []PreceptCount
+	Foundations  []FoundationShare
+	Moods        []MoodCount
+	AverageWords float64
+}
+
+// WeekCount is the number of entries in the week starting on Monday Start.
+type WeekCount struct {
+	Start   time.Time
+	Entries int
+}
+
+// PreceptCount is how often a precept was reflected on and how long the
+// reflections were.
+type PreceptCount struct {
+	Precept      journal.Precept
+	Reflections  int
+	AverageWords float64
+}
+
+// FoundationShare is the share of entries written with a foundation,
+// counting those written with an object within it. Objects breaks them down
+// by object in the order of the taxonomy; entries with the plain foundation
+// are in none of them.
+type FoundationShare struct {
+	Foundation journal.Foundation
+	Entries    int
+	Share      float64
+	Objects    []ObjectCount
+}
+
+// ObjectCount is how many entries contemplated an object of a foundation.
+type ObjectCount struct {
+	Object  journal.Foundation
+	Entries int
+}
+
+// MoodCount is how many entries recorded a mood.
+type MoodCount struct {
+	Mood    string
+	Entries int
+}
+
+// Service computes statistics over a journal.
+type Service struct {
+	repo  journal.Repository
+	moods journal.MoodVocabulary
+	now   func() time.Time
+}
+
+// Option configures a Service.
+type Option func(*Service)
+
+// WithMoodVocabulary reads moods with vocabulary instead of the built-in one.
+func WithMoodVocabulary(vocabulary journal.MoodVocabulary) Option {
+	return func(s *Service) {
+		s.moods = vocabulary
+	}
+}
+
+func NewService(repo journal.Repository, opts ...Option) *Service {
+	s := &Service{
+		repo:  repo,
+		moods: journal.DefaultMoodVocabulary(),
+		now:   time.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Stats summarizes the entries between since and until, inclusive. A zero
+// since starts at the first entry and a zero until ends today.
+func (s *Service) Stats(ctx context.Context, since time.Time, until time.Time) (Stats, error) {
+	entries, since, until, err := s.window(ctx, since, until)
+	if err != nil {
+		return Stats{}, err
+	}
+	if since.IsZero() {
+		return Stats{Until: until}, nil
+	}
+
+	stats := Stats{Since: since, Until: until, Entries: len(entries)}
+
+	journaled := make(map[time.Time]bool)
+	weeks := make(map[time.Time]int)
+	reflections := make(map[journal.Precept]int)
+	preceptWords := make(map[journal.Precept]int)
+	foundations := make(map[journal.Foundation]int)
+	moods := make(map[string]int)
+	moodLabels := make(map[string]string)
+	totalWords := 0
+	for _, entry := range entries {
+		day := startOfDay(entry.Date)
+		journaled[day] = true
+		weeks[weekStart(day)]++
+
+		if entry.Quicknote {
+			stats.Quicknotes++
+		} else {
+			stats.FullEntries++
+		}
+		for precept, text := range entry.Reflections {
+			words := len(strings.Fields(text))
+			reflections[precept]++
+			preceptWords[precept] += words
+			totalWords += words
+		}
+		if entry.Foundation != "" {
+			foundations[entry.Foundation.Root()]++
+			if entry.Foundation.Object() != "" {
+				foundations[entry.Foundation]++
+			}
+		}
+		if mood := s.moods.Normalize(entry.Mood); mood != "" {
+			key := strings.ToLower(mood)
+			if _, ok := moodLabels[key]; !ok {
+				moodLabels[key] = mood
+			}
+			moods[key]++
+		}
+	}
+
+	for week := weekStart(since); !week.After(until); week = week.AddDate(0, 0, 7) {
+		stats.Weeks = append(stats.Weeks, WeekCount{Start: week, Entries: weeks[week]})
+	}
+
+	streak := 0
+	for day := since; !day.After(until); day = day.AddDate(0, 0, 1) {
+		if journaled[day] {
+			stats.DaysJournaled++
+			streak++
+			if streak > stats.LongestStreak {
+				stats.LongestStreak = streak
+			}
+			continue
+		}
+		stats.DaysSkipped++
+		streak = 0
+	}
+	stats.CurrentStreak, stats.CurrentStreakEnd = currentStreak(journaled, since, until)
+
+	totalReflections := 0
+	for _, info := range journal.AllPrecepts() {
+		count := PreceptCount{Precept: info.ID, Reflections: reflections[info.ID]}
+		if count.Reflections > 0 {
+			count.AverageWords = float64(preceptWords[info.ID]) / float64(count.Reflections)
+		}
+		totalReflections += count.Reflections
+		stats.Precepts = append(stats.Precepts, count)
+	}
+	if totalReflections > 0 {
+		stats.AverageWords = float64(totalWords) / float64(totalReflections)
+	}
+
+	for _, foundation := range journal.Foundations() {
+		share := FoundationShare{Foundation: foundation, Entries: foundations[foundation]}
+		if stats.Entries > 0 {
+			share.Share = float64(share.Entries) / float64(stats.Entries)
+		}
+		for _, object := range journal.FoundationObjects(foundation) {
+			share.Objects = append(share.Objects, ObjectCount{Object: object.ID, Entries: foundations[object.ID]})
+		}
+		stats.Foundations = append(stats.Foundations, share)
+	}
+
+	for key, count := range moods {
+		stats.Moods = append(stats.Moods, MoodCount{Mood: moodLabels[key], Entries: count})
+	}
+	sort.Slice(stats.Moods, func(i, j int) bool {
+		if stats.Moods[i].Entries != stats.Moods[j].Entries {
+			return stats.Moods[i].Entries > stats.Moods[j].Entries
+		}
+		return stats.Moods[i].Mood < stats.Moods[j].Mood
+	})
+	if len(stats.Moods) > topMoods {
+		stats.Moods = stats.Moods[:topMoods]
+	}
+	return stats, nil
+}
+
+// window returns the entries between since and until, inclusive, with the
+// range resolved to whole days: a zero until is today and a zero since is
+// the first entry's day. since stays zero when it was open and no entry
+// falls in the range. Reviews look back on practice rather than record it,
+// so they are left out.
+func (s *Service) window(ctx context.Context, since time.Time, until time.Time) ([]journal.Entry, time.Time, time.Time, error) {
+	if until.IsZero() {
+		until = s.now()
+	}
+	until = startOfDay(until)
+	if !since.IsZero() {
+		since = startOfDay(since)
+		if since.After(until) {
+			return nil, time.Time{}, time.Time{}, fmt.Errorf("since %s is after until %s", since.Format("2006-01-02"), until.Format("2006-01-02"))
+		}
+	}
+
+	all, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, time.Time{}, time.Time{}, err
+	}
+	var entries []journal.Entry
+	var first time.Time
+	for _, entry := range all {
+		day := startOfDay(entry.Date)
+		if entry.Review != nil || (!since.IsZero() && day.Before(since)) || day.After(until) {
+			continue
+		}
+		entries = append(entries, entry)
+		if first.IsZero() || day.Before(first) {
+			first = day
+		}
+	}
+	if since.IsZero() {
+		since = first
+	}
+	return entries, since, until, nil
+}
+
+// currentStreak counts the journaled days running back from until and
+// returns the day the run ends on. A day not journaled yet does not break
+// the streak until it is over, so when until itself has no entry the count
+// starts from the day before.
+func currentStreak(journaled map[time.Time]bool, since time.Time, until time.Time) (int, time.Time) {
+	end := until
+	if !journaled[end] {
+		end = end.AddDate(0, 0, -1)
+	}
+	streak := 0
+	for day := end; !day.Before(since) && journaled[day]; day = day.AddDate(0, 0, -1) {
+		streak++
+	}
+	if streak == 0 {
+		return 0, time.Time{}
+	}
+	return streak, end
+}
+
+func weekStart(day time.Time) time.Time {
+	offset := (int(day.Weekday()) + 6) % 7
+	return day.AddDate(0, 0, -offset)
+}
+
+func startOfDay(date time.Time) time.Time {
+	utc := date.UTC()
+	return time.Date(utc.Year(), utc.Month(), utc.Day(), 0, 0, 0, 0, time.UTC)
+}
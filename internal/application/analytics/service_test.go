@@ -0,0 +1,162 @@
+package analytics
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/thatnerdjosh/mindfulness/internal/domain/journal"
+	"github.com/thatnerdjosh/mindfulness/internal/infrastructure/persistence/memory"
+)
+
+func day(value string) time.Time {
+	parsed, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
+
+func mustSave(t *testing.T, repo journal.Repository, date string, reflections map[journal.Precept]string, note string, mood string, foundation journal.Foundation, opts ...journal.EntryOption) {
+	t.Helper()
+	entry, err := journal.NewEntry(day(date), reflections, note, mood, foundation, day(date), opts...)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := repo.Save(context.Background(), entry); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestServiceStats(t *testing.T) {
+	repo := memory.NewJournalRepository()
+	// Monday 2024-04-29 through Sunday 2024-05-12, journaling 4/29-5/01, 5/03 and 5/09-5/11.
+	mustSave(t, repo, "2024-04-29", map[journal.Precept]string{journal.TrueLove: "called my sister back"}, "", "Calm", journal.FoundationKaya)
+	mustSave(t, repo, "2024-04-30", map[journal.Precept]string{journal.TrueLove: "listened", journal.ReverenceForLife: "walked around the ants"}, "", "calm", journal.FoundationDhamma)
+	mustSave(t, repo, "2024-05-01", nil, "quick thought", "", journal.FoundationDhamma, journal.AsQuicknote())
+	mustSave(t, repo, "2024-05-03", nil, "another", "", journal.FoundationCit, journal.AsQuicknote())
+	mustSave(t, repo, "2024-05-09", nil, "note", "tired", journal.FoundationVedana)
+	mustSave(t, repo, "2024-05-10", nil, "note", "", journal.FoundationDhamma, journal.AsQuicknote())
+	mustSave(t, repo, "2024-05-11", nil, "note", "", journal.FoundationDhamma, journal.AsQuicknote())
+	mustSave(t, repo, "2024-05-20", nil, "outside the range", "", journal.FoundationDhamma)
+
+	svc := NewService(repo)
+	stats, err := svc.Stats(context.Background(), time.Time{}, day("2024-05-12"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !stats.Since.Equal(day("2024-04-29")) || stats.Entries != 7 {
+		t.Fatalf("expected 7 entries from 2024-04-29, got %d from %s", stats.Entries, stats.Since)
+	}
+	if stats.Quicknotes != 4 || stats.FullEntries != 3 {
+		t.Fatalf("expected 4 quicknotes and 3 full entries, got %d and %d", stats.Quicknotes, stats.FullEntries)
+	}
+	if len(stats.Weeks) != 2 || stats.Weeks[0].Entries != 4 || stats.Weeks[1].Entries != 3 {
+		t.Fatalf("unexpected weeks: %+v", stats.Weeks)
+	}
+	if stats.DaysJournaled != 7 || stats.DaysSkipped != 7 {
+		t.Fatalf("expected 7 days journaled and 7 skipped, got %d and %d", stats.DaysJournaled, stats.DaysSkipped)
+	}
+	if stats.LongestStreak != 3 || stats.CurrentStreak != 3 {
+		t.Fatalf("expected longest and current streaks of 3, got %d and %d", stats.LongestStreak, stats.CurrentStreak)
+	}
+	if stats.Precepts[0].Precept != journal.ReverenceForLife || stats.Precepts[0].Reflections != 1 {
+		t.Fatalf("unexpected precept counts: %+v", stats.Precepts)
+	}
+	for _, count := range stats.Precepts {
+		if count.Precept == journal.TrueLove && (count.Reflections != 2 || count.AverageWords != 2.5) {
+			t.Fatalf("unexpected true love count: %+v", count)
+		}
+	}
+	if stats.AverageWords != 3 {
+		t.Fatalf("expected 3 words per reflection, got %v", stats.AverageWords)
+	}
+	if last := stats.Foundations[3]; last.Foundation != journal.FoundationDhamma || last.Entries != 4 {
+		t.Fatalf("unexpected foundations: %+v", stats.Foundations)
+	}
+	if len(stats.Moods) != 2 || stats.Moods[0].Mood != "calm" || stats.Moods[0].Entries != 2 {
+		t.Fatalf("unexpected moods: %+v", stats.Moods)
+	}
+}
+
+func TestServiceStatsRange(t *testing.T) {
+	repo := memory.NewJournalRepository()
+	mustSave(t, repo, "2024-05-01", nil, "one", "", journal.FoundationDhamma)
+	mustSave(t, repo, "2024-05-02", nil, "two", "", journal.FoundationDhamma)
+
+	svc := NewService(repo)
+	svc.now = func() time.Time { return day("2024-05-03").Add(9 * time.Hour) }
+
+	// Today has no entry yet, so yesterday's streak is still current.
+	stats, err := svc.Stats(context.Background(), day("2024-05-02"), time.Time{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats.Entries != 1 || stats.DaysJournaled != 1 || stats.DaysSkipped != 1 || stats.CurrentStreak != 1 || !stats.CurrentStreakEnd.Equal(day("2024-05-02")) {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+
+	if _, err := svc.Stats(context.Background(), day("2024-05-04"), day("2024-05-01")); err == nil {
+		t.Fatal("expected an error when since is after until")
+	}
+
+	empty, err := NewService(memory.NewJournalRepository()).Stats(context.Background(), time.Time{}, day("2024-05-01"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if empty.Entries != 0 || empty.DaysSkipped != 0 || !empty.Since.IsZero() {
+		t.Fatalf("expected empty stats, got %+v", empty)
+	}
+}
+
+func TestServiceStatsFoundationObjects(t *testing.T) {
+	repo := memory.NewJournalRepository()
+	mustSave(t, repo, "2024-05-01", nil, "restless", "", "dhamma.hindrances")
+	mustSave(t, repo, "2024-05-02", nil, "sleepy", "", "dhamma.hindrances")
+	mustSave(t, repo, "2024-05-03", nil, "five heaps", "", "dhamma.aggregates")
+	mustSave(t, repo, "2024-05-04", nil, "plain", "", journal.FoundationDhamma)
+	mustSave(t, repo, "2024-05-05", nil, "in and out", "", "kaya.breath")
+
+	stats, err := NewService(repo).Stats(context.Background(), time.Time{}, day("2024-05-05"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	dhamma := stats.Foundations[3]
+	if dhamma.Foundation != journal.FoundationDhamma || dhamma.Entries != 4 || dhamma.Share != 0.8 {
+		t.Fatalf("expected objects to count toward their foundation, got %+v", dhamma)
+	}
+	if len(dhamma.Objects) != len(journal.FoundationObjects(journal.FoundationDhamma)) ||
+		dhamma.Objects[0] != (ObjectCount{Object: "dhamma.hindrances", Entries: 2}) ||
+		dhamma.Objects[1] != (ObjectCount{Object: "dhamma.aggregates", Entries: 1}) ||
+		dhamma.Objects[2].Entries != 0 {
+		t.Fatalf("unexpected dhamma objects: %+v", dhamma.Objects)
+	}
+	if kaya := stats.Foundations[0]; kaya.Entries != 1 || kaya.Objects[0].Entries != 1 {
+		t.Fatalf("unexpected kaya: %+v", kaya)
+	}
+}
+
+func TestServiceStatsSkipsReviews(t *testing.T) {
+	repo := memory.NewJournalRepository()
+	mustSave(t, repo, "2024-05-01", map[journal.Precept]string{journal.TrueLove: "listened"}, "", "calm", journal.FoundationDhamma)
+	review, err := journal.NewReview(journal.ReviewWeek, day("2024-05-05"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	entry, err := journal.NewEntry(day("2024-05-05"), nil, "a good week", "", "", day("2024-05-05"), journal.WithReview(review))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := repo.Save(context.Background(), entry); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	stats, err := NewService(repo).Stats(context.Background(), day("2024-04-29"), day("2024-05-05"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats.Entries != 1 || stats.DaysJournaled != 1 {
+		t.Fatalf("expected the review to be left out, got %+v", stats)
+	}
+}
@@ -0,0 +1,161 @@
+package analytics
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/thatnerdjosh/mindfulness/internal/domain/journal"
+)
+
+// topVedanaDetails is how many of the most frequent body locations and
+// triggers FeelingTones reports.
+const topVedanaDetails = 5
+
+// ToneCount tallies feeling tones. Intensity averages the rated ones and is
+// zero when none was.
+type ToneCount struct {
+	Pleasant   int
+	Unpleasant int
+	Neutral    int
+	Rated      int
+	Intensity  float64
+}
+
+// Total is the number of feeling tones counted.
+func (c ToneCount) Total() int {
+	return c.Pleasant + c.Unpleasant + c.Neutral
+}
+
+// TonePoint is the feeling tones recorded in the period starting on Start.
+// The first period of a range starts on its first day, even when the week
+// it falls in began earlier.
+type TonePoint struct {
+	Start time.Time
+	ToneCount
+}
+
+// DetailCount is how many feeling tones were noted with a body location or
+// trigger.
+type DetailCount struct {
+	Detail  string
+	Entries int
+}
+
+// FeelingTones is the distribution of feeling tones over a range of days.
+type FeelingTones struct {
+	Since     time.Time
+	Until     time.Time
+	Points    []TonePoint
+	Total     ToneCount
+	Locations []DetailCount
+	Triggers  []DetailCount
+}
+
+// FeelingTones tallies the feeling tones recorded between since and until,
+// inclusive, per period, with the most frequent body locations and triggers.
+// Every period in the range has a point, so gaps show, and none starts
+// before since.
+func (s *Service) FeelingTones(ctx context.Context, since time.Time, until time.Time, period Period) (FeelingTones, error) {
+	if err := period.validate(); err != nil {
+		return FeelingTones{}, err
+	}
+	entries, since, until, err := s.window(ctx, since, until)
+	if err != nil || since.IsZero() {
+		return FeelingTones{}, err
+	}
+
+	report := FeelingTones{Since: since, Until: until}
+	index := make(map[time.Time]int)
+	for _, start := range period.starts(since, until) {
+		index[start] = len(report.Points)
+		if start.Before(since) {
+			start = since
+		}
+		report.Points = append(report.Points, TonePoint{Start: start})
+	}
+
+	locations := newDetailTally()
+	triggers := newDetailTally()
+	for _, entry := range entries {
+		if entry.Vedana == nil {
+			continue
+		}
+		vedana := *entry.Vedana
+		point := &report.Points[index[period.start(entry.Date)]]
+		point.add(vedana)
+		report.Total.add(vedana)
+		locations.add(vedana.Location)
+		triggers.add(vedana.Trigger)
+	}
+	for i := range report.Points {
+		report.Points[i].average()
+	}
+	report.Total.average()
+	report.Locations = locations.top()
+	report.Triggers = triggers.top()
+	return report, nil
+}
+
+// add counts vedana, keeping the intensity sum until average is called.
+func (c *ToneCount) add(vedana journal.Vedana) {
+	switch vedana.Tone {
+	case journal.TonePleasant:
+		c.Pleasant++
+	case journal.ToneUnpleasant:
+		c.Unpleasant++
+	case journal.ToneNeutral:
+		c.Neutral++
+	}
+	if vedana.Intensity > 0 {
+		c.Rated++
+		c.Intensity += float64(vedana.Intensity)
+	}
+}
+
+func (c *ToneCount) average() {
+	if c.Rated > 0 {
+		c.Intensity /= float64(c.Rated)
+	}
+}
+
+// detailTally counts free-text details case-insensitively, labelling each by
+// how it was first written.
+type detailTally struct {
+	counts map[string]int
+	labels map[string]string
+}
+
+func newDetailTally() detailTally {
+	return detailTally{counts: make(map[string]int), labels: make(map[string]string)}
+}
+
+func (t detailTally) add(detail string) {
+	detail = strings.TrimSpace(detail)
+	if detail == "" {
+		return
+	}
+	key := strings.ToLower(detail)
+	if _, ok := t.labels[key]; !ok {
+		t.labels[key] = detail
+	}
+	t.counts[key]++
+}
+
+func (t detailTally) top() []DetailCount {
+	list := make([]DetailCount, 0, len(t.counts))
+	for key, count := range t.counts {
+		list = append(list, DetailCount{Detail: t.labels[key], Entries: count})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Entries != list[j].Entries {
+			return list[i].Entries > list[j].Entries
+		}
+		return list[i].Detail < list[j].Detail
+	})
+	if len(list) > topVedanaDetails {
+		list = list[:topVedanaDetails]
+	}
+	return list
+}
@@ -0,0 +1,79 @@
+package analytics
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/thatnerdjosh/mindfulness/internal/domain/journal"
+	"github.com/thatnerdjosh/mindfulness/internal/infrastructure/persistence/memory"
+)
+
+func TestServiceFeelingTones(t *testing.T) {
+	repo := memory.NewJournalRepository()
+	for _, item := range []struct {
+		date      string
+		tone      journal.Tone
+		intensity int
+		location  string
+		trigger   string
+	}{
+		{"2024-04-29", journal.ToneUnpleasant, 4, "Chest", "email"},
+		{"2024-04-30", journal.TonePleasant, 2, "chest", ""},
+		{"2024-05-01", journal.ToneNeutral, 0, "", "walking"},
+		{"2024-05-14", journal.ToneUnpleasant, 3, "jaw", "email"},
+	} {
+		vedana, err := journal.NewVedana(item.tone, item.intensity, item.location, item.trigger)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		at := day(item.date)
+		entry, err := journal.NewEntry(at, nil, "noticed", "", journal.FoundationVedana, at, journal.WithVedana(vedana))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := repo.Save(context.Background(), entry); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	mustSave(t, repo, "2024-05-02", nil, "no tone", "", journal.FoundationVedana)
+
+	report, err := NewService(repo).FeelingTones(context.Background(), time.Time{}, day("2024-05-15"), Weekly)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(report.Points) != 3 || !report.Since.Equal(day("2024-04-29")) {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	first := report.Points[0]
+	if first.Pleasant != 1 || first.Unpleasant != 1 || first.Neutral != 1 || first.Rated != 2 || first.Intensity != 3 {
+		t.Fatalf("unexpected first week: %+v", first)
+	}
+	if report.Points[1].Total() != 0 || report.Points[2].Unpleasant != 1 {
+		t.Fatalf("unexpected later weeks: %+v", report.Points[1:])
+	}
+	if report.Total.Total() != 4 || report.Total.Unpleasant != 2 || report.Total.Intensity != 3 {
+		t.Fatalf("unexpected total: %+v", report.Total)
+	}
+	if len(report.Locations) != 2 || report.Locations[0] != (DetailCount{Detail: "Chest", Entries: 2}) {
+		t.Fatalf("unexpected locations: %+v", report.Locations)
+	}
+	if len(report.Triggers) != 2 || report.Triggers[0] != (DetailCount{Detail: "email", Entries: 2}) {
+		t.Fatalf("unexpected triggers: %+v", report.Triggers)
+	}
+
+	report, err = NewService(repo).FeelingTones(context.Background(), day("2024-05-01"), day("2024-05-15"), Weekly)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(report.Points) != 3 || !report.Points[0].Start.Equal(day("2024-05-01")) || !report.Points[1].Start.Equal(day("2024-05-06")) {
+		t.Fatalf("expected the first week to start on since: %+v", report.Points)
+	}
+	if report.Points[0].Total() != 1 || report.Points[0].Neutral != 1 {
+		t.Fatalf("unexpected partial week: %+v", report.Points[0])
+	}
+
+	if _, err := NewService(repo).FeelingTones(context.Background(), time.Time{}, time.Time{}, "month"); err == nil {
+		t.Fatal("expected an error for an unknown period")
+	}
+}
@@ -0,0 +1,142 @@
+package checkin
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	adherenceapp "github.com/thatnerdjosh/mindfulness/internal/application/adherence"
+	journalapp "github.com/thatnerdjosh/mindfulness/internal/application/journal"
+	"github.com/thatnerdjosh/mindfulness/internal/application/unitofwork"
+	"github.com/thatnerdjosh/mindfulness/internal/domain/adherence"
+	"github.com/thatnerdjosh/mindfulness/internal/domain/journal"
+)
+
+// CheckIn is what a daily check-in records: adherence changes with their
+// notes and a journal entry for the day. Vedana is only valid with the
+// vedana foundation; Questions holds the questions the reflections answered.
+// BeginningAnew holds the first steps of Beginning Anew for precepts the
+// check-in marks as broken.
+type CheckIn struct {
+	Date          time.Time
+	Adherence     adherence.Adherence
+	Notes         map[journal.Precept]string
+	BeginningAnew map[journal.Precept]adherence.BeginningAnew
+	Mood          string
+	Foundation    journal.Foundation
+	Vedana        *journal.Vedana
+	Note          string
+	Reflections   map[journal.Precept]string
+	Questions     map[journal.Precept]string
+}
+
+// Result is what a check-in stored.
+type Result struct {
+	Changes       []adherence.AdherenceLogEntry
+	Entry         journal.Entry
+	BeginningAnew []adherence.BeginningAnew
+}
+
+// Service records check-ins.
+type Service struct {
+	uow       unitofwork.UnitOfWork
+	journal   *journalapp.Service
+	adherence *adherenceapp.Service
+}
+
+func NewService(uow unitofwork.UnitOfWork, journalSvc *journalapp.Service, adherenceSvc *adherenceapp.Service) *Service {
+	return &Service{
+		uow:       uow,
+		journal:   journalSvc,
+		adherence: adherenceSvc,
+	}
+}
+
+// Moods returns the vocabulary the check-in's mood is normalized to.
+func (s *Service) Moods() journal.MoodVocabulary {
+	return s.journal.Moods()
+}
+
+// Questions picks the reflection questions for a check-in on date written
+// with foundation.
+func (s *Service) Questions(ctx context.Context, date time.Time, foundation journal.Foundation) (map[journal.Precept]string, error) {
+	return s.journal.Questions(ctx, date, foundation)
+}
+
+// Record applies the adherence changes, journals the day and starts
+// Beginning Anew for the breaks as one unit of work, so either all are
+// stored or none is. A change's note stands in as the reflection for its
+// precept when none was given, and the entry links every logged change.
+func (s *Service) Record(ctx context.Context, checkIn CheckIn) (Result, error) {
+	if !hasText(checkIn) {
+		return Result{}, journal.ErrEmptyEntry
+	}
+
+	var result Result
+	err := s.uow.Do(ctx, func(journalRepo journal.Repository, adherenceRepo adherence.Repository, renewals adherence.BeginningAnewRepository) error {
+		adherenceSvc := s.adherence.Within(adherenceRepo, renewals)
+		changes, err := adherenceSvc.Set(ctx, checkIn.Adherence, checkIn.Notes)
+		if err != nil {
+			return err
+		}
+
+		reflections := make(map[journal.Precept]string, len(checkIn.Reflections))
+		for precept, reflection := range checkIn.Reflections {
+			reflections[precept] = reflection
+		}
+		fingerprints := make([]string, 0, len(changes))
+		for _, change := range changes {
+			fingerprints = append(fingerprints, change.Fingerprint())
+			if strings.TrimSpace(reflections[change.Precept]) == "" && strings.TrimSpace(change.Note) != "" {
+				reflections[change.Precept] = change.Note
+			}
+		}
+
+		opts := []journal.EntryOption{journal.WithAdherenceEvents(fingerprints...), journal.WithQuestions(checkIn.Questions)}
+		if checkIn.Vedana != nil {
+			opts = append(opts, journal.WithVedana(*checkIn.Vedana))
+		}
+		entry, err := s.journal.Within(journalRepo).RecordEntry(ctx, checkIn.Date, reflections, checkIn.Note, checkIn.Mood, checkIn.Foundation, opts...)
+		if err != nil {
+			return err
+		}
+		result = Result{Changes: changes, Entry: entry}
+
+		for _, change := range changes {
+			reflection, ok := checkIn.BeginningAnew[change.Precept]
+			if !ok || !adherence.IsBreak(change) {
+				continue
+			}
+			record, err := adherenceSvc.BeginAnew(ctx, change, reflection)
+			if err != nil {
+				return err
+			}
+			result.BeginningAnew = append(result.BeginningAnew, record)
+		}
+		return nil
+	})
+	if err != nil {
+		return Result{}, err
+	}
+	return result, nil
+}
+
+// hasText reports whether the check-in has anything to journal. Notes only
+// count for precepts that change, but whether they do is only known inside
+// the unit of work; an unchanged precept's note leaves the entry empty there.
+func hasText(checkIn CheckIn) bool {
+	if strings.TrimSpace(checkIn.Note) != "" {
+		return true
+	}
+	for _, text := range checkIn.Reflections {
+		if strings.TrimSpace(text) != "" {
+			return true
+		}
+	}
+	for _, text := range checkIn.Notes {
+		if strings.TrimSpace(text) != "" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,187 @@
+package checkin
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	adherenceapp "github.com/thatnerdjosh/mindfulness/internal/application/adherence"
+	journalapp "github.com/thatnerdjosh/mindfulness/internal/application/journal"
+	"github.com/thatnerdjosh/mindfulness/internal/application/unitofwork"
+	"github.com/thatnerdjosh/mindfulness/internal/domain/adherence"
+	"github.com/thatnerdjosh/mindfulness/internal/domain/journal"
+	"github.com/thatnerdjosh/mindfulness/internal/infrastructure/persistence/memory"
+)
+
+// rollbackUnitOfWork restores the journal and adherence repositories when fn
+// fails, as a store that commits a unit of work as a whole would.
+type rollbackUnitOfWork struct {
+	journalRepo   journal.Repository
+	adherenceRepo *memory.AdherenceRepository
+	renewalRepo   adherence.BeginningAnewRepository
+}
+
+func (u rollbackUnitOfWork) Do(ctx context.Context, fn func(journal.Repository, adherence.Repository, adherence.BeginningAnewRepository) error) error {
+	entries, _ := u.journalRepo.List(ctx)
+	state, _ := u.adherenceRepo.Get(ctx)
+	log, _ := u.adherenceRepo.Log(ctx)
+	if err := fn(u.journalRepo, u.adherenceRepo, u.renewalRepo); err != nil {
+		_ = u.journalRepo.Replace(ctx, entries)
+		_ = u.adherenceRepo.Save(ctx, state)
+		_ = u.adherenceRepo.ReplaceLog(ctx, log)
+		return err
+	}
+	return nil
+}
+
+type failingJournalRepo struct {
+	*memory.JournalRepository
+}
+
+func (failingJournalRepo) Save(context.Context, journal.Entry) error {
+	return errors.New("disk full")
+}
+
+type failingRenewalRepo struct {
+	*memory.BeginningAnewRepository
+}
+
+func (failingRenewalRepo) Save(context.Context, adherence.BeginningAnew) error {
+	return errors.New("disk full")
+}
+
+func TestServiceRecord(t *testing.T) {
+	ctx := context.Background()
+	journalRepo := memory.NewJournalRepository()
+	adherenceRepo := memory.NewAdherenceRepository()
+	listened := 0
+	adherenceSvc := adherenceapp.NewService(adherenceRepo, adherenceapp.WithListener(func(context.Context, journal.Repository, []adherence.AdherenceLogEntry) error {
+		listened++
+		return nil
+	}))
+	svc := NewService(unitofwork.Direct(journalRepo, adherenceRepo, nil), journalapp.NewService(journalRepo), adherenceSvc)
+
+	date := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	result, err := svc.Record(ctx, CheckIn{
+		Date:        date,
+		Adherence:   adherence.Adherence{journal.TrueLove: false, journal.ReverenceForLife: false},
+		Notes:       map[journal.Precept]string{journal.TrueLove: "distracted with a friend", journal.ReverenceForLife: "swatted a fly"},
+		Mood:        "tired",
+		Reflections: map[journal.Precept]string{journal.ReverenceForLife: "I can be gentler"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Changes) != 2 {
+		t.Fatalf("expected two changes, got %d", len(result.Changes))
+	}
+	entry := result.Entry
+	if entry.Reflections[journal.TrueLove] != "distracted with a friend" || entry.Reflections[journal.ReverenceForLife] != "I can be gentler" {
+		t.Fatalf("unexpected reflections: %+v", entry.Reflections)
+	}
+	if len(entry.AdherenceEvents) != 2 || entry.AdherenceEvents[0] != result.Changes[0].Fingerprint() {
+		t.Fatalf("expected the entry to link both changes, got %v", entry.AdherenceEvents)
+	}
+	if listened != 0 {
+		t.Fatal("expected the check-in to journal the changes itself rather than through listeners")
+	}
+	entries, _ := journalRepo.List(ctx)
+	if len(entries) != 1 || entries[0].Mood != "tired" {
+		t.Fatalf("expected one stored entry, got %+v", entries)
+	}
+}
+
+func TestServiceRecordStoresNothingOnFailure(t *testing.T) {
+	ctx := context.Background()
+	journalRepo := failingJournalRepo{memory.NewJournalRepository()}
+	adherenceRepo := memory.NewAdherenceRepository()
+	uow := rollbackUnitOfWork{journalRepo: journalRepo, adherenceRepo: adherenceRepo}
+	svc := NewService(uow, journalapp.NewService(journalRepo), adherenceapp.NewService(adherenceRepo))
+
+	_, err := svc.Record(ctx, CheckIn{
+		Date:      time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+		Adherence: adherence.Adherence{journal.TrueLove: false},
+		Note:      "a long day",
+	})
+	if err == nil {
+		t.Fatal("expected the journal failure to fail the check-in")
+	}
+	state, _ := adherenceRepo.Get(ctx)
+	log, _ := adherenceRepo.Log(ctx)
+	if !state[journal.TrueLove] || len(adherence.Changes(log)) != 0 {
+		t.Fatalf("expected the adherence change to be rolled back, got %v with %d change(s)", state, len(adherence.Changes(log)))
+	}
+}
+
+func TestServiceRecordRequiresText(t *testing.T) {
+	journalRepo := memory.NewJournalRepository()
+	adherenceRepo := memory.NewAdherenceRepository()
+	svc := NewService(unitofwork.Direct(journalRepo, adherenceRepo, nil), journalapp.NewService(journalRepo), adherenceapp.NewService(adherenceRepo))
+
+	_, err := svc.Record(context.Background(), CheckIn{
+		Date:      time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+		Adherence: adherence.Adherence{journal.TrueLove: false},
+		Mood:      "calm",
+	})
+	if !errors.Is(err, journal.ErrEmptyEntry) {
+		t.Fatalf("expected ErrEmptyEntry, got %v", err)
+	}
+	if state, _ := adherenceRepo.Get(context.Background()); !state[journal.TrueLove] {
+		t.Fatal("expected adherence to be left alone")
+	}
+}
+
+func TestServiceRecordBeginsAnew(t *testing.T) {
+	ctx := context.Background()
+	journalRepo := memory.NewJournalRepository()
+	adherenceRepo := memory.NewAdherenceRepository()
+	renewals := memory.NewBeginningAnewRepository()
+	adherenceSvc := adherenceapp.NewService(adherenceRepo, adherenceapp.WithBeginningAnew(renewals))
+	svc := NewService(unitofwork.Direct(journalRepo, adherenceRepo, renewals), journalapp.NewService(journalRepo), adherenceSvc)
+
+	result, err := svc.Record(ctx, CheckIn{
+		Date:          time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+		Adherence:     adherence.Adherence{journal.TrueLove: false, journal.TrueHappiness: false},
+		Notes:         map[journal.Precept]string{journal.TrueLove: "snapped at a friend"},
+		BeginningAnew: map[journal.Precept]adherence.BeginningAnew{journal.TrueLove: {Intention: "listen fully"}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.BeginningAnew) != 1 || result.BeginningAnew[0].Intention != "listen fully" || result.BeginningAnew[0].Break != result.Changes[1].Fingerprint() {
+		t.Fatalf("expected Beginning Anew to start for the true love break, got %+v", result.BeginningAnew)
+	}
+	if stored, _ := renewals.List(ctx); len(stored) != 1 {
+		t.Fatalf("expected one stored record, got %+v", stored)
+	}
+}
+
+func TestServiceRecordStoresNothingWhenBeginningAnewFails(t *testing.T) {
+	ctx := context.Background()
+	journalRepo := memory.NewJournalRepository()
+	adherenceRepo := memory.NewAdherenceRepository()
+	renewals := failingRenewalRepo{memory.NewBeginningAnewRepository()}
+	uow := rollbackUnitOfWork{journalRepo: journalRepo, adherenceRepo: adherenceRepo, renewalRepo: renewals}
+	adherenceSvc := adherenceapp.NewService(adherenceRepo, adherenceapp.WithUnitOfWork(uow), adherenceapp.WithBeginningAnew(renewals))
+	svc := NewService(uow, journalapp.NewService(journalRepo), adherenceSvc)
+
+	_, err := svc.Record(ctx, CheckIn{
+		Date:          time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+		Adherence:     adherence.Adherence{journal.TrueLove: false},
+		Notes:         map[journal.Precept]string{journal.TrueLove: "snapped at a friend"},
+		BeginningAnew: map[journal.Precept]adherence.BeginningAnew{journal.TrueLove: {Intention: "listen fully"}},
+		Note:          "a long day",
+	})
+	if err == nil {
+		t.Fatal("expected the Beginning Anew failure to fail the check-in")
+	}
+	state, _ := adherenceRepo.Get(ctx)
+	log, _ := adherenceRepo.Log(ctx)
+	if !state[journal.TrueLove] || len(adherence.Changes(log)) != 0 {
+		t.Fatalf("expected the adherence change to be rolled back, got %v with %d change(s)", state, len(adherence.Changes(log)))
+	}
+	if entries, _ := journalRepo.List(ctx); len(entries) != 0 {
+		t.Fatalf("expected no journal entry, got %+v", entries)
+	}
+}
@@ -0,0 +1,153 @@
+package journal
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/thatnerdjosh/mindfulness/internal/domain/adherence"
+	"github.com/thatnerdjosh/mindfulness/internal/domain/journal"
+)
+
+// NudgePolicy weighs how much each precept needs attention in guided
+// journaling. A precept scores DayWeight for every day since it was last
+// reflected on, counting from the first entry when it never was, and
+// BreakWeight for a break not reflected on since, fading to nothing over
+// BreakDays. Precepts quiet for QuietDays or more are explained.
+type NudgePolicy struct {
+	DayWeight   float64
+	BreakWeight float64
+	BreakDays   int
+	QuietDays   int
+	// Disabled keeps the precepts in their usual order without
+	// explanations.
+	Disabled bool
+}
+
+// DefaultNudgePolicy returns the built-in weights: a fresh break counts as
+// much as a month without reflection.
+func DefaultNudgePolicy() NudgePolicy {
+	return NudgePolicy{
+		DayWeight:   1,
+		BreakWeight: 30,
+		BreakDays:   14,
+		QuietDays:   7,
+	}
+}
+
+// WithNudgePolicy orders guided prompts with policy instead of the built-in
+// one.
+func WithNudgePolicy(policy NudgePolicy) Option {
+	return func(s *Service) {
+		s.nudges = policy
+	}
+}
+
+// Nudge is a precept's place in guided journaling. Reason explains why it
+// needs attention and is empty when it does not.
+type Nudge struct {
+	Precept journal.Precept
+	Score   float64
+	// DaysQuiet is the number of days since the precept was last reflected
+	// on, or since the first entry when it never was.
+	DaysQuiet int
+	// Broken is when the precept was last marked broken without a
+	// reflection since, or zero.
+	Broken time.Time
+	Reason string
+}
+
+// Nudges orders the precepts for guided journaling on date, those needing
+// attention most first. log is the adherence log the breaks are read from.
+// Equal scores keep the usual order.
+func (s *Service) Nudges(ctx context.Context, date time.Time, log []adherence.AdherenceLogEntry) ([]Nudge, error) {
+	nudges := make([]Nudge, 0, len(journal.AllPrecepts()))
+	if s.nudges.Disabled {
+		for _, info := range journal.AllPrecepts() {
+			nudges = append(nudges, Nudge{Precept: info.ID})
+		}
+		return nudges, nil
+	}
+
+	entries, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	day := startOfDay(date)
+	first := day
+	lastReflected := make(map[journal.Precept]time.Time)
+	for _, entry := range entries {
+		if entry.Review != nil || entry.Date.After(day) {
+			continue
+		}
+		if entry.Date.Before(first) {
+			first = entry.Date
+		}
+		for precept := range entry.Reflections {
+			if entry.Date.After(lastReflected[precept]) {
+				lastReflected[precept] = entry.Date
+			}
+		}
+	}
+	lastBroken := make(map[journal.Precept]time.Time)
+	for _, event := range log {
+		if event.Kind == adherence.EventChange && event.From && !event.To && event.At.After(lastBroken[event.Precept]) && !startOfDay(event.At).After(day) {
+			lastBroken[event.Precept] = event.At
+		}
+	}
+
+	for _, info := range journal.AllPrecepts() {
+		nudge := Nudge{Precept: info.ID}
+		last, reflected := lastReflected[info.ID]
+		if !reflected {
+			last = first
+		}
+		nudge.DaysQuiet = int(day.Sub(last).Hours() / 24)
+		nudge.Score = s.nudges.DayWeight * float64(nudge.DaysQuiet)
+
+		var brokenAgo string
+		if broken := lastBroken[info.ID]; !broken.IsZero() && (!reflected || startOfDay(broken).After(last)) {
+			age := int(day.Sub(startOfDay(broken)).Hours() / 24)
+			if age < s.nudges.BreakDays {
+				nudge.Broken = broken
+				nudge.Score += s.nudges.BreakWeight * float64(s.nudges.BreakDays-age) / float64(s.nudges.BreakDays)
+				brokenAgo = daysAgo(age)
+			}
+		}
+		nudge.Reason = nudgeReason(info.Title, brokenAgo, nudge.DaysQuiet >= s.nudges.QuietDays && nudge.DaysQuiet > 0, reflected, nudge.DaysQuiet)
+		nudges = append(nudges, nudge)
+	}
+	sort.SliceStable(nudges, func(i, j int) bool {
+		return nudges[i].Score > nudges[j].Score
+	})
+	return nudges, nil
+}
+
+// nudgeReason explains a nudge in a short phrase, such as "no reflection on
+// True Love in 12 days".
+func nudgeReason(title string, brokenAgo string, quiet bool, reflected bool, days int) string {
+	switch {
+	case brokenAgo != "" && quiet:
+		return fmt.Sprintf("%s marked broken %s, no reflection on it in %d days", title, brokenAgo, days)
+	case brokenAgo != "":
+		return fmt.Sprintf("%s marked broken %s", title, brokenAgo)
+	case quiet && reflected:
+		return fmt.Sprintf("no reflection on %s in %d days", title, days)
+	case quiet:
+		return fmt.Sprintf("no reflection on %s in %d days of journaling", title, days)
+	default:
+		return ""
+	}
+}
+
+func daysAgo(days int) string {
+	switch days {
+	case 0:
+		return "today"
+	case 1:
+		return "yesterday"
+	default:
+		return fmt.Sprintf("%d days ago", days)
+	}
+}
@@ -0,0 +1,92 @@
+package journal
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/thatnerdjosh/mindfulness/internal/domain/adherence"
+	"github.com/thatnerdjosh/mindfulness/internal/domain/journal"
+	"github.com/thatnerdjosh/mindfulness/internal/infrastructure/persistence/memory"
+)
+
+func TestServiceNudges(t *testing.T) {
+	repo := memory.NewJournalRepository()
+	svc := NewService(repo)
+	day := time.Date(2024, 5, 20, 0, 0, 0, 0, time.UTC)
+	all := make(map[journal.Precept]string)
+	for _, info := range journal.AllPrecepts() {
+		all[info.ID] = "reflected"
+	}
+	if _, err := svc.RecordEntry(context.Background(), day.AddDate(0, 0, -19), all, "", "", journal.FoundationDhamma); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	delete(all, journal.TrueLove)
+	if _, err := svc.RecordEntry(context.Background(), day.AddDate(0, 0, -2), all, "", "", journal.FoundationDhamma); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	log := []adherence.AdherenceLogEntry{
+		{At: day.AddDate(0, 0, -1).Add(9 * time.Hour), Precept: journal.TrueHappiness, From: true, To: false},
+		{At: day.AddDate(0, 0, -10), Precept: journal.NourishmentAndHealing, From: true, To: false},
+	}
+
+	nudges, err := svc.Nudges(context.Background(), day, log)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []struct {
+		precept journal.Precept
+		reason  string
+	}{
+		{journal.TrueHappiness, "True Happiness marked broken yesterday"},
+		{journal.TrueLove, "no reflection on True Love in 19 days"},
+		{journal.ReverenceForLife, ""},
+		{journal.LovingSpeechDeepListening, ""},
+		{journal.NourishmentAndHealing, ""},
+	}
+	if len(nudges) != len(want) {
+		t.Fatalf("expected %d nudges, got %+v", len(want), nudges)
+	}
+	for i, w := range want {
+		if nudges[i].Precept != w.precept || nudges[i].Reason != w.reason {
+			t.Fatalf("nudge %d: expected %s %q, got %s %q", i, w.precept, w.reason, nudges[i].Precept, nudges[i].Reason)
+		}
+	}
+	if nudges[1].DaysQuiet != 19 || !nudges[4].Broken.IsZero() {
+		t.Fatalf("expected a break reflected on since to be ignored, got %+v", nudges)
+	}
+
+	quiet := NewService(repo, WithNudgePolicy(NudgePolicy{DayWeight: 1, BreakWeight: 0, BreakDays: 14, QuietDays: 30}))
+	nudges, err = quiet.Nudges(context.Background(), day, log)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if nudges[0].Precept != journal.TrueLove || nudges[2].Reason != "True Happiness marked broken yesterday" || nudges[0].Reason != "" {
+		t.Fatalf("expected the policy weights to apply, got %+v", nudges)
+	}
+}
+
+func TestServiceNudgesKeepUsualOrder(t *testing.T) {
+	day := time.Date(2024, 5, 20, 0, 0, 0, 0, time.UTC)
+	fresh := NewService(memory.NewJournalRepository())
+	disabled := NewService(memory.NewJournalRepository(), WithNudgePolicy(NudgePolicy{Disabled: true}))
+	log := []adherence.AdherenceLogEntry{{At: day, Precept: journal.TrueLove, From: true, To: false}}
+
+	for name, tc := range map[string]struct {
+		svc *Service
+		log []adherence.AdherenceLogEntry
+	}{
+		"fresh journal": {fresh, nil},
+		"disabled":      {disabled, log},
+	} {
+		nudges, err := tc.svc.Nudges(context.Background(), day, tc.log)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		for i, info := range journal.AllPrecepts() {
+			if nudges[i].Precept != info.ID || nudges[i].Reason != "" {
+				t.Fatalf("%s: expected the usual order without reasons, got %+v", name, nudges)
+			}
+		}
+	}
+}
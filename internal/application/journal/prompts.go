@@ -0,0 +1,47 @@
+package journal
+
+import (
+	"context"
+	"time"
+
+	"github.com/thatnerdjosh/mindfulness/internal/domain/journal"
+)
+
+// recentQuestionDays is how many days back a question counts as recently
+// asked.
+const recentQuestionDays = 7
+
+// Questions picks the reflection question to ask about each precept in an
+// entry for date written with foundation. Questions answered in the week
+// before date, or on date itself, are avoided, and no question is asked
+// about two precepts at once. Precepts the bank has no question for are left
+// out.
+func (s *Service) Questions(ctx context.Context, date time.Time, foundation journal.Foundation) (map[journal.Precept]string, error) {
+	entries, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	day := startOfDay(date)
+	since := day.AddDate(0, 0, -recentQuestionDays)
+	recent := make(map[string]bool)
+	for _, entry := range entries {
+		if entry.Date.Before(since) || entry.Date.After(day) {
+			continue
+		}
+		for _, question := range entry.Questions {
+			recent[question] = true
+		}
+	}
+
+	questions := make(map[journal.Precept]string)
+	for _, info := range journal.AllPrecepts() {
+		question := s.prompts.Pick(day, info.ID, foundation, recent)
+		if question == "" {
+			continue
+		}
+		questions[info.ID] = question
+		recent[question] = true
+	}
+	return questions, nil
+}
@@ -0,0 +1,58 @@
+package journal
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/thatnerdjosh/mindfulness/internal/domain/journal"
+	"github.com/thatnerdjosh/mindfulness/internal/infrastructure/persistence/memory"
+)
+
+func TestServiceQuestions(t *testing.T) {
+	bank, err := journal.NewPromptBank([]journal.Prompt{
+		{Precept: journal.TrueLove, Text: "a"},
+		{Precept: journal.TrueLove, Text: "b"},
+		{Foundation: journal.FoundationKaya, Text: "body"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	repo := memory.NewJournalRepository()
+	svc := NewService(repo, WithPromptBank(bank))
+	day := time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC)
+
+	questions, err := svc.Questions(context.Background(), day, journal.FoundationKaya)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(questions) != 5 || questions[journal.TrueHappiness] != "body" {
+		t.Fatalf("expected a question for every precept, got %+v", questions)
+	}
+	if love := questions[journal.TrueLove]; love != "a" && love != "b" {
+		t.Fatalf("expected the precept's own question first, got %+v", questions)
+	}
+
+	asked := questions[journal.TrueLove]
+	if _, err := svc.RecordEntry(context.Background(), day.AddDate(0, 0, -3), map[journal.Precept]string{journal.TrueLove: "called home"}, "", "", journal.FoundationDhamma, journal.WithQuestions(map[journal.Precept]string{journal.TrueLove: asked})); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	questions, err = svc.Questions(context.Background(), day, journal.FoundationDhamma)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if questions[journal.TrueLove] == asked || questions[journal.TrueLove] == "" {
+		t.Fatalf("expected a question other than the recent %q, got %+v", asked, questions)
+	}
+	if _, ok := questions[journal.TrueHappiness]; ok {
+		t.Fatalf("expected no question without candidates, got %+v", questions)
+	}
+
+	later, err := svc.Questions(context.Background(), day.AddDate(0, 0, 10), journal.FoundationDhamma)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if later[journal.TrueLove] == "" {
+		t.Fatalf("expected a question once the old one is no longer recent, got %+v", later)
+	}
+}
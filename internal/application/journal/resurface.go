@@ -0,0 +1,134 @@
+package journal
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/thatnerdjosh/mindfulness/internal/domain/journal"
+)
+
+// Resurfaced is a past entry brought back to be re-read. Ago says how long
+// before the day it was written, as in "1 year ago", for entries from this
+// day in an earlier month or year; it is empty for entries that were due.
+type Resurfaced struct {
+	Entry journal.Entry
+	Ago   string
+	Due   time.Time
+}
+
+// Resurfacing is what resurfaces on one day.
+type Resurfacing struct {
+	OnThisDay []Resurfaced
+	Due       []Resurfaced
+}
+
+// Entries lists the resurfaced entries, those from this day first.
+func (r Resurfacing) Entries() []Resurfaced {
+	return append(append([]Resurfaced{}, r.OnThisDay...), r.Due...)
+}
+
+// Resurface returns the entries written exactly one month, one year and each
+// further year before date, latest first, and up to limit other entries due
+// for re-reading, most overdue first. An entry is first due a month after it
+// was written and then at growing intervals after each revisit. A limit of
+// zero or less returns every due entry.
+func (s *Service) Resurface(ctx context.Context, date time.Time, limit int) (Resurfacing, error) {
+	entries, err := s.repo.List(ctx)
+	if err != nil {
+		return Resurfacing{}, err
+	}
+	revisits, err := s.revisitsByEntry(ctx)
+	if err != nil {
+		return Resurfacing{}, err
+	}
+
+	day := startOfDay(date)
+	var result Resurfacing
+	shown := make(map[string]bool)
+	for _, back := range onThisDayOffsets(day, entries) {
+		for _, entry := range entries {
+			if entry.Date.Equal(back.day) {
+				result.OnThisDay = append(result.OnThisDay, Resurfaced{Entry: entry, Ago: back.label})
+				shown[entry.Fingerprint()] = true
+			}
+		}
+	}
+
+	end := day.AddDate(0, 0, 1)
+	for _, entry := range entries {
+		fingerprint := entry.Fingerprint()
+		if shown[fingerprint] {
+			continue
+		}
+		if due := journal.DueAt(entry, revisits[fingerprint]); due.Before(end) {
+			result.Due = append(result.Due, Resurfaced{Entry: entry, Due: due})
+		}
+	}
+	sort.SliceStable(result.Due, func(i, j int) bool {
+		if !result.Due[i].Due.Equal(result.Due[j].Due) {
+			return result.Due[i].Due.Before(result.Due[j].Due)
+		}
+		return result.Due[i].Entry.Timestamp.Before(result.Due[j].Entry.Timestamp)
+	})
+	if limit > 0 && len(result.Due) > limit {
+		result.Due = result.Due[:limit]
+	}
+	return result, nil
+}
+
+// MarkRevisited records that entries were re-read now. Without a revisit
+// store nothing is recorded, so the same entries stay due.
+func (s *Service) MarkRevisited(ctx context.Context, entries ...journal.Entry) error {
+	if s.revisits == nil {
+		return nil
+	}
+	now := s.now()
+	for _, entry := range entries {
+		if err := s.revisits.Record(ctx, entry.Fingerprint(), now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Service) revisitsByEntry(ctx context.Context) (map[string]journal.Revisit, error) {
+	byEntry := make(map[string]journal.Revisit)
+	if s.revisits == nil {
+		return byEntry, nil
+	}
+	revisits, err := s.revisits.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, revisit := range revisits {
+		byEntry[revisit.Entry] = revisit
+	}
+	return byEntry, nil
+}
+
+type pastDay struct {
+	day   time.Time
+	label string
+}
+
+// onThisDayOffsets lists the day a month before day and the same day in
+// every earlier year back to the first entry.
+func onThisDayOffsets(day time.Time, entries []journal.Entry) []pastDay {
+	first := day
+	for _, entry := range entries {
+		if entry.Date.Before(first) {
+			first = entry.Date
+		}
+	}
+	offsets := []pastDay{{day: day.AddDate(0, -1, 0), label: "1 month ago"}}
+	for years := 1; !day.AddDate(-years, 0, 0).Before(first); years++ {
+		label := "1 year ago"
+		if years > 1 {
+			label = fmt.Sprintf("%d years ago", years)
+		}
+		offsets = append(offsets, pastDay{day: day.AddDate(-years, 0, 0), label: label})
+	}
+	return offsets
+}
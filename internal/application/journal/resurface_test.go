@@ -0,0 +1,78 @@
+package journal
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/thatnerdjosh/mindfulness/internal/domain/journal"
+	"github.com/thatnerdjosh/mindfulness/internal/infrastructure/persistence/memory"
+)
+
+func TestServiceResurface(t *testing.T) {
+	repo := memory.NewJournalRepository()
+	svc := NewService(repo, WithRevisits(memory.NewRevisitRepository()))
+	day := time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC)
+	record := func(date time.Time, note string) {
+		t.Helper()
+		if _, err := svc.RecordEntry(context.Background(), date, nil, note, "", journal.FoundationDhamma); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	record(day.AddDate(0, -1, 0), "a month ago")
+	record(day.AddDate(-1, 0, 0), "a year ago")
+	record(day.AddDate(-2, 0, 0), "two years ago")
+	record(day.AddDate(0, 0, -200), "older")
+	record(day.AddDate(0, 0, -100), "old")
+	record(day.AddDate(0, 0, -10), "recent")
+
+	resurfacing, err := svc.Resurface(context.Background(), day, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var ago []string
+	for _, item := range resurfacing.OnThisDay {
+		ago = append(ago, item.Ago+": "+item.Entry.Note)
+	}
+	if len(ago) != 3 || ago[0] != "1 month ago: a month ago" || ago[1] != "1 year ago: a year ago" || ago[2] != "2 years ago: two years ago" {
+		t.Fatalf("unexpected entries from this day: %v", ago)
+	}
+	if len(resurfacing.Due) != 2 || resurfacing.Due[0].Entry.Note != "older" || resurfacing.Due[1].Entry.Note != "old" {
+		t.Fatalf("expected the older entries due, most overdue first, got %+v", resurfacing.Due)
+	}
+
+	limited, err := svc.Resurface(context.Background(), day, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(limited.Due) != 1 || limited.Due[0].Entry.Note != "older" {
+		t.Fatalf("expected the limit to keep the most overdue entry, got %+v", limited.Due)
+	}
+
+	svc.now = func() time.Time { return day.Add(9 * time.Hour) }
+	var shown []journal.Entry
+	for _, item := range resurfacing.Entries() {
+		shown = append(shown, item.Entry)
+	}
+	if err := svc.MarkRevisited(context.Background(), shown...); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	next, err := svc.Resurface(context.Background(), day.AddDate(0, 0, 1), 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(next.OnThisDay) != 0 || len(next.Due) != 0 {
+		t.Fatalf("expected revisited entries to be pushed out, got %+v", next)
+	}
+	later, err := svc.Resurface(context.Background(), day.AddDate(0, 0, 90), 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	found := false
+	for _, item := range later.Due {
+		found = found || item.Entry.Note == "older"
+	}
+	if !found {
+		t.Fatalf("expected the revisited entry due again after its interval, got %+v", later.Due)
+	}
+}
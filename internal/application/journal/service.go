@@ -2,26 +2,81 @@ package journal
 
 import (
 	"context"
+	"fmt"
+	"sort"
+	"strings"
 	"time"
 
+	"github.com/thatnerdjosh/mindfulness/internal/domain/adherence"
 	"github.com/thatnerdjosh/mindfulness/internal/domain/journal"
 )
 
 // Service coordinates journaling use cases.
 type Service struct {
-	repo journal.Repository
-	now  func() time.Time
+	repo     journal.Repository
+	moods    journal.MoodVocabulary
+	prompts  journal.PromptBank
+	revisits journal.RevisitRepository
+	nudges   NudgePolicy
+	now      func() time.Time
 }
 
-func NewService(repo journal.Repository) *Service {
-	return &Service{
-		repo: repo,
-		now:  time.Now,
+// Option configures a Service.
+type Option func(*Service)
+
+// WithMoodVocabulary normalizes moods to vocabulary instead of the built-in
+// one.
+func WithMoodVocabulary(vocabulary journal.MoodVocabulary) Option {
+	return func(s *Service) {
+		s.moods = vocabulary
+	}
+}
+
+// WithPromptBank picks reflection questions from bank instead of the
+// built-in one.
+func WithPromptBank(bank journal.PromptBank) Option {
+	return func(s *Service) {
+		s.prompts = bank
+	}
+}
+
+// WithRevisits records when resurfaced entries are re-read in repo, which
+// spaces out when they resurface again.
+func WithRevisits(repo journal.RevisitRepository) Option {
+	return func(s *Service) {
+		s.revisits = repo
+	}
+}
+
+func NewService(repo journal.Repository, opts ...Option) *Service {
+	s := &Service{
+		repo:    repo,
+		moods:   journal.DefaultMoodVocabulary(),
+		prompts: journal.DefaultPromptBank(),
+		nudges:  DefaultNudgePolicy(),
+		now:     time.Now,
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Moods returns the vocabulary moods are normalized to.
+func (s *Service) Moods() journal.MoodVocabulary {
+	return s.moods
+}
+
+// Within returns a copy of the service that works on repo, such as the
+// journal as seen by a unit of work the caller is running.
+func (s *Service) Within(repo journal.Repository) *Service {
+	within := *s
+	within.repo = repo
+	return &within
 }
 
-func (s *Service) RecordEntry(ctx context.Context, date time.Time, reflections map[journal.Precept]string, note string, mood string, foundation journal.Foundation) (journal.Entry, error) {
-	entry, err := journal.NewEntry(date, reflections, note, mood, foundation, s.now())
+func (s *Service) RecordEntry(ctx context.Context, date time.Time, reflections map[journal.Precept]string, note string, mood string, foundation journal.Foundation, opts ...journal.EntryOption) (journal.Entry, error) {
+	entry, err := journal.NewEntry(date, reflections, note, s.moods.Normalize(mood), foundation, s.now(), opts...)
 	if err != nil {
 		return journal.Entry{}, err
 	}
@@ -33,6 +88,83 @@ func (s *Service) RecordEntry(ctx context.Context, date time.Time, reflections m
 	return entry, nil
 }
 
+// SaveEntry stores an entry that was already validated by journal.NewEntry,
+// normalizing its mood.
+func (s *Service) SaveEntry(ctx context.Context, entry journal.Entry) (journal.Entry, error) {
+	entry.Mood = s.moods.Normalize(entry.Mood)
+	if err := s.repo.Save(ctx, entry); err != nil {
+		return journal.Entry{}, err
+	}
+	return entry, nil
+}
+
+// JournalAdherenceChanges writes an entry for the day of the changes that
+// holds each change's note as the reflection for its precept and links every
+// change by its log fingerprint. Nothing is written when no change has a
+// note. repo is the journal as seen by the unit of work the changes were
+// made in, so the method can serve as an adherence service listener.
+func (s *Service) JournalAdherenceChanges(ctx context.Context, repo journal.Repository, changes []adherence.AdherenceLogEntry) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	if repo == nil {
+		return fmt.Errorf("no journal to link adherence changes to")
+	}
+
+	reflections := make(map[journal.Precept]string)
+	fingerprints := make([]string, 0, len(changes))
+	for _, change := range changes {
+		fingerprints = append(fingerprints, change.Fingerprint())
+		if note := strings.TrimSpace(change.Note); note != "" {
+			reflections[change.Precept] = note
+		}
+	}
+	if len(reflections) == 0 {
+		return nil
+	}
+
+	at := changes[0].At
+	entry, err := journal.NewEntry(at, reflections, "", "", "", at, journal.WithAdherenceEvents(fingerprints...))
+	if err != nil {
+		return err
+	}
+	return repo.Save(ctx, entry)
+}
+
+// MoodChange is a mood MigrateMoods rewrote to the vocabulary.
+type MoodChange struct {
+	Date      time.Time
+	Timestamp time.Time
+	From      string
+	To        string
+}
+
+// MigrateMoods normalizes the mood of every stored entry to the vocabulary
+// and returns what changed. With dryRun it only reports.
+func (s *Service) MigrateMoods(ctx context.Context, dryRun bool) ([]MoodChange, error) {
+	entries, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []MoodChange
+	for i, entry := range entries {
+		normalized := s.moods.Normalize(entry.Mood)
+		if normalized == entry.Mood {
+			continue
+		}
+		changes = append(changes, MoodChange{Date: entry.Date, Timestamp: entry.Timestamp, From: entry.Mood, To: normalized})
+		entries[i].Mood = normalized
+	}
+	if dryRun || len(changes) == 0 {
+		return changes, nil
+	}
+	if err := s.repo.Replace(ctx, entries); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
 func (s *Service) LatestEntry(ctx context.Context) (*journal.Entry, error) {
 	return s.repo.Latest(ctx)
 }
@@ -40,3 +172,90 @@ func (s *Service) LatestEntry(ctx context.Context) (*journal.Entry, error) {
 func (s *Service) ListEntries(ctx context.Context) ([]journal.Entry, error) {
 	return s.repo.List(ctx)
 }
+
+// EntriesOn returns the entries for the given UTC day in time order.
+func (s *Service) EntriesOn(ctx context.Context, date time.Time) ([]journal.Entry, error) {
+	entries, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	day := date.UTC().Format("2006-01-02")
+	var matched []journal.Entry
+	for _, entry := range entries {
+		if entry.Date.Format("2006-01-02") == day {
+			matched = append(matched, entry)
+		}
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		return matched[i].Timestamp.Before(matched[j].Timestamp)
+	})
+	return matched, nil
+}
+
+// Query narrows a listing of entries. Zero values match everything; Since and
+// Until are inclusive days. A plain Foundation also matches the objects
+// within it.
+type Query struct {
+	Since      time.Time
+	Until      time.Time
+	Precept    journal.Precept
+	Foundation journal.Foundation
+	Text       string
+}
+
+// QueryEntries returns the entries matching q in date order.
+func (s *Service) QueryEntries(ctx context.Context, q Query) ([]journal.Entry, error) {
+	if q.Precept != "" && !journal.IsKnownPrecept(q.Precept) {
+		return nil, journal.ErrUnknownPrecept
+	}
+	if q.Foundation != "" && !journal.IsKnownFoundation(q.Foundation) {
+		return nil, journal.ErrUnknownFoundation
+	}
+
+	entries, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	text := strings.ToLower(strings.TrimSpace(q.Text))
+	var matched []journal.Entry
+	for _, entry := range entries {
+		if !q.Since.IsZero() && entry.Date.Before(startOfDay(q.Since)) {
+			continue
+		}
+		if !q.Until.IsZero() && entry.Date.After(startOfDay(q.Until)) {
+			continue
+		}
+		if q.Precept != "" {
+			if _, ok := entry.Reflections[q.Precept]; !ok {
+				continue
+			}
+		}
+		if q.Foundation != "" && entry.Foundation != q.Foundation && entry.Foundation.Root() != q.Foundation {
+			continue
+		}
+		if text != "" && !entryContains(entry, text) {
+			continue
+		}
+		matched = append(matched, entry)
+	}
+	return matched, nil
+}
+
+func entryContains(entry journal.Entry, text string) bool {
+	if strings.Contains(strings.ToLower(entry.Note), text) || strings.Contains(strings.ToLower(entry.Mood), text) {
+		return true
+	}
+	for _, reflection := range entry.Reflections {
+		if strings.Contains(strings.ToLower(reflection), text) {
+			return true
+		}
+	}
+	return false
+}
+
+func startOfDay(date time.Time) time.Time {
+	utc := date.UTC()
+	return time.Date(utc.Year(), utc.Month(), utc.Day(), 0, 0, 0, 0, time.UTC)
+}
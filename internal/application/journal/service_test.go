@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/thatnerdjosh/mindfulness/internal/domain/adherence"
 	"github.com/thatnerdjosh/mindfulness/internal/domain/journal"
 )
 
@@ -42,6 +43,14 @@ func (f *fakeRepo) List(_ context.Context) ([]journal.Entry, error) {
 	return append([]journal.Entry{}, f.entries...), nil
 }
 
+func (f *fakeRepo) Replace(_ context.Context, entries []journal.Entry) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.entries = append([]journal.Entry{}, entries...)
+	return nil
+}
+
 func TestRecordEntry(t *testing.T) {
 	saveFailedErr := errors.New("save failed")
 	tests := []struct {
@@ -147,3 +156,172 @@ func TestLatestAndListDelegateToRepo(t *testing.T) {
 		t.Fatalf("expected 1 entry, got %d", len(list))
 	}
 }
+
+func TestEntriesOn(t *testing.T) {
+	day := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	repo := &fakeRepo{}
+	for _, hour := range []int{18, 7} {
+		entry, err := journal.NewEntry(day, nil, "note", "", journal.FoundationDhamma, day.Add(time.Duration(hour)*time.Hour))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		repo.entries = append(repo.entries, entry)
+	}
+	other, err := journal.NewEntry(day.AddDate(0, 0, 1), nil, "note", "", journal.FoundationDhamma, time.Time{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	repo.entries = append(repo.entries, other)
+
+	svc := NewService(repo)
+	entries, err := svc.EntriesOn(context.Background(), day)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	if entries[0].Timestamp.Hour() != 7 || entries[1].Timestamp.Hour() != 18 {
+		t.Fatalf("expected time order, got %v then %v", entries[0].Timestamp, entries[1].Timestamp)
+	}
+}
+
+func TestQueryEntries(t *testing.T) {
+	repo := &fakeRepo{}
+	for day, foundation := range map[int]journal.Foundation{1: journal.FoundationKaya, 2: journal.FoundationDhamma, 3: journal.FoundationKaya} {
+		date := time.Date(2024, 1, day, 0, 0, 0, 0, time.UTC)
+		entry, err := journal.NewEntry(date, map[journal.Precept]string{
+			journal.TrueLove: "Day " + date.Format("02"),
+		}, "", "", foundation, time.Time{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		repo.entries = append(repo.entries, entry)
+	}
+	svc := NewService(repo)
+
+	tests := []struct {
+		name    string
+		query   Query
+		want    int
+		wantErr error
+	}{
+		{name: "all", query: Query{}, want: 3},
+		{name: "since", query: Query{Since: time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)}, want: 2},
+		{name: "until", query: Query{Until: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)}, want: 2},
+		{name: "foundation", query: Query{Foundation: journal.FoundationKaya}, want: 2},
+		{name: "precept", query: Query{Precept: journal.TrueHappiness}, want: 0},
+		{name: "text", query: Query{Text: "day 03"}, want: 1},
+		{name: "unknown precept", query: Query{Precept: "other"}, wantErr: journal.ErrUnknownPrecept},
+		{name: "unknown foundation", query: Query{Foundation: "other"}, wantErr: journal.ErrUnknownFoundation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := svc.QueryEntries(context.Background(), tt.query)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(entries) != tt.want {
+				t.Fatalf("expected %d entries, got %d", tt.want, len(entries))
+			}
+		})
+	}
+}
+
+func TestServiceJournalAdherenceChanges(t *testing.T) {
+	at := time.Date(2024, 5, 2, 18, 30, 0, 0, time.UTC)
+	love := adherence.AdherenceLogEntry{At: at, Precept: journal.TrueLove, From: true, To: false, Note: "  ignored a friend's call "}
+	speech := adherence.AdherenceLogEntry{At: at, Precept: journal.LovingSpeechDeepListening, From: true, To: false}
+	repo := &fakeRepo{}
+	svc := NewService(&fakeRepo{})
+
+	if err := svc.JournalAdherenceChanges(context.Background(), repo, []adherence.AdherenceLogEntry{speech}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(repo.entries) != 0 {
+		t.Fatalf("expected no entry without a note, got %+v", repo.entries)
+	}
+
+	if err := svc.JournalAdherenceChanges(context.Background(), repo, []adherence.AdherenceLogEntry{love, speech}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(repo.entries) != 1 {
+		t.Fatalf("expected one entry, got %d", len(repo.entries))
+	}
+	entry := repo.entries[0]
+	if entry.Reflections[journal.TrueLove] != "ignored a friend's call" || len(entry.Reflections) != 1 {
+		t.Fatalf("expected the note as the reflection, got %+v", entry.Reflections)
+	}
+	if !entry.Timestamp.Equal(at) || entry.Date.Format("2006-01-02") != "2024-05-02" {
+		t.Fatalf("expected the entry on the day of the change, got %+v", entry)
+	}
+	if len(entry.AdherenceEvents) != 2 || entry.AdherenceEvents[0] != love.Fingerprint() || entry.AdherenceEvents[1] != speech.Fingerprint() {
+		t.Fatalf("expected both changes to be linked, got %+v", entry.AdherenceEvents)
+	}
+}
+
+func TestRecordEntryNormalizesMood(t *testing.T) {
+	repo := &fakeRepo{}
+	svc := NewService(repo)
+	date := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	entry, err := svc.RecordEntry(context.Background(), date, nil, "note", "Peaceful.", journal.FoundationDhamma)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if entry.Mood != "calm" || repo.saved.Mood != "calm" {
+		t.Fatalf("expected the mood to be normalized to calm, got %q", entry.Mood)
+	}
+
+	vocabulary, err := journal.NewMoodVocabulary([]journal.MoodTerm{{Name: "wistful", Synonyms: []string{"nostalgic"}}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	custom := NewService(repo, WithMoodVocabulary(vocabulary))
+	entry, err = custom.SaveEntry(context.Background(), journal.Entry{Date: date, Note: "note", Mood: "Nostalgic"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if entry.Mood != "wistful" || repo.saved.Mood != "wistful" {
+		t.Fatalf("expected the custom vocabulary to apply, got %q", entry.Mood)
+	}
+}
+
+func TestMigrateMoods(t *testing.T) {
+	date := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	repo := &fakeRepo{entries: []journal.Entry{
+		{Date: date, Note: "a", Mood: "Calm."},
+		{Date: date, Note: "b", Mood: "calm"},
+		{Date: date, Note: "c", Mood: "wistful"},
+		{Date: date, Note: "d", Mood: "exhausted"},
+	}}
+	svc := NewService(repo)
+
+	changes, err := svc.MigrateMoods(context.Background(), true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(changes) != 2 || changes[0].From != "Calm." || changes[0].To != "calm" || changes[1].To != "tired" {
+		t.Fatalf("unexpected changes: %+v", changes)
+	}
+	if repo.entries[0].Mood != "Calm." {
+		t.Fatal("expected a dry run to leave the entries alone")
+	}
+
+	if _, err := svc.MigrateMoods(context.Background(), false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.entries[0].Mood != "calm" || repo.entries[2].Mood != "wistful" || repo.entries[3].Mood != "tired" {
+		t.Fatalf("unexpected moods after migrating: %+v", repo.entries)
+	}
+	if changes, _ := svc.MigrateMoods(context.Background(), false); len(changes) != 0 {
+		t.Fatalf("expected nothing left to migrate, got %+v", changes)
+	}
+}
@@ -0,0 +1,110 @@
+// Package merge combines journal and adherence data written on different
+// devices into the local stores.
+package merge
+
+import (
+	"context"
+
+	"github.com/thatnerdjosh/mindfulness/internal/application/unitofwork"
+	"github.com/thatnerdjosh/mindfulness/internal/domain/adherence"
+	"github.com/thatnerdjosh/mindfulness/internal/domain/journal"
+)
+
+// Service merges other copies of the data into the local repositories. Each
+// merge is written as one unit of work.
+type Service struct {
+	uow unitofwork.UnitOfWork
+}
+
+func NewService(uow unitofwork.UnitOfWork) *Service {
+	return &Service{uow: uow}
+}
+
+// JournalReport summarizes a journal merge.
+type JournalReport struct {
+	Added     int
+	Total     int
+	Conflicts []journal.Conflict
+}
+
+// LogReport summarizes an adherence log merge and the state recomputed from
+// the merged log.
+type LogReport struct {
+	Added     int
+	Total     int
+	Conflicts []adherence.LogConflict
+	State     adherence.Adherence
+}
+
+// AdherenceLog returns the whole local log, archived events included, which
+// is what another copy needs to be merged with it.
+func (s *Service) AdherenceLog(ctx context.Context) ([]adherence.AdherenceLogEntry, error) {
+	var log []adherence.AdherenceLogEntry
+	err := s.uow.Do(ctx, func(_ journal.Repository, adherenceRepo adherence.Repository, _ adherence.BeginningAnewRepository) error {
+		var err error
+		log, err = adherenceRepo.Log(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return log, nil
+}
+
+// MergeJournal adds the entries from other that the local journal lacks.
+func (s *Service) MergeJournal(ctx context.Context, other []journal.Entry) (JournalReport, error) {
+	var report JournalReport
+	err := s.uow.Do(ctx, func(journalRepo journal.Repository, _ adherence.Repository, _ adherence.BeginningAnewRepository) error {
+		local, err := journalRepo.List(ctx)
+		if err != nil {
+			return err
+		}
+		merged, conflicts := journal.Merge(local, other)
+		if err := journalRepo.Replace(ctx, merged); err != nil {
+			return err
+		}
+		report = JournalReport{
+			Added:     len(merged) - len(local),
+			Total:     len(merged),
+			Conflicts: conflicts,
+		}
+		return nil
+	})
+	if err != nil {
+		return JournalReport{}, err
+	}
+	return report, nil
+}
+
+// MergeAdherenceLog adds the events from other that the local log lacks and
+// replaces the adherence state with the result of replaying the merged log,
+// storing the log and the state together. Passing no events only recomputes
+// the state.
+func (s *Service) MergeAdherenceLog(ctx context.Context, other []adherence.AdherenceLogEntry) (LogReport, error) {
+	var report LogReport
+	err := s.uow.Do(ctx, func(_ journal.Repository, adherenceRepo adherence.Repository, _ adherence.BeginningAnewRepository) error {
+		local, err := adherenceRepo.Log(ctx)
+		if err != nil {
+			return err
+		}
+		merged, conflicts := adherence.MergeLog(local, other)
+		if err := adherenceRepo.ReplaceLog(ctx, merged); err != nil {
+			return err
+		}
+		state := adherence.Fold(adherence.DefaultAdherence(), merged)
+		if err := adherenceRepo.Save(ctx, state); err != nil {
+			return err
+		}
+		report = LogReport{
+			Added:     len(merged) - len(local),
+			Total:     len(merged),
+			Conflicts: conflicts,
+			State:     state,
+		}
+		return nil
+	})
+	if err != nil {
+		return LogReport{}, err
+	}
+	return report, nil
+}
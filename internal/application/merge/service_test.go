@@ -0,0 +1,111 @@
+package merge
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/thatnerdjosh/mindfulness/internal/application/unitofwork"
+	"github.com/thatnerdjosh/mindfulness/internal/domain/adherence"
+	"github.com/thatnerdjosh/mindfulness/internal/domain/journal"
+	"github.com/thatnerdjosh/mindfulness/internal/infrastructure/persistence/memory"
+)
+
+func TestMergeJournal(t *testing.T) {
+	repo := memory.NewJournalRepository()
+	day := time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC)
+	local, err := journal.NewEntry(day, nil, "local", "", journal.FoundationDhamma, day.Add(9*time.Hour))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	other, err := journal.NewEntry(day, nil, "other", "", journal.FoundationDhamma, day.Add(10*time.Hour))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := repo.Save(context.Background(), local); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	svc := NewService(unitofwork.Direct(repo, memory.NewAdherenceRepository(), nil))
+	report, err := svc.MergeJournal(context.Background(), []journal.Entry{local, other})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Added != 1 || report.Total != 2 || len(report.Conflicts) != 0 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	entries, _ := repo.List(context.Background())
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 stored entries, got %d", len(entries))
+	}
+}
+
+func TestMergeAdherenceLogRecomputesState(t *testing.T) {
+	repo := memory.NewAdherenceRepository()
+	at := time.Date(2024, 1, 5, 9, 0, 0, 0, time.UTC)
+	if err := repo.AppendLog(context.Background(), adherence.AdherenceLogEntry{At: at, Precept: journal.TrueLove, From: true, To: false}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	stale := adherence.DefaultAdherence()
+	stale[journal.TrueLove] = false
+	if err := repo.Save(context.Background(), stale); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	other := []adherence.AdherenceLogEntry{
+		{At: at.Add(time.Hour), Precept: journal.TrueLove, From: false, To: true},
+		{At: at.Add(2 * time.Hour), Precept: journal.TrueHappiness, From: true, To: false},
+	}
+	svc := NewService(unitofwork.Direct(memory.NewJournalRepository(), repo, nil))
+	report, err := svc.MergeAdherenceLog(context.Background(), other)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Added != 2 || report.Total != 3 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+
+	state, _ := repo.Get(context.Background())
+	if !state[journal.TrueLove] || state[journal.TrueHappiness] {
+		t.Fatalf("expected state folded from merged log, got %+v", state)
+	}
+}
+
+// discardingUnitOfWork hands fn the repositories and then fails, as a store
+// would that could not commit the unit.
+type discardingUnitOfWork struct {
+	adherenceRepo *memory.AdherenceRepository
+	calls         int
+}
+
+func (u *discardingUnitOfWork) Do(ctx context.Context, fn func(journal.Repository, adherence.Repository, adherence.BeginningAnewRepository) error) error {
+	u.calls++
+	scratch := memory.NewAdherenceRepository()
+	log, _ := u.adherenceRepo.Log(ctx)
+	if err := scratch.ReplaceLog(ctx, log); err != nil {
+		return err
+	}
+	if err := fn(nil, scratch, nil); err != nil {
+		return err
+	}
+	return errors.New("disk full")
+}
+
+func TestMergeAdherenceLogIsOneUnitOfWork(t *testing.T) {
+	repo := memory.NewAdherenceRepository()
+	uow := &discardingUnitOfWork{adherenceRepo: repo}
+	other := []adherence.AdherenceLogEntry{{At: time.Date(2024, 1, 5, 9, 0, 0, 0, time.UTC), Precept: journal.TrueLove, From: true, To: false}}
+
+	if _, err := NewService(uow).MergeAdherenceLog(context.Background(), other); err == nil {
+		t.Fatal("expected the failed unit of work to fail the merge")
+	}
+	if uow.calls != 1 {
+		t.Fatalf("expected the log and state in one unit of work, got %d", uow.calls)
+	}
+	state, _ := repo.Get(context.Background())
+	log, _ := repo.Log(context.Background())
+	if !state[journal.TrueLove] || len(log) != 0 {
+		t.Fatalf("expected nothing stored, got %v with %d log entries", state, len(log))
+	}
+}
@@ -0,0 +1,166 @@
+package review
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	adherenceapp "github.com/thatnerdjosh/mindfulness/internal/application/adherence"
+	analyticsapp "github.com/thatnerdjosh/mindfulness/internal/application/analytics"
+	journalapp "github.com/thatnerdjosh/mindfulness/internal/application/journal"
+	"github.com/thatnerdjosh/mindfulness/internal/domain/adherence"
+	"github.com/thatnerdjosh/mindfulness/internal/domain/journal"
+)
+
+// Report gathers what a week, month or year of practice left behind.
+type Report struct {
+	Review journal.Review
+	// Entries are the daily entries of the period in time order; earlier
+	// reviews are not among them.
+	Entries []journal.Entry
+	// Changes are the adherence toggles logged during the period.
+	Changes   []adherence.AdherenceLogEntry
+	Adherence adherence.Adherence
+	Stats     analyticsapp.Stats
+	// Moods is the mood trend by day for a week and by week otherwise.
+	Moods     []analyticsapp.MoodPoint
+	Neglected []Neglected
+	// Previous is the latest review saved for the period before, whose
+	// intentions the report carries forward.
+	Previous *journal.Entry
+}
+
+// Neglected is a precept no entry of the period reflected on. LastReflected
+// is the day it last was, or zero when it never was; Broken counts the times
+// it was marked broken during the period.
+type Neglected struct {
+	Precept       journal.Precept
+	LastReflected time.Time
+	Broken        int
+}
+
+// Service builds and saves reviews.
+type Service struct {
+	journal   *journalapp.Service
+	adherence *adherenceapp.Service
+	analytics *analyticsapp.Service
+}
+
+func NewService(journalSvc *journalapp.Service, adherenceSvc *adherenceapp.Service, analyticsSvc *analyticsapp.Service) *Service {
+	return &Service{
+		journal:   journalSvc,
+		adherence: adherenceSvc,
+		analytics: analyticsSvc,
+	}
+}
+
+// Build reports on the period of the given length that contains date.
+func (s *Service) Build(ctx context.Context, period journal.ReviewPeriod, date time.Time) (Report, error) {
+	review, err := journal.NewReview(period, date)
+	if err != nil {
+		return Report{}, err
+	}
+	report := Report{Review: review}
+	since, until := review.Start, review.End()
+
+	all, err := s.journal.ListEntries(ctx)
+	if err != nil {
+		return Report{}, err
+	}
+	lastReflected := make(map[journal.Precept]time.Time)
+	previous := review.Previous()
+	for _, entry := range all {
+		if entry.Review != nil {
+			if *entry.Review == previous && (report.Previous == nil || entry.Timestamp.After(report.Previous.Timestamp)) {
+				saved := entry
+				report.Previous = &saved
+			}
+			continue
+		}
+		if entry.Date.After(until) {
+			continue
+		}
+		for precept := range entry.Reflections {
+			if entry.Date.After(lastReflected[precept]) {
+				lastReflected[precept] = entry.Date
+			}
+		}
+		if !entry.Date.Before(since) {
+			report.Entries = append(report.Entries, entry)
+		}
+	}
+	sort.SliceStable(report.Entries, func(i, j int) bool {
+		return report.Entries[i].Timestamp.Before(report.Entries[j].Timestamp)
+	})
+
+	log, err := s.adherence.Log(ctx)
+	if err != nil {
+		return Report{}, err
+	}
+	end := until.AddDate(0, 0, 1)
+	broken := make(map[journal.Precept]int)
+	for _, change := range log {
+		if change.At.Before(since) || !change.At.Before(end) {
+			continue
+		}
+		report.Changes = append(report.Changes, change)
+		if change.From && !change.To {
+			broken[change.Precept]++
+		}
+	}
+	sort.SliceStable(report.Changes, func(i, j int) bool {
+		return report.Changes[i].At.Before(report.Changes[j].At)
+	})
+	if report.Adherence, err = s.adherence.Current(ctx); err != nil {
+		return Report{}, err
+	}
+
+	if report.Stats, err = s.analytics.Stats(ctx, since, until); err != nil {
+		return Report{}, err
+	}
+	trend := analyticsapp.Weekly
+	if review.Period == journal.ReviewWeek {
+		trend = analyticsapp.Daily
+	}
+	if report.Moods, err = s.analytics.MoodTrend(ctx, since, until, trend); err != nil {
+		return Report{}, err
+	}
+
+	for _, count := range report.Stats.Precepts {
+		if count.Reflections > 0 {
+			continue
+		}
+		report.Neglected = append(report.Neglected, Neglected{
+			Precept:       count.Precept,
+			LastReflected: lastReflected[count.Precept],
+			Broken:        broken[count.Precept],
+		})
+	}
+	return report, nil
+}
+
+// IntentionQuestion is what a review asks about precept for the period
+// ahead; it is saved with the answer.
+func IntentionQuestion(precept journal.Precept, period journal.ReviewPeriod) string {
+	title := string(precept)
+	for _, info := range journal.AllPrecepts() {
+		if info.ID == precept {
+			title = info.Title
+		}
+	}
+	return fmt.Sprintf("What is your intention for %s in the %s ahead?", title, period)
+}
+
+// Save records a review as an entry dated date. The note holds what stood
+// out and intentions the resolve carried into the next period, by precept.
+func (s *Service) Save(ctx context.Context, review journal.Review, date time.Time, note string, intentions map[journal.Precept]string) (journal.Entry, error) {
+	questions := make(map[journal.Precept]string, len(intentions))
+	for precept, intention := range intentions {
+		if strings.TrimSpace(intention) != "" {
+			questions[precept] = IntentionQuestion(precept, review.Period)
+		}
+	}
+	return s.journal.RecordEntry(ctx, date, intentions, note, "", journal.FoundationDhamma, journal.WithReview(review), journal.WithQuestions(questions))
+}
@@ -0,0 +1,100 @@
+package review
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	adherenceapp "github.com/thatnerdjosh/mindfulness/internal/application/adherence"
+	analyticsapp "github.com/thatnerdjosh/mindfulness/internal/application/analytics"
+	journalapp "github.com/thatnerdjosh/mindfulness/internal/application/journal"
+	"github.com/thatnerdjosh/mindfulness/internal/domain/adherence"
+	"github.com/thatnerdjosh/mindfulness/internal/domain/journal"
+	"github.com/thatnerdjosh/mindfulness/internal/infrastructure/persistence/memory"
+)
+
+func TestServiceBuildAndSave(t *testing.T) {
+	journalRepo := memory.NewJournalRepository()
+	adherenceRepo := memory.NewAdherenceRepository()
+	journalSvc := journalapp.NewService(journalRepo)
+	svc := NewService(journalSvc, adherenceapp.NewService(adherenceRepo), analyticsapp.NewService(journalRepo))
+	ctx := context.Background()
+
+	record := func(date string, reflections map[journal.Precept]string, mood string) {
+		t.Helper()
+		day, _ := time.Parse("2006-01-02", date)
+		if _, err := journalSvc.RecordEntry(ctx, day, reflections, "note", mood, journal.FoundationKaya); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	record("2024-04-10", map[journal.Precept]string{journal.TrueHappiness: "gave away a coat"}, "")
+	record("2024-04-29", map[journal.Precept]string{journal.TrueLove: "called my sister"}, "calm")
+	record("2024-05-02", map[journal.Precept]string{journal.ReverenceForLife: "walked around the ants"}, "")
+	record("2024-05-06", map[journal.Precept]string{journal.NourishmentAndHealing: "next week"}, "")
+	for _, change := range []adherence.AdherenceLogEntry{
+		{At: time.Date(2024, 4, 30, 9, 0, 0, 0, time.UTC), Precept: journal.LovingSpeechDeepListening, From: true, To: false, Note: "snapped"},
+		{At: time.Date(2024, 5, 8, 9, 0, 0, 0, time.UTC), Precept: journal.TrueLove, From: true, To: false},
+	} {
+		if err := adherenceRepo.AppendLog(ctx, change); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	date := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	report, err := svc.Build(ctx, journal.ReviewWeek, date)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(report.Entries) != 2 || report.Stats.Entries != 2 {
+		t.Fatalf("expected the week's two entries, got %+v", report.Entries)
+	}
+	if len(report.Changes) != 1 || report.Changes[0].Note != "snapped" {
+		t.Fatalf("expected the week's adherence change, got %+v", report.Changes)
+	}
+	if len(report.Moods) != 7 || report.Moods[0].Entries != 1 {
+		t.Fatalf("expected a daily mood trend, got %+v", report.Moods)
+	}
+
+	neglected := make(map[journal.Precept]Neglected)
+	for _, item := range report.Neglected {
+		neglected[item.Precept] = item
+	}
+	if len(neglected) != 3 {
+		t.Fatalf("expected three neglected precepts, got %+v", report.Neglected)
+	}
+	if got := neglected[journal.TrueHappiness].LastReflected.Format("2006-01-02"); got != "2024-04-10" {
+		t.Fatalf("expected True Happiness last reflected on 2024-04-10, got %s", got)
+	}
+	if item := neglected[journal.LovingSpeechDeepListening]; !item.LastReflected.IsZero() || item.Broken != 1 {
+		t.Fatalf("expected Loving Speech never reflected on and broken once, got %+v", item)
+	}
+	if !neglected[journal.NourishmentAndHealing].LastReflected.IsZero() {
+		t.Fatal("expected reflections after the period not to count")
+	}
+
+	saved, err := svc.Save(ctx, report.Review, date.AddDate(0, 0, 4), "steadier than last week", map[journal.Precept]string{journal.TrueHappiness: "give something each day"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if saved.Review == nil || *saved.Review != report.Review {
+		t.Fatalf("expected a review entry, got %+v", saved)
+	}
+	if saved.Questions[journal.TrueHappiness] != IntentionQuestion(journal.TrueHappiness, journal.ReviewWeek) {
+		t.Fatalf("expected the intention question to be recorded, got %+v", saved.Questions)
+	}
+
+	again, err := svc.Build(ctx, journal.ReviewWeek, date)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(again.Entries) != 2 || again.Stats.Entries != 2 {
+		t.Fatalf("expected the saved review to stay out of the report, got %+v", again.Entries)
+	}
+	next, err := svc.Build(ctx, journal.ReviewWeek, date.AddDate(0, 0, 7))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if next.Previous == nil || next.Previous.Note != "steadier than last week" {
+		t.Fatalf("expected next week to carry the review forward, got %+v", next.Previous)
+	}
+}
@@ -0,0 +1,34 @@
+package unitofwork
+
+import (
+	"context"
+
+	"github.com/thatnerdjosh/mindfulness/internal/domain/adherence"
+	"github.com/thatnerdjosh/mindfulness/internal/domain/journal"
+)
+
+// UnitOfWork runs fn so that every write it makes through the repositories
+// it is given is stored together or not at all. If Do fails after the writes
+// were accepted, they are completed the next time the store is opened. The
+// Beginning Anew repository is nil when the store keeps no such records.
+type UnitOfWork interface {
+	Do(ctx context.Context, fn func(journal.Repository, adherence.Repository, adherence.BeginningAnewRepository) error) error
+}
+
+// Direct returns a UnitOfWork that hands fn the repositories themselves. It
+// gives no atomicity and suits stores that cannot be left half written, such
+// as the in-memory repositories. Any repository may be nil when fn does not
+// use it.
+func Direct(journalRepo journal.Repository, adherenceRepo adherence.Repository, renewalRepo adherence.BeginningAnewRepository) UnitOfWork {
+	return direct{journalRepo: journalRepo, adherenceRepo: adherenceRepo, renewalRepo: renewalRepo}
+}
+
+type direct struct {
+	journalRepo   journal.Repository
+	adherenceRepo adherence.Repository
+	renewalRepo   adherence.BeginningAnewRepository
+}
+
+func (d direct) Do(_ context.Context, fn func(journal.Repository, adherence.Repository, adherence.BeginningAnewRepository) error) error {
+	return fn(d.journalRepo, d.adherenceRepo, d.renewalRepo)
+}
@@ -0,0 +1,119 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thatnerdjosh/mindfulness/internal/domain/user"
+)
+
+type fakeUserRepo struct {
+	users []user.User
+	err   error
+}
+
+func (f *fakeUserRepo) List(_ context.Context) ([]user.User, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return append([]user.User{}, f.users...), nil
+}
+
+func (f *fakeUserRepo) Save(_ context.Context, u user.User) error {
+	if f.err != nil {
+		return f.err
+	}
+	for i := range f.users {
+		if f.users[i].Name == u.Name {
+			f.users[i] = u
+			return nil
+		}
+	}
+	f.users = append(f.users, u)
+	return nil
+}
+
+func newTestService(repo *fakeUserRepo) *Service {
+	svc := NewService(repo)
+	svc.now = func() time.Time { return time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC) }
+	return svc
+}
+
+func TestAddAndAuthenticate(t *testing.T) {
+	repo := &fakeUserRepo{}
+	svc := newTestService(repo)
+
+	created, token, err := svc.Add(context.Background(), "anna")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(token, tokenPrefix) {
+		t.Fatalf("unexpected token: %s", token)
+	}
+	if created.TokenHash == "" || strings.Contains(created.TokenHash, token) || repo.users[0].TokenHash == token {
+		t.Fatalf("expected only a token hash to be stored")
+	}
+
+	authenticated, err := svc.Authenticate(context.Background(), token)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if authenticated.Name != "anna" {
+		t.Fatalf("unexpected user: %+v", authenticated)
+	}
+
+	for _, bad := range []string{"", "mt_wrong", token + "x", strings.TrimPrefix(token, tokenPrefix)} {
+		if _, err := svc.Authenticate(context.Background(), bad); !errors.Is(err, user.ErrUnauthorized) {
+			t.Fatalf("expected unauthorized for %q, got %v", bad, err)
+		}
+	}
+
+	if _, _, err := svc.Add(context.Background(), "anna"); !errors.Is(err, user.ErrExists) {
+		t.Fatalf("expected ErrExists, got %v", err)
+	}
+	if _, _, err := svc.Add(context.Background(), "Not Valid"); !errors.Is(err, user.ErrInvalidName) {
+		t.Fatalf("expected ErrInvalidName, got %v", err)
+	}
+}
+
+func TestRevoke(t *testing.T) {
+	repo := &fakeUserRepo{}
+	svc := newTestService(repo)
+
+	_, token, err := svc.Add(context.Background(), "anna")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	revoked, err := svc.Revoke(context.Background(), "anna")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !revoked.Revoked() {
+		t.Fatalf("expected user revoked")
+	}
+	if _, err := svc.Authenticate(context.Background(), token); !errors.Is(err, user.ErrUnauthorized) {
+		t.Fatalf("expected revoked token to be rejected, got %v", err)
+	}
+	if _, err := svc.Revoke(context.Background(), "missing"); !errors.Is(err, user.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestListSortsByName(t *testing.T) {
+	repo := &fakeUserRepo{users: []user.User{{Name: "zen"}, {Name: "anna"}}}
+	users, err := newTestService(repo).List(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if users[0].Name != "anna" || users[1].Name != "zen" {
+		t.Fatalf("unexpected order: %+v", users)
+	}
+
+	repo.err = errors.New("read failed")
+	if _, err := newTestService(repo).List(context.Background()); err == nil {
+		t.Fatalf("expected error")
+	}
+}
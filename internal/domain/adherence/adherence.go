@@ -18,11 +18,48 @@ func DefaultAdherence() Adherence {
 	return adherence
 }
 
+// EventKind distinguishes the events in the adherence log.
+type EventKind string
+
+const (
+	// EventChange records a precept being toggled. It is the zero value, so
+	// entries written before kinds existed are changes.
+	EventChange EventKind = ""
+	// EventInitial records the value a precept's history starts from.
+	EventInitial EventKind = "initial"
+)
+
 // AdherenceLogEntry captures a change in adherence for a precept.
 type AdherenceLogEntry struct {
 	At      time.Time
+	Kind    EventKind
 	Precept journal.Precept
 	From    bool
 	To      bool
 	Note    string
 }
+
+// InitialEvents records state as the starting point of the log, one event
+// per known precept.
+func InitialEvents(at time.Time, state Adherence) []AdherenceLogEntry {
+	events := make([]AdherenceLogEntry, 0, len(journal.AllPrecepts()))
+	for _, info := range journal.AllPrecepts() {
+		value, ok := state[info.ID]
+		if !ok {
+			continue
+		}
+		events = append(events, AdherenceLogEntry{At: at, Kind: EventInitial, Precept: info.ID, From: value, To: value})
+	}
+	return events
+}
+
+// Changes leaves out the initial-state events, keeping the toggles.
+func Changes(log []AdherenceLogEntry) []AdherenceLogEntry {
+	var changes []AdherenceLogEntry
+	for _, entry := range log {
+		if entry.Kind == EventChange {
+			changes = append(changes, entry)
+		}
+	}
+	return changes
+}
@@ -2,6 +2,7 @@ package adherence
 
 import (
 	"testing"
+	"time"
 
 	"github.com/thatnerdjosh/mindfulness/internal/domain/journal"
 )
@@ -16,4 +17,23 @@ func TestDefaultAdherence(t *testing.T) {
 			t.Fatalf("expected default true for %s", info.ID)
 		}
 	}
-}
\ No newline at end of file
+}
+
+func TestInitialEvents(t *testing.T) {
+	at := time.Date(2024, 1, 5, 9, 0, 0, 0, time.UTC)
+	events := InitialEvents(at, DefaultAdherence())
+	if len(events) != len(journal.AllPrecepts()) {
+		t.Fatalf("expected one event per precept, got %d", len(events))
+	}
+	for i, info := range journal.AllPrecepts() {
+		event := events[i]
+		if event.Kind != EventInitial || event.Precept != info.ID || !event.From || !event.To || !event.At.Equal(at) {
+			t.Fatalf("unexpected event %+v", event)
+		}
+	}
+
+	change := AdherenceLogEntry{At: at, Precept: events[0].Precept, From: true, To: true}
+	if change.Fingerprint() == events[0].Fingerprint() {
+		t.Fatal("expected the kind to be part of the fingerprint")
+	}
+}
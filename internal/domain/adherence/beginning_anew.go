@@ -0,0 +1,93 @@
+package adherence
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/thatnerdjosh/mindfulness/internal/domain/journal"
+)
+
+// ErrNoOpenBreak is returned when renewing a precept that has no break
+// waiting to be renewed.
+var ErrNoOpenBreak = errors.New("no open break to renew")
+
+// BeginningAnew records the practice of Beginning Anew after a precept was
+// broken: acknowledging what happened, expressing regret, setting an
+// intention and, finally, renewing the commitment to the training.
+type BeginningAnew struct {
+	// Break is the fingerprint of the log event that broke the precept.
+	Break           string
+	Precept         journal.Precept
+	At              time.Time
+	Acknowledgement string
+	Regret          string
+	Intention       string
+	// RenewedAt is zero until the commitment has been renewed.
+	RenewedAt time.Time
+}
+
+// Renewed reports whether the commitment has been renewed.
+func (b BeginningAnew) Renewed() bool {
+	return !b.RenewedAt.IsZero()
+}
+
+// Merge fills the reflection fields of b that are empty from other, so a
+// renewal keeps what was written when the flow was started.
+func (b BeginningAnew) Merge(other BeginningAnew) BeginningAnew {
+	if strings.TrimSpace(b.Acknowledgement) == "" {
+		b.Acknowledgement = other.Acknowledgement
+	}
+	if strings.TrimSpace(b.Regret) == "" {
+		b.Regret = other.Regret
+	}
+	if strings.TrimSpace(b.Intention) == "" {
+		b.Intention = other.Intention
+	}
+	return b
+}
+
+// IsBreak reports whether entry records a precept going from kept to not
+// kept.
+func IsBreak(entry AdherenceLogEntry) bool {
+	return entry.Kind == EventChange && entry.From && !entry.To
+}
+
+// OpenBreaks returns the breaks in log that have not been renewed, oldest
+// first. Renewing a precept also closes the earlier breaks of that precept.
+func OpenBreaks(log []AdherenceLogEntry, records []BeginningAnew) []AdherenceLogEntry {
+	renewed := make(map[string]bool, len(records))
+	for _, record := range records {
+		if record.Renewed() {
+			renewed[record.Break] = true
+		}
+	}
+
+	var breaks []AdherenceLogEntry
+	for _, entry := range log {
+		if IsBreak(entry) {
+			breaks = append(breaks, entry)
+		}
+	}
+	sort.SliceStable(breaks, func(i, j int) bool {
+		return breaks[i].At.Before(breaks[j].At)
+	})
+
+	closed := make(map[journal.Precept]bool)
+	var open []AdherenceLogEntry
+	for i := len(breaks) - 1; i >= 0; i-- {
+		entry := breaks[i]
+		if renewed[entry.Fingerprint()] {
+			closed[entry.Precept] = true
+		}
+		if closed[entry.Precept] {
+			continue
+		}
+		open = append(open, entry)
+	}
+	for i, j := 0, len(open)-1; i < j; i, j = i+1, j-1 {
+		open[i], open[j] = open[j], open[i]
+	}
+	return open
+}
@@ -0,0 +1,42 @@
+package adherence
+
+import (
+	"testing"
+	"time"
+
+	"github.com/thatnerdjosh/mindfulness/internal/domain/journal"
+)
+
+func TestOpenBreaks(t *testing.T) {
+	at := time.Date(2024, 4, 1, 9, 0, 0, 0, time.UTC)
+	firstLove := AdherenceLogEntry{At: at, Precept: journal.TrueLove, From: true, To: false}
+	mended := AdherenceLogEntry{At: at.Add(time.Hour), Precept: journal.TrueLove, From: false, To: true}
+	secondLove := AdherenceLogEntry{At: at.Add(2 * time.Hour), Precept: journal.TrueLove, From: true, To: false}
+	speech := AdherenceLogEntry{At: at.Add(3 * time.Hour), Precept: journal.LovingSpeechDeepListening, From: true, To: false}
+	log := append(InitialEvents(at.Add(-time.Hour), DefaultAdherence()), firstLove, mended, secondLove, speech)
+
+	open := OpenBreaks(log, nil)
+	if len(open) != 3 || open[0] != firstLove || open[2] != speech {
+		t.Fatalf("expected every break to be open, got %+v", open)
+	}
+
+	// A record that was only started leaves the break open.
+	started := BeginningAnew{Break: speech.Fingerprint(), Precept: speech.Precept, At: speech.At, Intention: "pause before replying"}
+	if open := OpenBreaks(log, []BeginningAnew{started}); len(open) != 3 {
+		t.Fatalf("expected an unrenewed record to leave the break open, got %+v", open)
+	}
+
+	renewed := BeginningAnew{Break: secondLove.Fingerprint(), Precept: secondLove.Precept, At: secondLove.At, RenewedAt: at.Add(4 * time.Hour)}
+	open = OpenBreaks(log, []BeginningAnew{started, renewed})
+	if len(open) != 1 || open[0] != speech {
+		t.Fatalf("expected renewing the latest break to close the earlier one too, got %+v", open)
+	}
+}
+
+func TestBeginningAnewMerge(t *testing.T) {
+	started := BeginningAnew{Acknowledgement: "I snapped", Regret: "it hurt", Intention: "breathe first"}
+	renewal := BeginningAnew{Intention: "walk before answering"}.Merge(started)
+	if renewal.Acknowledgement != "I snapped" || renewal.Regret != "it hurt" || renewal.Intention != "walk before answering" {
+		t.Fatalf("unexpected merge: %+v", renewal)
+	}
+}
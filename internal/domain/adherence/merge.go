@@ -0,0 +1,107 @@
+package adherence
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/thatnerdjosh/mindfulness/internal/domain/journal"
+)
+
+// LogConflict groups different changes to one precept logged at the same
+// instant. Their relative order cannot be known, so Fold applies them in
+// fingerprint order.
+type LogConflict struct {
+	At      time.Time
+	Precept journal.Precept
+	Entries []AdherenceLogEntry
+}
+
+// Fingerprint identifies a log entry by its content.
+func (e AdherenceLogEntry) Fingerprint() string {
+	content := fmt.Sprintf("%s\x00%s\x00%t\x00%t\x00%s", e.At.UTC().Format(time.RFC3339Nano), e.Precept, e.From, e.To, e.Note)
+	if e.Kind != EventChange {
+		content += "\x00" + string(e.Kind)
+	}
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// MergeLog returns the time-ordered set of unique events from both logs.
+// Events at the same instant are ordered by precept and then fingerprint so
+// the result does not depend on which log is local.
+func MergeLog(local []AdherenceLogEntry, other []AdherenceLogEntry) ([]AdherenceLogEntry, []LogConflict) {
+	fingerprints := make(map[string]AdherenceLogEntry, len(local)+len(other))
+	for _, entry := range append(append([]AdherenceLogEntry{}, local...), other...) {
+		fingerprints[entry.Fingerprint()] = entry
+	}
+
+	keys := make([]string, 0, len(fingerprints))
+	for fingerprint := range fingerprints {
+		keys = append(keys, fingerprint)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := fingerprints[keys[i]], fingerprints[keys[j]]
+		if !a.At.Equal(b.At) {
+			return a.At.Before(b.At)
+		}
+		if a.Precept != b.Precept {
+			return a.Precept < b.Precept
+		}
+		return keys[i] < keys[j]
+	})
+	merged := make([]AdherenceLogEntry, 0, len(keys))
+	for _, fingerprint := range keys {
+		merged = append(merged, fingerprints[fingerprint])
+	}
+
+	var conflicts []LogConflict
+	for start := 0; start < len(merged); {
+		end := start + 1
+		for end < len(merged) && merged[end].At.Equal(merged[start].At) && merged[end].Precept == merged[start].Precept {
+			end++
+		}
+		if end-start > 1 && !sameOutcome(merged[start:end]) {
+			conflicts = append(conflicts, LogConflict{
+				At:      merged[start].At,
+				Precept: merged[start].Precept,
+				Entries: append([]AdherenceLogEntry{}, merged[start:end]...),
+			})
+		}
+		start = end
+	}
+	return merged, conflicts
+}
+
+// sameOutcome reports whether events agree on the resulting value, in which
+// case their order does not matter.
+func sameOutcome(entries []AdherenceLogEntry) bool {
+	for _, entry := range entries[1:] {
+		if entry.To != entries[0].To {
+			return false
+		}
+	}
+	return true
+}
+
+// Fold replays log over initial and returns the resulting state. The log must
+// be in time order. An initial-state event only counts when it is the first
+// event for its precept: a merged log can hold another device's starting
+// point after changes already made here.
+func Fold(initial Adherence, log []AdherenceLogEntry) Adherence {
+	state := make(Adherence, len(initial))
+	for precept, value := range initial {
+		state[precept] = value
+	}
+	seen := make(map[journal.Precept]bool)
+	for _, entry := range log {
+		if entry.Kind == EventInitial && seen[entry.Precept] {
+			continue
+		}
+		seen[entry.Precept] = true
+		state[entry.Precept] = entry.To
+	}
+	return state
+}
@@ -0,0 +1,76 @@
+package adherence
+
+import (
+	"testing"
+	"time"
+
+	"github.com/thatnerdjosh/mindfulness/internal/domain/journal"
+)
+
+func TestMergeLog(t *testing.T) {
+	base := time.Date(2024, 1, 5, 9, 0, 0, 0, time.UTC)
+	shared := AdherenceLogEntry{At: base, Precept: journal.TrueLove, From: true, To: false, Note: "harsh words"}
+	laptop := AdherenceLogEntry{At: base.Add(time.Hour), Precept: journal.TrueLove, From: false, To: true}
+	desktop := AdherenceLogEntry{At: base.Add(2 * time.Hour), Precept: journal.LovingSpeechDeepListening, From: true, To: false}
+	clashA := AdherenceLogEntry{At: base.Add(3 * time.Hour), Precept: journal.TrueHappiness, From: true, To: false}
+	clashB := AdherenceLogEntry{At: base.Add(3 * time.Hour), Precept: journal.TrueHappiness, From: false, To: true}
+	agreeA := AdherenceLogEntry{At: base.Add(4 * time.Hour), Precept: journal.TrueLove, From: true, To: false, Note: "a"}
+	agreeB := AdherenceLogEntry{At: base.Add(4 * time.Hour), Precept: journal.TrueLove, From: true, To: false, Note: "b"}
+
+	local := []AdherenceLogEntry{shared, laptop, clashA, agreeA}
+	other := []AdherenceLogEntry{shared, desktop, clashB, agreeB}
+
+	merged, conflicts := MergeLog(local, other)
+	reversed, _ := MergeLog(other, local)
+
+	if len(merged) != 7 {
+		t.Fatalf("expected 7 unique events, got %d", len(merged))
+	}
+	for i := range merged {
+		if merged[i].Fingerprint() != reversed[i].Fingerprint() {
+			t.Fatalf("expected merge to be independent of direction at %d", i)
+		}
+		if i > 0 && merged[i].At.Before(merged[i-1].At) {
+			t.Fatalf("expected events in time order")
+		}
+	}
+	if len(conflicts) != 1 || conflicts[0].Precept != journal.TrueHappiness {
+		t.Fatalf("expected one conflict for true happiness, got %+v", conflicts)
+	}
+}
+
+func TestFold(t *testing.T) {
+	base := time.Date(2024, 1, 5, 9, 0, 0, 0, time.UTC)
+	initial := DefaultAdherence()
+	log := []AdherenceLogEntry{
+		{At: base, Precept: journal.TrueLove, From: true, To: false},
+		{At: base.Add(time.Hour), Precept: journal.LovingSpeechDeepListening, From: true, To: false},
+		{At: base.Add(2 * time.Hour), Precept: journal.TrueLove, From: false, To: true},
+	}
+
+	state := Fold(initial, log)
+	if !state[journal.TrueLove] || state[journal.LovingSpeechDeepListening] {
+		t.Fatalf("unexpected state %+v", state)
+	}
+	if !initial[journal.LovingSpeechDeepListening] {
+		t.Fatalf("expected Fold to leave initial untouched")
+	}
+}
+
+func TestFoldInitialEvents(t *testing.T) {
+	base := time.Date(2024, 1, 5, 9, 0, 0, 0, time.UTC)
+	start := DefaultAdherence()
+	start[journal.TrueHappiness] = false
+	log := InitialEvents(base, start)
+	log = append(log, AdherenceLogEntry{At: base.Add(time.Hour), Precept: journal.TrueLove, From: true, To: false})
+	// Another device's starting point, merged in after the change above.
+	log = append(log, InitialEvents(base.Add(2*time.Hour), DefaultAdherence())...)
+
+	state := Fold(DefaultAdherence(), log)
+	if state[journal.TrueHappiness] || state[journal.TrueLove] {
+		t.Fatalf("expected later initial events to be ignored, got %+v", state)
+	}
+	if changes := Changes(log); len(changes) != 1 || changes[0].Precept != journal.TrueLove {
+		t.Fatalf("unexpected changes %+v", changes)
+	}
+}
@@ -1,10 +1,47 @@
 package adherence
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // Repository defines storage behavior for adherence state and logs.
 type Repository interface {
 	Get(ctx context.Context) (Adherence, error)
 	Save(ctx context.Context, adherence Adherence) error
 	AppendLog(ctx context.Context, entry AdherenceLogEntry) error
+	Log(ctx context.Context) ([]AdherenceLogEntry, error)
+	// ReplaceLog swaps the stored log for log, as after a merge.
+	ReplaceLog(ctx context.Context, log []AdherenceLogEntry) error
+}
+
+// Rebuilder is implemented by repositories whose state is a projection of
+// the log and can be regenerated from it.
+type Rebuilder interface {
+	// Rebuild folds the whole log into a fresh state, stores it and reports
+	// how many events were replayed.
+	Rebuild(ctx context.Context) (Adherence, int, error)
+}
+
+// LogSegment describes an archived, read-only part of the log.
+type LogSegment struct {
+	Name   string
+	First  time.Time
+	Last   time.Time
+	Events int
+}
+
+// Archiver is implemented by repositories that can move the current log
+// into an archive segment. Log still returns archived events.
+type Archiver interface {
+	// ArchiveLog archives the current log and applies the retention policy.
+	// It reports false when the current log holds no changes to archive.
+	ArchiveLog(ctx context.Context) (LogSegment, bool, error)
+}
+
+// BeginningAnewRepository stores Beginning Anew records, one per break.
+type BeginningAnewRepository interface {
+	List(ctx context.Context) ([]BeginningAnew, error)
+	// Save stores record, replacing any record for the same break.
+	Save(ctx context.Context, record BeginningAnew) error
 }
@@ -21,9 +21,45 @@ type Entry struct {
 	Note        string
 	Mood        string
 	Foundation  Foundation
+	// AdherenceEvents holds the fingerprints of the adherence log events
+	// the entry was written about.
+	AdherenceEvents []string
+	// Vedana is the feeling tone noted with the vedana foundation, if any.
+	Vedana *Vedana
+	// Questions holds the question each reflection answered, by precept,
+	// for reflections written with a prompt.
+	Questions map[Precept]string
+	// Review is set on entries written as a review of a week, month or
+	// year, which stand apart from the daily entries they look back on.
+	Review *Review
+	// Quicknote is set on entries written with `mt quicknote`, so they can
+	// be told apart from full entries that happen to hold only a note.
+	Quicknote bool
 }
 
-func NewEntry(date time.Time, reflections map[Precept]string, note string, mood string, foundation Foundation, timestamp time.Time) (Entry, error) {
+// EntryOption sets an optional part of an entry.
+type EntryOption func(*Entry)
+
+// WithAdherenceEvents links the entry to adherence log events by their
+// fingerprints.
+func WithAdherenceEvents(fingerprints ...string) EntryOption {
+	return func(e *Entry) {
+		for _, fingerprint := range fingerprints {
+			if fingerprint = strings.TrimSpace(fingerprint); fingerprint != "" {
+				e.AdherenceEvents = append(e.AdherenceEvents, fingerprint)
+			}
+		}
+	}
+}
+
+// AsQuicknote marks the entry as a quicknote.
+func AsQuicknote() EntryOption {
+	return func(e *Entry) {
+		e.Quicknote = true
+	}
+}
+
+func NewEntry(date time.Time, reflections map[Precept]string, note string, mood string, foundation Foundation, timestamp time.Time, opts ...EntryOption) (Entry, error) {
 	if date.IsZero() {
 		return Entry{}, ErrInvalidDate
 	}
@@ -47,14 +83,28 @@ func NewEntry(date time.Time, reflections map[Precept]string, note string, mood
 		timestamp = normalizeDate(date)
 	}
 
-	return Entry{
+	entry := Entry{
 		Date:        normalizeDate(date),
 		Timestamp:   timestamp,
 		Reflections: cleanedReflections,
 		Note:        note,
 		Mood:        mood,
 		Foundation:  foundation,
-	}, nil
+	}
+	for _, opt := range opts {
+		opt(&entry)
+	}
+	if entry.Vedana != nil && entry.Foundation.Root() != FoundationVedana {
+		return Entry{}, ErrVedanaFoundation
+	}
+	if entry.Review != nil {
+		review, err := NewReview(entry.Review.Period, entry.Review.Start)
+		if err != nil {
+			return Entry{}, err
+		}
+		entry.Review = &review
+	}
+	return entry, nil
 }
 
 func validateAndCleanReflections(reflections map[Precept]string) (map[Precept]string, error) {
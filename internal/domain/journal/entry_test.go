@@ -216,3 +216,69 @@ func TestFoundationLabel(t *testing.T) {
 		}
 	}
 }
+
+func TestParseFoundationObjects(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Foundation
+		wantErr bool
+	}{
+		{"d.hindrances", "dhamma.hindrances", false},
+		{"Dhamma.Hindrances", "dhamma.hindrances", false},
+		{"d.sense bases", "dhamma.sense-bases", false},
+		{"k.breath", "kaya.breath", false},
+		{"k.br", "kaya.breath", false},
+		{"c.", FoundationCit, false},
+		{"d.a", "", true},
+		{"d.breath", "", true},
+		{"x.breath", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseFoundation(tt.input)
+		if tt.wantErr {
+			if !errors.Is(err, ErrUnknownFoundation) {
+				t.Errorf("ParseFoundation(%q) expected ErrUnknownFoundation, got %v", tt.input, err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("ParseFoundation(%q) = %q, %v, want %q", tt.input, got, err, tt.want)
+		}
+	}
+}
+
+func TestFoundationObjects(t *testing.T) {
+	hindrances := Foundation("dhamma.hindrances")
+	if hindrances.Root() != FoundationDhamma || hindrances.Object() != "hindrances" || FoundationDhamma.Object() != "" {
+		t.Fatalf("unexpected parts of %q", hindrances)
+	}
+	if !IsKnownFoundation(hindrances) || IsKnownFoundation("dhamma.breath") || IsKnownFoundation("dhamma.") {
+		t.Fatal("expected only objects of the taxonomy to be known")
+	}
+	if got := FoundationLabel(hindrances); got != "Dhamma: Hindrances" {
+		t.Fatalf("unexpected label %q", got)
+	}
+	for _, foundation := range Foundations() {
+		objects := FoundationObjects(foundation)
+		if len(objects) == 0 {
+			t.Fatalf("expected objects for %s", foundation)
+		}
+		for _, object := range objects {
+			if object.ID.Root() != foundation || !IsKnownFoundation(object.ID) {
+				t.Fatalf("object %q does not belong to %s", object.ID, foundation)
+			}
+		}
+	}
+
+	at := time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)
+	entry, err := NewEntry(at, nil, "restless", "", hindrances, at)
+	if err != nil || entry.Foundation != hindrances {
+		t.Fatalf("expected an entry with an object, got %+v, %v", entry, err)
+	}
+	if _, err := NewEntry(at, nil, "restless", "", "dhamma.breath", at); !errors.Is(err, ErrUnknownFoundation) {
+		t.Fatalf("expected ErrUnknownFoundation, got %v", err)
+	}
+	if _, err := NewEntry(at, nil, "warm", "", "vedana.worldly", at, WithVedana(Vedana{Tone: TonePleasant})); err != nil {
+		t.Fatalf("expected a feeling tone with a vedana object, got %v", err)
+	}
+}
@@ -2,10 +2,14 @@ package journal
 
 import (
 	"errors"
+	"fmt"
 	"strings"
 )
 
 // Foundation represents the primary mindfulness foundation for the entry.
+// It is either one of the four foundations or an object contemplated within
+// one, written as the foundation and the object joined by a dot, such as
+// "dhamma.hindrances".
 type Foundation string
 
 const (
@@ -17,41 +21,153 @@ const (
 
 var ErrUnknownFoundation = errors.New("unknown foundation")
 
+// FoundationObject is an object of contemplation within a foundation.
+type FoundationObject struct {
+	ID    Foundation
+	Label string
+}
+
+// foundationObjects follows the Satipatthana Sutta, in its order.
+var foundationObjects = map[Foundation][]FoundationObject{
+	FoundationKaya: {
+		{ID: "kaya.breath", Label: "Breath"},
+		{ID: "kaya.postures", Label: "Postures"},
+		{ID: "kaya.activities", Label: "Clear comprehension"},
+		{ID: "kaya.body-parts", Label: "Parts of the body"},
+		{ID: "kaya.elements", Label: "Elements"},
+		{ID: "kaya.charnel-ground", Label: "Charnel ground"},
+	},
+	FoundationVedana: {
+		{ID: "vedana.worldly", Label: "Worldly feeling"},
+		{ID: "vedana.unworldly", Label: "Unworldly feeling"},
+	},
+	FoundationCit: {
+		{ID: "cit.lust", Label: "Lust"},
+		{ID: "cit.aversion", Label: "Aversion"},
+		{ID: "cit.delusion", Label: "Delusion"},
+		{ID: "cit.contracted", Label: "Contracted or scattered"},
+		{ID: "cit.exalted", Label: "Exalted"},
+		{ID: "cit.concentrated", Label: "Concentrated"},
+		{ID: "cit.liberated", Label: "Liberated"},
+	},
+	FoundationDhamma: {
+		{ID: "dhamma.hindrances", Label: "Hindrances"},
+		{ID: "dhamma.aggregates", Label: "Aggregates"},
+		{ID: "dhamma.sense-bases", Label: "Sense bases"},
+		{ID: "dhamma.awakening-factors", Label: "Awakening factors"},
+		{ID: "dhamma.noble-truths", Label: "Noble truths"},
+	},
+}
+
+// Foundations lists the four foundations in order.
+func Foundations() []Foundation {
+	return []Foundation{FoundationKaya, FoundationVedana, FoundationCit, FoundationDhamma}
+}
+
+// FoundationObjects lists the objects contemplated within a foundation.
+func FoundationObjects(foundation Foundation) []FoundationObject {
+	return append([]FoundationObject(nil), foundationObjects[foundation.Root()]...)
+}
+
+// Root returns the foundation an object belongs to, or the foundation
+// itself.
+func (f Foundation) Root() Foundation {
+	root, _, _ := strings.Cut(string(f), ".")
+	return Foundation(root)
+}
+
+// Object returns the object part of the foundation, such as "hindrances",
+// or "" for a plain foundation.
+func (f Foundation) Object() string {
+	_, object, _ := strings.Cut(string(f), ".")
+	return object
+}
+
 func IsKnownFoundation(foundation Foundation) bool {
-	switch foundation {
+	switch foundation.Root() {
 	case FoundationKaya, FoundationVedana, FoundationCit, FoundationDhamma:
-		return true
 	default:
 		return false
 	}
+	if foundation.Object() == "" {
+		return foundation == foundation.Root()
+	}
+	_, ok := lookupObject(foundation)
+	return ok
 }
 
+// ParseFoundation accepts a foundation or its first letter, optionally
+// followed by a dot and an object within it, such as "d.hindrances". The
+// object may be shortened to any unambiguous prefix.
 func ParseFoundation(input string) (Foundation, error) {
-	switch strings.ToLower(strings.TrimSpace(input)) {
+	rootInput, objectInput, hasObject := strings.Cut(strings.ToLower(strings.TrimSpace(input)), ".")
+
+	var root Foundation
+	switch strings.TrimSpace(rootInput) {
 	case "", "d", "dhamma":
-		return FoundationDhamma, nil
+		root = FoundationDhamma
 	case "k", "kaya":
-		return FoundationKaya, nil
+		root = FoundationKaya
 	case "v", "vedana":
-		return FoundationVedana, nil
+		root = FoundationVedana
 	case "c", "cit":
-		return FoundationCit, nil
+		root = FoundationCit
 	default:
 		return "", ErrUnknownFoundation
 	}
+
+	object := strings.Join(strings.FieldsFunc(objectInput, func(r rune) bool {
+		return r == ' ' || r == '_' || r == '-'
+	}), "-")
+	if !hasObject || object == "" {
+		return root, nil
+	}
+
+	var matches []Foundation
+	for _, candidate := range foundationObjects[root] {
+		name := candidate.ID.Object()
+		if name == object {
+			return candidate.ID, nil
+		}
+		if strings.HasPrefix(name, object) {
+			matches = append(matches, candidate.ID)
+		}
+	}
+	if len(matches) == 1 {
+		return matches[0], nil
+	}
+	return "", fmt.Errorf("%w: %q is not an object of %s", ErrUnknownFoundation, objectInput, root)
 }
 
 func FoundationLabel(foundation Foundation) string {
-	switch foundation {
+	var label string
+	switch foundation.Root() {
 	case FoundationKaya:
-		return "Kaya"
+		label = "Kaya"
 	case FoundationVedana:
-		return "Vedana"
+		label = "Vedana"
 	case FoundationCit:
-		return "Cit"
+		label = "Cit"
 	case FoundationDhamma:
-		return "Dhamma"
+		label = "Dhamma"
 	default:
 		return string(foundation)
 	}
+	if foundation.Object() == "" {
+		return label
+	}
+	object, ok := lookupObject(foundation)
+	if !ok {
+		return string(foundation)
+	}
+	return label + ": " + object.Label
+}
+
+func lookupObject(foundation Foundation) (FoundationObject, bool) {
+	for _, object := range foundationObjects[foundation.Root()] {
+		if object.ID == foundation {
+			return object, true
+		}
+	}
+	return FoundationObject{}, false
 }
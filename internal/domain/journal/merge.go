@@ -0,0 +1,104 @@
+package journal
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Conflict pairs two different entries recorded at the same moment, which
+// usually means the same entry was edited on two devices.
+type Conflict struct {
+	Timestamp time.Time
+	Entries   []Entry
+}
+
+// Fingerprint identifies an entry by its content. Timestamps are compared at
+// the full precision the journal file stores.
+func (e Entry) Fingerprint() string {
+	var b strings.Builder
+	b.WriteString(e.Date.UTC().Format("2006-01-02"))
+	b.WriteByte(0)
+	b.WriteString(e.Timestamp.UTC().Format(time.RFC3339Nano))
+	b.WriteByte(0)
+	b.WriteString(string(e.Foundation))
+	b.WriteByte(0)
+	b.WriteString(e.Mood)
+	b.WriteByte(0)
+	b.WriteString(e.Note)
+	for _, precept := range e.SortedPrecepts() {
+		b.WriteByte(0)
+		b.WriteString(string(precept))
+		b.WriteByte('=')
+		b.WriteString(e.Reflections[precept])
+	}
+	for _, precept := range e.SortedPrecepts() {
+		if question, ok := e.Questions[precept]; ok {
+			fmt.Fprintf(&b, "\x00question=%s=%s", precept, question)
+		}
+	}
+	if v := e.Vedana; v != nil {
+		fmt.Fprintf(&b, "\x00vedana=%s/%d/%s/%s", v.Tone, v.Intensity, v.Location, v.Trigger)
+	}
+	if r := e.Review; r != nil {
+		fmt.Fprintf(&b, "\x00review=%s/%s", r.Period, r.Start.Format("2006-01-02"))
+	}
+	if e.Quicknote {
+		b.WriteString("\x00quicknote")
+	}
+	for _, event := range e.AdherenceEvents {
+		b.WriteByte(0)
+		b.WriteString("adherence=")
+		b.WriteString(event)
+	}
+	sum := sha256.Sum256([]byte(b.String()))
+	return hex.EncodeToString(sum[:])
+}
+
+// Merge returns the union of both entry sets, dropping entries with equal
+// fingerprints. The result is ordered by timestamp and then fingerprint, so
+// merging in either direction gives the same list. Distinct entries sharing a
+// timestamp are all kept and reported as conflicts.
+func Merge(local []Entry, other []Entry) ([]Entry, []Conflict) {
+	fingerprints := make(map[string]Entry, len(local)+len(other))
+	for _, entry := range append(append([]Entry{}, local...), other...) {
+		fingerprint := entry.Fingerprint()
+		if _, ok := fingerprints[fingerprint]; !ok {
+			fingerprints[fingerprint] = entry
+		}
+	}
+
+	keys := make([]string, 0, len(fingerprints))
+	for fingerprint := range fingerprints {
+		keys = append(keys, fingerprint)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a := fingerprints[keys[i]].Timestamp.Truncate(time.Second)
+		b := fingerprints[keys[j]].Timestamp.Truncate(time.Second)
+		if !a.Equal(b) {
+			return a.Before(b)
+		}
+		return keys[i] < keys[j]
+	})
+	merged := make([]Entry, 0, len(keys))
+	for _, fingerprint := range keys {
+		merged = append(merged, fingerprints[fingerprint])
+	}
+
+	var conflicts []Conflict
+	for start := 0; start < len(merged); {
+		end := start + 1
+		at := merged[start].Timestamp.Truncate(time.Second)
+		for end < len(merged) && merged[end].Timestamp.Truncate(time.Second).Equal(at) {
+			end++
+		}
+		if end-start > 1 {
+			conflicts = append(conflicts, Conflict{Timestamp: at, Entries: append([]Entry{}, merged[start:end]...)})
+		}
+		start = end
+	}
+	return merged, conflicts
+}
@@ -0,0 +1,69 @@
+package journal
+
+import (
+	"testing"
+	"time"
+)
+
+func mustEntry(t *testing.T, note string, timestamp time.Time) Entry {
+	t.Helper()
+	entry, err := NewEntry(timestamp, nil, note, "", FoundationDhamma, timestamp)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return entry
+}
+
+func TestEntryFingerprint(t *testing.T) {
+	at := time.Date(2024, 1, 5, 9, 0, 0, 0, time.UTC)
+	a := mustEntry(t, "walked", at)
+	if a.Fingerprint() != mustEntry(t, "walked", at.In(time.FixedZone("UTC+2", 2*60*60))).Fingerprint() {
+		t.Fatalf("expected the time zone to be ignored")
+	}
+	if a.Fingerprint() == mustEntry(t, "walked", at.Add(300*time.Millisecond)).Fingerprint() {
+		t.Fatalf("expected sub-second differences to change the fingerprint")
+	}
+	if a.Fingerprint() == mustEntry(t, "walked slowly", at).Fingerprint() {
+		t.Fatalf("expected different content to change the fingerprint")
+	}
+}
+
+func TestMerge(t *testing.T) {
+	day := time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC)
+	shared := mustEntry(t, "shared", day.Add(8*time.Hour))
+	laptop := mustEntry(t, "laptop", day.Add(9*time.Hour))
+	desktop := mustEntry(t, "desktop", day.Add(10*time.Hour))
+	editedA := mustEntry(t, "edited on laptop", day.Add(11*time.Hour))
+	editedB := mustEntry(t, "edited on desktop", day.Add(11*time.Hour))
+
+	local := []Entry{shared, laptop, editedA}
+	other := []Entry{desktop, shared, editedB}
+
+	merged, conflicts := Merge(local, other)
+	reversed, _ := Merge(other, local)
+
+	if len(merged) != 5 {
+		t.Fatalf("expected 5 entries, got %d", len(merged))
+	}
+	for i := range merged {
+		if merged[i].Fingerprint() != reversed[i].Fingerprint() {
+			t.Fatalf("expected merge to be independent of direction at %d", i)
+		}
+	}
+	for i := 1; i < len(merged); i++ {
+		if merged[i].Timestamp.Before(merged[i-1].Timestamp) {
+			t.Fatalf("expected entries in time order")
+		}
+	}
+	if len(conflicts) != 1 || len(conflicts[0].Entries) != 2 {
+		t.Fatalf("expected one conflict with two versions, got %+v", conflicts)
+	}
+	if !conflicts[0].Timestamp.Equal(day.Add(11 * time.Hour)) {
+		t.Fatalf("unexpected conflict timestamp %v", conflicts[0].Timestamp)
+	}
+
+	again, conflicts := Merge(merged, merged)
+	if len(again) != len(merged) || len(conflicts) != 1 {
+		t.Fatalf("expected merging with itself to change nothing")
+	}
+}
@@ -0,0 +1,185 @@
+package journal
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// MoodScaleMin and MoodScaleMax bound both axes of a mood scale.
+const (
+	MoodScaleMin = -2
+	MoodScaleMax = 2
+)
+
+var ErrInvalidMoodTerm = errors.New("invalid mood term")
+
+// MoodScale places a mood on two axes: Valence runs from unpleasant to
+// pleasant and Energy from low to high, both from MoodScaleMin to
+// MoodScaleMax.
+type MoodScale struct {
+	Valence int
+	Energy  int
+}
+
+// MoodTerm is a mood in the vocabulary. Family is its core emotion on the
+// wheel; Synonyms are other words normalized to Name. Scale is optional, and
+// moods without one are left out of trends.
+type MoodTerm struct {
+	Name     string
+	Family   string
+	Synonyms []string
+	Scale    *MoodScale
+}
+
+// MoodVocabulary maps the words used for moods onto a set of terms. The zero
+// value knows no terms and leaves moods as they were written.
+type MoodVocabulary struct {
+	terms []MoodTerm
+	index map[string]int
+}
+
+// NewMoodVocabulary builds a vocabulary from terms. Names must be unique;
+// when a word is claimed by more than one term, the earliest one wins.
+func NewMoodVocabulary(terms []MoodTerm) (MoodVocabulary, error) {
+	v := MoodVocabulary{index: make(map[string]int)}
+	names := make(map[string]bool)
+	for _, term := range terms {
+		term.Name = moodKey(term.Name)
+		term.Family = moodKey(term.Family)
+		if term.Name == "" {
+			return MoodVocabulary{}, fmt.Errorf("%w: name is required", ErrInvalidMoodTerm)
+		}
+		if names[term.Name] {
+			return MoodVocabulary{}, fmt.Errorf("%w: %q is listed twice", ErrInvalidMoodTerm, term.Name)
+		}
+		names[term.Name] = true
+		if term.Scale != nil && !validMoodScale(*term.Scale) {
+			return MoodVocabulary{}, fmt.Errorf("%w: %q has a scale outside %d..%d", ErrInvalidMoodTerm, term.Name, MoodScaleMin, MoodScaleMax)
+		}
+
+		var synonyms []string
+		for _, synonym := range term.Synonyms {
+			if key := moodKey(synonym); key != "" && key != term.Name {
+				synonyms = append(synonyms, key)
+			}
+		}
+		term.Synonyms = synonyms
+
+		position := len(v.terms)
+		v.terms = append(v.terms, term)
+		for _, key := range append([]string{term.Name}, synonyms...) {
+			if _, taken := v.index[key]; !taken {
+				v.index[key] = position
+			}
+		}
+	}
+	return v, nil
+}
+
+// DefaultMoodVocabulary returns the built-in vocabulary.
+func DefaultMoodVocabulary() MoodVocabulary {
+	v, err := NewMoodVocabulary(DefaultMoodTerms())
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// Lookup finds the term mood is a name or synonym of.
+func (v MoodVocabulary) Lookup(mood string) (MoodTerm, bool) {
+	position, ok := v.index[moodKey(mood)]
+	if !ok {
+		return MoodTerm{}, false
+	}
+	return v.terms[position], true
+}
+
+// Normalize returns the name of the term mood belongs to, or mood trimmed
+// of surrounding space when the vocabulary does not know it.
+func (v MoodVocabulary) Normalize(mood string) string {
+	if term, ok := v.Lookup(mood); ok {
+		return term.Name
+	}
+	return strings.TrimSpace(mood)
+}
+
+// Families lists the core emotions in the order their first terms appear.
+func (v MoodVocabulary) Families() []string {
+	var families []string
+	seen := make(map[string]bool)
+	for _, term := range v.terms {
+		if term.Family != "" && !seen[term.Family] {
+			seen[term.Family] = true
+			families = append(families, term.Family)
+		}
+	}
+	return families
+}
+
+// Terms lists the terms of a family in vocabulary order.
+func (v MoodVocabulary) Terms(family string) []MoodTerm {
+	family = moodKey(family)
+	var terms []MoodTerm
+	for _, term := range v.terms {
+		if term.Family == family {
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
+
+// AllTerms lists every term in vocabulary order.
+func (v MoodVocabulary) AllTerms() []MoodTerm {
+	return append([]MoodTerm(nil), v.terms...)
+}
+
+// moodKey folds a mood to the form the vocabulary is indexed by: lower case,
+// single spaces and no surrounding punctuation, so "Calm." matches "calm".
+func moodKey(mood string) string {
+	mood = strings.Join(strings.Fields(strings.ToLower(mood)), " ")
+	return strings.Trim(mood, ".,;:!?'\"()")
+}
+
+func validMoodScale(scale MoodScale) bool {
+	return scale.Valence >= MoodScaleMin && scale.Valence <= MoodScaleMax &&
+		scale.Energy >= MoodScaleMin && scale.Energy <= MoodScaleMax
+}
+
+func scaled(valence int, energy int) *MoodScale {
+	return &MoodScale{Valence: valence, Energy: energy}
+}
+
+// DefaultMoodTerms returns the built-in terms, grouped by the core emotions
+// of the feelings wheel.
+func DefaultMoodTerms() []MoodTerm {
+	return []MoodTerm{
+		{Name: "happy", Family: "joyful", Synonyms: []string{"glad", "cheerful", "good", "great"}, Scale: scaled(2, 1)},
+		{Name: "excited", Family: "joyful", Synonyms: []string{"enthusiastic", "thrilled", "energized"}, Scale: scaled(2, 2)},
+		{Name: "grateful", Family: "joyful", Synonyms: []string{"thankful", "blessed"}, Scale: scaled(2, 0)},
+		{Name: "hopeful", Family: "joyful", Synonyms: []string{"optimistic", "inspired"}, Scale: scaled(1, 1)},
+		{Name: "playful", Family: "joyful", Synonyms: []string{"silly", "lighthearted"}, Scale: scaled(2, 2)},
+		{Name: "confident", Family: "powerful", Synonyms: []string{"proud", "strong"}, Scale: scaled(1, 1)},
+		{Name: "focused", Family: "powerful", Synonyms: []string{"motivated", "productive", "determined"}, Scale: scaled(1, 1)},
+		{Name: "appreciated", Family: "powerful", Synonyms: []string{"valued", "respected"}, Scale: scaled(1, 0)},
+		{Name: "calm", Family: "peaceful", Synonyms: []string{"peaceful", "serene", "tranquil", "relaxed", "at ease"}, Scale: scaled(1, -1)},
+		{Name: "content", Family: "peaceful", Synonyms: []string{"satisfied", "fulfilled"}, Scale: scaled(1, -1)},
+		{Name: "centered", Family: "peaceful", Synonyms: []string{"grounded", "balanced", "present", "mindful"}, Scale: scaled(1, 0)},
+		{Name: "loving", Family: "peaceful", Synonyms: []string{"affectionate", "tender", "compassionate"}, Scale: scaled(2, 0)},
+		{Name: "reflective", Family: "peaceful", Synonyms: []string{"thoughtful", "pensive", "contemplative"}, Scale: scaled(0, -1)},
+		{Name: "okay", Family: "peaceful", Synonyms: []string{"ok", "fine", "alright", "neutral", "meh"}, Scale: scaled(0, 0)},
+		{Name: "sad", Family: "sad", Synonyms: []string{"unhappy", "down", "blue", "low"}, Scale: scaled(-2, -1)},
+		{Name: "lonely", Family: "sad", Synonyms: []string{"isolated", "alone"}, Scale: scaled(-2, -1)},
+		{Name: "tired", Family: "sad", Synonyms: []string{"sleepy", "exhausted", "drained", "fatigued", "weary"}, Scale: scaled(-1, -2)},
+		{Name: "bored", Family: "sad", Synonyms: []string{"apathetic", "indifferent"}, Scale: scaled(-1, -2)},
+		{Name: "guilty", Family: "sad", Synonyms: []string{"ashamed", "remorseful", "regretful"}, Scale: scaled(-2, 0)},
+		{Name: "angry", Family: "mad", Synonyms: []string{"mad", "furious", "enraged"}, Scale: scaled(-2, 2)},
+		{Name: "frustrated", Family: "mad", Synonyms: []string{"annoyed", "irritated", "impatient"}, Scale: scaled(-1, 1)},
+		{Name: "hurt", Family: "mad", Synonyms: []string{"resentful", "bitter"}, Scale: scaled(-2, 0)},
+		{Name: "jealous", Family: "mad", Synonyms: []string{"envious"}, Scale: scaled(-1, 1)},
+		{Name: "anxious", Family: "scared", Synonyms: []string{"worried", "nervous", "uneasy", "afraid"}, Scale: scaled(-2, 1)},
+		{Name: "stressed", Family: "scared", Synonyms: []string{"overwhelmed", "pressured"}, Scale: scaled(-1, 2)},
+		{Name: "insecure", Family: "scared", Synonyms: []string{"inadequate", "self-conscious"}, Scale: scaled(-1, 0)},
+		{Name: "confused", Family: "scared", Synonyms: []string{"lost", "uncertain", "bewildered"}, Scale: scaled(-1, 0)},
+	}
+}
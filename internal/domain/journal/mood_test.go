@@ -0,0 +1,64 @@
+package journal
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMoodVocabularyNormalize(t *testing.T) {
+	v := DefaultMoodVocabulary()
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{input: "calm", want: "calm"},
+		{input: " Calm. ", want: "calm"},
+		{input: "peaceful", want: "calm"},
+		{input: "At  Ease!", want: "calm"},
+		{input: "Wistful", want: "Wistful"},
+		{input: "  ", want: ""},
+	}
+	for _, tt := range tests {
+		if got := v.Normalize(tt.input); got != tt.want {
+			t.Fatalf("Normalize(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+
+	term, ok := v.Lookup("Exhausted")
+	if !ok || term.Name != "tired" || term.Family != "sad" || term.Scale == nil || term.Scale.Energy != -2 {
+		t.Fatalf("unexpected term: %+v", term)
+	}
+	if got := (MoodVocabulary{}).Normalize(" Calm. "); got != "Calm." {
+		t.Fatalf("expected the zero vocabulary to only trim, got %q", got)
+	}
+}
+
+func TestNewMoodVocabulary(t *testing.T) {
+	v, err := NewMoodVocabulary([]MoodTerm{
+		{Name: "Serene", Family: "Peaceful", Synonyms: []string{"calm", "serene"}},
+		{Name: "calm", Family: "peaceful", Scale: &MoodScale{Valence: 1, Energy: -1}},
+		{Name: "sad", Family: "sad"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := v.Normalize("calm"); got != "serene" {
+		t.Fatalf("expected the earliest term to claim calm, got %q", got)
+	}
+	if families := v.Families(); len(families) != 2 || families[0] != "peaceful" {
+		t.Fatalf("unexpected families: %v", families)
+	}
+	if terms := v.Terms("Peaceful"); len(terms) != 2 || terms[0].Scale != nil {
+		t.Fatalf("unexpected terms: %+v", terms)
+	}
+
+	for _, terms := range [][]MoodTerm{
+		{{Name: " "}},
+		{{Name: "calm"}, {Name: "Calm"}},
+		{{Name: "calm", Scale: &MoodScale{Valence: 3}}},
+	} {
+		if _, err := NewMoodVocabulary(terms); !errors.Is(err, ErrInvalidMoodTerm) {
+			t.Fatalf("expected ErrInvalidMoodTerm for %+v, got %v", terms, err)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package journal
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var ErrInvalidPrompt = errors.New("invalid prompt")
+
+// Prompt is a reflection question. A prompt for a precept is asked only about
+// that precept; a prompt for a foundation, or an object within one, may be
+// asked about any precept of an entry written with it.
+type Prompt struct {
+	Precept    Precept
+	Foundation Foundation
+	Text       string
+}
+
+// PromptBank holds the questions guided reflection picks from.
+type PromptBank struct {
+	prompts []Prompt
+}
+
+// NewPromptBank validates prompts. Each needs text and exactly one known
+// precept or foundation; a question repeated for the same target is kept
+// once.
+func NewPromptBank(prompts []Prompt) (PromptBank, error) {
+	var bank PromptBank
+	seen := make(map[string]bool)
+	for _, prompt := range prompts {
+		prompt.Text = strings.TrimSpace(prompt.Text)
+		if prompt.Text == "" {
+			return PromptBank{}, fmt.Errorf("%w: empty question", ErrInvalidPrompt)
+		}
+		switch {
+		case prompt.Precept != "" && prompt.Foundation != "":
+			return PromptBank{}, fmt.Errorf("%w: %q has both a precept and a foundation", ErrInvalidPrompt, prompt.Text)
+		case prompt.Precept != "":
+			if !IsKnownPrecept(prompt.Precept) {
+				return PromptBank{}, fmt.Errorf("%w: %q: unknown precept %q", ErrInvalidPrompt, prompt.Text, prompt.Precept)
+			}
+		case prompt.Foundation != "":
+			if !IsKnownFoundation(prompt.Foundation) {
+				return PromptBank{}, fmt.Errorf("%w: %q: unknown foundation %q", ErrInvalidPrompt, prompt.Text, prompt.Foundation)
+			}
+		default:
+			return PromptBank{}, fmt.Errorf("%w: %q needs a precept or a foundation", ErrInvalidPrompt, prompt.Text)
+		}
+
+		key := string(prompt.Precept) + "\x00" + string(prompt.Foundation) + "\x00" + strings.ToLower(prompt.Text)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		bank.prompts = append(bank.prompts, prompt)
+	}
+	return bank, nil
+}
+
+// DefaultPromptBank returns the built-in questions.
+func DefaultPromptBank() PromptBank {
+	bank, err := NewPromptBank(DefaultPrompts())
+	if err != nil {
+		panic(err)
+	}
+	return bank
+}
+
+// Prompts returns the questions in the bank.
+func (b PromptBank) Prompts() []Prompt {
+	return append([]Prompt(nil), b.prompts...)
+}
+
+// Candidates lists the questions that may be asked about precept in an entry
+// written with foundation: the precept's own, then those of the foundation
+// and of its object.
+func (b PromptBank) Candidates(precept Precept, foundation Foundation) []string {
+	var texts []string
+	for _, prompt := range b.prompts {
+		if prompt.Precept == precept {
+			texts = append(texts, prompt.Text)
+		}
+	}
+	for _, prompt := range b.prompts {
+		if prompt.Foundation == "" {
+			continue
+		}
+		if prompt.Foundation == foundation.Root() || prompt.Foundation == foundation {
+			texts = append(texts, prompt.Text)
+		}
+	}
+	return texts
+}
+
+// Pick chooses the question to ask about precept on date. The choice rotates
+// by day and differs between precepts, so the same date always gets the same
+// question. Questions in recent are skipped unless every candidate is
+// recent. It returns "" when the bank has no candidate.
+func (b PromptBank) Pick(date time.Time, precept Precept, foundation Foundation, recent map[string]bool) string {
+	candidates := b.Candidates(precept, foundation)
+	fresh := make([]string, 0, len(candidates))
+	for _, text := range candidates {
+		if !recent[text] {
+			fresh = append(fresh, text)
+		}
+	}
+	if len(fresh) > 0 {
+		candidates = fresh
+	}
+	if len(candidates) == 0 {
+		return ""
+	}
+
+	offset := 0
+	for i, info := range AllPrecepts() {
+		if info.ID == precept {
+			offset = i
+		}
+	}
+	day := int(normalizeDate(date).Unix() / (24 * 60 * 60))
+	return candidates[(day+offset)%len(candidates)]
+}
+
+// WithQuestions records the question each reflection answered. Questions for
+// precepts without a reflection are dropped.
+func WithQuestions(questions map[Precept]string) EntryOption {
+	return func(e *Entry) {
+		for precept, question := range questions {
+			question = strings.TrimSpace(question)
+			if _, ok := e.Reflections[precept]; !ok || question == "" {
+				continue
+			}
+			if e.Questions == nil {
+				e.Questions = make(map[Precept]string)
+			}
+			e.Questions[precept] = question
+		}
+	}
+}
+
+// DefaultPrompts returns the built-in questions: several for each precept
+// and a few for each foundation.
+func DefaultPrompts() []Prompt {
+	return []Prompt{
+		{Precept: ReverenceForLife, Text: "Where did you protect life today, however small the creature?"},
+		{Precept: ReverenceForLife, Text: "When did anger or fear make you want to harm, even in thought?"},
+		{Precept: ReverenceForLife, Text: "What did you consume or buy today, and what life did it cost?"},
+		{Precept: ReverenceForLife, Text: "Whose view did you hold too tightly today?"},
+
+		{Precept: TrueHappiness, Text: "What did you share today that you could have kept?"},
+		{Precept: TrueHappiness, Text: "Where did you look for happiness in something you do not need?"},
+		{Precept: TrueHappiness, Text: "What conditions for happiness are already present for you?"},
+		{Precept: TrueHappiness, Text: "Whose suffering did you notice today, and how did you respond?"},
+
+		{Precept: TrueLove, Text: "How did you show care without trying to possess?"},
+		{Precept: TrueLove, Text: "Where did craving pass for love today?"},
+		{Precept: TrueLove, Text: "How did you honor your commitments to the people close to you?"},
+		{Precept: TrueLove, Text: "What did you do today to nourish loving kindness in yourself?"},
+
+		{Precept: LovingSpeechDeepListening, Text: "When did you listen only to understand, not to reply?"},
+		{Precept: LovingSpeechDeepListening, Text: "What did you say today that you would like to take back?"},
+		{Precept: LovingSpeechDeepListening, Text: "Where did you stay silent when speaking up was needed?"},
+		{Precept: LovingSpeechDeepListening, Text: "Whose words stayed with you today, and why?"},
+
+		{Precept: NourishmentAndHealing, Text: "What did you take in today through your eyes and ears?"},
+		{Precept: NourishmentAndHealing, Text: "Which meal did you eat mindfully, and which on autopilot?"},
+		{Precept: NourishmentAndHealing, Text: "What did you reach for to cover loneliness, anxiety or boredom?"},
+		{Precept: NourishmentAndHealing, Text: "What nourished your body and mind today?"},
+
+		{Foundation: FoundationKaya, Text: "Where in your body did you feel this?"},
+		{Foundation: FoundationKaya, Text: "What did your breath do when this came up?"},
+		{Foundation: FoundationVedana, Text: "Was the feeling around this pleasant, unpleasant or neutral, and what followed it?"},
+		{Foundation: FoundationVedana, Text: "Did you want more of this feeling, or less?"},
+		{Foundation: FoundationCit, Text: "What state was your mind in when this happened?"},
+		{Foundation: FoundationCit, Text: "Was your mind contracted or spacious around this?"},
+		{Foundation: FoundationDhamma, Text: "Which hindrance, if any, was present here?"},
+		{Foundation: FoundationDhamma, Text: "What arose here, and how did it pass away?"},
+	}
+}
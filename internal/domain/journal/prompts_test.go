@@ -0,0 +1,110 @@
+package journal
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNewPromptBank(t *testing.T) {
+	bank, err := NewPromptBank([]Prompt{
+		{Precept: TrueLove, Text: "Who did you hold today?"},
+		{Precept: TrueLove, Text: " who did you hold today? "},
+		{Foundation: "dhamma.hindrances", Text: "Which hindrance?"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(bank.Prompts()) != 2 {
+		t.Fatalf("expected the repeated question to be kept once, got %+v", bank.Prompts())
+	}
+
+	for _, prompt := range []Prompt{
+		{Precept: TrueLove},
+		{Text: "Whose?"},
+		{Precept: "kindness", Text: "Whose?"},
+		{Foundation: "dhamma.breath", Text: "Whose?"},
+		{Precept: TrueLove, Foundation: FoundationKaya, Text: "Whose?"},
+	} {
+		if _, err := NewPromptBank([]Prompt{prompt}); !errors.Is(err, ErrInvalidPrompt) {
+			t.Fatalf("expected ErrInvalidPrompt for %+v, got %v", prompt, err)
+		}
+	}
+
+	defaults := DefaultPromptBank()
+	for _, info := range AllPrecepts() {
+		if len(defaults.Candidates(info.ID, "")) < 3 {
+			t.Fatalf("expected several built-in questions for %s", info.ID)
+		}
+	}
+	for _, foundation := range Foundations() {
+		if len(defaults.Candidates("", foundation)) == 0 {
+			t.Fatalf("expected built-in questions for %s", foundation)
+		}
+	}
+}
+
+func TestPromptBankPick(t *testing.T) {
+	bank, err := NewPromptBank([]Prompt{
+		{Precept: TrueLove, Text: "a"},
+		{Precept: TrueLove, Text: "b"},
+		{Precept: TrueLove, Text: "c"},
+		{Foundation: FoundationKaya, Text: "body"},
+		{Foundation: "kaya.breath", Text: "breath"},
+		{Foundation: FoundationDhamma, Text: "dhamma"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := bank.Candidates(TrueLove, "kaya.breath"); len(got) != 5 || got[3] != "body" || got[4] != "breath" {
+		t.Fatalf("unexpected candidates: %v", got)
+	}
+
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	first := bank.Pick(day, TrueLove, FoundationDhamma, nil)
+	if again := bank.Pick(day.Add(20*time.Hour), TrueLove, FoundationDhamma, nil); again != first {
+		t.Fatalf("expected the same question all day, got %q and %q", first, again)
+	}
+	seen := map[string]bool{}
+	for i := 0; i < 4; i++ {
+		seen[bank.Pick(day.AddDate(0, 0, i), TrueLove, FoundationDhamma, nil)] = true
+	}
+	if len(seen) != 4 {
+		t.Fatalf("expected the questions to rotate by day, got %v", seen)
+	}
+
+	recent := map[string]bool{"a": true, "b": true, "dhamma": true}
+	if got := bank.Pick(day, TrueLove, FoundationDhamma, recent); got != "c" {
+		t.Fatalf("expected the only question not asked recently, got %q", got)
+	}
+	recent["c"] = true
+	if got := bank.Pick(day, TrueLove, FoundationDhamma, recent); got == "" {
+		t.Fatal("expected a question when every one was asked recently")
+	}
+	if got := bank.Pick(day, TrueHappiness, FoundationCit, nil); got != "" {
+		t.Fatalf("expected no question without candidates, got %q", got)
+	}
+}
+
+func TestWithQuestions(t *testing.T) {
+	at := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	entry, err := NewEntry(at, map[Precept]string{TrueLove: "called home"}, "", "", "", at, WithQuestions(map[Precept]string{
+		TrueLove:      "Who did you hold today?",
+		TrueHappiness: "What did you share?",
+	}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(entry.Questions) != 1 || entry.Questions[TrueLove] != "Who did you hold today?" {
+		t.Fatalf("expected only the answered question, got %+v", entry.Questions)
+	}
+
+	plain, err := NewEntry(at, map[Precept]string{TrueLove: "called home"}, "", "", "", at)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if plain.Questions != nil || plain.Fingerprint() == entry.Fingerprint() {
+		t.Fatal("expected the question to be part of the entry's fingerprint")
+	}
+}
@@ -12,4 +12,6 @@ type Repository interface {
 	Save(ctx context.Context, entry Entry) error
 	Latest(ctx context.Context) (*Entry, error)
 	List(ctx context.Context) ([]Entry, error)
+	// Replace swaps the stored entries for entries, as after a merge.
+	Replace(ctx context.Context, entries []Entry) error
 }
@@ -0,0 +1,86 @@
+package journal
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ReviewPeriod is the span a review looks back over.
+type ReviewPeriod string
+
+const (
+	ReviewWeek  ReviewPeriod = "week"
+	ReviewMonth ReviewPeriod = "month"
+	ReviewYear  ReviewPeriod = "year"
+)
+
+var ErrUnknownReviewPeriod = errors.New("unknown review period")
+
+// Review marks an entry as a review of the period starting on Start, written
+// by looking back over it rather than about a day of practice.
+type Review struct {
+	Period ReviewPeriod
+	Start  time.Time
+}
+
+// ParseReviewPeriod accepts a period or its first letter.
+func ParseReviewPeriod(input string) (ReviewPeriod, error) {
+	switch strings.ToLower(strings.TrimSpace(input)) {
+	case "w", "week":
+		return ReviewWeek, nil
+	case "m", "month":
+		return ReviewMonth, nil
+	case "y", "year":
+		return ReviewYear, nil
+	default:
+		return "", fmt.Errorf("%w: %q (expected week, month or year)", ErrUnknownReviewPeriod, input)
+	}
+}
+
+// NewReview returns the review of the period containing date. Weeks start on
+// Monday.
+func NewReview(period ReviewPeriod, date time.Time) (Review, error) {
+	period, err := ParseReviewPeriod(string(period))
+	if err != nil {
+		return Review{}, err
+	}
+	day := normalizeDate(date)
+	var start time.Time
+	switch period {
+	case ReviewWeek:
+		start = day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	case ReviewMonth:
+		start = time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+	case ReviewYear:
+		start = time.Date(day.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+	}
+	return Review{Period: period, Start: start}, nil
+}
+
+// End returns the last day of the period.
+func (r Review) End() time.Time {
+	switch r.Period {
+	case ReviewWeek:
+		return r.Start.AddDate(0, 0, 6)
+	case ReviewMonth:
+		return r.Start.AddDate(0, 1, -1)
+	default:
+		return r.Start.AddDate(1, 0, -1)
+	}
+}
+
+// Previous returns the review of the period before this one.
+func (r Review) Previous() Review {
+	previous, _ := NewReview(r.Period, r.Start.AddDate(0, 0, -1))
+	return previous
+}
+
+// WithReview marks the entry as the review r. The review must be for a
+// known period; use NewReview to build one.
+func WithReview(r Review) EntryOption {
+	return func(e *Entry) {
+		e.Review = &r
+	}
+}
@@ -0,0 +1,58 @@
+package journal
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNewReview(t *testing.T) {
+	day := time.Date(2024, 5, 1, 15, 0, 0, 0, time.UTC) // a Wednesday
+	cases := []struct {
+		period     ReviewPeriod
+		start, end string
+	}{
+		{ReviewWeek, "2024-04-29", "2024-05-05"},
+		{ReviewMonth, "2024-05-01", "2024-05-31"},
+		{"y", "2024-01-01", "2024-12-31"},
+	}
+	for _, tc := range cases {
+		review, err := NewReview(tc.period, day)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.period, err)
+		}
+		if got := review.Start.Format("2006-01-02"); got != tc.start {
+			t.Fatalf("%s: expected start %s, got %s", tc.period, tc.start, got)
+		}
+		if got := review.End().Format("2006-01-02"); got != tc.end {
+			t.Fatalf("%s: expected end %s, got %s", tc.period, tc.end, got)
+		}
+	}
+
+	review, _ := NewReview(ReviewMonth, day)
+	if got := review.Previous(); got.Start.Format("2006-01-02") != "2024-04-01" || got.Period != ReviewMonth {
+		t.Fatalf("expected April as the previous month, got %+v", got)
+	}
+	if _, err := NewReview("fortnight", day); !errors.Is(err, ErrUnknownReviewPeriod) {
+		t.Fatalf("expected ErrUnknownReviewPeriod, got %v", err)
+	}
+}
+
+func TestNewEntryWithReview(t *testing.T) {
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	entry, err := NewEntry(day, nil, "a quiet week", "", "", day, WithReview(Review{Period: ReviewWeek, Start: day}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if entry.Review == nil || entry.Review.Start.Format("2006-01-02") != "2024-04-29" {
+		t.Fatalf("expected the review to start on Monday, got %+v", entry.Review)
+	}
+	plain, _ := NewEntry(day, nil, "a quiet week", "", "", day)
+	if plain.Fingerprint() == entry.Fingerprint() {
+		t.Fatal("expected the review to change the fingerprint")
+	}
+
+	if _, err := NewEntry(day, nil, "note", "", "", day, WithReview(Review{Period: "decade", Start: day})); !errors.Is(err, ErrUnknownReviewPeriod) {
+		t.Fatalf("expected ErrUnknownReviewPeriod, got %v", err)
+	}
+}
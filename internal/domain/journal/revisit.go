@@ -0,0 +1,52 @@
+package journal
+
+import (
+	"context"
+	"time"
+)
+
+// revisitIntervals space out re-reading an entry: the first time a month
+// after it was written, then each interval after the previous revisit, the
+// last one repeating.
+var revisitIntervals = []time.Duration{
+	30 * 24 * time.Hour,
+	90 * 24 * time.Hour,
+	180 * 24 * time.Hour,
+	365 * 24 * time.Hour,
+}
+
+// Revisit records the times an entry was re-read, linked to the entry by its
+// fingerprint. Editing an entry changes its fingerprint, which starts its
+// revisits over.
+type Revisit struct {
+	Entry string
+	At    []time.Time
+}
+
+// RevisitRepository stores when entries were re-read.
+type RevisitRepository interface {
+	List(ctx context.Context) ([]Revisit, error)
+	// Record adds at to the revisits of the entry with fingerprint.
+	Record(ctx context.Context, fingerprint string, at time.Time) error
+}
+
+// Last returns the latest revisit, or zero when the entry was never
+// revisited.
+func (r Revisit) Last() time.Time {
+	var last time.Time
+	for _, at := range r.At {
+		if at.After(last) {
+			last = at
+		}
+	}
+	return last
+}
+
+// DueAt returns when entry is next due to be re-read given its revisits.
+func DueAt(entry Entry, revisit Revisit) time.Time {
+	if len(revisit.At) == 0 {
+		return entry.Date.Add(revisitIntervals[0])
+	}
+	interval := revisitIntervals[min(len(revisit.At), len(revisitIntervals)-1)]
+	return revisit.Last().Add(interval)
+}
@@ -0,0 +1,28 @@
+package journal
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDueAt(t *testing.T) {
+	entry := Entry{Date: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	if got, want := DueAt(entry, Revisit{}), entry.Date.AddDate(0, 0, 30); !got.Equal(want) {
+		t.Fatalf("expected first due %v, got %v", want, got)
+	}
+
+	first := time.Date(2024, 2, 5, 8, 0, 0, 0, time.UTC)
+	revisit := Revisit{Entry: "abc", At: []time.Time{first}}
+	if got, want := DueAt(entry, revisit), first.AddDate(0, 0, 90); !got.Equal(want) {
+		t.Fatalf("expected due %v after one revisit, got %v", want, got)
+	}
+
+	var at []time.Time
+	for i := 0; i < 6; i++ {
+		at = append(at, first.AddDate(0, i, 0))
+	}
+	revisit = Revisit{Entry: "abc", At: at}
+	if got, want := DueAt(entry, revisit), at[5].AddDate(0, 0, 365); !got.Equal(want) {
+		t.Fatalf("expected the longest interval to repeat, got %v want %v", got, want)
+	}
+}
@@ -0,0 +1,79 @@
+package journal
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Tone is the feeling tone of an experience.
+type Tone string
+
+const (
+	TonePleasant   Tone = "pleasant"
+	ToneUnpleasant Tone = "unpleasant"
+	ToneNeutral    Tone = "neutral"
+)
+
+// MaxVedanaIntensity is the strongest intensity; 0 means it was not rated.
+const MaxVedanaIntensity = 5
+
+var (
+	ErrUnknownTone      = errors.New("unknown feeling tone")
+	ErrInvalidIntensity = errors.New("intensity must be between 1 and 5")
+	ErrVedanaFoundation = errors.New("feeling tone can only be recorded with the vedana foundation")
+)
+
+// Vedana records the feeling tone noted in an entry whose foundation is
+// FoundationVedana. Only Tone is required.
+type Vedana struct {
+	Tone      Tone
+	Intensity int
+	Location  string
+	Trigger   string
+}
+
+// Tones lists the feeling tones in display order.
+func Tones() []Tone {
+	return []Tone{TonePleasant, ToneUnpleasant, ToneNeutral}
+}
+
+// ParseTone accepts a tone or its first letter.
+func ParseTone(input string) (Tone, error) {
+	switch strings.ToLower(strings.TrimSpace(input)) {
+	case "p", "pleasant":
+		return TonePleasant, nil
+	case "u", "unpleasant":
+		return ToneUnpleasant, nil
+	case "n", "neutral":
+		return ToneNeutral, nil
+	default:
+		return "", fmt.Errorf("%w: %q (expected pleasant, unpleasant or neutral)", ErrUnknownTone, input)
+	}
+}
+
+// NewVedana validates a feeling tone record. An intensity of 0 leaves it
+// unrated.
+func NewVedana(tone Tone, intensity int, location string, trigger string) (Vedana, error) {
+	tone, err := ParseTone(string(tone))
+	if err != nil {
+		return Vedana{}, err
+	}
+	if intensity < 0 || intensity > MaxVedanaIntensity {
+		return Vedana{}, ErrInvalidIntensity
+	}
+	return Vedana{
+		Tone:      tone,
+		Intensity: intensity,
+		Location:  strings.TrimSpace(location),
+		Trigger:   strings.TrimSpace(trigger),
+	}, nil
+}
+
+// WithVedana records the feeling tone of the entry. NewEntry rejects it
+// unless the foundation is FoundationVedana or an object within it.
+func WithVedana(vedana Vedana) EntryOption {
+	return func(e *Entry) {
+		e.Vedana = &vedana
+	}
+}
@@ -0,0 +1,47 @@
+package journal
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNewVedana(t *testing.T) {
+	vedana, err := NewVedana("U", 3, " chest ", " email ")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if vedana != (Vedana{Tone: ToneUnpleasant, Intensity: 3, Location: "chest", Trigger: "email"}) {
+		t.Fatalf("unexpected vedana: %+v", vedana)
+	}
+	if _, err := NewVedana("sour", 0, "", ""); !errors.Is(err, ErrUnknownTone) {
+		t.Fatalf("expected ErrUnknownTone, got %v", err)
+	}
+	if _, err := NewVedana(TonePleasant, 6, "", ""); !errors.Is(err, ErrInvalidIntensity) {
+		t.Fatalf("expected ErrInvalidIntensity, got %v", err)
+	}
+}
+
+func TestNewEntryWithVedana(t *testing.T) {
+	at := time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)
+	vedana := Vedana{Tone: TonePleasant, Intensity: 2}
+	entry, err := NewEntry(at, nil, "warm tea", "", FoundationVedana, at, WithVedana(vedana))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if entry.Vedana == nil || *entry.Vedana != vedana {
+		t.Fatalf("unexpected vedana: %+v", entry.Vedana)
+	}
+
+	without, err := NewEntry(at, nil, "warm tea", "", FoundationVedana, at)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if without.Fingerprint() == entry.Fingerprint() {
+		t.Fatal("expected the feeling tone to change the fingerprint")
+	}
+
+	if _, err := NewEntry(at, nil, "warm tea", "", FoundationDhamma, at, WithVedana(vedana)); !errors.Is(err, ErrVedanaFoundation) {
+		t.Fatalf("expected ErrVedanaFoundation, got %v", err)
+	}
+}
@@ -0,0 +1,9 @@
+package user
+
+import "context"
+
+// Repository defines storage behavior for the user registry.
+type Repository interface {
+	List(ctx context.Context) ([]User, error)
+	Save(ctx context.Context, user User) error
+}
@@ -0,0 +1,42 @@
+package user
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidName  = errors.New("user name must be 1-32 lowercase letters, digits, '-' or '_'")
+	ErrExists       = errors.New("user already exists")
+	ErrNotFound     = errors.New("user not found")
+	ErrUnauthorized = errors.New("invalid or revoked token")
+)
+
+var namePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,31}$`)
+
+// User is a practitioner registered with the multi-user server. Only a hash
+// of the API token is kept.
+type User struct {
+	Name      string
+	TokenHash string
+	CreatedAt time.Time
+	RevokedAt time.Time
+}
+
+func NewUser(name string, tokenHash string, createdAt time.Time) (User, error) {
+	name = strings.TrimSpace(name)
+	if !namePattern.MatchString(name) {
+		return User{}, ErrInvalidName
+	}
+	return User{
+		Name:      name,
+		TokenHash: tokenHash,
+		CreatedAt: createdAt.UTC(),
+	}, nil
+}
+
+func (u User) Revoked() bool {
+	return !u.RevokedAt.IsZero()
+}
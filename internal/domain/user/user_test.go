@@ -0,0 +1,41 @@
+package user
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNewUser(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr error
+	}{
+		{name: "simple", input: "anna"},
+		{name: "trims", input: "  anna-b_2 "},
+		{name: "empty", input: "", wantErr: ErrInvalidName},
+		{name: "uppercase", input: "Anna", wantErr: ErrInvalidName},
+		{name: "path traversal", input: "../anna", wantErr: ErrInvalidName},
+		{name: "leading dash", input: "-anna", wantErr: ErrInvalidName},
+		{name: "too long", input: "abcdefghijklmnopqrstuvwxyz0123456", wantErr: ErrInvalidName},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, err := NewUser(tt.input, "hash", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if u.Revoked() {
+				t.Fatalf("expected new user to be active")
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package atomicfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteReplacesFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "journal.json")
+	if err := os.WriteFile(path, []byte("old"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	if err := Write(path, []byte("new"), 0o600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != "new" {
+		t.Fatalf("expected the new contents, got %q, %v", data, err)
+	}
+	info, err := os.Stat(path)
+	if err != nil || info.Mode().Perm() != 0o600 {
+		t.Fatalf("expected 0600 permissions, got %v, %v", info, err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("expected no temporary files left behind, got %v, %v", entries, err)
+	}
+}
+
+func TestWriteFailsWithoutDirectory(t *testing.T) {
+	if err := Write(filepath.Join(t.TempDir(), "missing", "journal.json"), []byte("x"), 0o600); err == nil {
+		t.Fatal("expected an error for a missing directory")
+	}
+}
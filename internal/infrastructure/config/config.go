@@ -0,0 +1,171 @@
+// Package config reads and writes the user's mt settings file.
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/thatnerdjosh/mindfulness/internal/infrastructure/atomicfile"
+)
+
+// Config holds user settings. Every field is optional; the zero value means
+// "use the defaults".
+type Config struct {
+	WebDAV  WebDAV  `json:"webdav"`
+	Log     Log     `json:"log"`
+	Journal Journal `json:"journal"`
+	Mood    Mood    `json:"mood"`
+	Prompts Prompts `json:"prompts"`
+	Nudges  Nudges  `json:"nudges"`
+}
+
+// WebDAV describes the remote used by mt sync push and pull.
+type WebDAV struct {
+	URL      string `json:"url,omitempty"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+}
+
+// Configured reports whether a remote URL is set.
+func (w WebDAV) Configured() bool {
+	return strings.TrimSpace(w.URL) != ""
+}
+
+// Log sets when the adherence log is archived into compressed segments and
+// how long the segments are kept. Durations are Go durations such as "72h"
+// or whole days such as "90d"; empty or zero values turn a rule off, except
+// that an unset RotateBytes keeps the built-in size limit.
+type Log struct {
+	RotateBytes  int64  `json:"rotate_bytes,omitempty"`
+	RotateAfter  string `json:"rotate_after,omitempty"`
+	KeepSegments int    `json:"keep_segments,omitempty"`
+	KeepFor      string `json:"keep_for,omitempty"`
+}
+
+// Journal sets what other commands write to the journal.
+type Journal struct {
+	// LinkAdherence journals the notes given when adherence changes. Unset
+	// means on.
+	LinkAdherence *bool `json:"link_adherence,omitempty"`
+}
+
+// LinksAdherence reports whether adherence changes are journaled.
+func (j Journal) LinksAdherence() bool {
+	return j.LinkAdherence == nil || *j.LinkAdherence
+}
+
+// Mood extends the mood vocabulary. Terms are added to the built-in ones,
+// replacing any of the same name, or used alone with ReplaceDefaults.
+type Mood struct {
+	Terms           []MoodTerm `json:"terms,omitempty"`
+	ReplaceDefaults bool       `json:"replace_defaults,omitempty"`
+}
+
+// MoodTerm is a mood word with its synonyms. Valence and Energy run from -2
+// to 2 and must be set together to put the mood on the trend scale.
+type MoodTerm struct {
+	Name     string   `json:"name"`
+	Family   string   `json:"family,omitempty"`
+	Synonyms []string `json:"synonyms,omitempty"`
+	Valence  *int     `json:"valence,omitempty"`
+	Energy   *int     `json:"energy,omitempty"`
+}
+
+// Prompts extends the reflection questions guided mode asks. Questions are
+// added to the built-in ones, or used alone with ReplaceDefaults.
+type Prompts struct {
+	Questions       []Question `json:"questions,omitempty"`
+	ReplaceDefaults bool       `json:"replace_defaults,omitempty"`
+}
+
+// Question is a reflection question for one precept, or for a foundation
+// such as "kaya" or "dhamma.hindrances" to be asked about any precept.
+type Question struct {
+	Precept    string `json:"precept,omitempty"`
+	Foundation string `json:"foundation,omitempty"`
+	Text       string `json:"text"`
+}
+
+// Nudges tunes how guided mode orders the precepts. Unset weights keep the
+// built-in ones; Disabled asks them in the usual order.
+type Nudges struct {
+	DayWeight   *float64 `json:"day_weight,omitempty"`
+	BreakWeight *float64 `json:"break_weight,omitempty"`
+	BreakDays   *int     `json:"break_days,omitempty"`
+	QuietDays   *int     `json:"quiet_days,omitempty"`
+	Disabled    bool     `json:"disabled,omitempty"`
+}
+
+// ParseDuration parses a Go duration or a whole number of days such as
+// "30d". An empty string is zero.
+func ParseDuration(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, nil
+	}
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid duration %q: expected a whole number of days", value)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration < 0 {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+	return duration, nil
+}
+
+// DefaultPath returns $XDG_CONFIG_HOME/mt/config.json, falling back to
+// ~/.config.
+func DefaultPath() (string, error) {
+	configHome := strings.TrimSpace(os.Getenv("XDG_CONFIG_HOME"))
+	if configHome == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("resolve home directory: %w", err)
+		}
+		configHome = filepath.Join(home, ".config")
+	}
+	return filepath.Join(configHome, "mt", "config.json"), nil
+}
+
+// Load reads the config file. A missing file yields the zero Config.
+func Load(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return Config{}, nil
+		}
+		return Config{}, fmt.Errorf("read config file: %w", err)
+	}
+	var cfg Config
+	if len(strings.TrimSpace(string(data))) == 0 {
+		return cfg, nil
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return Config{}, fmt.Errorf("decode config file %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// Save writes the config file readable only by the owner, since it may hold
+// credentials.
+func Save(path string, cfg Config) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("create config directory: %w", err)
+	}
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode config file: %w", err)
+	}
+	data = append(data, '\n')
+
+	return atomicfile.Write(path, data, 0o600)
+}
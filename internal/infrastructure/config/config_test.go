@@ -0,0 +1,107 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLoadMissingFile(t *testing.T) {
+	cfg, err := Load(filepath.Join(t.TempDir(), "config.json"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.WebDAV.Configured() {
+		t.Fatalf("expected empty config, got %+v", cfg)
+	}
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mt", "config.json")
+	want := Config{WebDAV: WebDAV{URL: "https://dav.example/mt", Username: "alice", Password: "secret"}}
+	if err := Save(path, want); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Fatalf("expected 0600 permissions, got %v", info.Mode().Perm())
+	}
+
+	got, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %+v, got %+v", want, got)
+	}
+}
+
+func TestLoadInvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte("{"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Fatalf("expected decode error")
+	}
+}
+
+func TestDefaultPathUsesXDGConfigHome(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", "/tmp/xdg")
+	path, err := DefaultPath()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if path != filepath.Join("/tmp/xdg", "mt", "config.json") {
+		t.Fatalf("unexpected path %s", path)
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{input: "", want: 0},
+		{input: "90d", want: 90 * 24 * time.Hour},
+		{input: "36h", want: 36 * time.Hour},
+		{input: "-1d", wantErr: true},
+		{input: "soon", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseDuration(tt.input)
+		if tt.wantErr {
+			if err == nil {
+				t.Fatalf("%q: expected an error", tt.input)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Fatalf("%q: expected %s, got %s (%v)", tt.input, tt.want, got, err)
+		}
+	}
+}
+
+func TestJournalLinksAdherence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"journal": {"link_adherence": false}}`), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Journal.LinksAdherence() {
+		t.Fatal("expected linking to be turned off")
+	}
+	if !(Journal{}).LinksAdherence() {
+		t.Fatal("expected linking to be on by default")
+	}
+}
@@ -1,6 +1,7 @@
 package flatfile
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -12,6 +13,7 @@ import (
 
 	"github.com/thatnerdjosh/mindfulness/internal/domain/adherence"
 	"github.com/thatnerdjosh/mindfulness/internal/domain/journal"
+	"github.com/thatnerdjosh/mindfulness/internal/infrastructure/atomicfile"
 )
 
 // DefaultAdherencePath returns the default JSON adherence file path.
@@ -42,13 +44,22 @@ func DefaultAdherenceLogPath() (string, error) {
 
 // AdherenceRepository stores adherence state and log entries in flat files.
 type AdherenceRepository struct {
-	mu      sync.RWMutex
-	path    string
-	logPath string
-	state   adherence.Adherence
+	mu       sync.RWMutex
+	path     string
+	logPath  string
+	state    adherence.Adherence
+	opts     options
+	warnings []LoadWarning
+	// unparsedState holds state keys skipped by a lenient load, written back
+	// verbatim.
+	unparsedState map[string]json.RawMessage
+	damaged       bool
+	// rotations counts the times the log was archived since opening.
+	rotations int
+	now       func() time.Time
 }
 
-func NewAdherenceRepository(path string, logPath string) (*AdherenceRepository, error) {
+func NewAdherenceRepository(path string, logPath string, opts ...Option) (*AdherenceRepository, error) {
 	path = strings.TrimSpace(path)
 	if path == "" {
 		return nil, fmt.Errorf("adherence path is required")
@@ -69,6 +80,8 @@ func NewAdherenceRepository(path string, logPath string) (*AdherenceRepository,
 		path:    path,
 		logPath: logPath,
 		state:   adherence.DefaultAdherence(),
+		opts:    buildOptions(opts),
+		now:     time.Now,
 	}
 	if err := repo.load(); err != nil {
 		return nil, err
@@ -103,23 +116,121 @@ func (r *AdherenceRepository) AppendLog(_ context.Context, entry adherence.Adher
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	record := adherenceLogRecord{
-		Timestamp: entry.At.UTC().Format(time.RFC3339Nano),
-		Precept:   string(entry.Precept),
-		From:      entry.From,
-		To:        entry.To,
-		Note:      strings.TrimSpace(entry.Note),
-	}
-
-	data, err := json.Marshal(record)
+	data, err := json.Marshal(logRecordFromEntry(entry))
 	if err != nil {
 		return fmt.Errorf("encode adherence log entry: %w", err)
 	}
 	data = append(data, '\n')
-	return appendFileAtomic(r.logPath, data, 0o600)
+	if err := appendFileAtomic(r.logPath, data, 0o600); err != nil {
+		return err
+	}
+	_, _, err = r.rotateLocked(false)
+	return err
+}
+
+// Log returns every logged adherence event, archived segments first, in
+// time order.
+func (r *AdherenceRepository) Log(_ context.Context) ([]adherence.AdherenceLogEntry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	archived, err := r.readArchivedLocked()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(r.logPath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("read adherence log: %w", err)
+	}
+	current, err := r.decodeLogData(data)
+	if err != nil {
+		return nil, err
+	}
+	return joinLog(archived, current), nil
+}
+
+// decodeLogData parses log lines, skipping unreadable ones under a lenient
+// load.
+func (r *AdherenceRepository) decodeLogData(data []byte) ([]adherence.AdherenceLogEntry, error) {
+	if !r.opts.lenient {
+		return DecodeAdherenceLog(data)
+	}
+	var entries []adherence.AdherenceLogEntry
+	for _, scanned := range scanAdherenceLog(data) {
+		if scanned.err == nil {
+			entries = append(entries, scanned.entry)
+		}
+	}
+	return entries, nil
+}
+
+// LoadWarnings lists the records skipped by a lenient load.
+func (r *AdherenceRepository) LoadWarnings() []LoadWarning {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return append([]LoadWarning(nil), r.warnings...)
+}
+
+// ReplaceLog rewrites the log file with log, in the given order. Events
+// already archived in a segment are left there.
+func (r *AdherenceRepository) ReplaceLog(_ context.Context, log []adherence.AdherenceLogEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Events already archived stay in their segments.
+	archived, err := r.readArchivedLocked()
+	if err != nil {
+		return err
+	}
+	inSegments := make(map[string]bool, len(archived))
+	for _, entry := range archived {
+		inSegments[entry.Fingerprint()] = true
+	}
+
+	var data []byte
+	for _, entry := range log {
+		if inSegments[entry.Fingerprint()] {
+			continue
+		}
+		line, err := json.Marshal(logRecordFromEntry(entry))
+		if err != nil {
+			return fmt.Errorf("encode adherence log entry: %w", err)
+		}
+		data = append(append(data, line...), '\n')
+	}
+	if r.opts.lenient {
+		// Keep lines that could not be parsed rather than dropping them.
+		current, err := readIfExists(r.logPath)
+		if err != nil {
+			return err
+		}
+		for _, scanned := range scanAdherenceLog(current) {
+			if scanned.err != nil {
+				data = append(append(data, scanned.raw...), '\n')
+			}
+		}
+	}
+	return atomicfile.Write(r.logPath, data, 0o600)
+}
+
+// ReadAdherenceLogFile decodes an adherence log file without opening it as a
+// repository. A missing file holds no entries.
+func ReadAdherenceLogFile(path string) ([]adherence.AdherenceLogEntry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read adherence log: %w", err)
+	}
+	return DecodeAdherenceLog(data)
 }
 
 func (r *AdherenceRepository) load() error {
+	if r.opts.lenient {
+		return r.loadLenient()
+	}
 	data, err := os.ReadFile(r.path)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -144,14 +255,64 @@ func (r *AdherenceRepository) load() error {
 	return nil
 }
 
+func (r *AdherenceRepository) loadLenient() error {
+	data, err := readIfExists(r.path)
+	if err != nil {
+		return err
+	}
+	if len(bytes.TrimSpace(data)) > 0 {
+		var raw map[string]json.RawMessage
+		if err := json.Unmarshal(data, &raw); err != nil {
+			r.warnings = append(r.warnings, LoadWarning{File: r.path, Line: 1, Message: "invalid JSON: " + err.Error()})
+			r.damaged = true
+		}
+		for _, key := range sortedKeys(raw) {
+			var value bool
+			switch {
+			case !journal.IsKnownPrecept(journal.Precept(key)):
+				r.warnings = append(r.warnings, LoadWarning{File: r.path, Line: lineOfKey(data, key), Message: fmt.Sprintf("unknown precept %q", key)})
+			case json.Unmarshal(raw[key], &value) != nil:
+				r.warnings = append(r.warnings, LoadWarning{File: r.path, Line: lineOfKey(data, key), Message: fmt.Sprintf("%s must be true or false, got %s", key, raw[key])})
+			default:
+				r.state[journal.Precept(key)] = value
+				continue
+			}
+			if r.unparsedState == nil {
+				r.unparsedState = make(map[string]json.RawMessage)
+			}
+			r.unparsedState[key] = raw[key]
+		}
+	}
+
+	logData, err := readIfExists(r.logPath)
+	if err != nil {
+		return err
+	}
+	for _, scanned := range scanAdherenceLog(logData) {
+		if scanned.err != nil {
+			r.warnings = append(r.warnings, LoadWarning{File: r.logPath, Line: scanned.line, Record: scanned.record, Message: scanned.err.Error()})
+		}
+	}
+	return nil
+}
+
 func (r *AdherenceRepository) persistLocked() error {
-	record := recordFromAdherence(r.state)
+	if r.damaged {
+		return fmt.Errorf("write %s: %w", r.path, ErrDamagedFile)
+	}
+	record := make(map[string]json.RawMessage, len(r.state)+len(r.unparsedState))
+	for precept, value := range recordFromAdherence(r.state) {
+		record[precept] = json.RawMessage(fmt.Sprint(value))
+	}
+	for key, raw := range r.unparsedState {
+		record[key] = raw
+	}
 	data, err := json.MarshalIndent(record, "", "  ")
 	if err != nil {
 		return fmt.Errorf("encode adherence file: %w", err)
 	}
 	data = append(data, '\n')
-	return writeFileAtomic(r.path, data, 0o600)
+	return atomicfile.Write(r.path, data, 0o600)
 }
 
 type adherenceRecord map[string]bool
@@ -177,12 +338,80 @@ func (r adherenceRecord) toAdherence() (adherence.Adherence, error) {
 
 type adherenceLogRecord struct {
 	Timestamp string `json:"timestamp"`
+	Kind      string `json:"kind,omitempty"`
 	Precept   string `json:"precept"`
 	From      bool   `json:"from"`
 	To        bool   `json:"to"`
 	Note      string `json:"note,omitempty"`
 }
 
+func logRecordFromEntry(entry adherence.AdherenceLogEntry) adherenceLogRecord {
+	return adherenceLogRecord{
+		Timestamp: entry.At.UTC().Format(time.RFC3339Nano),
+		Kind:      string(entry.Kind),
+		Precept:   string(entry.Precept),
+		From:      entry.From,
+		To:        entry.To,
+		Note:      strings.TrimSpace(entry.Note),
+	}
+}
+
+func (r adherenceLogRecord) toLogEntry() (adherence.AdherenceLogEntry, error) {
+	at, err := time.Parse(time.RFC3339Nano, strings.TrimSpace(r.Timestamp))
+	if err != nil {
+		return adherence.AdherenceLogEntry{}, fmt.Errorf("invalid log timestamp %q: %w", r.Timestamp, err)
+	}
+	precept := journal.Precept(r.Precept)
+	if !journal.IsKnownPrecept(precept) {
+		return adherence.AdherenceLogEntry{}, fmt.Errorf("unknown precept in adherence log: %s", r.Precept)
+	}
+	kind := adherence.EventKind(r.Kind)
+	if kind != adherence.EventChange && kind != adherence.EventInitial {
+		return adherence.AdherenceLogEntry{}, fmt.Errorf("unknown event kind in adherence log: %s", r.Kind)
+	}
+	return adherence.AdherenceLogEntry{
+		At:      at.UTC(),
+		Kind:    kind,
+		Precept: precept,
+		From:    r.From,
+		To:      r.To,
+		Note:    r.Note,
+	}, nil
+}
+
+// DecodeAdherenceLog decodes the contents of an adherence log file.
+func DecodeAdherenceLog(data []byte) ([]adherence.AdherenceLogEntry, error) {
+	var entries []adherence.AdherenceLogEntry
+	for i, line := range strings.Split(string(data), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		var record adherenceLogRecord
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			return nil, fmt.Errorf("decode adherence log line %d: %w", i+1, err)
+		}
+		entry, err := record.toLogEntry()
+		if err != nil {
+			return nil, fmt.Errorf("adherence log line %d: %w", i+1, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// EncodeAdherenceLog encodes log as the contents of an adherence log file.
+func EncodeAdherenceLog(log []adherence.AdherenceLogEntry) ([]byte, error) {
+	var data []byte
+	for _, entry := range log {
+		line, err := json.Marshal(logRecordFromEntry(entry))
+		if err != nil {
+			return nil, fmt.Errorf("encode adherence log entry: %w", err)
+		}
+		data = append(append(data, line...), '\n')
+	}
+	return data, nil
+}
+
 func appendFileAtomic(path string, data []byte, perm os.FileMode) error {
 	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, perm)
 	if err != nil {
@@ -195,5 +424,8 @@ func appendFileAtomic(path string, data []byte, perm os.FileMode) error {
 	if _, err := file.Write(data); err != nil {
 		return fmt.Errorf("append log file: %w", err)
 	}
+	if err := file.Sync(); err != nil {
+		return fmt.Errorf("sync log file: %w", err)
+	}
 	return nil
 }
@@ -3,8 +3,10 @@ package flatfile
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -200,3 +202,170 @@ func bytesTrimSpace(data []byte) []byte {
 	}
 	return data
 }
+
+func TestAdherenceRepositoryLog(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "adherence.json")
+	logPath := filepath.Join(dir, "adherence.log.jsonl")
+
+	repo, err := NewAdherenceRepository(path, logPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	empty, err := repo.Log(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(empty) != 0 {
+		t.Fatalf("expected empty log, got %d", len(empty))
+	}
+
+	at := time.Date(2024, 2, 10, 12, 0, 0, 0, time.UTC)
+	for _, entry := range []adherence.AdherenceLogEntry{
+		{At: at, Precept: journal.TrueLove, From: true, To: false, Note: "slipped"},
+		{At: at.Add(time.Hour), Precept: journal.TrueLove, From: false, To: true},
+	} {
+		if err := repo.AppendLog(context.Background(), entry); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	log, err := repo.Log(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(log) != 2 {
+		t.Fatalf("expected 2 log entries, got %d", len(log))
+	}
+	if !log[0].At.Equal(at) || log[0].Note != "slipped" || log[1].To != true {
+		t.Fatalf("unexpected log: %+v", log)
+	}
+
+	if err := os.WriteFile(logPath, []byte("{\"timestamp\":\"bad\"}\n"), 0o600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := repo.Log(context.Background()); err == nil {
+		t.Fatalf("expected error for invalid log line")
+	}
+}
+
+func TestAdherenceRepositoryReplaceLog(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "adherence.log.jsonl")
+	repo, err := NewAdherenceRepository(filepath.Join(dir, "adherence.json"), logPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	at := time.Date(2024, 2, 10, 12, 0, 0, 0, time.UTC)
+	if err := repo.AppendLog(context.Background(), adherence.AdherenceLogEntry{At: at, Precept: journal.TrueLove, From: true, To: false}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	replacement := []adherence.AdherenceLogEntry{
+		{At: at, Precept: journal.TrueHappiness, From: true, To: false, Note: "merged"},
+		{At: at.Add(time.Minute), Precept: journal.TrueHappiness, From: false, To: true},
+	}
+	if err := repo.ReplaceLog(context.Background(), replacement); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	log, err := ReadAdherenceLogFile(logPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(log) != 2 || log[0].Precept != journal.TrueHappiness || log[0].Note != "merged" {
+		t.Fatalf("unexpected log after replace: %+v", log)
+	}
+}
+
+func TestAdherenceRepositoryLenientLoad(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "adherence.json")
+	logPath := filepath.Join(dir, "adherence.log.jsonl")
+	state := "{\n  \"true-love\": false,\n  \"made-up\": true,\n  \"true-happiness\": \"maybe\"\n}\n"
+	if err := os.WriteFile(path, []byte(state), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	log := `{"timestamp":"2024-02-10T12:00:00Z","precept":"true-love","from":true,"to":false}` + "\n" + "not json\n"
+	if err := os.WriteFile(logPath, []byte(log), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	if _, err := NewAdherenceRepository(path, logPath); err == nil {
+		t.Fatal("expected strict load to fail")
+	}
+
+	repo, err := NewAdherenceRepository(path, logPath, WithLenientLoad())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	warnings := repo.LoadWarnings()
+	if len(warnings) != 3 {
+		t.Fatalf("expected 3 warnings, got %+v", warnings)
+	}
+	if warnings[0].Line != 3 || warnings[1].Line != 4 || warnings[2].File != logPath || warnings[2].Line != 2 {
+		t.Fatalf("unexpected warnings: %+v", warnings)
+	}
+
+	current, err := repo.Get(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if current[journal.TrueLove] || !current[journal.TrueHappiness] {
+		t.Fatalf("unexpected state: %+v", current)
+	}
+	entries, err := repo.Log(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 readable log entry, got %d", len(entries))
+	}
+
+	current[journal.TrueLove] = true
+	if err := repo.Save(context.Background(), current); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := repo.ReplaceLog(context.Background(), entries); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	var saved map[string]json.RawMessage
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if string(saved["made-up"]) != "true" || string(saved["true-happiness"]) != `"maybe"` || string(saved["true-love"]) != "true" {
+		t.Fatalf("expected skipped keys to be written back:\n%s", data)
+	}
+	logData, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if !strings.HasSuffix(string(logData), "not json\n") {
+		t.Fatalf("expected the unreadable log line to be kept:\n%s", logData)
+	}
+}
+
+func TestAdherenceRepositoryLenientLoadRefusesDamagedWrites(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "adherence.json")
+	if err := os.WriteFile(path, []byte("{\"true-love\": fal"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	repo, err := NewAdherenceRepository(path, filepath.Join(dir, "adherence.log.jsonl"), WithLenientLoad())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(repo.LoadWarnings()) != 1 {
+		t.Fatalf("unexpected warnings: %+v", repo.LoadWarnings())
+	}
+	if err := repo.Save(context.Background(), adherence.DefaultAdherence()); !errors.Is(err, ErrDamagedFile) {
+		t.Fatalf("expected ErrDamagedFile, got %v", err)
+	}
+}
@@ -0,0 +1,148 @@
+package flatfile
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/thatnerdjosh/mindfulness/internal/domain/adherence"
+	"github.com/thatnerdjosh/mindfulness/internal/domain/journal"
+	"github.com/thatnerdjosh/mindfulness/internal/infrastructure/atomicfile"
+)
+
+// DefaultBeginningAnewPath returns the default path of the Beginning Anew
+// records.
+func DefaultBeginningAnewPath() (string, error) {
+	dataDir, err := defaultDataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dataDir, "beginning-anew.json"), nil
+}
+
+// BeginningAnewRepository stores Beginning Anew records in a JSON file, each
+// linked to the adherence log event it follows by that event's fingerprint.
+type BeginningAnewRepository struct {
+	mu   sync.Mutex
+	path string
+}
+
+func NewBeginningAnewRepository(path string) (*BeginningAnewRepository, error) {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return nil, fmt.Errorf("beginning anew path is required")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("create data directory: %w", err)
+	}
+	return &BeginningAnewRepository{path: path}, nil
+}
+
+func (r *BeginningAnewRepository) List(_ context.Context) ([]adherence.BeginningAnew, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.loadLocked()
+}
+
+func (r *BeginningAnewRepository) Save(_ context.Context, record adherence.BeginningAnew) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	records, err := r.loadLocked()
+	if err != nil {
+		return err
+	}
+	records = replaceRenewal(records, record)
+
+	stored := make([]beginningAnewRecord, 0, len(records))
+	for _, existing := range records {
+		stored = append(stored, recordFromBeginningAnew(existing))
+	}
+	data, err := json.MarshalIndent(stored, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode beginning anew records: %w", err)
+	}
+	return atomicfile.Write(r.path, append(data, '\n'), 0o600)
+}
+
+func (r *BeginningAnewRepository) loadLocked() ([]adherence.BeginningAnew, error) {
+	data, err := readIfExists(r.path)
+	if err != nil || len(strings.TrimSpace(string(data))) == 0 {
+		return nil, err
+	}
+
+	var stored []beginningAnewRecord
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, fmt.Errorf("decode beginning anew records: %w", err)
+	}
+	records := make([]adherence.BeginningAnew, 0, len(stored))
+	for _, record := range stored {
+		parsed, err := record.toBeginningAnew()
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, parsed)
+	}
+	return records, nil
+}
+
+type beginningAnewRecord struct {
+	Break           string `json:"break"`
+	Precept         string `json:"precept"`
+	At              string `json:"at"`
+	Acknowledgement string `json:"acknowledgement,omitempty"`
+	Regret          string `json:"regret,omitempty"`
+	Intention       string `json:"intention,omitempty"`
+	RenewedAt       string `json:"renewed_at,omitempty"`
+}
+
+func recordFromBeginningAnew(b adherence.BeginningAnew) beginningAnewRecord {
+	record := beginningAnewRecord{
+		Break:           b.Break,
+		Precept:         string(b.Precept),
+		At:              b.At.UTC().Format(time.RFC3339Nano),
+		Acknowledgement: b.Acknowledgement,
+		Regret:          b.Regret,
+		Intention:       b.Intention,
+	}
+	if b.Renewed() {
+		record.RenewedAt = b.RenewedAt.UTC().Format(time.RFC3339Nano)
+	}
+	return record
+}
+
+func (r beginningAnewRecord) toBeginningAnew() (adherence.BeginningAnew, error) {
+	precept := journal.Precept(r.Precept)
+	if !journal.IsKnownPrecept(precept) {
+		return adherence.BeginningAnew{}, fmt.Errorf("%w: %s", journal.ErrUnknownPrecept, r.Precept)
+	}
+	if r.Break == "" {
+		return adherence.BeginningAnew{}, fmt.Errorf("beginning anew record for %s has no break", r.Precept)
+	}
+	at, err := time.Parse(time.RFC3339Nano, r.At)
+	if err != nil {
+		return adherence.BeginningAnew{}, fmt.Errorf("invalid beginning anew at %q: %w", r.At, err)
+	}
+	record := adherence.BeginningAnew{
+		Break:           r.Break,
+		Precept:         precept,
+		At:              at,
+		Acknowledgement: r.Acknowledgement,
+		Regret:          r.Regret,
+		Intention:       r.Intention,
+	}
+	if r.RenewedAt != "" {
+		renewedAt, err := time.Parse(time.RFC3339Nano, r.RenewedAt)
+		if err != nil {
+			return adherence.BeginningAnew{}, fmt.Errorf("invalid beginning anew renewed_at %q: %w", r.RenewedAt, err)
+		}
+		record.RenewedAt = renewedAt
+	}
+	return record, nil
+}
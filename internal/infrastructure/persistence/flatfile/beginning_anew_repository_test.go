@@ -0,0 +1,65 @@
+package flatfile
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/thatnerdjosh/mindfulness/internal/domain/adherence"
+	"github.com/thatnerdjosh/mindfulness/internal/domain/journal"
+)
+
+func TestBeginningAnewRepositoryRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "beginning-anew.json")
+	repo, err := NewBeginningAnewRepository(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if records, err := repo.List(context.Background()); err != nil || len(records) != 0 {
+		t.Fatalf("expected no records, got %+v, %v", records, err)
+	}
+
+	at := time.Date(2024, 4, 1, 9, 0, 0, 0, time.UTC)
+	record := adherence.BeginningAnew{
+		Break:           "abc",
+		Precept:         journal.TrueLove,
+		At:              at,
+		Acknowledgement: "I was dismissive",
+		Intention:       "listen fully",
+	}
+	if err := repo.Save(context.Background(), record); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	record.RenewedAt = at.Add(24 * time.Hour)
+	if err := repo.Save(context.Background(), record); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	reopened, err := NewBeginningAnewRepository(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	records, err := reopened.List(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(records) != 1 || records[0] != record {
+		t.Fatalf("expected the renewed record back, got %+v", records)
+	}
+}
+
+func TestBeginningAnewRepositoryRejectsUnknownPrecept(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "beginning-anew.json")
+	if err := os.WriteFile(path, []byte(`[{"break":"abc","precept":"nope","at":"2024-04-01T09:00:00Z"}]`), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	repo, err := NewBeginningAnewRepository(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := repo.List(context.Background()); err == nil {
+		t.Fatal("expected an unknown precept to be rejected")
+	}
+}
@@ -0,0 +1,440 @@
+package flatfile
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/thatnerdjosh/mindfulness/internal/domain/adherence"
+	"github.com/thatnerdjosh/mindfulness/internal/domain/journal"
+	"github.com/thatnerdjosh/mindfulness/internal/infrastructure/atomicfile"
+)
+
+// Problem is one integrity problem found in a data file.
+type Problem struct {
+	File string
+	// Line is the 1-based line the problem starts on, or 0 for problems that
+	// concern the whole file.
+	Line int
+	// Record is the 1-based position of the record in the file, or 0.
+	Record  int
+	Message string
+	// Fix describes what Repair does about the problem; empty means it has to
+	// be fixed by hand.
+	Fix string
+}
+
+// RepairResult lists the files Repair touched.
+type RepairResult struct {
+	Backups     []string
+	Quarantines []string
+	Rewritten   []string
+	Removed     []string
+	Quarantined int
+}
+
+// Doctor validates the journal, adherence state and adherence log files,
+// along with the log's archived segments, their index and the state
+// snapshot, without loading them into repositories, so it works on files
+// the repositories refuse to open.
+type Doctor struct {
+	JournalPath      string
+	AdherencePath    string
+	AdherenceLogPath string
+	// BeginningAnewPath, RevisitPath and WALPath are checked when set.
+	BeginningAnewPath string
+	RevisitPath       string
+	WALPath           string
+	now               func() time.Time
+}
+
+func NewDoctor(journalPath string, adherencePath string, adherenceLogPath string) *Doctor {
+	return &Doctor{
+		JournalPath:      journalPath,
+		AdherencePath:    adherencePath,
+		AdherenceLogPath: adherenceLogPath,
+		now:              time.Now,
+	}
+}
+
+// fileFix is the repaired content of one file and the records it drops.
+// When remove is set the file is deleted instead of rewritten.
+type fileFix struct {
+	path        string
+	content     []byte
+	remove      bool
+	quarantined []quarantineRecord
+}
+
+type quarantineRecord struct {
+	QuarantinedAt string `json:"quarantined_at"`
+	Line          int    `json:"line,omitempty"`
+	Record        int    `json:"record,omitempty"`
+	Reason        string `json:"reason"`
+	Raw           string `json:"raw"`
+}
+
+// Check reports every problem found: journal first, then the log and its
+// archive, then the adherence state and its snapshot, then the Beginning
+// Anew records, the revisits and any interrupted write.
+func (d *Doctor) Check() ([]Problem, error) {
+	problems, _, err := d.diagnose()
+	return problems, err
+}
+
+// Repair fixes what it can. Each changed file is first copied to a
+// timestamped backup, and dropped records are appended to a quarantine file
+// next to it. It returns the problems found before repairing.
+func (d *Doctor) Repair() ([]Problem, RepairResult, error) {
+	problems, fixes, err := d.diagnose()
+	if err != nil {
+		return nil, RepairResult{}, err
+	}
+
+	var result RepairResult
+	stamp := d.now().UTC()
+	for _, fix := range fixes {
+		backup := fix.path + ".bak-" + stamp.Format("20060102T150405Z")
+		if err := copyFile(fix.path, backup); err != nil {
+			return problems, result, err
+		}
+		result.Backups = append(result.Backups, backup)
+
+		if len(fix.quarantined) > 0 {
+			quarantine := fix.path + ".quarantine.jsonl"
+			var data []byte
+			for _, record := range fix.quarantined {
+				record.QuarantinedAt = stamp.Format(time.RFC3339)
+				line, err := json.Marshal(record)
+				if err != nil {
+					return problems, result, fmt.Errorf("encode quarantine record: %w", err)
+				}
+				data = append(append(data, line...), '\n')
+			}
+			if err := appendFileAtomic(quarantine, data, 0o600); err != nil {
+				return problems, result, err
+			}
+			result.Quarantines = append(result.Quarantines, quarantine)
+			result.Quarantined += len(fix.quarantined)
+		}
+
+		if fix.remove {
+			if err := os.Remove(fix.path); err != nil {
+				return problems, result, fmt.Errorf("remove %s: %w", fix.path, err)
+			}
+			result.Removed = append(result.Removed, fix.path)
+			continue
+		}
+		if err := atomicfile.Write(fix.path, fix.content, 0o600); err != nil {
+			return problems, result, err
+		}
+		result.Rewritten = append(result.Rewritten, fix.path)
+	}
+	return problems, result, nil
+}
+
+func (d *Doctor) diagnose() ([]Problem, []fileFix, error) {
+	var problems []Problem
+	var fixes []fileFix
+
+	journalProblems, journalFix, err := checkJournalFile(d.JournalPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	problems = append(problems, journalProblems...)
+
+	logProblems, current, logFix, err := checkAdherenceLogFile(d.AdherenceLogPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	problems = append(problems, logProblems...)
+
+	archiveProblems, archived, archiveFixes, err := checkLogArchive(d.AdherenceLogPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	problems = append(problems, archiveProblems...)
+	log := joinLog(archived, current)
+
+	stateProblems, stateFix, err := checkAdherenceFile(d.AdherencePath, log)
+	if err != nil {
+		return nil, nil, err
+	}
+	problems = append(problems, stateProblems...)
+
+	logData, err := readIfExists(d.AdherenceLogPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	if logFix != nil {
+		logData = logFix.content
+	}
+	snapshotProblems, snapshotFix, err := checkSnapshot(SnapshotPath(d.AdherenceLogPath), logData, archived)
+	if err != nil {
+		return nil, nil, err
+	}
+	problems = append(problems, snapshotProblems...)
+
+	for _, fix := range []*fileFix{journalFix, logFix} {
+		if fix != nil {
+			fixes = append(fixes, *fix)
+		}
+	}
+	fixes = append(fixes, archiveFixes...)
+	for _, fix := range []*fileFix{stateFix, snapshotFix} {
+		if fix != nil {
+			fixes = append(fixes, *fix)
+		}
+	}
+
+	for _, check := range []struct {
+		path string
+		run  func(string) ([]Problem, *fileFix, error)
+	}{
+		{d.BeginningAnewPath, checkBeginningAnewFile},
+		{d.RevisitPath, checkRevisitFile},
+		{d.WALPath, checkWALFile},
+	} {
+		if check.path == "" {
+			continue
+		}
+		fileProblems, fix, err := check.run(check.path)
+		if err != nil {
+			return nil, nil, err
+		}
+		problems = append(problems, fileProblems...)
+		if fix != nil {
+			fixes = append(fixes, *fix)
+		}
+	}
+	return problems, fixes, nil
+}
+
+func checkJournalFile(path string) ([]Problem, *fileFix, error) {
+	return checkRecordFile(path, "journal file", func(raw json.RawMessage) (string, error) {
+		entry, err := checkEntryRecord(raw)
+		return entry.Fingerprint(), err
+	})
+}
+
+// checkRecordFile validates a file holding a JSON array, named what in
+// errors. check parses one record and returns the key that makes it unique;
+// records with an empty key are never treated as duplicates.
+func checkRecordFile(path string, what string, check func(json.RawMessage) (string, error)) ([]Problem, *fileFix, error) {
+	data, err := readIfExists(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var problems []Problem
+	var quarantined []quarantineRecord
+	good := []json.RawMessage{}
+	keys := make(map[string]int)
+	reject := func(scanned scannedRecord, message string, fix string) {
+		problems = append(problems, Problem{File: path, Line: scanned.line, Record: scanned.record, Message: message, Fix: fix})
+		quarantined = append(quarantined, quarantineRecord{Line: scanned.line, Record: scanned.record, Reason: message, Raw: string(scanned.raw)})
+	}
+
+	records, damaged := scanArray(data, what)
+	for _, scanned := range records {
+		key, err := check(scanned.raw)
+		if err != nil {
+			reject(scanned, err.Error(), "move to quarantine")
+			continue
+		}
+		if first, ok := keys[key]; ok && key != "" {
+			reject(scanned, fmt.Sprintf("duplicate of record %d", first), "move to quarantine")
+			continue
+		}
+		keys[key] = scanned.record
+		good = append(good, scanned.raw)
+	}
+	if damaged != nil {
+		// Nothing after a syntax error can be trusted; keep what came before
+		// and set the rest aside.
+		fix := "move this and all later records to quarantine"
+		if damaged.record == 0 {
+			fix = "move the whole file to quarantine"
+		}
+		reject(*damaged, damaged.err.Error(), fix)
+	}
+
+	if len(problems) == 0 {
+		return nil, nil, nil
+	}
+	content, err := json.MarshalIndent(good, "", "  ")
+	if err != nil {
+		return nil, nil, fmt.Errorf("encode %s: %w", what, err)
+	}
+	return problems, &fileFix{path: path, content: append(content, '\n'), quarantined: quarantined}, nil
+}
+
+func checkAdherenceLogFile(path string) ([]Problem, []adherence.AdherenceLogEntry, *fileFix, error) {
+	data, err := readIfExists(path)
+	if err != nil || len(data) == 0 {
+		return nil, nil, nil, err
+	}
+	problems, log, fix := checkAdherenceLogData(path, data)
+	return problems, log, fix, nil
+}
+
+// checkAdherenceLogData validates the lines of the log read from path. The
+// fix holds the good lines as plain text.
+func checkAdherenceLogData(path string, data []byte) ([]Problem, []adherence.AdherenceLogEntry, *fileFix) {
+	var problems []Problem
+	var quarantined []quarantineRecord
+	var log []adherence.AdherenceLogEntry
+	var content []byte
+	fingerprints := make(map[string]int)
+
+	for _, scanned := range scanAdherenceLog(data) {
+		message := ""
+		if scanned.err != nil {
+			message = scanned.err.Error()
+		} else if first, ok := fingerprints[scanned.entry.Fingerprint()]; ok {
+			message = fmt.Sprintf("duplicate of line %d", first)
+		}
+
+		if message != "" {
+			problems = append(problems, Problem{File: path, Line: scanned.line, Record: scanned.record, Message: message, Fix: "move to quarantine"})
+			quarantined = append(quarantined, quarantineRecord{Line: scanned.line, Record: scanned.record, Reason: message, Raw: scanned.raw})
+			continue
+		}
+		fingerprints[scanned.entry.Fingerprint()] = scanned.line
+		log = append(log, scanned.entry)
+		content = append(append(content, scanned.raw...), '\n')
+	}
+
+	if len(problems) == 0 {
+		return nil, log, nil
+	}
+	return problems, log, &fileFix{path: path, content: content, quarantined: quarantined}
+}
+
+// checkAdherenceFile validates the state file and compares it with the end
+// of the log. Precepts the log never mentions are not compared, since their
+// state may predate the log.
+func checkAdherenceFile(path string, log []adherence.AdherenceLogEntry) ([]Problem, *fileFix, error) {
+	data, err := readIfExists(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var problems []Problem
+	var quarantined []quarantineRecord
+	state := adherence.DefaultAdherence()
+	if len(bytes.TrimSpace(data)) > 0 {
+		var raw map[string]json.RawMessage
+		if err := json.Unmarshal(data, &raw); err != nil {
+			problems = append(problems, Problem{File: path, Line: 1, Message: "invalid JSON: " + err.Error(), Fix: "rebuild from the log"})
+			quarantined = append(quarantined, quarantineRecord{Line: 1, Reason: "invalid JSON", Raw: string(data)})
+		} else {
+			for _, key := range sortedKeys(raw) {
+				line := lineOfKey(data, key)
+				var value bool
+				switch {
+				case !journal.IsKnownPrecept(journal.Precept(key)):
+					message := fmt.Sprintf("unknown precept %q", key)
+					problems = append(problems, Problem{File: path, Line: line, Message: message, Fix: "move to quarantine"})
+					quarantined = append(quarantined, quarantineRecord{Line: line, Reason: message, Raw: fmt.Sprintf("%q: %s", key, raw[key])})
+				case json.Unmarshal(raw[key], &value) != nil:
+					message := fmt.Sprintf("%s must be true or false, got %s", key, raw[key])
+					problems = append(problems, Problem{File: path, Line: line, Message: message, Fix: "rebuild from the log"})
+					quarantined = append(quarantined, quarantineRecord{Line: line, Reason: message, Raw: fmt.Sprintf("%q: %s", key, raw[key])})
+				default:
+					state[journal.Precept(key)] = value
+				}
+			}
+		}
+	}
+
+	folded := adherence.Fold(state, log)
+	for _, info := range journal.AllPrecepts() {
+		if folded[info.ID] == state[info.ID] || !mentions(log, info.ID) {
+			continue
+		}
+		problems = append(problems, Problem{
+			File:    path,
+			Line:    lineOfKey(data, string(info.ID)),
+			Message: fmt.Sprintf("%s is %s but the log ends at %s", info.ID, yesNo(state[info.ID]), yesNo(folded[info.ID])),
+			Fix:     "rebuild from the log",
+		})
+	}
+
+	if len(problems) == 0 {
+		return nil, nil, nil
+	}
+	content, err := json.MarshalIndent(recordFromAdherence(folded), "", "  ")
+	if err != nil {
+		return nil, nil, fmt.Errorf("encode adherence file: %w", err)
+	}
+	return problems, &fileFix{path: path, content: append(content, '\n'), quarantined: quarantined}, nil
+}
+
+func mentions(log []adherence.AdherenceLogEntry, precept journal.Precept) bool {
+	for _, entry := range log {
+		if entry.Precept == precept {
+			return true
+		}
+	}
+	return false
+}
+
+func yesNo(value bool) string {
+	if value {
+		return "kept"
+	}
+	return "not kept"
+}
+
+func readIfExists(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+	return data, nil
+}
+
+func copyFile(from string, to string) error {
+	data, err := readIfExists(from)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(to, data, 0o600); err != nil {
+		return fmt.Errorf("write backup: %w", err)
+	}
+	return nil
+}
+
+// nextValueOffset skips the separators between array elements.
+func nextValueOffset(data []byte, offset int64) int64 {
+	for offset < int64(len(data)) {
+		switch data[offset] {
+		case ' ', '\t', '\r', '\n', ',':
+			offset++
+		default:
+			return offset
+		}
+	}
+	return offset
+}
+
+func lineAt(data []byte, offset int64) int {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	return bytes.Count(data[:offset], []byte("\n")) + 1
+}
+
+func lineOfKey(data []byte, key string) int {
+	index := bytes.Index(data, []byte(`"`+key+`"`))
+	if index < 0 {
+		return 0
+	}
+	return lineAt(data, int64(index))
+}
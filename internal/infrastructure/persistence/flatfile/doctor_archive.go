@@ -0,0 +1,166 @@
+package flatfile
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/thatnerdjosh/mindfulness/internal/domain/adherence"
+	"github.com/thatnerdjosh/mindfulness/internal/domain/journal"
+)
+
+const rebuildIndexFix = "rebuild the index from the segment files"
+
+// checkLogArchive validates the archived segments of the log at logPath and
+// the index listing them. It returns the events of the readable segments,
+// after any repair, in time order.
+func checkLogArchive(logPath string) ([]Problem, []adherence.AdherenceLogEntry, []fileFix, error) {
+	dir := filepath.Dir(logPath)
+	indexPath := LogIndexPath(logPath)
+	onDisk, err := filepath.Glob(filepath.Join(dir, strings.TrimSuffix(filepath.Base(logPath), ".jsonl")+".*.jsonl.gz"))
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("list log segments: %w", err)
+	}
+	data, err := readIfExists(indexPath)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if data == nil && len(onDisk) == 0 {
+		return nil, nil, nil, nil
+	}
+
+	var problems []Problem
+	var fixes []fileFix
+	rebuild := false
+	var index logIndex
+	if data != nil {
+		if err := json.Unmarshal(data, &index); err != nil {
+			problems = append(problems, Problem{File: indexPath, Line: 1, Message: "invalid JSON: " + err.Error(), Fix: rebuildIndexFix})
+			rebuild = true
+		}
+	}
+	indexed := make(map[string]bool, len(index.Segments))
+	for _, segment := range index.Segments {
+		indexed[segment.File] = true
+		if _, err := os.Stat(filepath.Join(dir, segment.File)); os.IsNotExist(err) {
+			problems = append(problems, Problem{File: indexPath, Line: lineOfKey(data, segment.File), Message: fmt.Sprintf("segment %s is missing", segment.File), Fix: rebuildIndexFix})
+			rebuild = true
+		}
+	}
+
+	var log []adherence.AdherenceLogEntry
+	var records []logSegmentRecord
+	for _, path := range onDisk {
+		name := filepath.Base(path)
+		if !indexed[name] {
+			problems = append(problems, Problem{File: path, Message: "segment is not listed in the log index", Fix: rebuildIndexFix})
+			rebuild = true
+		}
+		plain, err := readSegment(path)
+		if err != nil {
+			problems = append(problems, Problem{File: path, Message: err.Error()})
+			records = append(records, logSegmentRecord{File: name})
+			continue
+		}
+
+		segmentProblems, entries, fix := checkAdherenceLogData(path, plain)
+		problems = append(problems, segmentProblems...)
+		if fix != nil {
+			plain = fix.content
+			if fix.content, err = compressSegment(plain); err != nil {
+				return nil, nil, nil, err
+			}
+			fixes = append(fixes, *fix)
+			rebuild = true
+		}
+		log = append(log, entries...)
+		record := logSegmentRecord{File: name, Events: len(entries), Bytes: int64(len(plain))}
+		if len(entries) > 0 {
+			record.First = entries[0].At.UTC().Format(time.RFC3339Nano)
+			record.Last = entries[len(entries)-1].At.UTC().Format(time.RFC3339Nano)
+		}
+		records = append(records, record)
+	}
+
+	if rebuild {
+		sort.SliceStable(records, func(i, j int) bool {
+			return records[i].First < records[j].First
+		})
+		content, err := json.MarshalIndent(logIndex{Segments: records}, "", "  ")
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("encode log index: %w", err)
+		}
+		fixes = append(fixes, fileFix{path: indexPath, content: append(content, '\n')})
+	}
+	return problems, joinLog(log, nil), fixes, nil
+}
+
+// checkSnapshot compares the snapshot at path with the log it describes:
+// archived followed by the current log file holding logData. A snapshot that
+// does not match is ignored when the log is opened, but one naming the
+// right event with the wrong state would be trusted.
+func checkSnapshot(path string, logData []byte, archived []adherence.AdherenceLogEntry) ([]Problem, *fileFix, error) {
+	data, err := readIfExists(path)
+	if err != nil || data == nil {
+		return nil, nil, err
+	}
+
+	var problem Problem
+	var snapshot adherenceSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		problem = Problem{File: path, Line: 1, Message: "invalid JSON: " + err.Error()}
+	} else if last, ok := lastEvent(logData, snapshot.Offset); !ok || last.Fingerprint() != snapshot.Last {
+		problem = Problem{File: path, Message: "snapshot does not point at an event in the log"}
+	} else if state, err := snapshot.State.toAdherence(); err != nil {
+		problem = Problem{File: path, Message: err.Error()}
+	} else {
+		head, _ := DecodeAdherenceLog(logData[:snapshot.Offset])
+		folded := adherence.Fold(adherence.DefaultAdherence(), joinLog(archived, head))
+		for _, info := range journal.AllPrecepts() {
+			if state[info.ID] != folded[info.ID] {
+				message := fmt.Sprintf("snapshot has %s %s but the log folds to %s", info.ID, yesNo(state[info.ID]), yesNo(folded[info.ID]))
+				problem = Problem{File: path, Line: lineOfKey(data, string(info.ID)), Message: message}
+				break
+			}
+		}
+	}
+	if problem.Message == "" {
+		return nil, nil, nil
+	}
+
+	last, ok := lastEvent(logData, int64(len(logData)))
+	if !ok {
+		return []Problem{problem}, nil, nil
+	}
+	problem.Fix = "rebuild from the log"
+	current, _ := DecodeAdherenceLog(logData)
+	log := joinLog(archived, current)
+	content, err := json.MarshalIndent(adherenceSnapshot{
+		Events: len(log),
+		Offset: int64(len(logData)),
+		Last:   last.Fingerprint(),
+		State:  recordFromAdherence(adherence.Fold(adherence.DefaultAdherence(), log)),
+	}, "", "  ")
+	if err != nil {
+		return nil, nil, fmt.Errorf("encode adherence snapshot: %w", err)
+	}
+	return []Problem{problem}, &fileFix{path: path, content: append(content, '\n')}, nil
+}
+
+func compressSegment(data []byte) ([]byte, error) {
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	if _, err := writer.Write(data); err != nil {
+		return nil, fmt.Errorf("compress log segment: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("compress log segment: %w", err)
+	}
+	return compressed.Bytes(), nil
+}
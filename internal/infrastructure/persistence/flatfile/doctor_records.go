@@ -0,0 +1,89 @@
+package flatfile
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// checkBeginningAnewFile validates the Beginning Anew records. Each break
+// has at most one record, so a second record for it is a duplicate.
+func checkBeginningAnewFile(path string) ([]Problem, *fileFix, error) {
+	return checkRecordFile(path, "beginning anew file", func(raw json.RawMessage) (string, error) {
+		var record beginningAnewRecord
+		if err := json.Unmarshal(raw, &record); err != nil {
+			return "", fmt.Errorf("invalid record: %w", err)
+		}
+		renewal, err := record.toBeginningAnew()
+		return renewal.Break, err
+	})
+}
+
+// checkRevisitFile validates the journal revisit records. Two records for
+// the same entry are read together, so they are not reported.
+func checkRevisitFile(path string) ([]Problem, *fileFix, error) {
+	return checkRecordFile(path, "revisit file", func(raw json.RawMessage) (string, error) {
+		var record revisitRecord
+		if err := json.Unmarshal(raw, &record); err != nil {
+			return "", fmt.Errorf("invalid record: %w", err)
+		}
+		if record.Entry == "" {
+			return "", fmt.Errorf("revisit record has no entry")
+		}
+		for _, value := range record.At {
+			if _, err := time.Parse(time.RFC3339, value); err != nil {
+				return "", fmt.Errorf("invalid revisit time %q: %w", value, err)
+			}
+		}
+		return "", nil
+	})
+}
+
+// checkWALFile reports a write-ahead record that could never be completed,
+// since every command would refuse to start until it is removed.
+func checkWALFile(path string) ([]Problem, *fileFix, error) {
+	data, err := readIfExists(path)
+	if err != nil || data == nil {
+		return nil, nil, err
+	}
+	if err := checkWALRecord(data); err != nil {
+		message := "interrupted write cannot be completed: " + err.Error()
+		return []Problem{{File: path, Line: 1, Message: message, Fix: "move the whole file to quarantine"}},
+			&fileFix{path: path, remove: true, quarantined: []quarantineRecord{{Line: 1, Reason: message, Raw: string(data)}}}, nil
+	}
+	return nil, nil, nil
+}
+
+func checkWALRecord(data []byte) error {
+	var record walRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return fmt.Errorf("invalid JSON: %w", err)
+	}
+	if record.JournalReplace != nil {
+		if _, err := entriesFromRecords(*record.JournalReplace); err != nil {
+			return err
+		}
+	}
+	if _, err := entriesFromRecords(record.JournalAppend); err != nil {
+		return err
+	}
+	if record.Adherence != nil {
+		if _, err := record.Adherence.toAdherence(); err != nil {
+			return err
+		}
+	}
+	if record.LogReplace != nil {
+		if _, err := logFromRecords(*record.LogReplace); err != nil {
+			return err
+		}
+	}
+	if _, err := logFromRecords(record.LogAppend); err != nil {
+		return err
+	}
+	for _, renewal := range record.BeginningAnew {
+		if _, err := renewal.toBeginningAnew(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
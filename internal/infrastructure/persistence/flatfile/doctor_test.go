@@ -0,0 +1,354 @@
+package flatfile
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thatnerdjosh/mindfulness/internal/domain/adherence"
+	"github.com/thatnerdjosh/mindfulness/internal/domain/journal"
+)
+
+const corruptJournal = `[
+  {
+    "date": "2024-01-01",
+    "timestamp": "2024-01-01T09:00:00Z",
+    "note": "good"
+  },
+  {
+    "date": "2024-13-01",
+    "note": "bad date"
+  },
+  {
+    "date": "2024-01-02",
+    "reflections": {"true-lovee": "typo"}
+  },
+  {
+    "date": "2024-01-03",
+    "note": "odd foundation",
+    "foundation": "mind"
+  },
+  {
+    "date": "2024-01-01",
+    "timestamp": "2024-01-01T09:00:00Z",
+    "note": "good"
+  }
+]
+`
+
+const corruptLog = `{"timestamp":"2024-01-01T09:00:00Z","precept":"true-love","from":true,"to":false}
+not json
+{"timestamp":"2024-01-01T10:00:00Z","precept":"no-such-precept","from":true,"to":false}
+{"timestamp":"2024-01-01T09:00:00Z","precept":"true-love","from":true,"to":false}
+`
+
+func writeDoctorFiles(t *testing.T, journalData, stateData, logData string) *Doctor {
+	t.Helper()
+	dir := t.TempDir()
+	doctor := NewDoctor(filepath.Join(dir, "journal.json"), filepath.Join(dir, "adherence.json"), filepath.Join(dir, "adherence.log.jsonl"))
+	doctor.now = func() time.Time { return time.Date(2024, 2, 1, 12, 0, 0, 0, time.UTC) }
+	for path, data := range map[string]string{
+		doctor.JournalPath:      journalData,
+		doctor.AdherencePath:    stateData,
+		doctor.AdherenceLogPath: logData,
+	} {
+		if data == "" {
+			continue
+		}
+		if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	return doctor
+}
+
+func TestDoctorCheck(t *testing.T) {
+	doctor := writeDoctorFiles(t, corruptJournal, `{"true-love": true, "kindness": true}`, corruptLog)
+
+	problems, err := doctor.Check()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []struct {
+		file    string
+		line    int
+		record  int
+		message string
+	}{
+		{"journal.json", 7, 2, "invalid journal date"},
+		{"journal.json", 11, 3, `unknown precept "true-lovee"`},
+		{"journal.json", 15, 4, `unknown foundation "mind"`},
+		{"journal.json", 20, 5, "duplicate of record 1"},
+		{"adherence.log.jsonl", 2, 2, "invalid JSON"},
+		{"adherence.log.jsonl", 3, 3, "unknown precept in adherence log"},
+		{"adherence.log.jsonl", 4, 4, "duplicate of line 1"},
+		{"adherence.json", 1, 0, `unknown precept "kindness"`},
+		{"adherence.json", 1, 0, "true-love is kept but the log ends at not kept"},
+	}
+	if len(problems) != len(want) {
+		t.Fatalf("expected %d problems, got %d: %+v", len(want), len(problems), problems)
+	}
+	for i, w := range want {
+		got := problems[i]
+		if filepath.Base(got.File) != w.file || got.Line != w.line || got.Record != w.record || !strings.Contains(got.Message, w.message) {
+			t.Errorf("problem %d: expected %+v, got %+v", i, w, got)
+		}
+	}
+}
+
+func TestDoctorCheckCleanFiles(t *testing.T) {
+	doctor := writeDoctorFiles(t, `[{"date":"2024-01-01","note":"fine"}]`, `{"true-love": false}`,
+		`{"timestamp":"2024-01-01T09:00:00Z","precept":"true-love","from":true,"to":false}`+"\n")
+	problems, err := doctor.Check()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(problems) != 0 {
+		t.Fatalf("expected no problems, got %+v", problems)
+	}
+
+	empty := writeDoctorFiles(t, "", "", "")
+	if problems, err := empty.Check(); err != nil || len(problems) != 0 {
+		t.Fatalf("expected missing files to be fine, got %+v, %v", problems, err)
+	}
+}
+
+func TestDoctorRepair(t *testing.T) {
+	doctor := writeDoctorFiles(t, corruptJournal, `{"true-love": true, "kindness": true}`, corruptLog)
+
+	problems, result, err := doctor.Repair()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(problems) == 0 {
+		t.Fatalf("expected problems to be reported")
+	}
+	if len(result.Backups) != 3 || len(result.Rewritten) != 3 {
+		t.Fatalf("expected all three files backed up and rewritten, got %+v", result)
+	}
+	if result.Quarantined != 8 {
+		t.Fatalf("expected 8 quarantined records, got %d", result.Quarantined)
+	}
+
+	backup, err := os.ReadFile(doctor.JournalPath + ".bak-20240201T120000Z")
+	if err != nil || string(backup) != corruptJournal {
+		t.Fatalf("expected an untouched backup, got %v", err)
+	}
+	quarantine, err := os.ReadFile(doctor.JournalPath + ".quarantine.jsonl")
+	if err != nil {
+		t.Fatalf("read quarantine: %v", err)
+	}
+	if lines := strings.Count(string(quarantine), "\n"); lines != 4 {
+		t.Fatalf("expected 4 quarantined journal records, got %d", lines)
+	}
+
+	repo, err := NewJournalRepository(doctor.JournalPath)
+	if err != nil {
+		t.Fatalf("expected repaired journal to load: %v", err)
+	}
+	entries, _ := repo.List(context.Background())
+	if len(entries) != 1 || entries[0].Note != "good" {
+		t.Fatalf("unexpected entries after repair: %+v", entries)
+	}
+	adherenceRepo, err := NewAdherenceRepository(doctor.AdherencePath, doctor.AdherenceLogPath)
+	if err != nil {
+		t.Fatalf("expected repaired adherence files to load: %v", err)
+	}
+	state, _ := adherenceRepo.Get(context.Background())
+	if state["true-love"] {
+		t.Fatalf("expected state rebuilt from the log")
+	}
+
+	if problems, err := doctor.Check(); err != nil || len(problems) != 0 {
+		t.Fatalf("expected no problems after repair, got %+v, %v", problems, err)
+	}
+}
+
+func TestDoctorRepairSalvagesBeforeSyntaxError(t *testing.T) {
+	journalData := `[
+  {"date": "2024-01-01", "note": "kept"},
+  {"date": "2024-01-02", "note": "cut off"
+`
+	doctor := writeDoctorFiles(t, journalData, "", "")
+
+	problems, _, err := doctor.Repair()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(problems) != 1 || problems[0].Line != 3 || problems[0].Record != 2 {
+		t.Fatalf("unexpected problems: %+v", problems)
+	}
+	entries, err := ReadJournalFile(doctor.JournalPath)
+	if err != nil {
+		t.Fatalf("expected repaired journal to load: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Note != "kept" {
+		t.Fatalf("unexpected entries after repair: %+v", entries)
+	}
+}
+
+func TestDoctorRepairsLogArchive(t *testing.T) {
+	dir := t.TempDir()
+	doctor := NewDoctor(filepath.Join(dir, "journal.json"), filepath.Join(dir, "adherence.json"), filepath.Join(dir, "adherence.log.jsonl"))
+	doctor.now = func() time.Time { return time.Date(2024, 2, 1, 12, 0, 0, 0, time.UTC) }
+	repo, err := NewEventSourcedAdherenceRepository(doctor.AdherencePath, doctor.AdherenceLogPath, WithLogRotation(RotationPolicy{MaxBytes: 800}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	at := time.Date(2024, 2, 10, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 10; i++ {
+		if err := repo.AppendLog(context.Background(), toggle(at.Add(time.Duration(i)*time.Minute), journal.TrueLove, i%2 == 1)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if _, _, err := repo.Rebuild(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if problems, err := doctor.Check(); err != nil || len(problems) != 0 {
+		t.Fatalf("expected a rotated log to be fine, got %+v, %v", problems, err)
+	}
+
+	segments, err := repo.Segments(context.Background())
+	if err != nil || len(segments) == 0 {
+		t.Fatalf("expected the log to be rotated, got %+v, %v", segments, err)
+	}
+	segmentPath := filepath.Join(dir, segments[0].Name)
+	plain, err := readSegment(segmentPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	damaged, err := compressSegment(append(plain, "not json\n"...))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	snapshot, err := os.ReadFile(SnapshotPath(doctor.AdherenceLogPath))
+	if err != nil {
+		t.Fatalf("expected a snapshot: %v", err)
+	}
+	for path, data := range map[string][]byte{
+		segmentPath:                           damaged,
+		LogIndexPath(doctor.AdherenceLogPath): []byte("{"),
+		SnapshotPath(doctor.AdherenceLogPath): []byte(strings.Replace(string(snapshot), `"true-love": true`, `"true-love": false`, 1)),
+	} {
+		if err := os.WriteFile(path, data, 0o600); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+
+	problems, err := doctor.Check()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	files := map[string]bool{}
+	for _, problem := range problems {
+		files[filepath.Base(problem.File)] = true
+	}
+	for _, name := range []string{segments[0].Name, "adherence.log.index.json", "adherence.log.snapshot.json"} {
+		if !files[name] {
+			t.Fatalf("expected a problem in %s, got %+v", name, problems)
+		}
+	}
+
+	if _, _, err := doctor.Repair(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if problems, err := doctor.Check(); err != nil || len(problems) != 0 {
+		t.Fatalf("expected no problems after repair, got %+v, %v", problems, err)
+	}
+	reopened, err := NewEventSourcedAdherenceRepository(doctor.AdherencePath, doctor.AdherenceLogPath)
+	if err != nil {
+		t.Fatalf("expected repaired files to load: %v", err)
+	}
+	log, err := reopened.Log(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if changes := adherence.Changes(log); len(changes) != 10 {
+		t.Fatalf("expected every change after repair, got %d", len(changes))
+	}
+	if state, _ := reopened.Get(context.Background()); !state[journal.TrueLove] {
+		t.Fatalf("expected the last change to hold after repair, got %+v", state)
+	}
+}
+
+func TestDoctorRepairsRenewalsRevisitsAndWAL(t *testing.T) {
+	doctor := writeDoctorFiles(t, "", "", "")
+	dir := filepath.Dir(doctor.JournalPath)
+	doctor.BeginningAnewPath = filepath.Join(dir, "beginning-anew.json")
+	doctor.RevisitPath = filepath.Join(dir, "revisits.json")
+	doctor.WALPath = filepath.Join(dir, "pending.wal.json")
+	for path, data := range map[string]string{
+		doctor.BeginningAnewPath: `[
+  {"break": "abc", "precept": "true-love", "at": "2024-01-01T09:00:00Z"},
+  {"break": "abc", "precept": "true-love", "at": "2024-01-01T10:00:00Z"},
+  {"break": "def", "precept": "no-such-precept", "at": "2024-01-01T09:00:00Z"}
+]
+`,
+		doctor.RevisitPath: `[
+  {"entry": "abc", "at": ["2024-01-01T09:00:00Z"]},
+  {"entry": "abc", "at": ["yesterday"]}
+]
+`,
+		doctor.WALPath: `{"journal_append": [`,
+	} {
+		if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+
+	problems, err := doctor.Check()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []struct {
+		file    string
+		record  int
+		message string
+	}{
+		{"beginning-anew.json", 2, "duplicate of record 1"},
+		{"beginning-anew.json", 3, "unknown precept"},
+		{"revisits.json", 2, `invalid revisit time "yesterday"`},
+		{"pending.wal.json", 0, "interrupted write cannot be completed"},
+	}
+	if len(problems) != len(want) {
+		t.Fatalf("expected %d problems, got %+v", len(want), problems)
+	}
+	for i, w := range want {
+		got := problems[i]
+		if filepath.Base(got.File) != w.file || got.Record != w.record || !strings.Contains(got.Message, w.message) {
+			t.Fatalf("problem %d: expected %+v, got %+v", i, w, got)
+		}
+	}
+
+	_, result, err := doctor.Repair()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Removed) != 1 || result.Quarantined != 4 {
+		t.Fatalf("unexpected repair result: %+v", result)
+	}
+	if _, err := os.Stat(doctor.WALPath); !os.IsNotExist(err) {
+		t.Fatalf("expected the write-ahead record to be removed, got %v", err)
+	}
+	renewals, err := NewBeginningAnewRepository(doctor.BeginningAnewPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if records, err := renewals.List(context.Background()); err != nil || len(records) != 1 {
+		t.Fatalf("expected the good record to load, got %+v, %v", records, err)
+	}
+	revisits, err := NewRevisitRepository(doctor.RevisitPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if records, err := revisits.List(context.Background()); err != nil || len(records) != 1 {
+		t.Fatalf("expected the good record to load, got %+v, %v", records, err)
+	}
+	if problems, err := doctor.Check(); err != nil || len(problems) != 0 {
+		t.Fatalf("expected no problems after repair, got %+v, %v", problems, err)
+	}
+}
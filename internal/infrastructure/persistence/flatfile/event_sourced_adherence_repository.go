@@ -0,0 +1,295 @@
+package flatfile
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/thatnerdjosh/mindfulness/internal/domain/adherence"
+	"github.com/thatnerdjosh/mindfulness/internal/domain/journal"
+	"github.com/thatnerdjosh/mindfulness/internal/infrastructure/atomicfile"
+)
+
+const defaultSnapshotEvery = 100
+
+// EventSourcedAdherenceRepository treats the adherence log as the source of
+// truth. The state file is a projection of the log, rewritten whenever the
+// two disagree, and a snapshot of the fold lets startup replay only the
+// events logged since it was taken.
+type EventSourcedAdherenceRepository struct {
+	*AdherenceRepository
+
+	writeMu       sync.Mutex
+	snapshotPath  string
+	snapshotEvery int
+	events        int
+	sinceSnapshot int
+}
+
+// adherenceSnapshot is the folded state after the first Events events of
+// the log, which end Offset bytes into the file with the event whose
+// fingerprint is Last.
+type adherenceSnapshot struct {
+	Events int             `json:"events"`
+	Offset int64           `json:"offset"`
+	Last   string          `json:"last"`
+	State  adherenceRecord `json:"state"`
+}
+
+// SnapshotPath returns where the snapshot of the log at logPath is kept.
+func SnapshotPath(logPath string) string {
+	return strings.TrimSuffix(logPath, ".jsonl") + ".snapshot.json"
+}
+
+// NewEventSourcedAdherenceRepository opens the log and derives the state
+// from it. A log that does not start with initial-state events gets them
+// first, so every precept's history has an explicit starting point.
+func NewEventSourcedAdherenceRepository(path string, logPath string, opts ...Option) (*EventSourcedAdherenceRepository, error) {
+	inner, err := NewAdherenceRepository(path, logPath, opts...)
+	if err != nil {
+		return nil, err
+	}
+	repo := &EventSourcedAdherenceRepository{
+		AdherenceRepository: inner,
+		snapshotPath:        SnapshotPath(logPath),
+		snapshotEvery:       defaultSnapshotEvery,
+	}
+	if err := repo.open(context.Background()); err != nil {
+		return nil, err
+	}
+	return repo, nil
+}
+
+func (r *EventSourcedAdherenceRepository) open(ctx context.Context) error {
+	data, err := readIfExists(r.logPath)
+	if err != nil {
+		return err
+	}
+
+	if snapshot, ok := r.readSnapshot(data); ok {
+		tail, err := r.decodeLogData(data[snapshot.Offset:])
+		if err != nil {
+			return err
+		}
+		state, err := snapshot.State.toAdherence()
+		if err != nil {
+			return err
+		}
+		r.events = snapshot.Events + len(tail)
+		r.sinceSnapshot = len(tail)
+		return r.project(adherence.Fold(state, tail), false)
+	}
+
+	log, err := r.AdherenceRepository.Log(ctx)
+	if err != nil {
+		return err
+	}
+	if !hasInitialEvents(log) {
+		projection, err := r.AdherenceRepository.Get(ctx)
+		if err != nil {
+			return err
+		}
+		at := r.now().UTC()
+		if len(log) > 0 {
+			at = log[0].At.Add(-time.Second)
+		}
+		log = append(adherence.InitialEvents(at, startingState(log, projection)), log...)
+		if err := r.AdherenceRepository.ReplaceLog(ctx, log); err != nil {
+			return err
+		}
+	}
+	_, err = r.rebuildFrom(log, false)
+	return err
+}
+
+// AppendLog records the event and folds it into the state.
+func (r *EventSourcedAdherenceRepository) AppendLog(ctx context.Context, entry adherence.AdherenceLogEntry) error {
+	r.writeMu.Lock()
+	defer r.writeMu.Unlock()
+
+	rotations := r.rotations
+	if err := r.AdherenceRepository.AppendLog(ctx, entry); err != nil {
+		return err
+	}
+	state, err := r.AdherenceRepository.Get(ctx)
+	if err != nil {
+		return err
+	}
+	state = adherence.Fold(state, []adherence.AdherenceLogEntry{entry})
+	if err := r.project(state, false); err != nil {
+		return err
+	}
+	r.events++
+	r.sinceSnapshot++
+	// A rotation rewrote the file the snapshot points into.
+	if r.sinceSnapshot < r.snapshotEvery && r.rotations == rotations {
+		return nil
+	}
+	return r.writeSnapshot(state)
+}
+
+// ArchiveLog archives the current log and snapshots the fresh file.
+func (r *EventSourcedAdherenceRepository) ArchiveLog(ctx context.Context) (adherence.LogSegment, bool, error) {
+	r.writeMu.Lock()
+	defer r.writeMu.Unlock()
+
+	segment, archived, err := r.AdherenceRepository.ArchiveLog(ctx)
+	if err != nil || !archived {
+		return segment, archived, err
+	}
+	state, err := r.AdherenceRepository.Get(ctx)
+	if err != nil {
+		return segment, archived, err
+	}
+	return segment, archived, r.writeSnapshot(state)
+}
+
+// ReplaceLog swaps the log and rebuilds the state from it.
+func (r *EventSourcedAdherenceRepository) ReplaceLog(ctx context.Context, log []adherence.AdherenceLogEntry) error {
+	r.writeMu.Lock()
+	defer r.writeMu.Unlock()
+
+	if err := r.AdherenceRepository.ReplaceLog(ctx, log); err != nil {
+		return err
+	}
+	_, err := r.rebuildFrom(log, false)
+	return err
+}
+
+// Rebuild regenerates the state file and the snapshot from the whole log,
+// overwriting a state file that could not be read.
+func (r *EventSourcedAdherenceRepository) Rebuild(ctx context.Context) (adherence.Adherence, int, error) {
+	r.writeMu.Lock()
+	defer r.writeMu.Unlock()
+
+	log, err := r.AdherenceRepository.Log(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	state, err := r.rebuildFrom(log, true)
+	if err != nil {
+		return nil, 0, err
+	}
+	return state, len(log), nil
+}
+
+func (r *EventSourcedAdherenceRepository) rebuildFrom(log []adherence.AdherenceLogEntry, force bool) (adherence.Adherence, error) {
+	state := adherence.Fold(adherence.DefaultAdherence(), log)
+	if err := r.project(state, force); err != nil {
+		return nil, err
+	}
+	r.events = len(log)
+	return state, r.writeSnapshot(state)
+}
+
+// project makes state the current state, rewriting the state file when it
+// differs. A state file that could not be parsed is only overwritten when
+// force is set.
+func (r *EventSourcedAdherenceRepository) project(state adherence.Adherence, force bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	changed := len(state) != len(r.state)
+	for precept, value := range state {
+		if current, ok := r.state[precept]; !ok || current != value {
+			changed = true
+		}
+	}
+	r.state = state
+	if force {
+		r.damaged = false
+	}
+	if r.damaged || (!changed && !force) {
+		return nil
+	}
+	return r.persistLocked()
+}
+
+// writeSnapshot records state as the fold of the log file up to its end.
+// It writes nothing when the file does not end with a readable event, since
+// the snapshot could not be checked against it on the next open.
+func (r *EventSourcedAdherenceRepository) writeSnapshot(state adherence.Adherence) error {
+	r.sinceSnapshot = 0
+	log, err := readIfExists(r.logPath)
+	if err != nil {
+		return err
+	}
+	last, ok := lastEvent(log, int64(len(log)))
+	if !ok {
+		return nil
+	}
+	data, err := json.MarshalIndent(adherenceSnapshot{
+		Events: r.events,
+		Offset: int64(len(log)),
+		Last:   last.Fingerprint(),
+		State:  recordFromAdherence(state),
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode adherence snapshot: %w", err)
+	}
+	return atomicfile.Write(r.snapshotPath, append(data, '\n'), 0o600)
+}
+
+// readSnapshot returns the snapshot if it still describes the start of
+// data: the line ending at its offset must be the event it names.
+func (r *EventSourcedAdherenceRepository) readSnapshot(data []byte) (adherenceSnapshot, bool) {
+	raw, err := readIfExists(r.snapshotPath)
+	if err != nil || raw == nil {
+		return adherenceSnapshot{}, false
+	}
+	var snapshot adherenceSnapshot
+	if err := json.Unmarshal(raw, &snapshot); err != nil {
+		return adherenceSnapshot{}, false
+	}
+	last, ok := lastEvent(data, snapshot.Offset)
+	if !ok || last.Fingerprint() != snapshot.Last {
+		return adherenceSnapshot{}, false
+	}
+	return snapshot, true
+}
+
+// lastEvent parses the line that ends offset bytes into data.
+func lastEvent(data []byte, offset int64) (adherence.AdherenceLogEntry, bool) {
+	if offset <= 0 || offset > int64(len(data)) || data[offset-1] != '\n' {
+		return adherence.AdherenceLogEntry{}, false
+	}
+	head := data[:offset-1]
+	var record adherenceLogRecord
+	if err := json.Unmarshal(head[bytes.LastIndexByte(head, '\n')+1:], &record); err != nil {
+		return adherence.AdherenceLogEntry{}, false
+	}
+	entry, err := record.toLogEntry()
+	if err != nil {
+		return adherence.AdherenceLogEntry{}, false
+	}
+	return entry, true
+}
+
+func hasInitialEvents(log []adherence.AdherenceLogEntry) bool {
+	for _, entry := range log {
+		if entry.Kind == adherence.EventInitial {
+			return true
+		}
+	}
+	return false
+}
+
+// startingState is what the history of an existing log starts from: the
+// value each precept had before its first change, or the stored state for
+// precepts the log never mentions.
+func startingState(log []adherence.AdherenceLogEntry, projection adherence.Adherence) adherence.Adherence {
+	state := adherence.DefaultAdherence()
+	for _, info := range journal.AllPrecepts() {
+		if value, ok := projection[info.ID]; ok && !mentions(log, info.ID) {
+			state[info.ID] = value
+		}
+	}
+	for i := len(log) - 1; i >= 0; i-- {
+		state[log[i].Precept] = log[i].From
+	}
+	return state
+}
@@ -0,0 +1,234 @@
+package flatfile
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thatnerdjosh/mindfulness/internal/domain/adherence"
+	"github.com/thatnerdjosh/mindfulness/internal/domain/journal"
+)
+
+func TestEventSourcedAdherenceRepositoryRecordsInitialState(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "adherence.log.jsonl")
+
+	repo, err := NewEventSourcedAdherenceRepository(filepath.Join(dir, "adherence.json"), logPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	log, err := repo.Log(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(log) != len(journal.AllPrecepts()) {
+		t.Fatalf("expected one initial event per precept, got %d", len(log))
+	}
+	for _, entry := range log {
+		if entry.Kind != adherence.EventInitial || !entry.To {
+			t.Fatalf("expected initial events at the default, got %+v", entry)
+		}
+	}
+
+	if _, err := NewEventSourcedAdherenceRepository(filepath.Join(dir, "adherence.json"), logPath); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if reopened, _ := ReadAdherenceLogFile(logPath); len(reopened) != len(log) {
+		t.Fatalf("expected initial events to be recorded once, got %d events", len(reopened))
+	}
+}
+
+func TestEventSourcedAdherenceRepositoryMigratesExistingLog(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "adherence.json")
+	logPath := filepath.Join(dir, "adherence.log.jsonl")
+	if err := os.WriteFile(path, []byte(`{"true-happiness": false, "true-love": true}`), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	line := `{"timestamp":"2024-02-10T12:00:00Z","precept":"true-love","from":false,"to":true}` + "\n"
+	if err := os.WriteFile(logPath, []byte(line), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	repo, err := NewEventSourcedAdherenceRepository(path, logPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	log, err := repo.Log(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	initial := make(map[journal.Precept]adherence.AdherenceLogEntry)
+	for _, entry := range log {
+		if entry.Kind == adherence.EventInitial {
+			initial[entry.Precept] = entry
+		}
+	}
+	if len(initial) != len(journal.AllPrecepts()) || len(log) != len(initial)+1 {
+		t.Fatalf("expected initial events before the existing change, got %+v", log)
+	}
+	if initial[journal.TrueLove].To || initial[journal.TrueHappiness].To || !initial[journal.ReverenceForLife].To {
+		t.Fatalf("unexpected starting point: %+v", initial)
+	}
+	if want := time.Date(2024, 2, 10, 11, 59, 59, 0, time.UTC); !initial[journal.TrueLove].At.Equal(want) {
+		t.Fatalf("expected initial events just before the first change, got %s", initial[journal.TrueLove].At)
+	}
+
+	state, err := repo.Get(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !state[journal.TrueLove] || state[journal.TrueHappiness] {
+		t.Fatalf("unexpected state: %+v", state)
+	}
+}
+
+func TestEventSourcedAdherenceRepositoryDerivesStateFromLog(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "adherence.json")
+	logPath := filepath.Join(dir, "adherence.log.jsonl")
+	repo, err := NewEventSourcedAdherenceRepository(path, logPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	at := time.Date(2024, 2, 10, 12, 0, 0, 0, time.UTC)
+	if err := repo.AppendLog(context.Background(), adherence.AdherenceLogEntry{At: at, Precept: journal.TrueLove, From: true, To: false}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if state, _ := repo.Get(context.Background()); state[journal.TrueLove] {
+		t.Fatal("expected the appended event to be folded into the state")
+	}
+
+	// A projection edited behind the repository's back is corrected from
+	// the log on the next open.
+	if err := os.WriteFile(path, []byte(`{"true-love": true}`), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	reopened, err := NewEventSourcedAdherenceRepository(path, logPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if state, _ := reopened.Get(context.Background()); state[journal.TrueLove] {
+		t.Fatal("expected the state to follow the log")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	var projection map[string]bool
+	if err := json.Unmarshal(data, &projection); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if value, ok := projection["true-love"]; !ok || value {
+		t.Fatalf("expected the projection to be rewritten, got %s", data)
+	}
+}
+
+func TestEventSourcedAdherenceRepositorySnapshots(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "adherence.json")
+	logPath := filepath.Join(dir, "adherence.log.jsonl")
+	repo, err := NewEventSourcedAdherenceRepository(path, logPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	repo.snapshotEvery = 2
+
+	at := time.Date(2024, 2, 10, 12, 0, 0, 0, time.UTC)
+	toggles := []adherence.AdherenceLogEntry{
+		{At: at, Precept: journal.TrueLove, From: true, To: false},
+		{At: at.Add(time.Minute), Precept: journal.TrueHappiness, From: true, To: false},
+		{At: at.Add(2 * time.Minute), Precept: journal.TrueLove, From: false, To: true},
+	}
+	for _, entry := range toggles {
+		if err := repo.AppendLog(context.Background(), entry); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	raw, err := os.ReadFile(SnapshotPath(logPath))
+	if err != nil {
+		t.Fatalf("expected a snapshot: %v", err)
+	}
+	var snapshot adherenceSnapshot
+	if err := json.Unmarshal(raw, &snapshot); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if snapshot.Events != len(journal.AllPrecepts())+2 || snapshot.Last != toggles[1].Fingerprint() {
+		t.Fatalf("unexpected snapshot: %+v", snapshot)
+	}
+
+	// Startup folds only the events after the snapshot, so a marker planted
+	// in the snapshot state shows that it was used.
+	snapshot.State[string(journal.ReverenceForLife)] = false
+	raw, _ = json.Marshal(snapshot)
+	if err := os.WriteFile(SnapshotPath(logPath), raw, 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	reopened, err := NewEventSourcedAdherenceRepository(path, logPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	state, _ := reopened.Get(context.Background())
+	if state[journal.ReverenceForLife] || !state[journal.TrueLove] || state[journal.TrueHappiness] {
+		t.Fatalf("expected the snapshot plus the tail, got %+v", state)
+	}
+
+	// A snapshot that no longer matches the log is ignored.
+	log, _ := reopened.Log(context.Background())
+	if err := reopened.AdherenceRepository.ReplaceLog(context.Background(), log[1:]); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	reopened, err = NewEventSourcedAdherenceRepository(path, logPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if state, _ := reopened.Get(context.Background()); !state[journal.ReverenceForLife] {
+		t.Fatal("expected a full replay after the log changed under the snapshot")
+	}
+}
+
+func TestEventSourcedAdherenceRepositoryRebuild(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "adherence.json")
+	logPath := filepath.Join(dir, "adherence.log.jsonl")
+	repo, err := NewEventSourcedAdherenceRepository(path, logPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	at := time.Date(2024, 2, 10, 12, 0, 0, 0, time.UTC)
+	if err := repo.AppendLog(context.Background(), adherence.AdherenceLogEntry{At: at, Precept: journal.TrueLove, From: true, To: false}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := os.WriteFile(path, []byte("{broken"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	damaged, err := NewEventSourcedAdherenceRepository(path, logPath, WithLenientLoad())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if state, _ := damaged.Get(context.Background()); state[journal.TrueLove] {
+		t.Fatal("expected the state to come from the log despite the damaged projection")
+	}
+
+	state, events, err := damaged.Rebuild(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if events != len(journal.AllPrecepts())+1 || state[journal.TrueLove] {
+		t.Fatalf("unexpected rebuild: %d events, %+v", events, state)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if !strings.Contains(string(data), `"true-love": false`) {
+		t.Fatalf("expected the projection to be rewritten, got %s", data)
+	}
+}
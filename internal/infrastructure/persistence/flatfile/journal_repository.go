@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/thatnerdjosh/mindfulness/internal/domain/journal"
+	"github.com/thatnerdjosh/mindfulness/internal/infrastructure/atomicfile"
 )
 
 // DefaultJournalPath returns the default JSON journal file path.
@@ -29,12 +30,18 @@ func DefaultJournalPath() (string, error) {
 
 // JournalRepository stores journal entries in a JSON file.
 type JournalRepository struct {
-	mu      sync.RWMutex
-	path    string
-	entries []journal.Entry
+	mu       sync.RWMutex
+	path     string
+	entries  []journal.Entry
+	opts     options
+	warnings []LoadWarning
+	// unparsed holds records skipped by a lenient load, written back
+	// verbatim after the valid entries.
+	unparsed []json.RawMessage
+	damaged  bool
 }
 
-func NewJournalRepository(path string) (*JournalRepository, error) {
+func NewJournalRepository(path string, opts ...Option) (*JournalRepository, error) {
 	path = strings.TrimSpace(path)
 	if path == "" {
 		return nil, fmt.Errorf("journal path is required")
@@ -47,6 +54,7 @@ func NewJournalRepository(path string) (*JournalRepository, error) {
 	repo := &JournalRepository{
 		path:    path,
 		entries: []journal.Entry{},
+		opts:    buildOptions(opts),
 	}
 	if err := repo.load(); err != nil {
 		return nil, err
@@ -98,49 +106,113 @@ func (r *JournalRepository) List(_ context.Context) ([]journal.Entry, error) {
 	return entries, nil
 }
 
+func (r *JournalRepository) Replace(_ context.Context, entries []journal.Entry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.entries = append([]journal.Entry{}, entries...)
+	return r.persistLocked()
+}
+
+// LoadWarnings lists the records skipped by a lenient load.
+func (r *JournalRepository) LoadWarnings() []LoadWarning {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return append([]LoadWarning(nil), r.warnings...)
+}
+
 func (r *JournalRepository) load() error {
-	data, err := os.ReadFile(r.path)
+	if r.opts.lenient {
+		return r.loadLenient()
+	}
+	entries, err := ReadJournalFile(r.path)
+	if err != nil {
+		return err
+	}
+	r.entries = append(r.entries, entries...)
+	return nil
+}
+
+func (r *JournalRepository) loadLenient() error {
+	data, err := readIfExists(r.path)
+	if err != nil {
+		return err
+	}
+	scan := scanJournal(data)
+	for _, scanned := range scan.records {
+		if scanned.err != nil {
+			r.warnings = append(r.warnings, LoadWarning{File: r.path, Line: scanned.line, Record: scanned.record, Message: scanned.err.Error()})
+			r.unparsed = append(r.unparsed, scanned.raw)
+			continue
+		}
+		r.entries = append(r.entries, scanned.entry)
+	}
+	if scan.damaged != nil {
+		r.warnings = append(r.warnings, LoadWarning{File: r.path, Line: scan.damaged.line, Record: scan.damaged.record, Message: scan.damaged.err.Error()})
+		r.damaged = true
+	}
+	return nil
+}
+
+// ReadJournalFile decodes a journal file without opening it as a repository,
+// for example a copy left behind by a file-sync tool. A missing file holds no
+// entries.
+func ReadJournalFile(path string) ([]journal.Entry, error) {
+	data, err := os.ReadFile(path)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return nil
+			return nil, nil
 		}
-		return fmt.Errorf("read journal file: %w", err)
+		return nil, fmt.Errorf("read journal file: %w", err)
 	}
+	return DecodeJournal(data)
+}
+
+// DecodeJournal decodes the contents of a journal file.
+func DecodeJournal(data []byte) ([]journal.Entry, error) {
 	if len(data) == 0 {
-		return nil
+		return nil, nil
 	}
 
 	var records []entryRecord
 	if err := json.Unmarshal(data, &records); err != nil {
-		return fmt.Errorf("decode journal file: %w", err)
+		return nil, fmt.Errorf("decode journal file: %w", err)
 	}
 
+	entries := make([]journal.Entry, 0, len(records))
 	for _, record := range records {
 		entry, err := record.toEntry()
 		if err != nil {
-			return err
+			return nil, err
 		}
-		r.entries = append(r.entries, entry)
+		entries = append(entries, entry)
 	}
-	return nil
+	return entries, nil
 }
 
 func (r *JournalRepository) persistLocked() error {
+	if r.damaged {
+		return fmt.Errorf("write %s: %w", r.path, ErrDamagedFile)
+	}
 	entries := append([]journal.Entry{}, r.entries...)
 	sort.SliceStable(entries, func(i, j int) bool {
 		return entries[i].Date.Before(entries[j].Date)
 	})
-	records := make([]entryRecord, 0, len(entries))
+	records := make([]any, 0, len(entries)+len(r.unparsed))
 	for _, entry := range entries {
 		records = append(records, recordFromEntry(entry))
 	}
+	for _, raw := range r.unparsed {
+		records = append(records, raw)
+	}
 
 	data, err := json.MarshalIndent(records, "", "  ")
 	if err != nil {
 		return fmt.Errorf("encode journal file: %w", err)
 	}
 	data = append(data, '\n')
-	return writeFileAtomic(r.path, data, 0o600)
+	return atomicfile.Write(r.path, data, 0o600)
 }
 
 type entryRecord struct {
@@ -150,6 +222,26 @@ type entryRecord struct {
 	Note        string            `json:"note,omitempty"`
 	Mood        string            `json:"mood,omitempty"`
 	Foundation  string            `json:"foundation,omitempty"`
+	Adherence   []string          `json:"adherence_events,omitempty"`
+	Vedana      *vedanaRecord     `json:"vedana,omitempty"`
+	Questions   map[string]string `json:"questions,omitempty"`
+	Review      *reviewRecord     `json:"review,omitempty"`
+	Quicknote   bool              `json:"quicknote,omitempty"`
+}
+
+// vedanaRecord is absent from files written before feeling tones were
+// recorded, which load without one.
+type vedanaRecord struct {
+	Tone      string `json:"tone"`
+	Intensity int    `json:"intensity,omitempty"`
+	Location  string `json:"location,omitempty"`
+	Trigger   string `json:"trigger,omitempty"`
+}
+
+// reviewRecord is only present on entries saved by `mt review`.
+type reviewRecord struct {
+	Period string `json:"period"`
+	Start  string `json:"start"`
 }
 
 func recordFromEntry(entry journal.Entry) entryRecord {
@@ -157,14 +249,29 @@ func recordFromEntry(entry journal.Entry) entryRecord {
 	for precept, reflection := range entry.Reflections {
 		reflections[string(precept)] = reflection
 	}
-	return entryRecord{
+	record := entryRecord{
 		Date:        entry.Date.UTC().Format("2006-01-02"),
-		Timestamp:   entry.Timestamp.Format(time.RFC3339),
+		Timestamp:   entry.Timestamp.UTC().Format(time.RFC3339Nano),
 		Reflections: reflections,
 		Note:        entry.Note,
 		Mood:        entry.Mood,
 		Foundation:  string(entry.Foundation),
+		Adherence:   entry.AdherenceEvents,
+		Quicknote:   entry.Quicknote,
+	}
+	if v := entry.Vedana; v != nil {
+		record.Vedana = &vedanaRecord{Tone: string(v.Tone), Intensity: v.Intensity, Location: v.Location, Trigger: v.Trigger}
+	}
+	if len(entry.Questions) > 0 {
+		record.Questions = make(map[string]string, len(entry.Questions))
+		for precept, question := range entry.Questions {
+			record.Questions[string(precept)] = question
+		}
 	}
+	if r := entry.Review; r != nil {
+		record.Review = &reviewRecord{Period: string(r.Period), Start: r.Start.Format("2006-01-02")}
+	}
+	return record
 }
 
 func (r entryRecord) toEntry() (journal.Entry, error) {
@@ -177,7 +284,7 @@ func (r entryRecord) toEntry() (journal.Entry, error) {
 	if strings.TrimSpace(r.Timestamp) == "" {
 		timestamp = parsed.UTC()
 	} else {
-		parsedTimestamp, err := time.Parse(time.RFC3339, strings.TrimSpace(r.Timestamp))
+		parsedTimestamp, err := time.Parse(time.RFC3339Nano, strings.TrimSpace(r.Timestamp))
 		if err != nil {
 			return journal.Entry{}, fmt.Errorf("invalid journal timestamp %q: %w", r.Timestamp, err)
 		}
@@ -189,36 +296,33 @@ func (r entryRecord) toEntry() (journal.Entry, error) {
 		reflections[journal.Precept(precept)] = reflection
 	}
 
-	entry, err := journal.NewEntry(parsed, reflections, r.Note, r.Mood, journal.Foundation(strings.ToLower(strings.TrimSpace(r.Foundation))), timestamp)
-	if err != nil {
-		return journal.Entry{}, fmt.Errorf("invalid journal entry for %s: %w", r.Date, err)
-	}
-	return entry, nil
-}
-
-func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
-	dir := filepath.Dir(path)
-	tmp, err := os.CreateTemp(dir, ".journal-*.json")
-	if err != nil {
-		return fmt.Errorf("create temp file: %w", err)
+	questions := make(map[journal.Precept]string, len(r.Questions))
+	for precept, question := range r.Questions {
+		questions[journal.Precept(precept)] = question
 	}
-	defer func() {
-		_ = os.Remove(tmp.Name())
-	}()
 
-	if _, err := tmp.Write(data); err != nil {
-		_ = tmp.Close()
-		return fmt.Errorf("write temp file: %w", err)
+	opts := []journal.EntryOption{journal.WithAdherenceEvents(r.Adherence...), journal.WithQuestions(questions)}
+	if r.Vedana != nil {
+		vedana, err := journal.NewVedana(journal.Tone(r.Vedana.Tone), r.Vedana.Intensity, r.Vedana.Location, r.Vedana.Trigger)
+		if err != nil {
+			return journal.Entry{}, fmt.Errorf("invalid journal entry for %s: %w", r.Date, err)
+		}
+		opts = append(opts, journal.WithVedana(vedana))
 	}
-	if err := tmp.Chmod(perm); err != nil {
-		_ = tmp.Close()
-		return fmt.Errorf("chmod temp file: %w", err)
+	if r.Review != nil {
+		start, err := time.Parse("2006-01-02", strings.TrimSpace(r.Review.Start))
+		if err != nil {
+			return journal.Entry{}, fmt.Errorf("invalid review start %q: %w", r.Review.Start, err)
+		}
+		opts = append(opts, journal.WithReview(journal.Review{Period: journal.ReviewPeriod(r.Review.Period), Start: start}))
 	}
-	if err := tmp.Close(); err != nil {
-		return fmt.Errorf("close temp file: %w", err)
+	if r.Quicknote {
+		opts = append(opts, journal.AsQuicknote())
 	}
-	if err := os.Rename(tmp.Name(), path); err != nil {
-		return fmt.Errorf("replace journal file: %w", err)
+
+	entry, err := journal.NewEntry(parsed, reflections, r.Note, r.Mood, journal.Foundation(strings.ToLower(strings.TrimSpace(r.Foundation))), timestamp, opts...)
+	if err != nil {
+		return journal.Entry{}, fmt.Errorf("invalid journal entry for %s: %w", r.Date, err)
 	}
-	return nil
+	return entry, nil
 }
@@ -2,8 +2,10 @@ package flatfile
 
 import (
 	"context"
+	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -319,3 +321,356 @@ func TestNewJournalRepository(t *testing.T) {
 		})
 	}
 }
+
+func TestJournalRepositoryReplace(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.json")
+	repo, err := NewJournalRepository(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var entries []journal.Entry
+	for _, day := range []int{2, 1} {
+		date := time.Date(2024, 2, day, 0, 0, 0, 0, time.UTC)
+		entry, err := journal.NewEntry(date, nil, "note", "", journal.FoundationDhamma, date.Add(9*time.Hour))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := repo.Replace(context.Background(), entries); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	reloaded, err := ReadJournalFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(reloaded) != 2 || reloaded[0].Date.Day() != 1 {
+		t.Fatalf("unexpected entries after replace: %+v", reloaded)
+	}
+
+	missing, err := ReadJournalFile(filepath.Join(t.TempDir(), "missing.json"))
+	if err != nil || missing != nil {
+		t.Fatalf("expected no entries for a missing file, got %v, %v", missing, err)
+	}
+}
+
+func TestJournalRepositoryLenientLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.json")
+	data := "[\n  {\"date\": \"2024-01-01\", \"note\": \"good\"},\n  {\"date\": \"2024-01-02\", \"foundation\": \"mind\", \"note\": \"bad\"}\n]\n"
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	if _, err := NewJournalRepository(path); err == nil {
+		t.Fatal("expected strict load to fail")
+	}
+
+	repo, err := NewJournalRepository(path, WithLenientLoad())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	warnings := repo.LoadWarnings()
+	if len(warnings) != 1 || warnings[0].Line != 3 || warnings[0].Record != 2 || warnings[0].Message != `unknown foundation "mind"` {
+		t.Fatalf("unexpected warnings: %+v", warnings)
+	}
+	list, err := repo.List(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(list) != 1 || list[0].Note != "good" {
+		t.Fatalf("unexpected entries: %+v", list)
+	}
+
+	entry, err := journal.NewEntry(time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC), nil, "new", "", "", time.Date(2024, 1, 3, 9, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := repo.Save(context.Background(), entry); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	written, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if !strings.Contains(string(written), `"foundation": "mind"`) {
+		t.Fatalf("expected the skipped record to be written back:\n%s", written)
+	}
+	reloaded, err := NewJournalRepository(path, WithLenientLoad())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(reloaded.LoadWarnings()) != 1 {
+		t.Fatalf("expected the skipped record to still be reported, got %+v", reloaded.LoadWarnings())
+	}
+	if list, _ := reloaded.List(context.Background()); len(list) != 2 {
+		t.Fatalf("expected 2 entries after save, got %d", len(list))
+	}
+}
+
+func TestJournalRepositoryLenientLoadRefusesDamagedWrites(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.json")
+	data := "[\n  {\"date\": \"2024-01-01\", \"note\": \"good\"},\n  {\"date\": \"2024-01-02\", \n"
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	repo, err := NewJournalRepository(path, WithLenientLoad())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if warnings := repo.LoadWarnings(); len(warnings) != 1 || warnings[0].Line != 3 {
+		t.Fatalf("unexpected warnings: %+v", warnings)
+	}
+	if list, _ := repo.List(context.Background()); len(list) != 1 {
+		t.Fatalf("expected the record before the damage, got %d", len(list))
+	}
+
+	entry, err := journal.NewEntry(time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC), nil, "new", "", "", time.Date(2024, 1, 3, 9, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := repo.Save(context.Background(), entry); !errors.Is(err, ErrDamagedFile) {
+		t.Fatalf("expected ErrDamagedFile, got %v", err)
+	}
+	written, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if string(written) != data {
+		t.Fatalf("expected the damaged file to be left alone:\n%s", written)
+	}
+}
+
+func TestJournalRepositoryPersistsAdherenceLinks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.json")
+	repo, err := NewJournalRepository(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	at := time.Date(2024, 5, 2, 18, 30, 0, 0, time.UTC)
+	entry, err := journal.NewEntry(at, map[journal.Precept]string{journal.TrueLove: "ignored a call"}, "", "", "", at, journal.WithAdherenceEvents("abc", " ", "def"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := repo.Save(context.Background(), entry); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	reloaded, err := NewJournalRepository(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	list, err := reloaded.List(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(list) != 1 || len(list[0].AdherenceEvents) != 2 || list[0].AdherenceEvents[1] != "def" {
+		t.Fatalf("expected the links to survive a reload, got %+v", list)
+	}
+	if list[0].Fingerprint() != entry.Fingerprint() {
+		t.Fatal("expected the reloaded entry to keep its fingerprint")
+	}
+}
+
+func TestJournalRepositoryPersistsVedana(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.json")
+	// Written before feeling tones were recorded.
+	legacy := `[{"date":"2024-05-01","timestamp":"2024-05-01T08:00:00Z","note":"sat","foundation":"vedana"}]`
+	if err := os.WriteFile(path, []byte(legacy), 0o600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	repo, err := NewJournalRepository(path)
+	if err != nil {
+		t.Fatalf("expected a journal without feeling tones to load, got %v", err)
+	}
+
+	vedana, err := journal.NewVedana(journal.ToneUnpleasant, 4, "chest", "email")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	at := time.Date(2024, 5, 2, 18, 30, 0, 0, time.UTC)
+	entry, err := journal.NewEntry(at, nil, "tight", "", journal.FoundationVedana, at, journal.WithVedana(vedana))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := repo.Save(context.Background(), entry); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Count(string(data), `"vedana": {`) != 1 {
+		t.Fatalf("expected only the new entry to carry a feeling tone, got %s", data)
+	}
+
+	reloaded, err := NewJournalRepository(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	list, err := reloaded.List(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(list) != 2 || list[0].Vedana != nil || list[1].Vedana == nil || *list[1].Vedana != vedana {
+		t.Fatalf("expected the feeling tone to survive a reload, got %+v", list)
+	}
+	if list[1].Fingerprint() != entry.Fingerprint() {
+		t.Fatal("expected the reloaded entry to keep its fingerprint")
+	}
+}
+
+func TestJournalRepositoryPersistsQuestions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.json")
+	repo, err := NewJournalRepository(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	at := time.Date(2024, 5, 2, 18, 30, 0, 0, time.UTC)
+	entry, err := journal.NewEntry(at, map[journal.Precept]string{journal.TrueLove: "called home"}, "", "", "", at,
+		journal.WithQuestions(map[journal.Precept]string{journal.TrueLove: "Who did you hold today?"}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := repo.Save(context.Background(), entry); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	reloaded, err := NewJournalRepository(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	list, err := reloaded.List(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(list) != 1 || list[0].Questions[journal.TrueLove] != "Who did you hold today?" {
+		t.Fatalf("expected the question to survive a reload, got %+v", list)
+	}
+	if list[0].Fingerprint() != entry.Fingerprint() {
+		t.Fatal("expected the reloaded entry to keep its fingerprint")
+	}
+}
+
+func TestJournalRepositoryPersistsReview(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.json")
+	repo, err := NewJournalRepository(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	at := time.Date(2024, 5, 5, 20, 0, 0, 0, time.UTC)
+	review, err := journal.NewReview(journal.ReviewWeek, at)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	entry, err := journal.NewEntry(at, nil, "a steady week", "", "", at, journal.WithReview(review))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := repo.Save(context.Background(), entry); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if !strings.Contains(string(data), `"review": {`) || !strings.Contains(string(data), `"start": "2024-04-29"`) {
+		t.Fatalf("expected the review to be stored, got %s", data)
+	}
+
+	reloaded, err := NewJournalRepository(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	list, err := reloaded.List(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(list) != 1 || list[0].Review == nil || *list[0].Review != review {
+		t.Fatalf("expected the review to survive a reload, got %+v", list)
+	}
+	if list[0].Fingerprint() != entry.Fingerprint() {
+		t.Fatal("expected the reloaded entry to keep its fingerprint")
+	}
+}
+
+func TestJournalRepositoryPersistsSubSecondTimestamps(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.json")
+	repo, err := NewJournalRepository(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	at := time.Date(2026, 1, 1, 10, 0, 0, 123456789, time.FixedZone("UTC+2", 2*60*60))
+	entry, err := journal.NewEntry(at, nil, "x", "", "", at)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := repo.Save(context.Background(), entry); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if !strings.Contains(string(data), `"timestamp": "2026-01-01T08:00:00.123456789Z"`) {
+		t.Fatalf("expected the timestamp to be stored in UTC with nanoseconds, got %s", data)
+	}
+
+	reloaded, err := NewJournalRepository(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	list, err := reloaded.List(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(list) != 1 || !list[0].Timestamp.Equal(at) {
+		t.Fatalf("expected timestamp %s after a reload, got %+v", at, list)
+	}
+	if list[0].Fingerprint() != entry.Fingerprint() {
+		t.Fatal("expected the reloaded entry to keep its fingerprint")
+	}
+}
+
+func TestJournalRepositoryPersistsQuicknotes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.json")
+	repo, err := NewJournalRepository(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	at := time.Date(2024, 5, 5, 20, 0, 0, 0, time.UTC)
+	quicknote, err := journal.NewEntry(at, nil, "jotted down", "", "", at, journal.AsQuicknote())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	note, err := journal.NewEntry(at, nil, "jotted down", "", "", at)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if quicknote.Fingerprint() == note.Fingerprint() {
+		t.Fatal("expected the quicknote mark to change the fingerprint")
+	}
+	for _, entry := range []journal.Entry{quicknote, note} {
+		if err := repo.Save(context.Background(), entry); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	reloaded, err := NewJournalRepository(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	list, err := reloaded.List(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(list) != 2 || !list[0].Quicknote || list[1].Quicknote {
+		t.Fatalf("expected only the first entry to be a quicknote, got %+v", list)
+	}
+}
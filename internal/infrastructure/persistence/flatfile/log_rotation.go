@@ -0,0 +1,312 @@
+package flatfile
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/thatnerdjosh/mindfulness/internal/domain/adherence"
+	"github.com/thatnerdjosh/mindfulness/internal/infrastructure/atomicfile"
+)
+
+// DefaultRotateBytes is the size at which the adherence log is archived
+// when no policy is configured.
+const DefaultRotateBytes = 1 << 20
+
+// RotationPolicy decides when the adherence log is archived into a
+// compressed segment and how long segments are kept. Zero fields disable
+// the corresponding rule.
+type RotationPolicy struct {
+	// MaxBytes archives the log once it reaches this size.
+	MaxBytes int64
+	// MaxAge archives the log once its oldest change is this old.
+	MaxAge time.Duration
+	// KeepSegments deletes the oldest segments beyond this count.
+	KeepSegments int
+	// KeepFor deletes segments whose last event is older than this.
+	KeepFor time.Duration
+}
+
+// DefaultRotationPolicy archives the log every DefaultRotateBytes and keeps
+// every segment.
+func DefaultRotationPolicy() RotationPolicy {
+	return RotationPolicy{MaxBytes: DefaultRotateBytes}
+}
+
+// WithLogRotation sets the rotation policy of an adherence repository.
+func WithLogRotation(policy RotationPolicy) Option {
+	return func(o *options) {
+		o.rotation = &policy
+	}
+}
+
+// LogIndexPath returns where the segment index of the log at logPath is
+// kept.
+func LogIndexPath(logPath string) string {
+	return strings.TrimSuffix(logPath, ".jsonl") + ".index.json"
+}
+
+// logIndex lists the archived segments of a log, oldest first. Segment
+// files live next to the log.
+type logIndex struct {
+	Segments []logSegmentRecord `json:"segments"`
+}
+
+type logSegmentRecord struct {
+	File   string `json:"file"`
+	First  string `json:"first,omitempty"`
+	Last   string `json:"last,omitempty"`
+	Events int    `json:"events"`
+	Bytes  int64  `json:"bytes"`
+}
+
+func (s logSegmentRecord) toSegment() adherence.LogSegment {
+	first, _ := time.Parse(time.RFC3339Nano, s.First)
+	last, _ := time.Parse(time.RFC3339Nano, s.Last)
+	return adherence.LogSegment{Name: s.File, First: first, Last: last, Events: s.Events}
+}
+
+// ArchiveLog archives the current log regardless of the policy's size and
+// age rules, then applies its retention rules.
+func (r *AdherenceRepository) ArchiveLog(_ context.Context) (adherence.LogSegment, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.rotateLocked(true)
+}
+
+// Segments lists the archived segments, oldest first.
+func (r *AdherenceRepository) Segments(_ context.Context) ([]adherence.LogSegment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	index, err := r.readIndex()
+	if err != nil {
+		return nil, err
+	}
+	segments := make([]adherence.LogSegment, 0, len(index.Segments))
+	for _, segment := range index.Segments {
+		segments = append(segments, segment.toSegment())
+	}
+	return segments, nil
+}
+
+// rotateLocked moves the current log into a gzip segment, records it in the
+// index and starts the log again from initial-state events holding the
+// current state, so the new file can be folded on its own. Unless force is
+// set, it only rotates when the policy says the log is due.
+func (r *AdherenceRepository) rotateLocked(force bool) (adherence.LogSegment, bool, error) {
+	data, err := readIfExists(r.logPath)
+	if err != nil {
+		return adherence.LogSegment{}, false, err
+	}
+	var entries []adherence.AdherenceLogEntry
+	for _, scanned := range scanAdherenceLog(data) {
+		if scanned.err == nil {
+			entries = append(entries, scanned.entry)
+		}
+	}
+	changes := adherence.Changes(entries)
+	if len(changes) == 0 {
+		return adherence.LogSegment{}, false, nil
+	}
+
+	now := r.now().UTC()
+	policy := r.rotationPolicy()
+	if !force {
+		tooBig := policy.MaxBytes > 0 && int64(len(data)) >= policy.MaxBytes
+		tooOld := policy.MaxAge > 0 && now.Sub(changes[0].At) >= policy.MaxAge
+		if !tooBig && !tooOld {
+			return adherence.LogSegment{}, false, nil
+		}
+	}
+
+	index, err := r.readIndex()
+	if err != nil {
+		return adherence.LogSegment{}, false, err
+	}
+	name := r.segmentName(now)
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	if _, err := writer.Write(data); err != nil {
+		return adherence.LogSegment{}, false, fmt.Errorf("compress log segment: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return adherence.LogSegment{}, false, fmt.Errorf("compress log segment: %w", err)
+	}
+	if err := atomicfile.Write(filepath.Join(filepath.Dir(r.logPath), name), compressed.Bytes(), 0o600); err != nil {
+		return adherence.LogSegment{}, false, err
+	}
+
+	record := logSegmentRecord{
+		File:   name,
+		First:  entries[0].At.UTC().Format(time.RFC3339Nano),
+		Last:   entries[len(entries)-1].At.UTC().Format(time.RFC3339Nano),
+		Events: len(entries),
+		Bytes:  int64(len(data)),
+	}
+	index.Segments = append(index.Segments, record)
+	if err := r.writeIndex(index); err != nil {
+		return adherence.LogSegment{}, false, err
+	}
+
+	var checkpoint []byte
+	for _, event := range adherence.InitialEvents(now, r.state) {
+		line, err := json.Marshal(logRecordFromEntry(event))
+		if err != nil {
+			return adherence.LogSegment{}, false, fmt.Errorf("encode adherence log entry: %w", err)
+		}
+		checkpoint = append(append(checkpoint, line...), '\n')
+	}
+	if err := atomicfile.Write(r.logPath, checkpoint, 0o600); err != nil {
+		return adherence.LogSegment{}, false, err
+	}
+	r.rotations++
+
+	if err := r.pruneLocked(index, policy, now); err != nil {
+		return adherence.LogSegment{}, false, err
+	}
+	return record.toSegment(), true, nil
+}
+
+// pruneLocked deletes the segments the retention rules no longer keep. Every
+// segment after the first starts with initial-state events, so the oldest
+// remaining one still folds to the right state.
+func (r *AdherenceRepository) pruneLocked(index logIndex, policy RotationPolicy, now time.Time) error {
+	keep := index.Segments
+	for len(keep) > 0 {
+		oldest := keep[0].toSegment()
+		tooMany := policy.KeepSegments > 0 && len(keep) > policy.KeepSegments
+		tooOld := policy.KeepFor > 0 && now.Sub(oldest.Last) > policy.KeepFor
+		if !tooMany && !tooOld {
+			break
+		}
+		keep = keep[1:]
+	}
+	if len(keep) == len(index.Segments) {
+		return nil
+	}
+
+	removed := index.Segments[:len(index.Segments)-len(keep)]
+	if err := r.writeIndex(logIndex{Segments: keep}); err != nil {
+		return err
+	}
+	for _, segment := range removed {
+		err := os.Remove(filepath.Join(filepath.Dir(r.logPath), segment.File))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("remove log segment: %w", err)
+		}
+	}
+	return nil
+}
+
+// readArchivedLocked returns the events of every indexed segment in order.
+func (r *AdherenceRepository) readArchivedLocked() ([]adherence.AdherenceLogEntry, error) {
+	index, err := r.readIndex()
+	if err != nil {
+		return nil, err
+	}
+	var log []adherence.AdherenceLogEntry
+	for _, segment := range index.Segments {
+		data, err := readSegment(filepath.Join(filepath.Dir(r.logPath), segment.File))
+		if err != nil {
+			return nil, err
+		}
+		entries, err := r.decodeLogData(data)
+		if err != nil {
+			return nil, fmt.Errorf("log segment %s: %w", segment.File, err)
+		}
+		log = append(log, entries...)
+	}
+	return log, nil
+}
+
+func readSegment(path string) ([]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open log segment: %w", err)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("read log segment %s: %w", filepath.Base(path), err)
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("read log segment %s: %w", filepath.Base(path), err)
+	}
+	return data, nil
+}
+
+func (r *AdherenceRepository) readIndex() (logIndex, error) {
+	data, err := readIfExists(LogIndexPath(r.logPath))
+	if err != nil || data == nil {
+		return logIndex{}, err
+	}
+	var index logIndex
+	if err := json.Unmarshal(data, &index); err != nil {
+		return logIndex{}, fmt.Errorf("decode log index: %w", err)
+	}
+	return index, nil
+}
+
+func (r *AdherenceRepository) writeIndex(index logIndex) error {
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode log index: %w", err)
+	}
+	return atomicfile.Write(LogIndexPath(r.logPath), append(data, '\n'), 0o600)
+}
+
+func (r *AdherenceRepository) segmentName(now time.Time) string {
+	base := strings.TrimSuffix(filepath.Base(r.logPath), ".jsonl") + "." + now.Format("20060102T150405Z")
+	name := base + ".jsonl.gz"
+	for i := 2; ; i++ {
+		if _, err := os.Stat(filepath.Join(filepath.Dir(r.logPath), name)); errors.Is(err, os.ErrNotExist) {
+			return name
+		}
+		name = fmt.Sprintf("%s-%d.jsonl.gz", base, i)
+	}
+}
+
+func (r *AdherenceRepository) rotationPolicy() RotationPolicy {
+	if r.opts.rotation == nil {
+		return DefaultRotationPolicy()
+	}
+	return *r.opts.rotation
+}
+
+// joinLog puts archived and current events back together. A crash during
+// rotation can leave events in both places, and a merge can bring in events
+// older than the newest segment, so the result is deduplicated and put back
+// in time order.
+func joinLog(archived []adherence.AdherenceLogEntry, current []adherence.AdherenceLogEntry) []adherence.AdherenceLogEntry {
+	if len(archived) == 0 {
+		return current
+	}
+	seen := make(map[string]bool, len(archived)+len(current))
+	var log []adherence.AdherenceLogEntry
+	for _, entry := range append(archived, current...) {
+		fingerprint := entry.Fingerprint()
+		if seen[fingerprint] {
+			continue
+		}
+		seen[fingerprint] = true
+		log = append(log, entry)
+	}
+	sort.SliceStable(log, func(i, j int) bool {
+		return log[i].At.Before(log[j].At)
+	})
+	return log
+}
@@ -0,0 +1,171 @@
+package flatfile
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/thatnerdjosh/mindfulness/internal/domain/adherence"
+	"github.com/thatnerdjosh/mindfulness/internal/domain/journal"
+)
+
+func toggle(at time.Time, precept journal.Precept, to bool) adherence.AdherenceLogEntry {
+	return adherence.AdherenceLogEntry{At: at, Precept: precept, From: !to, To: to}
+}
+
+func TestAdherenceLogRotatesBySize(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "adherence.json")
+	logPath := filepath.Join(dir, "adherence.log.jsonl")
+	repo, err := NewEventSourcedAdherenceRepository(path, logPath, WithLogRotation(RotationPolicy{MaxBytes: 800}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	at := time.Date(2024, 2, 10, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 10; i++ {
+		if err := repo.AppendLog(context.Background(), toggle(at.Add(time.Duration(i)*time.Minute), journal.TrueLove, i%2 == 1)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	segments, err := repo.Segments(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(segments) == 0 {
+		t.Fatal("expected the log to be rotated once it grew past the limit")
+	}
+	for _, segment := range segments {
+		if _, err := os.Stat(filepath.Join(dir, segment.Name)); err != nil {
+			t.Fatalf("expected segment file %s: %v", segment.Name, err)
+		}
+	}
+
+	log, err := repo.Log(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if changes := adherence.Changes(log); len(changes) != 10 {
+		t.Fatalf("expected every change across segments, got %d", len(changes))
+	}
+
+	// The rotated repository still opens to the same state.
+	reopened, err := NewEventSourcedAdherenceRepository(path, logPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if state, _ := reopened.Get(context.Background()); !state[journal.TrueLove] {
+		t.Fatalf("expected the last change to hold after rotation, got %+v", state)
+	}
+	if _, _, err := reopened.Rebuild(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if state, _ := reopened.Get(context.Background()); !state[journal.TrueLove] {
+		t.Fatalf("expected a rebuild across segments to agree, got %+v", state)
+	}
+}
+
+func TestAdherenceLogArchive(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "adherence.json")
+	logPath := filepath.Join(dir, "adherence.log.jsonl")
+	repo, err := NewEventSourcedAdherenceRepository(path, logPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, archived, err := repo.ArchiveLog(context.Background()); err != nil || archived {
+		t.Fatalf("expected nothing to archive without changes, got %v, %v", archived, err)
+	}
+
+	at := time.Date(2024, 2, 10, 12, 0, 0, 0, time.UTC)
+	change := toggle(at, journal.LovingSpeechDeepListening, false)
+	if err := repo.AppendLog(context.Background(), change); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	segment, archived, err := repo.ArchiveLog(context.Background())
+	if err != nil || !archived {
+		t.Fatalf("expected the log to be archived, got %v, %v", archived, err)
+	}
+	if segment.Events != len(journal.AllPrecepts())+1 || !segment.Last.Equal(at) {
+		t.Fatalf("unexpected segment: %+v", segment)
+	}
+
+	current, err := ReadAdherenceLogFile(logPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(adherence.Changes(current)) != 0 || len(current) != len(journal.AllPrecepts()) {
+		t.Fatalf("expected the current file to hold only a checkpoint, got %+v", current)
+	}
+	if state := adherence.Fold(adherence.DefaultAdherence(), current); state[journal.LovingSpeechDeepListening] {
+		t.Fatal("expected the checkpoint to carry the current state")
+	}
+
+	// Replacing the log with what it already holds does not copy archived
+	// events back into the current file.
+	log, err := repo.Log(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := repo.ReplaceLog(context.Background(), log); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if current, _ := ReadAdherenceLogFile(logPath); len(adherence.Changes(current)) != 0 {
+		t.Fatalf("expected archived events to stay archived, got %+v", current)
+	}
+	if replaced, _ := repo.Log(context.Background()); len(replaced) != len(log) {
+		t.Fatalf("expected %d events after replace, got %d", len(log), len(replaced))
+	}
+}
+
+func TestAdherenceLogRetention(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "adherence.json")
+	logPath := filepath.Join(dir, "adherence.log.jsonl")
+	repo, err := NewEventSourcedAdherenceRepository(path, logPath, WithLogRotation(RotationPolicy{KeepSegments: 2}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	repo.now = func() time.Time { return now }
+
+	var names []string
+	for i := 0; i < 3; i++ {
+		if err := repo.AppendLog(context.Background(), toggle(now, journal.TrueLove, i%2 == 1)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		segment, _, err := repo.ArchiveLog(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		names = append(names, segment.Name)
+		now = now.Add(time.Hour)
+	}
+
+	segments, err := repo.Segments(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(segments) != 2 || segments[0].Name != names[1] {
+		t.Fatalf("expected the two newest segments to be kept, got %+v", segments)
+	}
+	if _, err := os.Stat(filepath.Join(dir, names[0])); !os.IsNotExist(err) {
+		t.Fatalf("expected the oldest segment to be deleted, got %v", err)
+	}
+
+	reopened, err := NewEventSourcedAdherenceRepository(path, logPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	state, _, err := reopened.Rebuild(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if state[journal.TrueLove] {
+		t.Fatalf("expected the kept segments to fold to the latest state, got %+v", state)
+	}
+}
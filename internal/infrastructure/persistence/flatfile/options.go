@@ -0,0 +1,188 @@
+package flatfile
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/thatnerdjosh/mindfulness/internal/domain/adherence"
+	"github.com/thatnerdjosh/mindfulness/internal/domain/journal"
+)
+
+// ErrDamagedFile is returned by writes to a file that was loaded leniently
+// but could not be parsed past some point; writing would drop the damaged
+// tail.
+var ErrDamagedFile = errors.New("data file is damaged; run `mt doctor --repair` before writing")
+
+// Option configures a flat-file repository.
+type Option func(*options)
+
+type options struct {
+	lenient  bool
+	rotation *RotationPolicy
+}
+
+func buildOptions(opts []Option) options {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithLenientLoad makes the repository skip records it cannot parse instead
+// of failing to open. Skipped records are reported by LoadWarnings and
+// written back unchanged, so saving never loses them.
+func WithLenientLoad() Option {
+	return func(o *options) {
+		o.lenient = true
+	}
+}
+
+// LoadWarning describes a record skipped by a lenient load.
+type LoadWarning struct {
+	File string
+	// Line is the 1-based line the record starts on.
+	Line int
+	// Record is the 1-based position of the record in the file, or 0 when
+	// the problem concerns the whole file.
+	Record  int
+	Message string
+}
+
+func (w LoadWarning) String() string {
+	if w.Record == 0 {
+		return fmt.Sprintf("%s:%d: %s", w.File, w.Line, w.Message)
+	}
+	return fmt.Sprintf("%s:%d (record %d): %s", w.File, w.Line, w.Record, w.Message)
+}
+
+// scannedEntry is one record of a journal file, parsed independently of the
+// others. err is set when the record is invalid.
+type scannedEntry struct {
+	line   int
+	record int
+	raw    json.RawMessage
+	entry  journal.Entry
+	err    error
+}
+
+// journalScan is a record-by-record reading of a journal file. When the file
+// cannot be parsed past some point, damaged holds the rest of it verbatim.
+type journalScan struct {
+	records []scannedEntry
+	damaged *scannedEntry
+}
+
+func scanJournal(data []byte) journalScan {
+	var scan journalScan
+	records, damaged := scanArray(data, "journal file")
+	for _, record := range records {
+		entry, err := checkEntryRecord(record.raw)
+		scan.records = append(scan.records, scannedEntry{line: record.line, record: record.record, raw: record.raw, entry: entry, err: err})
+	}
+	if damaged != nil {
+		scan.damaged = &scannedEntry{line: damaged.line, record: damaged.record, raw: damaged.raw, err: damaged.err}
+	}
+	return scan
+}
+
+// scannedRecord is one raw element of a file holding a JSON array.
+type scannedRecord struct {
+	line   int
+	record int
+	raw    json.RawMessage
+	err    error
+}
+
+// scanArray splits a file holding a JSON array, named what in errors, into
+// its elements. When the file cannot be parsed past some point, damaged
+// holds the rest of it verbatim.
+func scanArray(data []byte, what string) (records []scannedRecord, damaged *scannedRecord) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if token, err := decoder.Token(); err != nil || token != json.Delim('[') {
+		return nil, &scannedRecord{line: 1, raw: data, err: fmt.Errorf("%s must hold a JSON array", what)}
+	}
+	for record := 1; decoder.More(); record++ {
+		start := nextValueOffset(data, decoder.InputOffset())
+		line := lineAt(data, start)
+
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
+			var syntaxErr *json.SyntaxError
+			if errors.As(err, &syntaxErr) {
+				err = fmt.Errorf("invalid JSON at line %d: %s", lineAt(data, syntaxErr.Offset), syntaxErr.Error())
+			} else {
+				err = fmt.Errorf("invalid JSON: %w", err)
+			}
+			return records, &scannedRecord{line: line, record: record, raw: data[start:], err: err}
+		}
+		records = append(records, scannedRecord{line: line, record: record, raw: raw})
+	}
+	return records, nil
+}
+
+// checkEntryRecord decodes one journal record, naming unknown precepts and
+// foundations rather than only reporting that one exists.
+func checkEntryRecord(raw json.RawMessage) (journal.Entry, error) {
+	var record entryRecord
+	if err := json.Unmarshal(raw, &record); err != nil {
+		return journal.Entry{}, fmt.Errorf("invalid record: %w", err)
+	}
+	for _, precept := range sortedKeys(record.Reflections) {
+		if !journal.IsKnownPrecept(journal.Precept(precept)) {
+			return journal.Entry{}, fmt.Errorf("unknown precept %q", precept)
+		}
+	}
+	foundation := strings.ToLower(strings.TrimSpace(record.Foundation))
+	if foundation != "" && !journal.IsKnownFoundation(journal.Foundation(foundation)) {
+		return journal.Entry{}, fmt.Errorf("unknown foundation %q", record.Foundation)
+	}
+	return record.toEntry()
+}
+
+// scannedLogLine is one line of an adherence log file. err is set when the
+// line is invalid.
+type scannedLogLine struct {
+	line   int
+	record int
+	raw    string
+	entry  adherence.AdherenceLogEntry
+	err    error
+}
+
+func scanAdherenceLog(data []byte) []scannedLogLine {
+	var lines []scannedLogLine
+	record := 0
+	for i, line := range strings.Split(string(data), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		record++
+		scanned := scannedLogLine{line: i + 1, record: record, raw: line}
+		var parsed adherenceLogRecord
+		if err := json.Unmarshal([]byte(line), &parsed); err != nil {
+			scanned.err = fmt.Errorf("invalid JSON: %w", err)
+		} else {
+			scanned.entry, scanned.err = parsed.toLogEntry()
+		}
+		lines = append(lines, scanned)
+	}
+	return lines
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
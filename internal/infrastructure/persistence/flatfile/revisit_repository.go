@@ -0,0 +1,117 @@
+package flatfile
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/thatnerdjosh/mindfulness/internal/domain/journal"
+	"github.com/thatnerdjosh/mindfulness/internal/infrastructure/atomicfile"
+)
+
+// DefaultRevisitPath returns the default path of the journal revisit
+// records.
+func DefaultRevisitPath() (string, error) {
+	dataDir, err := defaultDataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dataDir, "revisits.json"), nil
+}
+
+// RevisitRepository stores when journal entries were re-read in a JSON
+// file, each record linked to its entry by the entry's fingerprint.
+type RevisitRepository struct {
+	mu   sync.Mutex
+	path string
+}
+
+func NewRevisitRepository(path string) (*RevisitRepository, error) {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return nil, fmt.Errorf("revisit path is required")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("create data directory: %w", err)
+	}
+	return &RevisitRepository{path: path}, nil
+}
+
+func (r *RevisitRepository) List(_ context.Context) ([]journal.Revisit, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.loadLocked()
+}
+
+func (r *RevisitRepository) Record(_ context.Context, fingerprint string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	revisits, err := r.loadLocked()
+	if err != nil {
+		return err
+	}
+	recorded := false
+	for i := range revisits {
+		if revisits[i].Entry == fingerprint {
+			revisits[i].At = append(revisits[i].At, at)
+			recorded = true
+		}
+	}
+	if !recorded {
+		revisits = append(revisits, journal.Revisit{Entry: fingerprint, At: []time.Time{at}})
+	}
+
+	stored := make([]revisitRecord, 0, len(revisits))
+	for _, revisit := range revisits {
+		record := revisitRecord{Entry: revisit.Entry}
+		for _, at := range revisit.At {
+			record.At = append(record.At, at.UTC().Format(time.RFC3339))
+		}
+		stored = append(stored, record)
+	}
+	data, err := json.MarshalIndent(stored, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode revisit records: %w", err)
+	}
+	return atomicfile.Write(r.path, append(data, '\n'), 0o600)
+}
+
+func (r *RevisitRepository) loadLocked() ([]journal.Revisit, error) {
+	data, err := readIfExists(r.path)
+	if err != nil || len(strings.TrimSpace(string(data))) == 0 {
+		return nil, err
+	}
+
+	var stored []revisitRecord
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, fmt.Errorf("decode revisit records: %w", err)
+	}
+	revisits := make([]journal.Revisit, 0, len(stored))
+	for _, record := range stored {
+		if record.Entry == "" {
+			return nil, fmt.Errorf("revisit record has no entry")
+		}
+		revisit := journal.Revisit{Entry: record.Entry}
+		for _, value := range record.At {
+			at, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid revisit time %q: %w", value, err)
+			}
+			revisit.At = append(revisit.At, at)
+		}
+		revisits = append(revisits, revisit)
+	}
+	return revisits, nil
+}
+
+type revisitRecord struct {
+	Entry string   `json:"entry"`
+	At    []string `json:"at"`
+}
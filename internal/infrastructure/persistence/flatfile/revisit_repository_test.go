@@ -0,0 +1,47 @@
+package flatfile
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRevisitRepositoryRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "revisits.json")
+	repo, err := NewRevisitRepository(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if revisits, err := repo.List(context.Background()); err != nil || len(revisits) != 0 {
+		t.Fatalf("expected no revisits, got %+v, %v", revisits, err)
+	}
+
+	first := time.Date(2024, 4, 1, 9, 0, 0, 0, time.UTC)
+	second := first.AddDate(0, 1, 0)
+	for _, at := range []time.Time{first, second} {
+		if err := repo.Record(context.Background(), "abc", at); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if err := repo.Record(context.Background(), "def", first); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	reopened, err := NewRevisitRepository(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	revisits, err := reopened.List(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(revisits) != 2 {
+		t.Fatalf("expected revisits for two entries, got %+v", revisits)
+	}
+	for _, revisit := range revisits {
+		if revisit.Entry == "abc" && (len(revisit.At) != 2 || !revisit.Last().Equal(second)) {
+			t.Fatalf("expected both revisits of abc, got %+v", revisit)
+		}
+	}
+}
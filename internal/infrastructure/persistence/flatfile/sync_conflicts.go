@@ -0,0 +1,91 @@
+package flatfile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// SyncFileKind names what a data file holds.
+type SyncFileKind string
+
+const (
+	SyncFileJournal        SyncFileKind = "journal"
+	SyncFileAdherenceLog   SyncFileKind = "adherence-log"
+	SyncFileAdherenceState SyncFileKind = "adherence-state"
+)
+
+const syncConflictMarker = ".sync-conflict-"
+
+// SyncConflict is a conflicting copy written by a file-sync tool such as
+// Syncthing, e.g. journal.sync-conflict-20240105-101500-ABCDEFG.json.
+type SyncConflict struct {
+	Path string
+	Kind SyncFileKind
+}
+
+// FindSyncConflicts lists the conflicting copies of mt's data files in dir,
+// sorted by path. Conflict copies of unrelated files are ignored.
+func FindSyncConflicts(dir string) ([]SyncConflict, error) {
+	matches, err := filepath.Glob(filepath.Join(dir, "*"+syncConflictMarker+"*"))
+	if err != nil {
+		return nil, fmt.Errorf("find sync conflicts: %w", err)
+	}
+	sort.Strings(matches)
+
+	var conflicts []SyncConflict
+	for _, path := range matches {
+		if info, err := os.Stat(path); err != nil || info.IsDir() {
+			continue
+		}
+		kind, ok := kindForName(originalName(filepath.Base(path)))
+		if !ok {
+			continue
+		}
+		conflicts = append(conflicts, SyncConflict{Path: path, Kind: kind})
+	}
+	return conflicts, nil
+}
+
+// ClassifySyncFile infers what a journal, adherence log or adherence state
+// file holds from its name, looking through sync-conflict suffixes.
+func ClassifySyncFile(path string) (SyncFileKind, error) {
+	name := originalName(filepath.Base(path))
+	if kind, ok := kindForName(name); ok {
+		return kind, nil
+	}
+	switch {
+	case strings.HasSuffix(name, ".jsonl"):
+		return SyncFileAdherenceLog, nil
+	case strings.HasSuffix(name, ".json"):
+		return SyncFileJournal, nil
+	default:
+		return "", fmt.Errorf("cannot tell what %s holds: expected a .json journal or .jsonl adherence log", path)
+	}
+}
+
+// originalName strips a sync-conflict marker, keeping the extension:
+// adherence.log.sync-conflict-20240105-101500-ABCDEFG.jsonl becomes
+// adherence.log.jsonl.
+func originalName(name string) string {
+	prefix, rest, ok := strings.Cut(name, syncConflictMarker)
+	if !ok {
+		return name
+	}
+	return prefix + filepath.Ext(rest)
+}
+
+func kindForName(name string) (SyncFileKind, bool) {
+	switch name {
+	case "journal.json":
+		return SyncFileJournal, true
+	case "adherence.log.jsonl":
+		return SyncFileAdherenceLog, true
+	case "adherence.json":
+		return SyncFileAdherenceState, true
+	default:
+		return "", false
+	}
+}
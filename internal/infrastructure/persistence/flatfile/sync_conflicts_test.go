@@ -0,0 +1,61 @@
+package flatfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFindSyncConflicts(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{
+		"journal.json",
+		"journal.sync-conflict-20240105-101500-ABCDEFG.json",
+		"adherence.log.sync-conflict-20240105-101500-ABCDEFG.jsonl",
+		"adherence.sync-conflict-20240105-101500-ABCDEFG.json",
+		"notes.sync-conflict-20240105-101500-ABCDEFG.txt",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o600); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+
+	conflicts, err := FindSyncConflicts(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := map[string]SyncFileKind{
+		"journal.sync-conflict-20240105-101500-ABCDEFG.json":        SyncFileJournal,
+		"adherence.log.sync-conflict-20240105-101500-ABCDEFG.jsonl": SyncFileAdherenceLog,
+		"adherence.sync-conflict-20240105-101500-ABCDEFG.json":      SyncFileAdherenceState,
+	}
+	if len(conflicts) != len(want) {
+		t.Fatalf("expected %d conflicts, got %+v", len(want), conflicts)
+	}
+	for _, conflict := range conflicts {
+		if kind := want[filepath.Base(conflict.Path)]; kind != conflict.Kind {
+			t.Fatalf("unexpected kind for %s: %s", conflict.Path, conflict.Kind)
+		}
+	}
+}
+
+func TestClassifySyncFile(t *testing.T) {
+	tests := []struct {
+		path    string
+		want    SyncFileKind
+		wantErr bool
+	}{
+		{path: "/backup/journal.json", want: SyncFileJournal},
+		{path: "laptop-journal.json", want: SyncFileJournal},
+		{path: "adherence.log.jsonl", want: SyncFileAdherenceLog},
+		{path: "exported.jsonl", want: SyncFileAdherenceLog},
+		{path: "adherence.sync-conflict-20240105-101500-ABCDEFG.json", want: SyncFileAdherenceState},
+		{path: "notes.txt", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ClassifySyncFile(tt.path)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ClassifySyncFile(%q) = %q, %v", tt.path, got, err)
+		}
+	}
+}
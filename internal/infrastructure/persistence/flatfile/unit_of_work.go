@@ -0,0 +1,475 @@
+package flatfile
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/thatnerdjosh/mindfulness/internal/domain/adherence"
+	"github.com/thatnerdjosh/mindfulness/internal/domain/journal"
+	"github.com/thatnerdjosh/mindfulness/internal/infrastructure/atomicfile"
+)
+
+// DefaultWALPath returns the default path of the write-ahead record kept
+// while a unit of work is being applied.
+func DefaultWALPath() (string, error) {
+	dataDir, err := defaultDataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dataDir, "pending.wal.json"), nil
+}
+
+// UnitOfWork applies writes to the journal, adherence and Beginning Anew
+// files together.
+// The writes are first recorded in a write-ahead file and synced to disk;
+// if applying them is interrupted, the next Do, or NewUnitOfWork when the
+// files are next opened, finishes the job.
+type UnitOfWork struct {
+	mu            sync.Mutex
+	walPath       string
+	journalRepo   journal.Repository
+	adherenceRepo adherence.Repository
+	renewalRepo   adherence.BeginningAnewRepository
+	// fault lets tests fail the unit of work at a named step.
+	fault func(step string) error
+}
+
+// NewUnitOfWork returns a UnitOfWork over the given repositories, first
+// completing any unit of work a previous process left unfinished.
+// renewalRepo may be nil when Beginning Anew records are not kept.
+func NewUnitOfWork(walPath string, journalRepo journal.Repository, adherenceRepo adherence.Repository, renewalRepo adherence.BeginningAnewRepository) (*UnitOfWork, error) {
+	uow := &UnitOfWork{
+		walPath:       walPath,
+		journalRepo:   journalRepo,
+		adherenceRepo: adherenceRepo,
+		renewalRepo:   renewalRepo,
+		fault:         func(string) error { return nil },
+	}
+	if err := uow.recover(context.Background()); err != nil {
+		return nil, err
+	}
+	return uow, nil
+}
+
+// walRecord is the write-ahead file: everything one unit of work writes.
+// Replacements are applied before appends.
+type walRecord struct {
+	JournalReplace *[]entryRecord        `json:"journal_replace,omitempty"`
+	JournalAppend  []entryRecord         `json:"journal_append,omitempty"`
+	Adherence      adherenceRecord       `json:"adherence,omitempty"`
+	LogReplace     *[]adherenceLogRecord `json:"log_replace,omitempty"`
+	LogAppend      []adherenceLogRecord  `json:"log_append,omitempty"`
+	BeginningAnew  []beginningAnewRecord `json:"beginning_anew,omitempty"`
+}
+
+// Do runs fn against repositories that hold its writes in memory, then
+// stores them. Nothing is written when fn returns an error. A unit an
+// earlier Do failed to finish is completed first, so its write-ahead
+// record is never overwritten.
+func (u *UnitOfWork) Do(ctx context.Context, fn func(journal.Repository, adherence.Repository, adherence.BeginningAnewRepository) error) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if err := u.recover(ctx); err != nil {
+		return err
+	}
+
+	tx := &pendingWrites{journalBase: u.journalRepo, adherenceBase: u.adherenceRepo, renewalBase: u.renewalRepo}
+	var renewals adherence.BeginningAnewRepository
+	if u.renewalRepo != nil {
+		renewals = txRenewals{tx}
+	}
+	if err := fn(txJournal{tx}, txAdherence{tx}, renewals); err != nil {
+		return err
+	}
+	if tx.empty() {
+		return nil
+	}
+
+	record := tx.record()
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("encode write-ahead record: %w", err)
+	}
+	if err := u.fault("wal"); err != nil {
+		return err
+	}
+	if err := atomicfile.Write(u.walPath, data, 0o600); err != nil {
+		return err
+	}
+	return u.apply(ctx, record, false)
+}
+
+func (u *UnitOfWork) recover(ctx context.Context) error {
+	data, err := readIfExists(u.walPath)
+	if err != nil || data == nil {
+		return err
+	}
+	var record walRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return fmt.Errorf("decode write-ahead record %s: %w", u.walPath, err)
+	}
+	if err := u.apply(ctx, record, true); err != nil {
+		return fmt.Errorf("complete interrupted write: %w", err)
+	}
+	return nil
+}
+
+// apply stores record and removes the write-ahead file. When recovering,
+// appends already present are skipped, since the interrupted attempt may
+// have stored some of them.
+func (u *UnitOfWork) apply(ctx context.Context, record walRecord, recovering bool) error {
+	if err := u.fault("journal"); err != nil {
+		return err
+	}
+	if err := u.applyJournal(ctx, record, recovering); err != nil {
+		return err
+	}
+
+	if err := u.fault("adherence"); err != nil {
+		return err
+	}
+	if record.Adherence != nil {
+		state, err := record.Adherence.toAdherence()
+		if err != nil {
+			return err
+		}
+		if err := u.adherenceRepo.Save(ctx, state); err != nil {
+			return err
+		}
+	}
+
+	if err := u.fault("log"); err != nil {
+		return err
+	}
+	if err := u.applyLog(ctx, record, recovering); err != nil {
+		return err
+	}
+
+	if err := u.fault("beginning anew"); err != nil {
+		return err
+	}
+	if err := u.applyBeginningAnew(ctx, record); err != nil {
+		return err
+	}
+
+	if err := u.fault("clear"); err != nil {
+		return err
+	}
+	if err := os.Remove(u.walPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("remove write-ahead record: %w", err)
+	}
+	return atomicfile.SyncDir(filepath.Dir(u.walPath))
+}
+
+func (u *UnitOfWork) applyJournal(ctx context.Context, record walRecord, recovering bool) error {
+	if record.JournalReplace != nil {
+		entries, err := entriesFromRecords(*record.JournalReplace)
+		if err != nil {
+			return err
+		}
+		if err := u.journalRepo.Replace(ctx, entries); err != nil {
+			return err
+		}
+	}
+
+	stored := make(map[string]bool)
+	if recovering && len(record.JournalAppend) > 0 {
+		existing, err := u.journalRepo.List(ctx)
+		if err != nil {
+			return err
+		}
+		for _, entry := range existing {
+			stored[entry.Fingerprint()] = true
+		}
+	}
+	appended, err := entriesFromRecords(record.JournalAppend)
+	if err != nil {
+		return err
+	}
+	for _, entry := range appended {
+		if stored[entry.Fingerprint()] {
+			continue
+		}
+		if err := u.journalRepo.Save(ctx, entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (u *UnitOfWork) applyLog(ctx context.Context, record walRecord, recovering bool) error {
+	if record.LogReplace != nil {
+		log, err := logFromRecords(*record.LogReplace)
+		if err != nil {
+			return err
+		}
+		if err := u.adherenceRepo.ReplaceLog(ctx, log); err != nil {
+			return err
+		}
+	}
+
+	stored := make(map[string]bool)
+	if recovering && len(record.LogAppend) > 0 {
+		existing, err := u.adherenceRepo.Log(ctx)
+		if err != nil {
+			return err
+		}
+		for _, entry := range existing {
+			stored[entry.Fingerprint()] = true
+		}
+	}
+	appended, err := logFromRecords(record.LogAppend)
+	if err != nil {
+		return err
+	}
+	for _, entry := range appended {
+		if stored[entry.Fingerprint()] {
+			continue
+		}
+		if err := u.adherenceRepo.AppendLog(ctx, entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyBeginningAnew saves the unit's Beginning Anew records. Saving
+// replaces the record for the same break, so repeating it is harmless.
+func (u *UnitOfWork) applyBeginningAnew(ctx context.Context, record walRecord) error {
+	if len(record.BeginningAnew) == 0 {
+		return nil
+	}
+	if u.renewalRepo == nil {
+		return fmt.Errorf("write-ahead record holds beginning anew records but none are kept")
+	}
+	for _, stored := range record.BeginningAnew {
+		renewal, err := stored.toBeginningAnew()
+		if err != nil {
+			return err
+		}
+		if err := u.renewalRepo.Save(ctx, renewal); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func entriesFromRecords(records []entryRecord) ([]journal.Entry, error) {
+	entries := make([]journal.Entry, 0, len(records))
+	for _, record := range records {
+		entry, err := record.toEntry()
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func logFromRecords(records []adherenceLogRecord) ([]adherence.AdherenceLogEntry, error) {
+	log := make([]adherence.AdherenceLogEntry, 0, len(records))
+	for _, record := range records {
+		entry, err := record.toLogEntry()
+		if err != nil {
+			return nil, err
+		}
+		log = append(log, entry)
+	}
+	return log, nil
+}
+
+// pendingWrites collects the writes of one unit of work. Reads through the
+// transactional repositories see them on top of the stored data.
+type pendingWrites struct {
+	journalBase   journal.Repository
+	adherenceBase adherence.Repository
+	renewalBase   adherence.BeginningAnewRepository
+
+	journalReplace *[]journal.Entry
+	journalAppend  []journal.Entry
+	state          adherence.Adherence
+	logReplace     *[]adherence.AdherenceLogEntry
+	logAppend      []adherence.AdherenceLogEntry
+	renewals       []adherence.BeginningAnew
+}
+
+func (p *pendingWrites) empty() bool {
+	return p.journalReplace == nil && len(p.journalAppend) == 0 && p.state == nil &&
+		p.logReplace == nil && len(p.logAppend) == 0 && len(p.renewals) == 0
+}
+
+func (p *pendingWrites) record() walRecord {
+	var record walRecord
+	if p.journalReplace != nil {
+		records := make([]entryRecord, 0, len(*p.journalReplace))
+		for _, entry := range *p.journalReplace {
+			records = append(records, recordFromEntry(entry))
+		}
+		record.JournalReplace = &records
+	}
+	for _, entry := range p.journalAppend {
+		record.JournalAppend = append(record.JournalAppend, recordFromEntry(entry))
+	}
+	if p.state != nil {
+		record.Adherence = recordFromAdherence(p.state)
+	}
+	if p.logReplace != nil {
+		records := make([]adherenceLogRecord, 0, len(*p.logReplace))
+		for _, entry := range *p.logReplace {
+			records = append(records, logRecordFromEntry(entry))
+		}
+		record.LogReplace = &records
+	}
+	for _, entry := range p.logAppend {
+		record.LogAppend = append(record.LogAppend, logRecordFromEntry(entry))
+	}
+	for _, renewal := range p.renewals {
+		record.BeginningAnew = append(record.BeginningAnew, recordFromBeginningAnew(renewal))
+	}
+	return record
+}
+
+func (p *pendingWrites) entries(ctx context.Context) ([]journal.Entry, error) {
+	var entries []journal.Entry
+	if p.journalReplace != nil {
+		entries = append(entries, *p.journalReplace...)
+	} else {
+		stored, err := p.journalBase.List(ctx)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, stored...)
+	}
+	return append(entries, p.journalAppend...), nil
+}
+
+// txJournal is the journal repository handed to a unit of work.
+type txJournal struct{ p *pendingWrites }
+
+func (t txJournal) Save(_ context.Context, entry journal.Entry) error {
+	t.p.journalAppend = append(t.p.journalAppend, entry)
+	return nil
+}
+
+func (t txJournal) Replace(_ context.Context, entries []journal.Entry) error {
+	replaced := append([]journal.Entry{}, entries...)
+	t.p.journalReplace = &replaced
+	t.p.journalAppend = nil
+	return nil
+}
+
+func (t txJournal) List(ctx context.Context) ([]journal.Entry, error) {
+	entries, err := t.p.entries(ctx)
+	if err != nil || len(entries) == 0 {
+		return nil, err
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Date.Before(entries[j].Date)
+	})
+	return entries, nil
+}
+
+func (t txJournal) Latest(ctx context.Context) (*journal.Entry, error) {
+	entries, err := t.p.entries(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, journal.ErrNotFound
+	}
+	latest := entries[0]
+	for _, entry := range entries[1:] {
+		if !entry.Date.Before(latest.Date) {
+			latest = entry
+		}
+	}
+	return &latest, nil
+}
+
+// txAdherence is the adherence repository handed to a unit of work.
+type txAdherence struct{ p *pendingWrites }
+
+func (t txAdherence) Get(ctx context.Context) (adherence.Adherence, error) {
+	if t.p.state == nil {
+		return t.p.adherenceBase.Get(ctx)
+	}
+	copy := make(adherence.Adherence, len(t.p.state))
+	for precept, value := range t.p.state {
+		copy[precept] = value
+	}
+	return copy, nil
+}
+
+func (t txAdherence) Save(_ context.Context, state adherence.Adherence) error {
+	copy := make(adherence.Adherence, len(state))
+	for precept, value := range state {
+		copy[precept] = value
+	}
+	t.p.state = copy
+	return nil
+}
+
+func (t txAdherence) AppendLog(_ context.Context, entry adherence.AdherenceLogEntry) error {
+	t.p.logAppend = append(t.p.logAppend, entry)
+	return nil
+}
+
+func (t txAdherence) Log(ctx context.Context) ([]adherence.AdherenceLogEntry, error) {
+	var log []adherence.AdherenceLogEntry
+	if t.p.logReplace != nil {
+		log = append(log, *t.p.logReplace...)
+	} else {
+		stored, err := t.p.adherenceBase.Log(ctx)
+		if err != nil {
+			return nil, err
+		}
+		log = append(log, stored...)
+	}
+	return append(log, t.p.logAppend...), nil
+}
+
+func (t txAdherence) ReplaceLog(_ context.Context, log []adherence.AdherenceLogEntry) error {
+	replaced := append([]adherence.AdherenceLogEntry{}, log...)
+	t.p.logReplace = &replaced
+	t.p.logAppend = nil
+	return nil
+}
+
+// txRenewals is the Beginning Anew repository handed to a unit of work.
+type txRenewals struct{ p *pendingWrites }
+
+func (t txRenewals) List(ctx context.Context) ([]adherence.BeginningAnew, error) {
+	records, err := t.p.renewalBase.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	records = append([]adherence.BeginningAnew{}, records...)
+	for _, pending := range t.p.renewals {
+		records = replaceRenewal(records, pending)
+	}
+	return records, nil
+}
+
+func (t txRenewals) Save(_ context.Context, record adherence.BeginningAnew) error {
+	t.p.renewals = replaceRenewal(t.p.renewals, record)
+	return nil
+}
+
+// replaceRenewal puts record in place of the one for the same break, or
+// appends it.
+func replaceRenewal(records []adherence.BeginningAnew, record adherence.BeginningAnew) []adherence.BeginningAnew {
+	for i := range records {
+		if records[i].Break == record.Break {
+			records[i] = record
+			return records
+		}
+	}
+	return append(records, record)
+}
@@ -0,0 +1,251 @@
+package flatfile
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/thatnerdjosh/mindfulness/internal/domain/adherence"
+	"github.com/thatnerdjosh/mindfulness/internal/domain/journal"
+)
+
+type uowFiles struct {
+	journal string
+	state   string
+	log     string
+	renewal string
+	wal     string
+}
+
+func newUOWFiles(t *testing.T) uowFiles {
+	dir := t.TempDir()
+	return uowFiles{
+		journal: filepath.Join(dir, "journal.json"),
+		state:   filepath.Join(dir, "adherence.json"),
+		log:     filepath.Join(dir, "adherence.log.jsonl"),
+		renewal: filepath.Join(dir, "beginning-anew.json"),
+		wal:     filepath.Join(dir, "pending.wal.json"),
+	}
+}
+
+func (f uowFiles) open(t *testing.T) (*JournalRepository, *AdherenceRepository, *BeginningAnewRepository, *UnitOfWork) {
+	t.Helper()
+	journalRepo, err := NewJournalRepository(f.journal)
+	if err != nil {
+		t.Fatalf("open journal: %v", err)
+	}
+	adherenceRepo, err := NewAdherenceRepository(f.state, f.log)
+	if err != nil {
+		t.Fatalf("open adherence: %v", err)
+	}
+	renewalRepo, err := NewBeginningAnewRepository(f.renewal)
+	if err != nil {
+		t.Fatalf("open beginning anew: %v", err)
+	}
+	uow, err := NewUnitOfWork(f.wal, journalRepo, adherenceRepo, renewalRepo)
+	if err != nil {
+		t.Fatalf("open unit of work: %v", err)
+	}
+	return journalRepo, adherenceRepo, renewalRepo, uow
+}
+
+func checkIn(t *testing.T) func(journal.Repository, adherence.Repository, adherence.BeginningAnewRepository) error {
+	at := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	entry, err := journal.NewEntry(at, nil, "evening", "calm", "", at)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return func(journalRepo journal.Repository, adherenceRepo adherence.Repository, renewalRepo adherence.BeginningAnewRepository) error {
+		ctx := context.Background()
+		if err := journalRepo.Save(ctx, entry); err != nil {
+			return err
+		}
+		state, err := adherenceRepo.Get(ctx)
+		if err != nil {
+			return err
+		}
+		state[journal.TrueLove] = false
+		if err := adherenceRepo.Save(ctx, state); err != nil {
+			return err
+		}
+		broken := adherence.AdherenceLogEntry{At: at, Precept: journal.TrueLove, From: true, To: false}
+		if err := adherenceRepo.AppendLog(ctx, broken); err != nil {
+			return err
+		}
+		return renewalRepo.Save(ctx, adherence.BeginningAnew{Break: broken.Fingerprint(), Precept: journal.TrueLove, At: at, Intention: "listen fully"})
+	}
+}
+
+func TestUnitOfWorkRecoversFromFailureAtEachStep(t *testing.T) {
+	tests := []struct {
+		step      string
+		wantSaved bool
+	}{
+		{step: "wal", wantSaved: false},
+		{step: "journal", wantSaved: true},
+		{step: "adherence", wantSaved: true},
+		{step: "log", wantSaved: true},
+		{step: "beginning anew", wantSaved: true},
+		{step: "clear", wantSaved: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.step, func(t *testing.T) {
+			files := newUOWFiles(t)
+			_, _, _, uow := files.open(t)
+			failure := errors.New("disk unplugged")
+			uow.fault = func(step string) error {
+				if step == tt.step {
+					return failure
+				}
+				return nil
+			}
+
+			if err := uow.Do(context.Background(), checkIn(t)); !errors.Is(err, failure) {
+				t.Fatalf("expected injected failure, got %v", err)
+			}
+
+			journalRepo, adherenceRepo, renewalRepo, _ := files.open(t)
+			entries, err := journalRepo.List(context.Background())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			state, err := adherenceRepo.Get(context.Background())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			log, err := adherenceRepo.Log(context.Background())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			renewals, err := renewalRepo.List(context.Background())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tt.wantSaved {
+				if len(entries) != 1 || state[journal.TrueLove] || len(log) != 1 || len(renewals) != 1 {
+					t.Fatalf("expected the whole unit after recovery, got %d entries, true-love=%v, %d log entries, %d renewals", len(entries), state[journal.TrueLove], len(log), len(renewals))
+				}
+			} else if len(entries) != 0 || !state[journal.TrueLove] || len(log) != 0 || len(renewals) != 0 {
+				t.Fatalf("expected nothing stored, got %d entries, true-love=%v, %d log entries, %d renewals", len(entries), state[journal.TrueLove], len(log), len(renewals))
+			}
+			if _, err := os.Stat(files.wal); !os.IsNotExist(err) {
+				t.Fatalf("expected the write-ahead record to be removed, got %v", err)
+			}
+		})
+	}
+}
+
+func TestUnitOfWorkRejectsUnreadableRecord(t *testing.T) {
+	files := newUOWFiles(t)
+	if err := os.WriteFile(files.wal, []byte("{not json"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	journalRepo, err := NewJournalRepository(files.journal)
+	if err != nil {
+		t.Fatalf("open journal: %v", err)
+	}
+	adherenceRepo, err := NewAdherenceRepository(files.state, files.log)
+	if err != nil {
+		t.Fatalf("open adherence: %v", err)
+	}
+
+	if _, err := NewUnitOfWork(files.wal, journalRepo, adherenceRepo, nil); err == nil {
+		t.Fatal("expected an unreadable write-ahead record to be reported")
+	}
+	if _, err := os.Stat(files.wal); err != nil {
+		t.Fatalf("expected the write-ahead record to be kept: %v", err)
+	}
+}
+
+func TestUnitOfWorkDoesNotWriteWhenFuncFails(t *testing.T) {
+	files := newUOWFiles(t)
+	journalRepo, adherenceRepo, renewalRepo, uow := files.open(t)
+	failure := errors.New("validation failed")
+
+	err := uow.Do(context.Background(), func(txJournal journal.Repository, txAdherence adherence.Repository, txRenewals adherence.BeginningAnewRepository) error {
+		if err := checkIn(t)(txJournal, txAdherence, txRenewals); err != nil {
+			return err
+		}
+		state, err := txAdherence.Get(context.Background())
+		if err != nil {
+			return err
+		}
+		if state[journal.TrueLove] {
+			t.Fatal("expected reads inside the unit to see its own writes")
+		}
+		if list, _ := txJournal.List(context.Background()); len(list) != 1 {
+			t.Fatalf("expected the pending entry to be listed, got %d", len(list))
+		}
+		if list, _ := txRenewals.List(context.Background()); len(list) != 1 {
+			t.Fatalf("expected the pending renewal to be listed, got %d", len(list))
+		}
+		return failure
+	})
+	if !errors.Is(err, failure) {
+		t.Fatalf("expected func error, got %v", err)
+	}
+
+	if entries, _ := journalRepo.List(context.Background()); len(entries) != 0 {
+		t.Fatalf("expected no entries, got %d", len(entries))
+	}
+	if state, _ := adherenceRepo.Get(context.Background()); !state[journal.TrueLove] {
+		t.Fatal("expected the state to be unchanged")
+	}
+	if renewals, _ := renewalRepo.List(context.Background()); len(renewals) != 0 {
+		t.Fatalf("expected no renewals, got %d", len(renewals))
+	}
+	if _, err := os.Stat(files.wal); !os.IsNotExist(err) {
+		t.Fatalf("expected no write-ahead record, got %v", err)
+	}
+}
+
+func TestUnitOfWorkCompletesFailedUnitBeforeTheNext(t *testing.T) {
+	files := newUOWFiles(t)
+	journalRepo, adherenceRepo, _, uow := files.open(t)
+	failure := errors.New("disk unplugged")
+	uow.fault = func(step string) error {
+		if step == "log" {
+			return failure
+		}
+		return nil
+	}
+	if err := uow.Do(context.Background(), checkIn(t)); !errors.Is(err, failure) {
+		t.Fatalf("expected injected failure, got %v", err)
+	}
+	uow.fault = func(string) error { return nil }
+
+	at := time.Date(2024, 3, 2, 9, 0, 0, 0, time.UTC)
+	entry, err := journal.NewEntry(at, nil, "morning", "", "", at)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = uow.Do(context.Background(), func(txJournal journal.Repository, txAdherence adherence.Repository, _ adherence.BeginningAnewRepository) error {
+		if err := txJournal.Save(context.Background(), entry); err != nil {
+			return err
+		}
+		return txAdherence.AppendLog(context.Background(), adherence.AdherenceLogEntry{At: at, Precept: journal.TrueHappiness, From: true, To: false})
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	entries, err := journalRepo.List(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	log, err := adherenceRepo.Log(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(entries) != 2 || len(log) != 2 {
+		t.Fatalf("expected both units stored, got %d entries and %d log entries", len(entries), len(log))
+	}
+	if _, err := os.Stat(files.wal); !os.IsNotExist(err) {
+		t.Fatalf("expected the write-ahead record to be removed, got %v", err)
+	}
+}
@@ -0,0 +1,164 @@
+package flatfile
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/thatnerdjosh/mindfulness/internal/domain/user"
+	"github.com/thatnerdjosh/mindfulness/internal/infrastructure/atomicfile"
+)
+
+// DefaultUserRegistryPath returns the default path of the multi-user registry.
+func DefaultUserRegistryPath() (string, error) {
+	dataDir, err := defaultDataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dataDir, "users.json"), nil
+}
+
+// UserDataDir returns the directory holding a user's journal and adherence
+// files beneath the registry directory.
+func UserDataDir(registryPath string, name string) string {
+	return filepath.Join(filepath.Dir(registryPath), "users", name)
+}
+
+func defaultDataDir() (string, error) {
+	dataHome := strings.TrimSpace(os.Getenv("XDG_DATA_HOME"))
+	if dataHome == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("resolve home directory: %w", err)
+		}
+		dataHome = filepath.Join(home, ".local", "share")
+	}
+	return filepath.Join(dataHome, "mt"), nil
+}
+
+// UserRepository stores the user registry in a JSON file. It rereads the file
+// on every call so that administration commands take effect on a running
+// server.
+type UserRepository struct {
+	mu   sync.Mutex
+	path string
+}
+
+func NewUserRepository(path string) (*UserRepository, error) {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return nil, fmt.Errorf("user registry path is required")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("create data directory: %w", err)
+	}
+	return &UserRepository{path: path}, nil
+}
+
+func (r *UserRepository) List(_ context.Context) ([]user.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.loadLocked()
+}
+
+func (r *UserRepository) Save(_ context.Context, u user.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	users, err := r.loadLocked()
+	if err != nil {
+		return err
+	}
+	replaced := false
+	for i := range users {
+		if users[i].Name == u.Name {
+			users[i] = u
+			replaced = true
+		}
+	}
+	if !replaced {
+		users = append(users, u)
+	}
+
+	records := make([]userRecord, 0, len(users))
+	for _, existing := range users {
+		records = append(records, recordFromUser(existing))
+	}
+	data, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode user registry: %w", err)
+	}
+	data = append(data, '\n')
+	return atomicfile.Write(r.path, data, 0o600)
+}
+
+func (r *UserRepository) loadLocked() ([]user.User, error) {
+	data, err := os.ReadFile(r.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read user registry: %w", err)
+	}
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	var records []userRecord
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, fmt.Errorf("decode user registry: %w", err)
+	}
+	users := make([]user.User, 0, len(records))
+	for _, record := range records {
+		u, err := record.toUser()
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, u)
+	}
+	return users, nil
+}
+
+type userRecord struct {
+	Name      string `json:"name"`
+	TokenHash string `json:"token_hash"`
+	CreatedAt string `json:"created_at"`
+	RevokedAt string `json:"revoked_at,omitempty"`
+}
+
+func recordFromUser(u user.User) userRecord {
+	record := userRecord{
+		Name:      u.Name,
+		TokenHash: u.TokenHash,
+		CreatedAt: u.CreatedAt.UTC().Format(time.RFC3339),
+	}
+	if u.Revoked() {
+		record.RevokedAt = u.RevokedAt.UTC().Format(time.RFC3339)
+	}
+	return record
+}
+
+func (r userRecord) toUser() (user.User, error) {
+	createdAt, err := time.Parse(time.RFC3339, r.CreatedAt)
+	if err != nil {
+		return user.User{}, fmt.Errorf("invalid user created_at %q: %w", r.CreatedAt, err)
+	}
+	u, err := user.NewUser(r.Name, r.TokenHash, createdAt)
+	if err != nil {
+		return user.User{}, fmt.Errorf("invalid user %q: %w", r.Name, err)
+	}
+	if r.RevokedAt != "" {
+		revokedAt, err := time.Parse(time.RFC3339, r.RevokedAt)
+		if err != nil {
+			return user.User{}, fmt.Errorf("invalid user revoked_at %q: %w", r.RevokedAt, err)
+		}
+		u.RevokedAt = revokedAt
+	}
+	return u, nil
+}
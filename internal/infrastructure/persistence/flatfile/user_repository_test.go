@@ -0,0 +1,97 @@
+package flatfile
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/thatnerdjosh/mindfulness/internal/domain/user"
+)
+
+func TestUserRepositoryPersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "users.json")
+	repo, err := NewUserRepository(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	users, err := repo.List(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(users) != 0 {
+		t.Fatalf("expected empty registry")
+	}
+
+	anna, err := user.NewUser("anna", "hash-a", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := repo.Save(context.Background(), anna); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	anna.RevokedAt = time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	if err := repo.Save(context.Background(), anna); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	reloaded, err := NewUserRepository(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	users, err = reloaded.List(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(users) != 1 || !users[0].Revoked() || users[0].TokenHash != "hash-a" {
+		t.Fatalf("unexpected users: %+v", users)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Fatalf("expected registry to be private, got %v", info.Mode().Perm())
+	}
+}
+
+func TestUserRepositoryRejectsInvalidFile(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "invalid JSON", data: "{"},
+		{name: "invalid name", data: `[{"name":"../x","token_hash":"h","created_at":"2024-01-01T00:00:00Z"}]`},
+		{name: "invalid created_at", data: `[{"name":"anna","token_hash":"h","created_at":"bad"}]`},
+		{name: "invalid revoked_at", data: `[{"name":"anna","token_hash":"h","created_at":"2024-01-01T00:00:00Z","revoked_at":"bad"}]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "users.json")
+			if err := os.WriteFile(path, []byte(tt.data), 0o600); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			repo, err := NewUserRepository(path)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if _, err := repo.List(context.Background()); err == nil {
+				t.Fatalf("expected error")
+			}
+		})
+	}
+}
+
+func TestUserDataDir(t *testing.T) {
+	got := UserDataDir(filepath.Join("/data", "mt", "users.json"), "anna")
+	if got != filepath.Join("/data", "mt", "users", "anna") {
+		t.Fatalf("unexpected dir: %s", got)
+	}
+	if _, err := NewUserRepository(" "); err == nil {
+		t.Fatalf("expected error for empty path")
+	}
+}
@@ -51,3 +51,21 @@ func (r *AdherenceRepository) AppendLog(_ context.Context, entry adherence.Adher
 	r.logs = append(r.logs, entry)
 	return nil
 }
+
+func (r *AdherenceRepository) Log(_ context.Context) ([]adherence.AdherenceLogEntry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if len(r.logs) == 0 {
+		return nil, nil
+	}
+	return append([]adherence.AdherenceLogEntry{}, r.logs...), nil
+}
+
+func (r *AdherenceRepository) ReplaceLog(_ context.Context, log []adherence.AdherenceLogEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.logs = append([]adherence.AdherenceLogEntry{}, log...)
+	return nil
+}
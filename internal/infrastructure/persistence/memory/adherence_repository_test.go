@@ -36,3 +36,18 @@ func TestAdherenceRepositorySave(t *testing.T) {
 		t.Fatalf("expected TrueHappiness false")
 	}
 }
+
+func TestAdherenceRepositoryLog(t *testing.T) {
+	repo := NewAdherenceRepository()
+	entry := adherence.AdherenceLogEntry{Precept: journal.TrueLove, From: true, To: false}
+	if err := repo.AppendLog(context.Background(), entry); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	log, err := repo.Log(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(log) != 1 || log[0].Precept != journal.TrueLove {
+		t.Fatalf("unexpected log: %+v", log)
+	}
+}
@@ -0,0 +1,43 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/thatnerdjosh/mindfulness/internal/domain/adherence"
+)
+
+// BeginningAnewRepository is an in-memory implementation for Beginning Anew
+// records.
+type BeginningAnewRepository struct {
+	mu      sync.RWMutex
+	records []adherence.BeginningAnew
+}
+
+func NewBeginningAnewRepository() *BeginningAnewRepository {
+	return &BeginningAnewRepository{}
+}
+
+func (r *BeginningAnewRepository) List(_ context.Context) ([]adherence.BeginningAnew, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if len(r.records) == 0 {
+		return nil, nil
+	}
+	return append([]adherence.BeginningAnew{}, r.records...), nil
+}
+
+func (r *BeginningAnewRepository) Save(_ context.Context, record adherence.BeginningAnew) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.records {
+		if r.records[i].Break == record.Break {
+			r.records[i] = record
+			return nil
+		}
+	}
+	r.records = append(r.records, record)
+	return nil
+}
@@ -0,0 +1,31 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/thatnerdjosh/mindfulness/internal/domain/adherence"
+	"github.com/thatnerdjosh/mindfulness/internal/domain/journal"
+)
+
+func TestBeginningAnewRepositoryReplacesRecordForBreak(t *testing.T) {
+	repo := NewBeginningAnewRepository()
+	at := time.Date(2024, 4, 1, 9, 0, 0, 0, time.UTC)
+	record := adherence.BeginningAnew{Break: "abc", Precept: journal.TrueLove, At: at, Intention: "listen"}
+	if err := repo.Save(context.Background(), record); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	record.RenewedAt = at.Add(time.Hour)
+	if err := repo.Save(context.Background(), record); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	records, err := repo.List(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(records) != 1 || !records[0].Renewed() {
+		t.Fatalf("expected one renewed record, got %+v", records)
+	}
+}
@@ -63,3 +63,11 @@ func (r *JournalRepository) List(_ context.Context) ([]journal.Entry, error) {
 	})
 	return entries, nil
 }
+
+func (r *JournalRepository) Replace(_ context.Context, entries []journal.Entry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.entries = append([]journal.Entry{}, entries...)
+	return nil
+}
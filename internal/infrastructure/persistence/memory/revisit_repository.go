@@ -0,0 +1,44 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/thatnerdjosh/mindfulness/internal/domain/journal"
+)
+
+// RevisitRepository is an in-memory implementation for journal revisits.
+type RevisitRepository struct {
+	mu       sync.RWMutex
+	revisits []journal.Revisit
+}
+
+func NewRevisitRepository() *RevisitRepository {
+	return &RevisitRepository{}
+}
+
+func (r *RevisitRepository) List(_ context.Context) ([]journal.Revisit, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	list := make([]journal.Revisit, 0, len(r.revisits))
+	for _, revisit := range r.revisits {
+		list = append(list, journal.Revisit{Entry: revisit.Entry, At: append([]time.Time{}, revisit.At...)})
+	}
+	return list, nil
+}
+
+func (r *RevisitRepository) Record(_ context.Context, fingerprint string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.revisits {
+		if r.revisits[i].Entry == fingerprint {
+			r.revisits[i].At = append(r.revisits[i].At, at)
+			return nil
+		}
+	}
+	r.revisits = append(r.revisits, journal.Revisit{Entry: fingerprint, At: []time.Time{at}})
+	return nil
+}
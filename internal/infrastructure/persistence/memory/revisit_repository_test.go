@@ -0,0 +1,26 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRevisitRepositoryAppendsRevisits(t *testing.T) {
+	repo := NewRevisitRepository()
+	at := time.Date(2024, 4, 1, 9, 0, 0, 0, time.UTC)
+	if err := repo.Record(context.Background(), "abc", at); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := repo.Record(context.Background(), "abc", at.Add(time.Hour)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	revisits, err := repo.List(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(revisits) != 1 || len(revisits[0].At) != 2 || !revisits[0].Last().Equal(at.Add(time.Hour)) {
+		t.Fatalf("expected one entry revisited twice, got %+v", revisits)
+	}
+}
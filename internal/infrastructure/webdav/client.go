@@ -0,0 +1,175 @@
+// Package webdav is a minimal WebDAV client for storing mt's data files on a
+// remote collection with ETag-based optimistic concurrency.
+package webdav
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+var (
+	ErrNotFound           = errors.New("remote file not found")
+	ErrPreconditionFailed = errors.New("remote file changed since last sync")
+)
+
+const maxFileBytes = 64 << 20
+
+// Client talks to a single WebDAV collection.
+type Client struct {
+	base     *url.URL
+	username string
+	password string
+	http     *http.Client
+}
+
+// NewClient returns a client for the collection at rawURL. Credentials are
+// sent with HTTP Basic authentication when username is set.
+func NewClient(rawURL string, username string, password string, httpClient *http.Client) (*Client, error) {
+	base, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || (base.Scheme != "http" && base.Scheme != "https") || base.Host == "" {
+		return nil, fmt.Errorf("invalid WebDAV URL %q", rawURL)
+	}
+	if !strings.HasSuffix(base.Path, "/") {
+		base.Path += "/"
+	}
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &Client{base: base, username: username, password: password, http: httpClient}, nil
+}
+
+// Get downloads name and returns its content and ETag.
+func (c *Client) Get(ctx context.Context, name string) ([]byte, string, error) {
+	resp, err := c.do(ctx, http.MethodGet, name, nil, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, "", ErrNotFound
+	default:
+		return nil, "", statusError(http.MethodGet, name, resp)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxFileBytes))
+	if err != nil {
+		return nil, "", fmt.Errorf("read %s: %w", name, err)
+	}
+	return data, resp.Header.Get("ETag"), nil
+}
+
+// Put uploads data as name. A non-empty etag must still match the remote
+// file; an empty etag only succeeds if the file does not exist yet. Either
+// mismatch returns ErrPreconditionFailed. The new ETag is returned.
+func (c *Client) Put(ctx context.Context, name string, data []byte, etag string) (string, error) {
+	header := http.Header{}
+	if etag != "" {
+		header.Set("If-Match", etag)
+	} else {
+		header.Set("If-None-Match", "*")
+	}
+
+	resp, err := c.do(ctx, http.MethodPut, name, data, header)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode == http.StatusConflict {
+		// The collection itself is missing; create it and try once more.
+		if err := c.mkcol(ctx); err != nil {
+			return "", err
+		}
+		if resp, err = c.do(ctx, http.MethodPut, name, data, header); err != nil {
+			return "", err
+		}
+		resp.Body.Close()
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated, http.StatusNoContent:
+	case http.StatusPreconditionFailed:
+		return "", ErrPreconditionFailed
+	default:
+		return "", statusError(http.MethodPut, name, resp)
+	}
+
+	if newETag := resp.Header.Get("ETag"); newETag != "" {
+		return newETag, nil
+	}
+	// Some servers omit the ETag on PUT.
+	return c.head(ctx, name)
+}
+
+func (c *Client) head(ctx context.Context, name string) (string, error) {
+	resp, err := c.do(ctx, http.MethodHead, name, nil, nil)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", statusError(http.MethodHead, name, resp)
+	}
+	return resp.Header.Get("ETag"), nil
+}
+
+func (c *Client) mkcol(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, "MKCOL", c.base.String(), nil)
+	if err != nil {
+		return err
+	}
+	c.authorize(req)
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("create remote collection: %w", err)
+	}
+	resp.Body.Close()
+	// 405 means the collection already exists.
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusMethodNotAllowed {
+		return statusError("MKCOL", c.base.Path, resp)
+	}
+	return nil
+}
+
+func (c *Client) do(ctx context.Context, method string, name string, body []byte, header http.Header) (*http.Response, error) {
+	target := c.base.ResolveReference(&url.URL{Path: url.PathEscape(name)})
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target.String(), reader)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	c.authorize(req)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %w", method, name, err)
+	}
+	return resp, nil
+}
+
+func (c *Client) authorize(req *http.Request) {
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+}
+
+func statusError(method string, name string, resp *http.Response) error {
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return fmt.Errorf("%s %s: %s (check the WebDAV credentials)", method, name, resp.Status)
+	}
+	return fmt.Errorf("%s %s: unexpected status %s", method, name, resp.Status)
+}
@@ -0,0 +1,117 @@
+package webdav
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/thatnerdjosh/mindfulness/internal/infrastructure/webdav/webdavtest"
+)
+
+func TestClientRoundTrip(t *testing.T) {
+	server := webdavtest.NewServer("alice", "secret")
+	defer server.Close()
+
+	client, err := NewClient(server.CollectionURL(), "alice", "secret", server.Client())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ctx := context.Background()
+
+	if _, _, err := client.Get(ctx, "journal.json"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+
+	first, err := client.Put(ctx, "journal.json", []byte("one"), "")
+	if err != nil {
+		t.Fatalf("unexpected error creating file: %v", err)
+	}
+	if first == "" {
+		t.Fatalf("expected an ETag")
+	}
+	if _, err := client.Put(ctx, "journal.json", []byte("again"), ""); !errors.Is(err, ErrPreconditionFailed) {
+		t.Fatalf("expected create-only put to fail, got %v", err)
+	}
+
+	second, err := client.Put(ctx, "journal.json", []byte("two"), first)
+	if err != nil {
+		t.Fatalf("unexpected error updating file: %v", err)
+	}
+	if _, err := client.Put(ctx, "journal.json", []byte("stale"), first); !errors.Is(err, ErrPreconditionFailed) {
+		t.Fatalf("expected stale put to fail, got %v", err)
+	}
+
+	data, tag, err := client.Get(ctx, "journal.json")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(data) != "two" || tag != second {
+		t.Fatalf("unexpected get result %q %q", data, tag)
+	}
+}
+
+func TestClientRejectsBadCredentials(t *testing.T) {
+	server := webdavtest.NewServer("alice", "secret")
+	defer server.Close()
+
+	client, err := NewClient(server.CollectionURL(), "alice", "wrong", server.Client())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, _, err = client.Get(context.Background(), "journal.json")
+	if err == nil || !strings.Contains(err.Error(), "credentials") {
+		t.Fatalf("expected credentials error, got %v", err)
+	}
+}
+
+func TestNewClientValidatesURL(t *testing.T) {
+	for _, raw := range []string{"", "ftp://host/dav", "not a url", "https://"} {
+		if _, err := NewClient(raw, "", "", nil); err == nil {
+			t.Errorf("expected error for %q", raw)
+		}
+	}
+}
+
+func TestStateRoundTrip(t *testing.T) {
+	path := t.TempDir() + "/mt/webdav.json"
+	empty, err := LoadState(path)
+	if err != nil || len(empty.ETags) != 0 {
+		t.Fatalf("expected empty state, got %+v, %v", empty, err)
+	}
+
+	empty.ETags["journal.json"] = `"abc"`
+	if err := SaveState(path, empty); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	loaded, err := LoadState(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if loaded.ETags["journal.json"] != `"abc"` {
+		t.Fatalf("unexpected state %+v", loaded)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil || info.Mode().Perm() != 0o600 {
+		t.Fatalf("expected 0600 permissions, got %v, %v", info, err)
+	}
+	names, err := os.ReadDir(filepath.Dir(path))
+	if err != nil || len(names) != 1 {
+		t.Fatalf("expected no temporary files left behind, got %v, %v", names, err)
+	}
+}
+
+func TestStateUseDropsETagsOfAnotherRemote(t *testing.T) {
+	state := State{URL: "https://a.example/dav/", ETags: map[string]string{"journal.json": `"abc"`}}
+	state.Use("https://a.example/dav/")
+	if state.ETags["journal.json"] != `"abc"` {
+		t.Fatalf("expected the same remote to keep its ETags, got %+v", state)
+	}
+	state.Use("https://b.example/dav/")
+	if state.URL != "https://b.example/dav/" || len(state.ETags) != 0 {
+		t.Fatalf("expected a new remote to start without ETags, got %+v", state)
+	}
+}
@@ -0,0 +1,78 @@
+package webdav
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/thatnerdjosh/mindfulness/internal/infrastructure/atomicfile"
+)
+
+// State remembers the ETag of each remote file as of the last push or pull,
+// so the next push only succeeds if nobody changed the file in between. URL
+// is the remote the ETags came from.
+type State struct {
+	URL   string            `json:"url,omitempty"`
+	ETags map[string]string `json:"etags"`
+}
+
+// Use points the state at the remote at url. ETags from another remote say
+// nothing about this one, so they are dropped when the URL changes.
+func (s *State) Use(url string) {
+	if s.URL == url {
+		return
+	}
+	s.URL = url
+	s.ETags = make(map[string]string)
+}
+
+// DefaultStatePath returns $XDG_STATE_HOME/mt/webdav.json. The state is
+// local to this device and deliberately kept out of the synced data
+// directory.
+func DefaultStatePath() (string, error) {
+	stateHome := strings.TrimSpace(os.Getenv("XDG_STATE_HOME"))
+	if stateHome == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("resolve home directory: %w", err)
+		}
+		stateHome = filepath.Join(home, ".local", "state")
+	}
+	return filepath.Join(stateHome, "mt", "webdav.json"), nil
+}
+
+// LoadState reads the sync state. A missing file yields an empty state.
+func LoadState(path string) (State, error) {
+	state := State{ETags: make(map[string]string)}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return state, nil
+		}
+		return State{}, fmt.Errorf("read sync state: %w", err)
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return State{}, fmt.Errorf("decode sync state: %w", err)
+	}
+	if state.ETags == nil {
+		state.ETags = make(map[string]string)
+	}
+	return state, nil
+}
+
+// SaveState writes the sync state through a temporary file, so an
+// interrupted write cannot leave ETags that no longer match the data.
+func SaveState(path string, state State) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("create state directory: %w", err)
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode sync state: %w", err)
+	}
+	data = append(data, '\n')
+
+	return atomicfile.Write(path, data, 0o600)
+}
@@ -0,0 +1,141 @@
+// Package webdavtest provides an in-process WebDAV stand-in for tests. It
+// implements just enough of RFC 4918 for mt: GET, HEAD, PUT with If-Match and
+// If-None-Match, and MKCOL on a single flat collection.
+package webdavtest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strings"
+	"sync"
+)
+
+// Server is a running WebDAV stand-in. Its files live in memory.
+type Server struct {
+	*httptest.Server
+
+	mu         sync.Mutex
+	files      map[string][]byte
+	collection bool
+	username   string
+	password   string
+}
+
+// NewServer starts a server whose collection lives at /dav/. When username
+// is non-empty every request must carry matching Basic credentials. The
+// collection does not exist until it is created with MKCOL.
+func NewServer(username string, password string) *Server {
+	s := &Server{files: make(map[string][]byte), username: username, password: password}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+// CollectionURL returns the URL of the collection.
+func (s *Server) CollectionURL() string {
+	return s.Server.URL + "/dav/"
+}
+
+// File returns the content of name and whether it exists.
+func (s *Server) File(name string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.files[name]
+	return append([]byte(nil), data...), ok
+}
+
+// SetFile stores data as name, as if another device had uploaded it.
+func (s *Server) SetFile(name string, data []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.collection = true
+	s.files[name] = append([]byte(nil), data...)
+}
+
+func etag(data []byte) string {
+	sum := sha256.Sum256(data)
+	return `"` + hex.EncodeToString(sum[:8]) + `"`
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	if s.username != "" {
+		user, pass, ok := r.BasicAuth()
+		if !ok || user != s.username || pass != s.password {
+			w.Header().Set("WWW-Authenticate", `Basic realm="dav"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+	}
+
+	dir, name := path.Split(r.URL.Path)
+	if dir != "/dav/" && !(dir == "/" && name == "dav") {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.Method == "MKCOL" {
+		if s.collection {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		s.collection = true
+		w.WriteHeader(http.StatusCreated)
+		return
+	}
+
+	current, exists := s.files[name]
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		if !exists {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("ETag", etag(current))
+		w.WriteHeader(http.StatusOK)
+		if r.Method == http.MethodGet {
+			_, _ = w.Write(current)
+		}
+	case http.MethodPut:
+		if !s.collection {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		if match := r.Header.Get("If-Match"); match != "" && (!exists || !matches(match, etag(current))) {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+		if r.Header.Get("If-None-Match") == "*" && exists {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		s.files[name] = data
+		w.Header().Set("ETag", etag(data))
+		if exists {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func matches(header string, want string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		if strings.TrimSpace(candidate) == want {
+			return true
+		}
+	}
+	return false
+}
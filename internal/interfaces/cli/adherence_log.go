@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	adherenceapp "github.com/thatnerdjosh/mindfulness/internal/application/adherence"
+	"github.com/thatnerdjosh/mindfulness/internal/infrastructure/config"
+	"github.com/thatnerdjosh/mindfulness/internal/infrastructure/persistence/flatfile"
+	"github.com/thatnerdjosh/mindfulness/internal/interfaces/schema"
+)
+
+func runAdherenceLog(args []string, svc *adherenceapp.Service, format outputFormat, out io.Writer, errOut io.Writer) error {
+	if len(args) > 0 {
+		switch args[0] {
+		case "archive":
+			return runAdherenceLogArchive(svc, format, out)
+		case "help", "-h", "--help":
+			printAdherenceUsage(out)
+			return nil
+		default:
+			fmt.Fprintf(errOut, "unknown adherence log command: %s\n", args[0])
+			printAdherenceUsage(errOut)
+			return fmt.Errorf("unknown adherence log command: %s", args[0])
+		}
+	}
+
+	log, err := svc.Log(context.Background())
+	if err != nil {
+		return err
+	}
+	if format != formatText {
+		return writeList(out, format, schema.FromLogEntries(log))
+	}
+	if len(log) == 0 {
+		fmt.Fprintln(out, "no adherence changes logged")
+		return nil
+	}
+
+	var buf bytes.Buffer
+	width := terminalWidth()
+	for _, change := range log {
+		fmt.Fprintf(&buf, "%s  %s: %s -> %s\n",
+			change.At.Local().Format("2006-01-02 15:04"),
+			preceptTitle(change.Precept),
+			yesNoLabel(change.From),
+			yesNoLabel(change.To),
+		)
+		if change.Note != "" {
+			writeWrapped(&buf, change.Note, "    ", width)
+		}
+	}
+	return page(out, buf.Bytes())
+}
+
+func runAdherenceLogArchive(svc *adherenceapp.Service, format outputFormat, out io.Writer) error {
+	segment, archived, err := svc.ArchiveLog(context.Background())
+	if err != nil {
+		return err
+	}
+
+	if format != formatText {
+		result := schema.LogArchive{Archived: archived}
+		if archived {
+			result.Segment = segment.Name
+			result.First = segment.First.UTC().Format(time.RFC3339Nano)
+			result.Last = segment.Last.UTC().Format(time.RFC3339Nano)
+			result.Events = segment.Events
+		}
+		return writeObject(out, format, result)
+	}
+	if !archived {
+		fmt.Fprintln(out, "nothing to archive: the log holds no changes since the last archive")
+		return nil
+	}
+	fmt.Fprintf(out, "archived %d event(s) to %s\n", segment.Events, segment.Name)
+	return nil
+}
+
+// rotationPolicy turns the log settings of the config file into a rotation
+// policy, starting from the defaults.
+func rotationPolicy(cfg config.Log) (flatfile.RotationPolicy, error) {
+	policy := flatfile.DefaultRotationPolicy()
+	if cfg.RotateBytes != 0 {
+		policy.MaxBytes = cfg.RotateBytes
+	}
+	policy.KeepSegments = cfg.KeepSegments
+
+	var err error
+	if policy.MaxAge, err = config.ParseDuration(cfg.RotateAfter); err != nil {
+		return flatfile.RotationPolicy{}, fmt.Errorf("log.rotate_after: %w", err)
+	}
+	if policy.KeepFor, err = config.ParseDuration(cfg.KeepFor); err != nil {
+		return flatfile.RotationPolicy{}, fmt.Errorf("log.keep_for: %w", err)
+	}
+	return policy, nil
+}
@@ -3,6 +3,7 @@ package cli
 import (
 	"bufio"
 	"context"
+	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
@@ -12,10 +13,16 @@ import (
 	"time"
 
 	adherenceapp "github.com/thatnerdjosh/mindfulness/internal/application/adherence"
+	analyticsapp "github.com/thatnerdjosh/mindfulness/internal/application/analytics"
+	checkinapp "github.com/thatnerdjosh/mindfulness/internal/application/checkin"
 	journalapp "github.com/thatnerdjosh/mindfulness/internal/application/journal"
+	mergeapp "github.com/thatnerdjosh/mindfulness/internal/application/merge"
+	reviewapp "github.com/thatnerdjosh/mindfulness/internal/application/review"
 	adherencedomain "github.com/thatnerdjosh/mindfulness/internal/domain/adherence"
 	"github.com/thatnerdjosh/mindfulness/internal/domain/journal"
+	"github.com/thatnerdjosh/mindfulness/internal/infrastructure/config"
 	"github.com/thatnerdjosh/mindfulness/internal/infrastructure/persistence/flatfile"
+	"github.com/thatnerdjosh/mindfulness/internal/interfaces/schema"
 )
 
 const version = "0.1.0"
@@ -27,51 +34,171 @@ func Run(args []string, out io.Writer, errOut io.Writer) error {
 		return nil
 	}
 
-	repoPath, err := flatfile.DefaultJournalPath()
+	format, args, err := parseGlobalFlags(args[1:])
 	if err != nil {
 		return err
 	}
-	repo, err := flatfile.NewJournalRepository(repoPath)
-	if err != nil {
-		return err
+	if len(args) < 1 {
+		printUsage(out)
+		return nil
 	}
-	svc := journalapp.NewService(repo)
 
-	adherencePath, err := flatfile.DefaultAdherencePath()
-	if err != nil {
-		return err
+	switch args[0] {
+	case "version", "-v", "--version":
+		if format != formatText {
+			return writeObject(out, format, map[string]string{"version": version})
+		}
+		fmt.Fprintln(out, "mt", version)
+		return nil
+	case "help", "-h", "--help":
+		printUsage(out)
+		return nil
+	case "doctor":
+		// doctor inspects the raw files itself and reports every problem,
+		// not just the summary the repositories warn about.
+		files, err := defaultDataFiles()
+		if err != nil {
+			return err
+		}
+		return runDoctor(args[1:], files, format, out, errOut)
+	case "user":
+		userSvc, err := openUserService()
+		if err != nil {
+			return err
+		}
+		return runUser(args[1:], userSvc, format, out, errOut)
+	case "serve":
+		files, err := defaultDataFiles()
+		if err != nil {
+			return err
+		}
+		return runServe(args[1:], files, out, errOut)
+	case "journal", "quicknote", "adherence", "checkin", "stats", "calendar", "mood", "vedana", "report", "review", "web", "sync":
+		files, err := defaultDataFiles()
+		if err != nil {
+			return err
+		}
+		return runWithData(args, files, format, out, errOut)
+	default:
+		fmt.Fprintf(errOut, "unknown command: %s\n", args[0])
+		printUsage(errOut)
+		return fmt.Errorf("unknown command: %s", args[0])
 	}
-	adherenceLogPath, err := flatfile.DefaultAdherenceLogPath()
+}
+
+// runWithData opens the data files and runs a command that reads or writes
+// them. Opening finishes any interrupted write first, so the other commands
+// stay out of here and keep working whatever state the files are in.
+func runWithData(args []string, files dataFiles, format outputFormat, out io.Writer, errOut io.Writer) error {
+	data, err := openData(files, errOut)
 	if err != nil {
 		return err
 	}
-	adherenceRepo, err := flatfile.NewAdherenceRepository(adherencePath, adherenceLogPath)
-	if err != nil {
-		return err
+	if args[0] != "sync" {
+		warnSyncConflicts(files.dir(), errOut)
 	}
-	adherenceSvc := adherenceapp.NewService(adherenceRepo)
 
-	switch args[1] {
-	case "version", "-v", "--version":
-		fmt.Fprintln(out, "mt", version)
-		return nil
+	switch args[0] {
 	case "journal":
-		return runJournal(args[2:], svc, os.Stdin, out, errOut)
+		return runJournal(args[1:], data.journal, data.adherence, format, os.Stdin, out, errOut)
 	case "quicknote":
-		return runQuicknote(args[2:], svc, os.Stdin, out, errOut)
+		return runQuicknote(args[1:], data.journal, format, os.Stdin, out, errOut)
 	case "adherence":
-		return runAdherence(args[2:], adherenceSvc, os.Stdin, out, errOut)
-	case "help", "-h", "--help":
-		printUsage(out)
-		return nil
+		return runAdherence(args[1:], data.adherence, format, os.Stdin, out, errOut)
+	case "checkin":
+		return runCheckin(args[1:], checkinapp.NewService(data.uow, data.journal, data.adherence), data.adherence, format, os.Stdin, out, errOut)
+	case "stats":
+		return runStats(args[1:], data.analytics, format, out, errOut)
+	case "calendar":
+		return runCalendar(args[1:], data.journal, data.adherence, data.analytics, format, out, errOut)
+	case "mood":
+		return runMood(args[1:], data.journal, data.analytics, format, out, errOut)
+	case "vedana":
+		return runVedana(args[1:], data.analytics, format, out, errOut)
+	case "report":
+		return runReport(args[1:], data.journal, data.adherence, data.analytics, format, out, errOut)
+	case "review":
+		return runReview(args[1:], reviewapp.NewService(data.journal, data.adherence, data.analytics), format, os.Stdin, out, errOut)
+	case "web":
+		return runWeb(args[1:], data.journal, data.adherence, out, errOut)
+	case "sync":
+		return runSync(args[1:], mergeapp.NewService(data.uow), files, format, os.Stdin, out, errOut)
 	default:
-		fmt.Fprintf(errOut, "unknown command: %s\n", args[1])
-		printUsage(errOut)
-		return fmt.Errorf("unknown command: %s", args[1])
+		return fmt.Errorf("unknown command: %s", args[0])
+	}
+}
+
+// appData holds the services built over the data files.
+type appData struct {
+	journal   *journalapp.Service
+	adherence *adherenceapp.Service
+	analytics *analyticsapp.Service
+	uow       *flatfile.UnitOfWork
+}
+
+// openData opens the data files and builds the services over them, warning
+// about any records it had to skip.
+func openData(files dataFiles, errOut io.Writer) (*appData, error) {
+	repo, err := flatfile.NewJournalRepository(files.journal, flatfile.WithLenientLoad())
+	if err != nil {
+		return nil, withDoctorHint(err)
+	}
+	configPath, err := config.DefaultPath()
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return nil, err
+	}
+	moods, err := moodVocabulary(cfg.Mood)
+	if err != nil {
+		return nil, err
+	}
+	prompts, err := promptBank(cfg.Prompts)
+	if err != nil {
+		return nil, err
+	}
+	nudges, err := nudgePolicy(cfg.Nudges)
+	if err != nil {
+		return nil, err
+	}
+	revisitRepo, err := flatfile.NewRevisitRepository(files.revisits)
+	if err != nil {
+		return nil, err
+	}
+	svc := journalapp.NewService(repo, journalapp.WithMoodVocabulary(moods), journalapp.WithPromptBank(prompts), journalapp.WithRevisits(revisitRepo), journalapp.WithNudgePolicy(nudges))
+	analytics := analyticsapp.NewService(repo, analyticsapp.WithMoodVocabulary(moods))
+	policy, err := rotationPolicy(cfg.Log)
+	if err != nil {
+		return nil, err
+	}
+	adherenceRepo, err := flatfile.NewEventSourcedAdherenceRepository(files.adherence, files.adherenceLog, flatfile.WithLenientLoad(), flatfile.WithLogRotation(policy))
+	if err != nil {
+		return nil, withDoctorHint(err)
 	}
+
+	// Finish any write a previous run left half done before anything reads
+	// the files.
+	renewalRepo, err := flatfile.NewBeginningAnewRepository(files.beginningAnew)
+	if err != nil {
+		return nil, err
+	}
+	uow, err := flatfile.NewUnitOfWork(files.wal, repo, adherenceRepo, renewalRepo)
+	if err != nil {
+		return nil, withDoctorHint(err)
+	}
+	adherenceOpts := []adherenceapp.Option{adherenceapp.WithUnitOfWork(uow), adherenceapp.WithBeginningAnew(renewalRepo)}
+	if cfg.Journal.LinksAdherence() {
+		adherenceOpts = append(adherenceOpts, adherenceapp.WithListener(svc.JournalAdherenceChanges))
+	}
+	adherenceSvc := adherenceapp.NewService(adherenceRepo, adherenceOpts...)
+
+	warnSkippedRecords(errOut, append(repo.LoadWarnings(), adherenceRepo.LoadWarnings()...))
+	return &appData{journal: svc, adherence: adherenceSvc, analytics: analytics, uow: uow}, nil
 }
 
-func runAdherence(args []string, svc *adherenceapp.Service, in io.Reader, out io.Writer, errOut io.Writer) error {
+func runAdherence(args []string, svc *adherenceapp.Service, format outputFormat, in io.Reader, out io.Writer, errOut io.Writer) error {
 	if len(args) < 1 {
 		printAdherenceUsage(errOut)
 		return fmt.Errorf("adherence subcommand required")
@@ -79,7 +206,17 @@ func runAdherence(args []string, svc *adherenceapp.Service, in io.Reader, out io
 
 	switch args[0] {
 	case "guided":
-		return runAdherenceGuided(args[1:], svc, in, out, errOut)
+		return runAdherenceGuided(args[1:], svc, format, in, out, errOut)
+	case "status":
+		return runAdherenceStatus(svc, format, out)
+	case "rebuild":
+		return runAdherenceRebuild(svc, format, out)
+	case "log":
+		return runAdherenceLog(args[1:], svc, format, out, errOut)
+	case "renew":
+		return runAdherenceRenew(args[1:], svc, format, in, out, errOut)
+	case "breaks":
+		return runAdherenceBreaks(svc, format, out)
 	case "help", "-h", "--help":
 		printAdherenceUsage(out)
 		return nil
@@ -90,7 +227,7 @@ func runAdherence(args []string, svc *adherenceapp.Service, in io.Reader, out io
 	}
 }
 
-func runJournal(args []string, svc *journalapp.Service, in io.Reader, out io.Writer, errOut io.Writer) error {
+func runJournal(args []string, svc *journalapp.Service, adherenceSvc *adherenceapp.Service, format outputFormat, in io.Reader, out io.Writer, errOut io.Writer) error {
 	if len(args) < 1 {
 		printJournalUsage(errOut)
 		return fmt.Errorf("journal subcommand required")
@@ -98,13 +235,17 @@ func runJournal(args []string, svc *journalapp.Service, in io.Reader, out io.Wri
 
 	switch args[0] {
 	case "add":
-		return runJournalAdd(args[1:], svc, out, errOut)
+		return runJournalAdd(args[1:], svc, format, in, out, errOut)
 	case "guided":
-		return runJournalGuided(args[1:], svc, in, out, errOut)
+		return runJournalGuided(args[1:], svc, adherenceSvc, format, in, out, errOut)
 	case "latest":
-		return runJournalLatest(svc, out)
+		return runJournalLatest(svc, format, out)
 	case "list":
-		return runJournalList(svc, out)
+		return runJournalList(svc, format, out)
+	case "show":
+		return runJournalShow(args[1:], svc, adherenceSvc, format, out)
+	case "resurface":
+		return runJournalResurface(args[1:], svc, format, out, errOut)
 	case "help", "-h", "--help":
 		printJournalUsage(out)
 		return nil
@@ -115,9 +256,10 @@ func runJournal(args []string, svc *journalapp.Service, in io.Reader, out io.Wri
 	}
 }
 
-func runQuicknote(args []string, svc *journalapp.Service, in io.Reader, out io.Writer, errOut io.Writer) error {
+func runQuicknote(args []string, svc *journalapp.Service, format outputFormat, in io.Reader, out io.Writer, errOut io.Writer) error {
 	reader := bufio.NewReader(in)
-	note, err := prompt(reader, out, "Quicknote: ")
+	promptOut := promptWriter(format, out, errOut)
+	note, err := prompt(reader, promptOut, "Quicknote: ")
 	if note == "" {
 		// TODO: Extract to const for error string
 		return errors.New("Unable to create quicknote without content.")
@@ -127,7 +269,11 @@ func runQuicknote(args []string, svc *journalapp.Service, in io.Reader, out io.W
 		return err
 	}
 
-	foundation, err := promptFoundation(reader, out)
+	foundation, err := promptFoundation(reader, promptOut)
+	if err != nil {
+		return err
+	}
+	vedana, err := promptVedanaFor(reader, promptOut, foundation)
 	if err != nil {
 		return err
 	}
@@ -137,18 +283,18 @@ func runQuicknote(args []string, svc *journalapp.Service, in io.Reader, out io.W
 		return err
 	}
 
-	entry, err := svc.RecordEntry(context.Background(), date, map[journal.Precept]string{}, note, "", foundation)
+	entry, err := svc.RecordEntry(context.Background(), date, map[journal.Precept]string{}, note, "", foundation, append(vedanaOptions(vedana), journal.AsQuicknote())...)
 	if err != nil {
 		return err
 	}
 
-	fmt.Fprintf(out, "journaled %s reflections=%d mood=%s\n", entry.Date.Format("2006-01-02"), len(entry.Reflections), entry.Mood)
-	return nil
+	return writeJournaled(out, format, entry)
 }
 
-func runJournalAdd(args []string, svc *journalapp.Service, out io.Writer, errOut io.Writer) error {
+func runJournalAdd(args []string, svc *journalapp.Service, format outputFormat, in io.Reader, out io.Writer, errOut io.Writer) error {
 	fs := flag.NewFlagSet("journal add", flag.ContinueOnError)
 	fs.SetOutput(errOut)
+	jsonPath := fs.String("json", "", "read a complete entry as JSON from a file, or - for stdin")
 	dateStr := fs.String("date", "", "date in YYYY-MM-DD (defaults to today)")
 	note := fs.String("note", "", "overall note")
 	mood := fs.String("mood", "", "overall mood")
@@ -161,6 +307,10 @@ func runJournalAdd(args []string, svc *journalapp.Service, out io.Writer, errOut
 		return err
 	}
 
+	if *jsonPath != "" {
+		return runJournalAddJSON(*jsonPath, svc, format, in, out)
+	}
+
 	date, err := parseDate(*dateStr)
 	if err != nil {
 		return err
@@ -179,19 +329,53 @@ func runJournalAdd(args []string, svc *journalapp.Service, out io.Writer, errOut
 		return err
 	}
 
-	fmt.Fprintf(out, "journaled %s reflections=%d mood=%s\n", entry.Date.Format("2006-01-02"), len(entry.Reflections), entry.Mood)
-	return nil
+	return writeJournaled(out, format, entry)
 }
 
-func runJournalGuided(args []string, svc *journalapp.Service, in io.Reader, out io.Writer, errOut io.Writer) error {
+func runJournalAddJSON(path string, svc *journalapp.Service, format outputFormat, in io.Reader, out io.Writer) error {
+	source := in
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("open entry file: %w", err)
+		}
+		defer func() {
+			_ = file.Close()
+		}()
+		source = file
+	}
+
+	var record schema.Entry
+	decoder := json.NewDecoder(source)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&record); err != nil {
+		return fmt.Errorf("decode entry: %w", err)
+	}
+
+	entry, err := record.ToEntry(time.Now())
+	if err != nil {
+		return err
+	}
+	entry, err = svc.SaveEntry(context.Background(), entry)
+	if err != nil {
+		return err
+	}
+
+	return writeJournaled(out, format, entry)
+}
+
+func runJournalGuided(args []string, svc *journalapp.Service, adherenceSvc *adherenceapp.Service, format outputFormat, in io.Reader, out io.Writer, errOut io.Writer) error {
 	fs := flag.NewFlagSet("journal guided", flag.ContinueOnError)
 	fs.SetOutput(errOut)
 	noConfirm := fs.Bool("no-confirm", false, "save without confirmation")
+	resurface := fs.Bool("resurface", false, "show a past reflection on each precept before writing about it")
 	if err := fs.Parse(args); err != nil {
 		return err
 	}
 
 	reader := bufio.NewReader(in)
+	journalOut := out
+	out = promptWriter(format, out, errOut)
 	dateInput, err := prompt(reader, out, "Date (YYYY-MM-DD, default today): ")
 	if err != nil {
 		return err
@@ -201,7 +385,7 @@ func runJournalGuided(args []string, svc *journalapp.Service, in io.Reader, out
 		return err
 	}
 
-	mood, err := prompt(reader, out, "Mood (optional): ")
+	mood, err := promptMood(reader, out, svc.Moods())
 	if err != nil {
 		return err
 	}
@@ -214,26 +398,60 @@ func runJournalGuided(args []string, svc *journalapp.Service, in io.Reader, out
 	if err != nil {
 		return err
 	}
+	vedana, err := promptVedanaFor(reader, out, foundation)
+	if err != nil {
+		return err
+	}
+	questions, err := svc.Questions(context.Background(), date, foundation)
+	if err != nil {
+		return err
+	}
+	var resurfacing journalapp.Resurfacing
+	if *resurface {
+		if resurfacing, err = svc.Resurface(context.Background(), date, 0); err != nil {
+			return err
+		}
+	}
+	shown := make(map[string]journal.Entry)
+	log, err := adherenceSvc.Log(context.Background())
+	if err != nil {
+		return err
+	}
+	nudges, err := svc.Nudges(context.Background(), date, log)
+	if err != nil {
+		return err
+	}
 
 	reflections := make(map[journal.Precept]string)
-	for _, info := range journal.AllPrecepts() {
-		question := fmt.Sprintf("%s reflection (optional): ", info.Title)
-		reflection, err := prompt(reader, out, question)
+	for _, nudge := range nudges {
+		if nudge.Reason != "" {
+			fmt.Fprintf(out, "* %s\n", nudge.Reason)
+		}
+		resurfaceReflection(out, resurfacing, nudge.Precept, shown)
+		if question, ok := questions[nudge.Precept]; ok {
+			fmt.Fprintln(out, question)
+		}
+		label := fmt.Sprintf("%s reflection (optional): ", preceptTitle(nudge.Precept))
+		reflection, err := prompt(reader, out, label)
 		if err != nil {
 			return err
 		}
 		reflection = strings.TrimSpace(reflection)
 		if reflection != "" {
-			reflections[info.ID] = reflection
+			reflections[nudge.Precept] = reflection
 		}
 	}
 
+	if err := markShown(svc, shown); err != nil {
+		return err
+	}
+
 	if strings.TrimSpace(note) == "" && len(reflections) == 0 {
 		return journal.ErrEmptyEntry
 	}
 
 	if !*noConfirm {
-		printGuidedSummary(out, date, mood, note, reflections, foundation)
+		printGuidedSummary(out, date, mood, note, reflections, foundation, vedana)
 		confirm, err := prompt(reader, out, "Save? (y/n): ")
 		if err != nil {
 			return err
@@ -244,31 +462,37 @@ func runJournalGuided(args []string, svc *journalapp.Service, in io.Reader, out
 		}
 	}
 
-	entry, err := svc.RecordEntry(context.Background(), date, reflections, note, mood, foundation)
+	opts := append(vedanaOptions(vedana), journal.WithQuestions(answeredQuestions(questions, reflections)))
+	entry, err := svc.RecordEntry(context.Background(), date, reflections, note, mood, foundation, opts...)
 	if err != nil {
 		return err
 	}
 
-	fmt.Fprintf(out, "journaled %s reflections=%d mood=%s\n", entry.Date.Format("2006-01-02"), len(entry.Reflections), entry.Mood)
-	return nil
+	return writeJournaled(journalOut, format, entry)
 }
 
-func runJournalLatest(svc *journalapp.Service, out io.Writer) error {
+func runJournalLatest(svc *journalapp.Service, format outputFormat, out io.Writer) error {
 	entry, err := svc.LatestEntry(context.Background())
 	if err != nil {
 		return err
 	}
 
+	if format != formatText {
+		return writeObject(out, format, schema.FromEntry(*entry))
+	}
 	fmt.Fprintf(out, "latest %s reflections=%d mood=%s\n", entry.Date.Format("2006-01-02"), len(entry.Reflections), entry.Mood)
 	return nil
 }
 
-func runJournalList(svc *journalapp.Service, out io.Writer) error {
+func runJournalList(svc *journalapp.Service, format outputFormat, out io.Writer) error {
 	entries, err := svc.ListEntries(context.Background())
 	if err != nil {
 		return err
 	}
 
+	if format != formatText {
+		return writeList(out, format, schema.FromEntries(entries))
+	}
 	if len(entries) == 0 {
 		fmt.Fprintln(out, "no entries yet")
 		return nil
@@ -280,7 +504,7 @@ func runJournalList(svc *journalapp.Service, out io.Writer) error {
 	return nil
 }
 
-func runAdherenceGuided(args []string, svc *adherenceapp.Service, in io.Reader, out io.Writer, errOut io.Writer) error {
+func runAdherenceGuided(args []string, svc *adherenceapp.Service, format outputFormat, in io.Reader, out io.Writer, errOut io.Writer) error {
 	fs := flag.NewFlagSet("adherence guided", flag.ContinueOnError)
 	fs.SetOutput(errOut)
 	noConfirm := fs.Bool("no-confirm", false, "save without confirmation")
@@ -288,14 +512,68 @@ func runAdherenceGuided(args []string, svc *adherenceapp.Service, in io.Reader,
 		return err
 	}
 
+	resultOut := out
+	out = promptWriter(format, out, errOut)
+
 	current, err := svc.Current(context.Background())
 	if err != nil {
 		return err
 	}
 
 	reader := bufio.NewReader(in)
+	next, notes, reflections, err := promptAdherence(reader, out, svc, current)
+	if err != nil {
+		return err
+	}
+
+	if !*noConfirm {
+		printAdherenceSummary(out, current, next, notes)
+		confirm, err := prompt(reader, out, "Save? (y/n): ")
+		if err != nil {
+			return err
+		}
+		if !isYes(confirm) {
+			fmt.Fprintln(out, "not saved")
+			return nil
+		}
+	}
+
+	changes, err := svc.Set(context.Background(), next, notes)
+	if err != nil {
+		return err
+	}
+	records, err := beginAnew(svc, changes, reflections)
+	if err != nil {
+		return err
+	}
+
+	if format != formatText {
+		updated, err := svc.Current(context.Background())
+		if err != nil {
+			return err
+		}
+		update := schema.AdherenceUpdate{
+			Adherence: schema.FromAdherence(updated),
+			Changes:   schema.FromLogEntries(changes),
+		}
+		for _, record := range records {
+			update.BeginningAnew = append(update.BeginningAnew, schema.FromBeginningAnew(record))
+		}
+		return writeObject(resultOut, format, update)
+	}
+	fmt.Fprintln(out, "adherence updated")
+	for _, record := range records {
+		fmt.Fprintf(out, "Beginning Anew started for %s; renew it with `mt adherence renew %s`\n", preceptTitle(record.Precept), record.Precept)
+	}
+	return nil
+}
+
+// promptAdherence asks whether each precept is kept, taking a note for every
+// change and offering Beginning Anew for breaks when svc can record it.
+func promptAdherence(reader *bufio.Reader, out io.Writer, svc *adherenceapp.Service, current adherencedomain.Adherence) (adherencedomain.Adherence, map[journal.Precept]string, map[journal.Precept]adherencedomain.BeginningAnew, error) {
 	next := make(adherencedomain.Adherence, len(current))
 	notes := make(map[journal.Precept]string)
+	reflections := make(map[journal.Precept]adherencedomain.BeginningAnew)
 
 	for _, info := range journal.AllPrecepts() {
 		currentValue := current[info.ID]
@@ -306,43 +584,78 @@ func runAdherenceGuided(args []string, svc *adherenceapp.Service, in io.Reader,
 		)
 		answer, err := prompt(reader, out, question)
 		if err != nil {
-			return err
+			return nil, nil, nil, err
 		}
 		value, err := parseYesNoDefault(answer, currentValue)
 		if err != nil {
-			return err
+			return nil, nil, nil, err
 		}
 		next[info.ID] = value
 
 		if value != currentValue {
 			note, err := prompt(reader, out, fmt.Sprintf("Note for %s (optional): ", info.Title))
 			if err != nil {
-				return err
+				return nil, nil, nil, err
 			}
 			note = strings.TrimSpace(note)
 			if note != "" {
 				notes[info.ID] = note
 			}
 		}
-	}
 
-	if !*noConfirm {
-		printAdherenceSummary(out, current, next, notes)
-		confirm, err := prompt(reader, out, "Save? (y/n): ")
-		if err != nil {
-			return err
-		}
-		if !isYes(confirm) {
-			fmt.Fprintln(out, "not saved")
-			return nil
+		if currentValue && !value && svc.BeginningAnewEnabled() {
+			answer, err := prompt(reader, out, fmt.Sprintf("Begin anew with %s now? (y/n, default n): ", info.Title))
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			if isYes(answer) {
+				reflection, err := promptBeginningAnew(reader, out, adherencedomain.BeginningAnew{})
+				if err != nil {
+					return nil, nil, nil, err
+				}
+				reflections[info.ID] = reflection
+			}
 		}
 	}
+	return next, notes, reflections, nil
+}
 
-	if err := svc.Set(context.Background(), next, notes); err != nil {
+func runAdherenceStatus(svc *adherenceapp.Service, format outputFormat, out io.Writer) error {
+	current, err := svc.Current(context.Background())
+	if err != nil {
 		return err
 	}
 
-	fmt.Fprintln(out, "adherence updated")
+	if format != formatText {
+		return writeObject(out, format, schema.FromAdherence(current))
+	}
+	for _, info := range journal.AllPrecepts() {
+		fmt.Fprintf(out, "%s: %s\n", info.Title, yesNoLabel(current[info.ID]))
+	}
+	return nil
+}
+
+func runAdherenceRebuild(svc *adherenceapp.Service, format outputFormat, out io.Writer) error {
+	state, events, err := svc.Rebuild(context.Background())
+	if err != nil {
+		return err
+	}
+
+	if format != formatText {
+		return writeObject(out, format, schema.AdherenceRebuild{Adherence: schema.FromAdherence(state), Events: events})
+	}
+	fmt.Fprintf(out, "rebuilt adherence state from %d log event(s)\n", events)
+	for _, info := range journal.AllPrecepts() {
+		fmt.Fprintf(out, "%s: %s\n", info.Title, yesNoLabel(state[info.ID]))
+	}
+	return nil
+}
+
+func writeJournaled(out io.Writer, format outputFormat, entry journal.Entry) error {
+	if format != formatText {
+		return writeObject(out, format, schema.FromEntry(entry))
+	}
+	fmt.Fprintf(out, "journaled %s reflections=%d mood=%s\n", entry.Date.Format("2006-01-02"), len(entry.Reflections), entry.Mood)
 	return nil
 }
 
@@ -355,7 +668,7 @@ func prompt(reader *bufio.Reader, out io.Writer, label string) (string, error) {
 	return strings.TrimSpace(line), nil
 }
 
-func printGuidedSummary(out io.Writer, date time.Time, mood string, note string, reflections map[journal.Precept]string, foundation journal.Foundation) {
+func printGuidedSummary(out io.Writer, date time.Time, mood string, note string, reflections map[journal.Precept]string, foundation journal.Foundation, vedana *journal.Vedana) {
 	fmt.Fprintln(out, "Summary:")
 	fmt.Fprintf(out, "Date: %s\n", date.Format("2006-01-02"))
 	if strings.TrimSpace(mood) != "" {
@@ -365,6 +678,9 @@ func printGuidedSummary(out io.Writer, date time.Time, mood string, note string,
 		fmt.Fprintf(out, "Note: %s\n", strings.TrimSpace(note))
 	}
 	fmt.Fprintf(out, "Foundation: %s\n", journal.FoundationLabel(foundation))
+	if vedana != nil {
+		fmt.Fprintf(out, "Feeling tone: %s\n", vedanaLabel(*vedana))
+	}
 	for _, info := range journal.AllPrecepts() {
 		if reflection, ok := reflections[info.ID]; ok {
 			fmt.Fprintf(out, "%s: %s\n", info.Title, reflection)
@@ -372,18 +688,34 @@ func printGuidedSummary(out io.Writer, date time.Time, mood string, note string,
 	}
 }
 
+// promptFoundation asks for a foundation, or an object within one such as
+// d.hindrances; "?" lists the objects.
 func promptFoundation(reader *bufio.Reader, out io.Writer) (journal.Foundation, error) {
 	fmt.Fprintln(out, "Note: Dhamma is most important.")
 	for {
-		input, err := prompt(reader, out, "Foundation (k/v/c/d) [default d]: ")
+		input, err := prompt(reader, out, "Foundation (k/v/c/d, or an object such as d.hindrances; ? lists them) [default d]: ")
 		if err != nil {
 			return "", err
 		}
+		if input == "?" {
+			printFoundationObjects(out)
+			continue
+		}
 		foundation, err := journal.ParseFoundation(input)
 		if err == nil {
 			return foundation, nil
 		}
-		fmt.Fprintln(out, "Please enter k, v, c, or d.")
+		fmt.Fprintln(out, "Please enter k, v, c, or d, optionally followed by an object (? lists them).")
+	}
+}
+
+func printFoundationObjects(out io.Writer) {
+	for _, foundation := range journal.Foundations() {
+		names := make([]string, 0)
+		for _, object := range journal.FoundationObjects(foundation) {
+			names = append(names, object.ID.Object())
+		}
+		fmt.Fprintf(out, "  %s (%s): %s\n", journal.FoundationLabel(foundation), string(foundation)[:1], strings.Join(names, ", "))
 	}
 }
 
@@ -448,13 +780,39 @@ func printUsage(out io.Writer) {
 	fmt.Fprintln(out, "mindfulness (mt) - daily precept journal")
 	fmt.Fprintln(out, "")
 	fmt.Fprintln(out, "Usage:")
+	fmt.Fprintln(out, "  mt [--format text|json|jsonl] <command>")
+	fmt.Fprintln(out, "")
 	fmt.Fprintln(out, "  mt journal add --date=YYYY-MM-DD --note=\"...\" --mood=... \\")
 	fmt.Fprintln(out, "    --reverence=\"...\" --happiness=\"...\" --love=\"...\" --speech=\"...\" --nourishment=\"...\"")
-	fmt.Fprintln(out, "  mt journal guided")
+	fmt.Fprintln(out, "  mt journal add --json -")
+	fmt.Fprintln(out, "  mt journal guided [--resurface]")
 	fmt.Fprintln(out, "  mt journal latest")
 	fmt.Fprintln(out, "  mt journal list")
+	fmt.Fprintln(out, "  mt journal show [YYYY-MM-DD|today|latest]")
+	fmt.Fprintln(out, "  mt journal resurface [--date YYYY-MM-DD] [--limit N] [--peek]")
 	fmt.Fprintln(out, "  mt quicknote")
 	fmt.Fprintln(out, "  mt adherence guided")
+	fmt.Fprintln(out, "  mt adherence status")
+	fmt.Fprintln(out, "  mt adherence rebuild")
+	fmt.Fprintln(out, "  mt adherence log")
+	fmt.Fprintln(out, "  mt adherence log archive")
+	fmt.Fprintln(out, "  mt adherence renew <precept> [--acknowledge=...] [--regret=...] [--intention=...]")
+	fmt.Fprintln(out, "  mt adherence breaks")
+	fmt.Fprintln(out, "  mt checkin [--no-confirm]")
+	fmt.Fprintln(out, "  mt stats [--since YYYY-MM-DD] [--until YYYY-MM-DD]")
+	fmt.Fprintln(out, "  mt mood trend [--since YYYY-MM-DD] [--until YYYY-MM-DD] [--by day|week]")
+	fmt.Fprintln(out, "  mt mood migrate [--dry-run]")
+	fmt.Fprintln(out, "  mt vedana [--since YYYY-MM-DD] [--until YYYY-MM-DD] [--by day|week]")
+	fmt.Fprintln(out, "  mt review week|month|year [--date YYYY-MM-DD] [--as text|markdown|html] [--no-prompt]")
+	fmt.Fprintln(out, "  mt report [--template FILE | --builtin NAME] [--html] [--since YYYY-MM-DD] [--until YYYY-MM-DD]")
+	fmt.Fprintln(out, "  mt calendar [--year YYYY | --month YYYY-MM] [--by entries|reflections] [--color auto|always|never]")
+	fmt.Fprintln(out, "  mt serve [--listen 127.0.0.1:8080] [--multi-user]")
+	fmt.Fprintln(out, "  mt user add <name> | list | revoke <name>")
+	fmt.Fprintln(out, "  mt sync merge [file...]")
+	fmt.Fprintln(out, "  mt sync push|pull")
+	fmt.Fprintln(out, "  mt sync remote [--url URL] [--username NAME] [--password-stdin]")
+	fmt.Fprintln(out, "  mt web [--listen 127.0.0.1:8081]")
+	fmt.Fprintln(out, "  mt doctor [--repair]")
 	fmt.Fprintln(out, "  mt version")
 }
 
@@ -462,12 +820,21 @@ func printJournalUsage(out io.Writer) {
 	fmt.Fprintln(out, "Usage:")
 	fmt.Fprintln(out, "  mt journal add --date=YYYY-MM-DD --note=\"...\" --mood=... \\")
 	fmt.Fprintln(out, "    --reverence=\"...\" --happiness=\"...\" --love=\"...\" --speech=\"...\" --nourishment=\"...\"")
-	fmt.Fprintln(out, "  mt journal guided")
+	fmt.Fprintln(out, "  mt journal add --json -")
+	fmt.Fprintln(out, "  mt journal guided [--resurface]")
 	fmt.Fprintln(out, "  mt journal latest")
 	fmt.Fprintln(out, "  mt journal list")
+	fmt.Fprintln(out, "  mt journal show [YYYY-MM-DD|today|latest]")
+	fmt.Fprintln(out, "  mt journal resurface [--date YYYY-MM-DD] [--limit N] [--peek]")
 }
 
 func printAdherenceUsage(out io.Writer) {
 	fmt.Fprintln(out, "Usage:")
 	fmt.Fprintln(out, "  mt adherence guided")
+	fmt.Fprintln(out, "  mt adherence status")
+	fmt.Fprintln(out, "  mt adherence rebuild")
+	fmt.Fprintln(out, "  mt adherence log")
+	fmt.Fprintln(out, "  mt adherence log archive")
+	fmt.Fprintln(out, "  mt adherence renew <precept> [--acknowledge=...] [--regret=...] [--intention=...]")
+	fmt.Fprintln(out, "  mt adherence breaks")
 }
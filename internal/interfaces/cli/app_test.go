@@ -5,6 +5,8 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -60,7 +62,7 @@ func TestRunJournalAdd(t *testing.T) {
 			var out bytes.Buffer
 			var errOut bytes.Buffer
 
-			err := runJournalAdd(tt.args, svc, &out, &errOut)
+			err := runJournalAdd(tt.args, svc, formatText, strings.NewReader(""), &out, &errOut)
 			switch {
 			case tt.wantErr != nil:
 				if !errors.Is(err, tt.wantErr) {
@@ -101,7 +103,7 @@ func TestRunJournalLatest(t *testing.T) {
 				err := runJournalAdd([]string{
 					"--date=2024-01-02",
 					"--note=steady day",
-				}, svc, &out, &errOut)
+				}, svc, formatText, strings.NewReader(""), &out, &errOut)
 				if err != nil {
 					t.Fatalf("unexpected setup error: %v", err)
 				}
@@ -119,7 +121,7 @@ func TestRunJournalLatest(t *testing.T) {
 			}
 
 			var out bytes.Buffer
-			err := runJournalLatest(svc, &out)
+			err := runJournalLatest(svc, formatText, &out)
 			if tt.wantErr != nil {
 				if !errors.Is(err, tt.wantErr) {
 					t.Fatalf("expected %v, got %v", tt.wantErr, err)
@@ -157,11 +159,11 @@ func TestRunJournalList(t *testing.T) {
 			setup: func(t *testing.T, svc *journalapp.Service) {
 				var out bytes.Buffer
 				var errOut bytes.Buffer
-				if err := runJournalAdd([]string{"--date=2024-01-01", "--note=steady"}, svc, &out, &errOut); err != nil {
+				if err := runJournalAdd([]string{"--date=2024-01-01", "--note=steady"}, svc, formatText, strings.NewReader(""), &out, &errOut); err != nil {
 					t.Fatalf("unexpected setup error: %v", err)
 				}
 				out.Reset()
-				if err := runJournalAdd([]string{"--date=2024-01-03", "--note=focused"}, svc, &out, &errOut); err != nil {
+				if err := runJournalAdd([]string{"--date=2024-01-03", "--note=focused"}, svc, formatText, strings.NewReader(""), &out, &errOut); err != nil {
 					t.Fatalf("unexpected setup error: %v", err)
 				}
 			},
@@ -185,7 +187,7 @@ func TestRunJournalList(t *testing.T) {
 			}
 
 			var out bytes.Buffer
-			err := runJournalList(svc, &out)
+			err := runJournalList(svc, formatText, &out)
 			if tt.wantErrAny {
 				if err == nil {
 					t.Fatalf("expected error")
@@ -288,6 +290,16 @@ func TestRunTopLevel(t *testing.T) {
 			args:            []string{"mt", "journal", "list"},
 			wantOutContains: "no entries yet",
 		},
+		{
+			name:            "journal list json",
+			args:            []string{"mt", "--format", "json", "journal", "list"},
+			wantOutContains: "[]",
+		},
+		{
+			name:    "invalid format",
+			args:    []string{"mt", "--format=xml", "journal", "list"},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -316,6 +328,45 @@ func TestRunTopLevel(t *testing.T) {
 	}
 }
 
+func TestRunLeavesDataFilesAloneForCommandsWithoutData(t *testing.T) {
+	dataHome := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dataHome)
+	dir := filepath.Join(dataHome, "mt")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	walPath := filepath.Join(dir, "pending.wal.json")
+	for path, data := range map[string]string{
+		walPath:                            `{"journal_append": [`,
+		filepath.Join(dir, "journal.json"): "[]",
+		filepath.Join(dir, "journal.sync-conflict-20240105-101500-ABCDEFG.json"): "[]",
+	} {
+		if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+
+	for _, args := range [][]string{{"mt", "version"}, {"mt", "help"}, {"mt", "--format", "json", "version"}} {
+		var out, errOut bytes.Buffer
+		if err := Run(args, &out, &errOut); err != nil {
+			t.Fatalf("%v: unexpected error: %v", args, err)
+		}
+		if errOut.Len() != 0 {
+			t.Fatalf("%v: expected no warnings, got %q", args, errOut.String())
+		}
+	}
+	if err := Run([]string{"mt", "unknown"}, &bytes.Buffer{}, &bytes.Buffer{}); err == nil || !strings.Contains(err.Error(), "unknown command") {
+		t.Fatalf("expected an unknown command error, got %v", err)
+	}
+	if data, err := os.ReadFile(walPath); err != nil || string(data) != `{"journal_append": [` {
+		t.Fatalf("expected the write-ahead record to be left alone, got %q, %v", data, err)
+	}
+
+	if err := Run([]string{"mt", "journal", "list"}, &bytes.Buffer{}, &bytes.Buffer{}); err == nil {
+		t.Fatal("expected commands that read the data to open it")
+	}
+}
+
 func TestRunJournalCommands(t *testing.T) {
 	tests := []struct {
 		name              string
@@ -350,7 +401,7 @@ func TestRunJournalCommands(t *testing.T) {
 			var out bytes.Buffer
 			var errOut bytes.Buffer
 
-			err := runJournal(tt.args, svc, strings.NewReader(""), &out, &errOut)
+			err := runJournal(tt.args, svc, adherenceapp.NewService(memory.NewAdherenceRepository()), formatText, strings.NewReader(""), &out, &errOut)
 			if tt.wantErr {
 				if err == nil {
 					t.Fatalf("expected error")
@@ -484,7 +535,7 @@ func TestRunJournalGuided(t *testing.T) {
 			var out bytes.Buffer
 			var errOut bytes.Buffer
 
-			err := runJournalGuided(tt.args, svc, newInput(tt.input...), &out, &errOut)
+			err := runJournalGuided(tt.args, svc, adherenceapp.NewService(memory.NewAdherenceRepository()), formatText, newInput(tt.input...), &out, &errOut)
 			switch {
 			case tt.wantErr != nil:
 				if !errors.Is(err, tt.wantErr) {
@@ -549,7 +600,7 @@ func TestRunJournalRoutes(t *testing.T) {
 			setup: func(t *testing.T, svc *journalapp.Service) {
 				var out bytes.Buffer
 				var errOut bytes.Buffer
-				if err := runJournalAdd([]string{"--date=2024-01-08", "--note=steady"}, svc, &out, &errOut); err != nil {
+				if err := runJournalAdd([]string{"--date=2024-01-08", "--note=steady"}, svc, formatText, strings.NewReader(""), &out, &errOut); err != nil {
 					t.Fatalf("unexpected setup error: %v", err)
 				}
 			},
@@ -567,7 +618,7 @@ func TestRunJournalRoutes(t *testing.T) {
 
 			var out bytes.Buffer
 			var errOut bytes.Buffer
-			err := runJournal(tt.args, svc, newInput(tt.input...), &out, &errOut)
+			err := runJournal(tt.args, svc, adherenceapp.NewService(memory.NewAdherenceRepository()), formatText, newInput(tt.input...), &out, &errOut)
 			if err != nil {
 				t.Fatalf("unexpected error: %v", err)
 			}
@@ -634,7 +685,7 @@ func TestRunAdherence(t *testing.T) {
 			name: "requires subcommand",
 			args: []string{},
 			run: func(args []string, svc *adherenceapp.Service, input *strings.Reader, out, errOut *bytes.Buffer) error {
-				return runAdherence(args, svc, input, out, errOut)
+				return runAdherence(args, svc, formatText, input, out, errOut)
 			},
 			wantErr:           true,
 			wantErrOutContain: "Usage:",
@@ -651,7 +702,7 @@ func TestRunAdherence(t *testing.T) {
 				"",
 			},
 			run: func(args []string, svc *adherenceapp.Service, input *strings.Reader, out, errOut *bytes.Buffer) error {
-				return runAdherenceGuided(args, svc, input, out, errOut)
+				return runAdherenceGuided(args, svc, formatText, input, out, errOut)
 			},
 			wantOutContains: "adherence updated",
 			verify: func(t *testing.T, svc *adherenceapp.Service) {
@@ -677,7 +728,7 @@ func TestRunAdherence(t *testing.T) {
 				"n",
 			},
 			run: func(args []string, svc *adherenceapp.Service, input *strings.Reader, out, errOut *bytes.Buffer) error {
-				return runAdherenceGuided(args, svc, input, out, errOut)
+				return runAdherenceGuided(args, svc, formatText, input, out, errOut)
 			},
 			wantOutContains: "not saved",
 			verify: func(t *testing.T, svc *adherenceapp.Service) {
@@ -744,13 +795,16 @@ func TestRunQuicknote(t *testing.T) {
 			var out bytes.Buffer
 			var errOut bytes.Buffer
 
-			err := runQuicknote([]string{}, svc, newInput(tt.input...), &out, &errOut)
+			err := runQuicknote([]string{}, svc, formatText, newInput(tt.input...), &out, &errOut)
 			if err != nil {
 				t.Fatalf("unexpected error: %v", err)
 			}
 			if !strings.Contains(out.String(), tt.wantOutContains+" "+today.Format("2006-01-02")) {
 				t.Fatalf("unexpected output: %s", out.String())
 			}
+			if latest, err := repo.Latest(context.Background()); err != nil || !latest.Quicknote {
+				t.Fatalf("expected the entry to be marked as a quicknote, got %+v, %v", latest, err)
+			}
 		})
 	}
 }
@@ -769,6 +823,10 @@ func (errorRepo) List(_ context.Context) ([]journal.Entry, error) {
 	return nil, errors.New("list failed")
 }
 
+func (errorRepo) Replace(_ context.Context, _ []journal.Entry) error {
+	return errors.New("replace failed")
+}
+
 type errorReader struct{}
 
 func (errorReader) Read(_ []byte) (int, error) {
@@ -0,0 +1,183 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	adherenceapp "github.com/thatnerdjosh/mindfulness/internal/application/adherence"
+	adherencedomain "github.com/thatnerdjosh/mindfulness/internal/domain/adherence"
+	"github.com/thatnerdjosh/mindfulness/internal/domain/journal"
+	"github.com/thatnerdjosh/mindfulness/internal/interfaces/schema"
+)
+
+// promptBeginningAnew walks through the reflective steps of Beginning Anew
+// that done has not answered yet. Every answer is optional.
+func promptBeginningAnew(reader *bufio.Reader, out io.Writer, done adherencedomain.BeginningAnew) (adherencedomain.BeginningAnew, error) {
+	var reflection adherencedomain.BeginningAnew
+	steps := []struct {
+		label string
+		done  string
+		field *string
+	}{
+		{label: "Acknowledge what happened: ", done: done.Acknowledgement, field: &reflection.Acknowledgement},
+		{label: "Express regret: ", done: done.Regret, field: &reflection.Regret},
+		{label: "Set an intention: ", done: done.Intention, field: &reflection.Intention},
+	}
+	for _, step := range steps {
+		if step.done != "" {
+			continue
+		}
+		answer, err := prompt(reader, out, step.label)
+		if err != nil {
+			return adherencedomain.BeginningAnew{}, err
+		}
+		*step.field = answer
+	}
+	return reflection, nil
+}
+
+// beginAnew stores the reflections gathered during guided mode against the
+// breaks that were just logged.
+func beginAnew(svc *adherenceapp.Service, changes []adherencedomain.AdherenceLogEntry, reflections map[journal.Precept]adherencedomain.BeginningAnew) ([]adherencedomain.BeginningAnew, error) {
+	var records []adherencedomain.BeginningAnew
+	for _, change := range changes {
+		reflection, ok := reflections[change.Precept]
+		if !ok || !adherencedomain.IsBreak(change) {
+			continue
+		}
+		record, err := svc.BeginAnew(context.Background(), change, reflection)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+func runAdherenceRenew(args []string, svc *adherenceapp.Service, format outputFormat, in io.Reader, out io.Writer, errOut io.Writer) error {
+	fs := flag.NewFlagSet("adherence renew", flag.ContinueOnError)
+	fs.SetOutput(errOut)
+	acknowledgement := fs.String("acknowledge", "", "what happened")
+	regret := fs.String("regret", "", "the regret to express")
+	intention := fs.String("intention", "", "the intention to set")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	// Accept flags on either side of the precept.
+	rest := fs.Args()
+	if len(rest) > 0 {
+		if err := fs.Parse(rest[1:]); err != nil {
+			return err
+		}
+	}
+	if len(rest) == 0 || fs.NArg() > 0 {
+		fmt.Fprintln(errOut, "Usage: mt adherence renew <precept> [--acknowledge=...] [--regret=...] [--intention=...]")
+		return fmt.Errorf("adherence renew takes one precept")
+	}
+	precept := journal.Precept(strings.TrimSpace(rest[0]))
+	if !journal.IsKnownPrecept(precept) {
+		var ids []string
+		for _, info := range journal.AllPrecepts() {
+			ids = append(ids, string(info.ID))
+		}
+		return fmt.Errorf("%w: %s (expected one of %s)", journal.ErrUnknownPrecept, precept, strings.Join(ids, ", "))
+	}
+
+	open, err := svc.OpenBreaks(context.Background())
+	if err != nil {
+		return err
+	}
+	var started *adherencedomain.BeginningAnew
+	for _, item := range open {
+		if item.Event.Precept == precept {
+			started = &adherencedomain.BeginningAnew{}
+			if item.Record != nil {
+				started = item.Record
+			}
+		}
+	}
+	if started == nil {
+		return fmt.Errorf("%w for %s", adherencedomain.ErrNoOpenBreak, preceptTitle(precept))
+	}
+
+	reflection := adherencedomain.BeginningAnew{Acknowledgement: *acknowledgement, Regret: *regret, Intention: *intention}
+	if reflection == (adherencedomain.BeginningAnew{}) {
+		reflection, err = promptBeginningAnew(bufio.NewReader(in), promptWriter(format, out, errOut), *started)
+		if err != nil {
+			return err
+		}
+	}
+
+	record, changes, err := svc.Renew(context.Background(), precept, reflection)
+	if err != nil {
+		return err
+	}
+
+	if format != formatText {
+		updated, err := svc.Current(context.Background())
+		if err != nil {
+			return err
+		}
+		return writeObject(out, format, schema.Renewal{
+			Adherence:     schema.FromAdherence(updated),
+			BeginningAnew: schema.FromBeginningAnew(record),
+			Changes:       schema.FromLogEntries(changes),
+		})
+	}
+	if len(changes) > 0 {
+		fmt.Fprintf(out, "renewed %s; it is marked as kept again\n", preceptTitle(precept))
+	} else {
+		fmt.Fprintf(out, "renewed %s\n", preceptTitle(precept))
+	}
+	return nil
+}
+
+func runAdherenceBreaks(svc *adherenceapp.Service, format outputFormat, out io.Writer) error {
+	open, err := svc.OpenBreaks(context.Background())
+	if err != nil {
+		return err
+	}
+
+	if format != formatText {
+		list := make([]schema.OpenBreak, 0, len(open))
+		for _, item := range open {
+			list = append(list, schema.FromOpenBreak(item.Event, item.Record))
+		}
+		return writeList(out, format, list)
+	}
+	if len(open) == 0 {
+		fmt.Fprintln(out, "no open breaks")
+		return nil
+	}
+
+	var buf bytes.Buffer
+	width := terminalWidth()
+	now := time.Now()
+	for _, item := range open {
+		days := int(now.Sub(item.Event.At).Hours() / 24)
+		fmt.Fprintf(&buf, "%s  %s (open %d day(s))\n", item.Event.At.Local().Format("2006-01-02 15:04"), preceptTitle(item.Event.Precept), days)
+		if item.Event.Note != "" {
+			writeWrapped(&buf, item.Event.Note, "    ", width)
+		}
+		if item.Record == nil {
+			continue
+		}
+		for _, step := range []struct{ label, text string }{
+			{"Acknowledged", item.Record.Acknowledgement},
+			{"Regret", item.Record.Regret},
+			{"Intention", item.Record.Intention},
+		} {
+			if step.text != "" {
+				writeWrapped(&buf, step.label+": "+step.text, "    ", width)
+			}
+		}
+	}
+	fmt.Fprintln(&buf, "Renew a precept with `mt adherence renew <precept>`.")
+	return page(out, buf.Bytes())
+}
@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	adherencedomain "github.com/thatnerdjosh/mindfulness/internal/domain/adherence"
+	"github.com/thatnerdjosh/mindfulness/internal/domain/journal"
+	"github.com/thatnerdjosh/mindfulness/internal/interfaces/schema"
+)
+
+func TestRunAdherenceGuidedBeginsAnew(t *testing.T) {
+	svc := newTestData().offerBeginningAnew().adherence
+	var out bytes.Buffer
+	input := newInput("n", "snapped at a cyclist", "y", "I shouted", "I scared them", "slow down on the bike path", "", "", "", "")
+	if err := runAdherenceGuided([]string{"--no-confirm"}, svc, formatText, input, &out, &bytes.Buffer{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out.String(), "Begin anew with Reverence For Life now?") {
+		t.Fatalf("expected the Beginning Anew offer, got %q", out.String())
+	}
+	if !strings.Contains(out.String(), "mt adherence renew reverence-for-life") {
+		t.Fatalf("expected a hint to renew, got %q", out.String())
+	}
+
+	out.Reset()
+	if err := runAdherenceBreaks(svc, formatText, &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out.String(), "Reverence For Life") || !strings.Contains(out.String(), "Intention: slow down on the bike path") {
+		t.Fatalf("expected the open break with its record, got %q", out.String())
+	}
+
+	// Every step was answered in guided mode, so renewing asks nothing.
+	out.Reset()
+	if err := runAdherenceRenew([]string{"reverence-for-life"}, svc, formatText, newInput(), &out, &bytes.Buffer{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(out.String(), "Acknowledge") || !strings.Contains(out.String(), "marked as kept again") {
+		t.Fatalf("unexpected renew output: %q", out.String())
+	}
+	if state, _ := svc.Current(context.Background()); !state[journal.ReverenceForLife] {
+		t.Fatal("expected the precept to be kept again")
+	}
+
+	out.Reset()
+	if err := runAdherenceBreaks(svc, formatText, &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out.String(), "no open breaks") {
+		t.Fatalf("expected no open breaks, got %q", out.String())
+	}
+	err := runAdherenceRenew([]string{"reverence-for-life"}, svc, formatText, newInput(), &out, &bytes.Buffer{})
+	if !errors.Is(err, adherencedomain.ErrNoOpenBreak) {
+		t.Fatalf("expected ErrNoOpenBreak, got %v", err)
+	}
+}
+
+func TestRunAdherenceRenewJSON(t *testing.T) {
+	svc := newTestData().offerBeginningAnew().adherence
+	if _, err := svc.Set(context.Background(), adherencedomain.Adherence{journal.TrueLove: false}, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := runAdherenceBreaks(svc, formatJSONL, &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var open schema.OpenBreak
+	if err := json.Unmarshal(out.Bytes(), &open); err != nil {
+		t.Fatalf("expected a JSON line, got %q", out.String())
+	}
+	if open.Event.Precept != "true-love" || open.BeginningAnew != nil {
+		t.Fatalf("unexpected open break: %+v", open)
+	}
+
+	out.Reset()
+	if err := runAdherenceRenew([]string{"--intention=listen fully", "true-love"}, svc, formatJSON, newInput(), &out, &bytes.Buffer{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var renewal schema.Renewal
+	if err := json.Unmarshal(out.Bytes(), &renewal); err != nil {
+		t.Fatalf("expected JSON output, got %q", out.String())
+	}
+	if !renewal.Adherence["true-love"] || renewal.BeginningAnew.Intention != "listen fully" || renewal.BeginningAnew.RenewedAt == "" || len(renewal.Changes) != 1 {
+		t.Fatalf("unexpected renewal: %+v", renewal)
+	}
+
+	if err := runAdherenceRenew([]string{"kindness"}, svc, formatText, newInput(), &out, &bytes.Buffer{}); !errors.Is(err, journal.ErrUnknownPrecept) {
+		t.Fatalf("expected ErrUnknownPrecept, got %v", err)
+	}
+}
@@ -0,0 +1,312 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	adherenceapp "github.com/thatnerdjosh/mindfulness/internal/application/adherence"
+	analyticsapp "github.com/thatnerdjosh/mindfulness/internal/application/analytics"
+	journalapp "github.com/thatnerdjosh/mindfulness/internal/application/journal"
+	"github.com/thatnerdjosh/mindfulness/internal/interfaces/schema"
+)
+
+// calendarLabelWidth is the width of the weekday labels left of the grid.
+// Every cell after it is two columns: the intensity glyph and a marker.
+const calendarLabelWidth = 4
+
+var (
+	calendarGlyphs = []string{"·", "░", "▒", "▓", "█"}
+	// calendarColors are 256-color greens from empty to busiest.
+	calendarColors = []int{238, 22, 28, 34, 40}
+)
+
+const (
+	calendarChangeColor = 205
+	calendarStreakColor = 220
+)
+
+// calendarDay is what happened on one day of the calendar.
+type calendarDay struct {
+	entries     int
+	reflections int
+	changes     int
+	streak      bool
+}
+
+func runCalendar(args []string, svc *journalapp.Service, adherenceSvc *adherenceapp.Service, analytics *analyticsapp.Service, format outputFormat, out io.Writer, errOut io.Writer) error {
+	fs := flag.NewFlagSet("calendar", flag.ContinueOnError)
+	fs.SetOutput(errOut)
+	year := fs.String("year", "", "year to show (YYYY, default this year)")
+	month := fs.String("month", "", "month to show (YYYY-MM)")
+	by := fs.String("by", "entries", "what cell intensity counts: entries or reflections")
+	colorMode := fs.String("color", "auto", "color output: auto, always or never")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *by != "entries" && *by != "reflections" {
+		return fmt.Errorf("invalid --by %q: expected entries or reflections", *by)
+	}
+	color, err := useColor(*colorMode, out)
+	if err != nil {
+		return err
+	}
+
+	today, err := parseDate("")
+	if err != nil {
+		return err
+	}
+	start, end, title, err := calendarRange(*year, *month, today)
+	if err != nil {
+		return err
+	}
+
+	days, err := collectCalendar(svc, adherenceSvc, analytics, start, end)
+	if err != nil {
+		return err
+	}
+
+	if format != formatText {
+		list := []schema.CalendarDay{}
+		for day := start; !day.After(end) && !day.After(today); day = day.AddDate(0, 0, 1) {
+			info := days[day]
+			list = append(list, schema.CalendarDay{
+				Date:             day.Format("2006-01-02"),
+				Entries:          info.entries,
+				Reflections:      info.reflections,
+				AdherenceChanges: info.changes,
+				Streak:           info.streak,
+			})
+		}
+		return writeList(out, format, list)
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%s (%s per day)\n\n", title, *by)
+	renderCalendar(&buf, days, start, end, today, *by == "reflections", color, terminalWidth())
+	return page(out, buf.Bytes())
+}
+
+// calendarRange resolves --year or --month to the inclusive days they cover.
+func calendarRange(year string, month string, today time.Time) (time.Time, time.Time, string, error) {
+	switch {
+	case year != "" && month != "":
+		return time.Time{}, time.Time{}, "", fmt.Errorf("use either --year or --month")
+	case month != "":
+		start, err := time.Parse("2006-01", strings.TrimSpace(month))
+		if err != nil {
+			return time.Time{}, time.Time{}, "", fmt.Errorf("invalid month %q: expected YYYY-MM", month)
+		}
+		return start, start.AddDate(0, 1, -1), start.Format("January 2006"), nil
+	default:
+		value := today.Year()
+		if year != "" {
+			parsed, err := strconv.Atoi(strings.TrimSpace(year))
+			if err != nil || parsed < 1 {
+				return time.Time{}, time.Time{}, "", fmt.Errorf("invalid year %q: expected YYYY", year)
+			}
+			value = parsed
+		}
+		start := time.Date(value, time.January, 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(1, 0, -1), strconv.Itoa(value), nil
+	}
+}
+
+// collectCalendar gathers the entries, adherence changes and current streak
+// for the days from start to end.
+func collectCalendar(svc *journalapp.Service, adherenceSvc *adherenceapp.Service, analytics *analyticsapp.Service, start time.Time, end time.Time) (map[time.Time]calendarDay, error) {
+	ctx := context.Background()
+	days := make(map[time.Time]calendarDay)
+
+	entries, err := svc.QueryEntries(ctx, journalapp.Query{Since: start, Until: end})
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		day := dayOf(entry.Date)
+		info := days[day]
+		info.entries++
+		info.reflections += len(entry.Reflections)
+		days[day] = info
+	}
+
+	log, err := adherenceSvc.Log(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, change := range log {
+		day := dayOf(change.At)
+		if day.Before(start) || day.After(end) {
+			continue
+		}
+		info := days[day]
+		info.changes++
+		days[day] = info
+	}
+
+	stats, err := analytics.Stats(ctx, time.Time{}, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < stats.CurrentStreak; i++ {
+		day := stats.CurrentStreakEnd.AddDate(0, 0, -i)
+		info := days[day]
+		info.streak = true
+		days[day] = info
+	}
+	return days, nil
+}
+
+// renderCalendar draws one column per week and one row per weekday. Weeks
+// that do not fit in width wrap into further blocks below.
+func renderCalendar(out io.Writer, days map[time.Time]calendarDay, start time.Time, end time.Time, today time.Time, byReflections bool, color bool, width int) {
+	count := func(info calendarDay) int {
+		if byReflections {
+			return info.reflections
+		}
+		return info.entries
+	}
+	busiest := 0
+	for _, info := range days {
+		busiest = max(busiest, count(info))
+	}
+
+	var weeks []time.Time
+	offset := (int(start.Weekday()) + 6) % 7
+	for week := start.AddDate(0, 0, -offset); !week.After(end); week = week.AddDate(0, 0, 7) {
+		weeks = append(weeks, week)
+	}
+	perBlock := max(1, (width-calendarLabelWidth)/2)
+	last := end
+	if today.Before(last) {
+		last = today
+	}
+
+	for first := 0; first < len(weeks); first += perBlock {
+		block := weeks[first:min(first+perBlock, len(weeks))]
+		if first > 0 {
+			fmt.Fprintln(out)
+		}
+		fmt.Fprintln(out, calendarMonthLine(block, start, last))
+		for weekday := 0; weekday < 7; weekday++ {
+			label := ""
+			if weekday%2 == 0 && weekday < 6 {
+				label = block[0].AddDate(0, 0, weekday).Format("Mon")
+			}
+			line := fmt.Sprintf("%-*s", calendarLabelWidth, label)
+			for _, week := range block {
+				day := week.AddDate(0, 0, weekday)
+				if day.Before(start) || day.After(end) || day.After(today) {
+					line += "  "
+					continue
+				}
+				info := days[day]
+				line += calendarCell(calendarLevel(count(info), busiest), info, color)
+			}
+			fmt.Fprintln(out, strings.TrimRight(line, " "))
+		}
+	}
+
+	fmt.Fprintln(out)
+	legend := "Less "
+	for level := range calendarGlyphs {
+		legend += calendarCell(level, calendarDay{}, color)
+	}
+	fmt.Fprintln(out, legend+"More")
+	fmt.Fprintln(out, calendarPaint("*", calendarChangeColor, color)+" adherence change  "+calendarPaint("+", calendarStreakColor, color)+" current streak")
+}
+
+// calendarMonthLine labels each week of the block in which a month begins.
+// The first week is labelled with the month it shows when no month begins
+// in it or the week after, so every block says where it starts. Weeks after
+// last, the last day drawn, are left unlabelled, as are labels that would
+// run past the block.
+func calendarMonthLine(block []time.Time, start time.Time, last time.Time) string {
+	line := []rune(strings.Repeat(" ", calendarLabelWidth+2*len(block)))
+	for i, week := range block {
+		label := calendarMonthStart(week, start, last)
+		if i == 0 && label == "" && (len(block) == 1 || calendarMonthStart(block[1], start, last) == "") {
+			for weekday := 0; weekday < 7; weekday++ {
+				day := week.AddDate(0, 0, weekday)
+				if !day.Before(start) && !day.After(last) {
+					label = day.Format("Jan")
+					break
+				}
+			}
+		}
+		column := calendarLabelWidth + 2*i
+		if label != "" && column+3 <= len(line) {
+			copy(line[column:], []rune(label))
+		}
+	}
+	return strings.TrimRight(string(line), " ")
+}
+
+// calendarMonthStart names the month whose first day falls in week between
+// start and last, if any.
+func calendarMonthStart(week time.Time, start time.Time, last time.Time) string {
+	for weekday := 0; weekday < 7; weekday++ {
+		day := week.AddDate(0, 0, weekday)
+		if day.Day() == 1 && !day.Before(start) && !day.After(last) {
+			return day.Format("Jan")
+		}
+	}
+	return ""
+}
+
+// calendarLevel scales count against the busiest day to one of the glyphs.
+func calendarLevel(count int, busiest int) int {
+	if count <= 0 || busiest <= 0 {
+		return 0
+	}
+	last := len(calendarGlyphs) - 1
+	return min(last, (count*last+busiest-1)/busiest)
+}
+
+func calendarCell(level int, info calendarDay, color bool) string {
+	glyph := calendarGlyphs[level]
+	if color {
+		glyph = calendarPaint("■", calendarColors[level], true)
+	}
+	switch {
+	case info.changes > 0:
+		return glyph + calendarPaint("*", calendarChangeColor, color)
+	case info.streak:
+		return glyph + calendarPaint("+", calendarStreakColor, color)
+	default:
+		return glyph + " "
+	}
+}
+
+func calendarPaint(text string, code int, color bool) string {
+	if !color {
+		return text
+	}
+	return fmt.Sprintf("\x1b[38;5;%dm%s\x1b[0m", code, text)
+}
+
+// useColor resolves --color. auto colors a terminal unless NO_COLOR is set.
+func useColor(mode string, out io.Writer) (bool, error) {
+	switch mode {
+	case "always":
+		return true, nil
+	case "never":
+		return false, nil
+	case "auto":
+		file, ok := out.(*os.File)
+		return ok && isTerminal(file) && os.Getenv("NO_COLOR") == "", nil
+	default:
+		return false, fmt.Errorf("invalid --color %q: expected auto, always or never", mode)
+	}
+}
+
+func dayOf(at time.Time) time.Time {
+	utc := at.UTC()
+	return time.Date(utc.Year(), utc.Month(), utc.Day(), 0, 0, 0, 0, time.UTC)
+}
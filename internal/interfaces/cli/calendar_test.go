@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	adherencedomain "github.com/thatnerdjosh/mindfulness/internal/domain/adherence"
+	"github.com/thatnerdjosh/mindfulness/internal/domain/journal"
+	"github.com/thatnerdjosh/mindfulness/internal/interfaces/schema"
+)
+
+func mustDate(t *testing.T, value string) time.Time {
+	t.Helper()
+	parsed, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return parsed
+}
+
+func TestRenderCalendar(t *testing.T) {
+	days := map[time.Time]calendarDay{
+		mustDate(t, "2024-05-01"): {entries: 1},
+		mustDate(t, "2024-05-02"): {entries: 4, changes: 1},
+		mustDate(t, "2024-05-03"): {entries: 2, streak: true},
+	}
+	start, end := mustDate(t, "2024-05-01"), mustDate(t, "2024-05-31")
+
+	var out bytes.Buffer
+	renderCalendar(&out, days, start, end, mustDate(t, "2024-05-20"), false, false, 80)
+	lines := strings.Split(out.String(), "\n")
+	if lines[0] != "    May" {
+		t.Fatalf("expected the month label first, got %q", lines[0])
+	}
+	// May 2024 starts on a Wednesday, so the first column only has days
+	// from Wednesday on; days after today are left blank.
+	if lines[3] != "Wed ░ · ·" || lines[4] != "    █*· ·" || lines[5] != "Fri ▒+· ·" || lines[1] != "Mon   · · ·" {
+		t.Fatalf("unexpected grid:\n%s", out.String())
+	}
+	if strings.Contains(out.String(), "\x1b[") {
+		t.Fatal("expected no escape codes without color")
+	}
+
+	out.Reset()
+	renderCalendar(&out, days, start, end, mustDate(t, "2024-05-20"), false, true, 80)
+	if !strings.Contains(out.String(), "\x1b[38;5;40m■\x1b[0m\x1b[38;5;205m*\x1b[0m") {
+		t.Fatalf("expected a colored busiest cell with a change marker, got %q", out.String())
+	}
+}
+
+func TestRenderCalendarWraps(t *testing.T) {
+	start, end := mustDate(t, "2024-01-01"), mustDate(t, "2024-12-31")
+	var out bytes.Buffer
+	renderCalendar(&out, nil, start, end, end, false, false, 40)
+	for _, line := range strings.Split(out.String(), "\n") {
+		if len([]rune(line)) > 40 {
+			t.Fatalf("line wider than the terminal: %q", line)
+		}
+	}
+	if strings.Count(out.String(), "Mon ") != 3 {
+		t.Fatalf("expected 53 weeks to wrap into three blocks, got:\n%s", out.String())
+	}
+}
+
+func TestRunCalendarJSON(t *testing.T) {
+	today, _ := parseDate("")
+	d := newTestData().seed(t, []testEntry{{
+		date: today.Format("2006-01-02"), at: today, foundation: journal.FoundationDhamma,
+		reflections: map[journal.Precept]string{journal.TrueLove: "a", journal.ReverenceForLife: "b"},
+	}})
+	adherenceSvc := d.adherence
+	if _, err := adherenceSvc.Set(context.Background(), adherencedomain.Adherence{journal.TrueLove: false}, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var out bytes.Buffer
+	args := []string{"--month", today.Format("2006-01")}
+	if err := runCalendar(args, d.journal, adherenceSvc, d.analytics, formatJSON, &out, &bytes.Buffer{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var days []schema.CalendarDay
+	if err := json.Unmarshal(out.Bytes(), &days); err != nil {
+		t.Fatalf("expected a JSON array, got %q", out.String())
+	}
+	last := days[len(days)-1]
+	if len(days) != today.Day() || last.Date != today.Format("2006-01-02") || last.Entries != 1 || last.Reflections != 2 || last.AdherenceChanges != 1 || !last.Streak {
+		t.Fatalf("unexpected days: %+v", days)
+	}
+
+	if err := runCalendar([]string{"--year", "2024", "--month", "2024-01"}, d.journal, adherenceSvc, d.analytics, formatText, &out, &bytes.Buffer{}); err == nil {
+		t.Fatal("expected an error for --year with --month")
+	}
+}
+
+func TestRenderCalendarLabelsEveryMonth(t *testing.T) {
+	start, end := mustDate(t, "2026-01-01"), mustDate(t, "2026-12-31")
+	var out bytes.Buffer
+	renderCalendar(&out, nil, start, end, end, false, false, 80)
+	lines := strings.Split(out.String(), "\n")
+	// Oct 1 falls in the second column of the second block, so that block
+	// opens with it rather than with September.
+	if lines[0] != "    Jan     Feb     Mar       Apr     May       Jun     Jul     Aug       Sep" {
+		t.Fatalf("unexpected first label row %q", lines[0])
+	}
+	if lines[9] != "      Oct     Nov       Dec" {
+		t.Fatalf("unexpected second label row %q", lines[9])
+	}
+
+	out.Reset()
+	renderCalendar(&out, nil, start, end, mustDate(t, "2026-10-18"), false, false, 80)
+	if lines := strings.Split(out.String(), "\n"); lines[9] != "      Oct" {
+		t.Fatalf("expected no labels over future weeks, got %q", lines[9])
+	}
+}
@@ -0,0 +1,165 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	adherenceapp "github.com/thatnerdjosh/mindfulness/internal/application/adherence"
+	checkinapp "github.com/thatnerdjosh/mindfulness/internal/application/checkin"
+	adherencedomain "github.com/thatnerdjosh/mindfulness/internal/domain/adherence"
+	"github.com/thatnerdjosh/mindfulness/internal/domain/journal"
+	"github.com/thatnerdjosh/mindfulness/internal/interfaces/schema"
+)
+
+// runCheckin walks through adherence and the day's journal entry in one go
+// and stores both only after a single confirmation.
+func runCheckin(args []string, svc *checkinapp.Service, adherenceSvc *adherenceapp.Service, format outputFormat, in io.Reader, out io.Writer, errOut io.Writer) error {
+	fs := flag.NewFlagSet("checkin", flag.ContinueOnError)
+	fs.SetOutput(errOut)
+	noConfirm := fs.Bool("no-confirm", false, "save without confirmation")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	resultOut := out
+	out = promptWriter(format, out, errOut)
+
+	current, err := adherenceSvc.Current(context.Background())
+	if err != nil {
+		return err
+	}
+	date, err := parseDate("")
+	if err != nil {
+		return err
+	}
+
+	reader := bufio.NewReader(in)
+	next, notes, renewals, err := promptAdherence(reader, out, adherenceSvc, current)
+	if err != nil {
+		return err
+	}
+
+	mood, err := promptMood(reader, out, svc.Moods())
+	if err != nil {
+		return err
+	}
+	foundation, err := promptFoundation(reader, out)
+	if err != nil {
+		return err
+	}
+	vedana, err := promptVedanaFor(reader, out, foundation)
+	if err != nil {
+		return err
+	}
+	questions, err := svc.Questions(context.Background(), date, foundation)
+	if err != nil {
+		return err
+	}
+
+	reflections := make(map[journal.Precept]string)
+	for _, info := range journal.AllPrecepts() {
+		if asked, ok := questions[info.ID]; ok {
+			fmt.Fprintln(out, asked)
+		}
+		question := fmt.Sprintf("%s reflection (optional): ", info.Title)
+		if _, ok := notes[info.ID]; ok {
+			question = fmt.Sprintf("%s reflection (optional, default the note): ", info.Title)
+		}
+		reflection, err := prompt(reader, out, question)
+		if err != nil {
+			return err
+		}
+		if reflection = strings.TrimSpace(reflection); reflection != "" {
+			reflections[info.ID] = reflection
+		}
+	}
+	note, err := prompt(reader, out, "Overall note (optional): ")
+	if err != nil {
+		return err
+	}
+
+	if strings.TrimSpace(note) == "" && len(reflections) == 0 && len(notes) == 0 {
+		return journal.ErrEmptyEntry
+	}
+
+	if !*noConfirm {
+		journaled := make(map[journal.Precept]string, len(reflections))
+		for precept, text := range notes {
+			journaled[precept] = text
+		}
+		for precept, text := range reflections {
+			journaled[precept] = text
+		}
+		printGuidedSummary(out, date, mood, note, journaled, foundation, vedana)
+		printCheckinChanges(out, current, next)
+		confirm, err := prompt(reader, out, "Save? (y/n): ")
+		if err != nil {
+			return err
+		}
+		if !isYes(confirm) {
+			fmt.Fprintln(out, "not saved")
+			return nil
+		}
+	}
+
+	result, err := svc.Record(context.Background(), checkinapp.CheckIn{
+		Date:          date,
+		Adherence:     next,
+		Notes:         notes,
+		BeginningAnew: renewals,
+		Mood:          mood,
+		Foundation:    foundation,
+		Vedana:        vedana,
+		Note:          note,
+		Reflections:   reflections,
+		Questions:     answeredQuestions(questions, reflections),
+	})
+	if err != nil {
+		return err
+	}
+
+	if format != formatText {
+		updated, err := adherenceSvc.Current(context.Background())
+		if err != nil {
+			return err
+		}
+		checkIn := schema.CheckIn{
+			Adherence: schema.FromAdherence(updated),
+			Changes:   schema.FromLogEntries(result.Changes),
+			Entry:     schema.FromEntry(result.Entry),
+		}
+		for _, record := range result.BeginningAnew {
+			checkIn.BeginningAnew = append(checkIn.BeginningAnew, schema.FromBeginningAnew(record))
+		}
+		return writeObject(resultOut, format, checkIn)
+	}
+	fmt.Fprintf(out, "checked in: %d adherence change(s), journaled %s reflections=%d mood=%s\n",
+		len(result.Changes), result.Entry.Date.Format("2006-01-02"), len(result.Entry.Reflections), result.Entry.Mood)
+	for _, record := range result.BeginningAnew {
+		fmt.Fprintf(out, "Beginning Anew started for %s; renew it with `mt adherence renew %s`\n", preceptTitle(record.Precept), record.Precept)
+	}
+	return nil
+}
+
+// printCheckinChanges ends the check-in summary with the adherence changes;
+// their notes already show as reflections above.
+func printCheckinChanges(out io.Writer, current adherencedomain.Adherence, next adherencedomain.Adherence) {
+	changed := false
+	for _, info := range journal.AllPrecepts() {
+		if current[info.ID] == next[info.ID] {
+			continue
+		}
+		if !changed {
+			fmt.Fprintln(out, "Adherence:")
+			changed = true
+		}
+		fmt.Fprintf(out, "  %s: %s -> %s\n", info.Title, yesNoLabel(current[info.ID]), yesNoLabel(next[info.ID]))
+	}
+	if !changed {
+		fmt.Fprintln(out, "Adherence: no changes")
+	}
+}
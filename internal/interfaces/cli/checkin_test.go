@@ -0,0 +1,111 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/thatnerdjosh/mindfulness/internal/domain/journal"
+	"github.com/thatnerdjosh/mindfulness/internal/interfaces/schema"
+)
+
+func TestRunCheckin(t *testing.T) {
+	d := newTestData()
+	svc, journalSvc, adherenceSvc := d.checkin(), d.journal, d.adherence
+	var out bytes.Buffer
+	input := newInput("n", "swatted a fly", "", "", "", "", "calm", "d", "", "", "", "", "", "slept well", "y")
+	if err := runCheckin(nil, svc, adherenceSvc, formatText, input, &out, &bytes.Buffer{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"Reverence For Life reflection (optional, default the note): ",
+		"Reverence For Life: swatted a fly",
+		"Adherence:\n  Reverence For Life: yes -> no",
+		"checked in: 1 adherence change(s)",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("expected %q in output, got %q", want, out.String())
+		}
+	}
+	if strings.Count(out.String(), "Save? (y/n): ") != 1 {
+		t.Fatalf("expected a single confirmation, got %q", out.String())
+	}
+
+	entry, err := journalSvc.LatestEntry(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if entry.Note != "slept well" || entry.Mood != "calm" || entry.Reflections[journal.ReverenceForLife] != "swatted a fly" || len(entry.AdherenceEvents) != 1 {
+		t.Fatalf("unexpected entry: %+v", entry)
+	}
+	if state, _ := adherenceSvc.Current(context.Background()); state[journal.ReverenceForLife] {
+		t.Fatal("expected the precept to be marked as not kept")
+	}
+}
+
+func TestRunCheckinDeclined(t *testing.T) {
+	d := newTestData()
+	svc, journalSvc, adherenceSvc := d.checkin(), d.journal, d.adherence
+	var out bytes.Buffer
+	input := newInput("n", "", "", "", "", "", "", "d", "", "", "", "", "", "a note", "n")
+	if err := runCheckin(nil, svc, adherenceSvc, formatText, input, &out, &bytes.Buffer{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out.String(), "not saved") {
+		t.Fatalf("expected not saved, got %q", out.String())
+	}
+	if entries, _ := journalSvc.ListEntries(context.Background()); len(entries) != 0 {
+		t.Fatalf("expected no entries, got %d", len(entries))
+	}
+	if state, _ := adherenceSvc.Current(context.Background()); !state[journal.ReverenceForLife] {
+		t.Fatal("expected adherence to be unchanged")
+	}
+}
+
+func TestRunCheckinJSON(t *testing.T) {
+	d := newTestData()
+	svc, adherenceSvc := d.checkin(), d.adherence
+	var out, errOut bytes.Buffer
+	input := newInput("", "n", "", "", "", "", "", "d", "", "", "", "", "", "listened half-heartedly")
+	if err := runCheckin([]string{"--no-confirm"}, svc, adherenceSvc, formatJSON, input, &out, &errOut); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var checkIn schema.CheckIn
+	if err := json.Unmarshal(out.Bytes(), &checkIn); err != nil {
+		t.Fatalf("expected JSON output, got %q", out.String())
+	}
+	if checkIn.Adherence["true-happiness"] || len(checkIn.Changes) != 1 || checkIn.Entry.Note != "listened half-heartedly" {
+		t.Fatalf("unexpected check-in: %+v", checkIn)
+	}
+	if !strings.Contains(errOut.String(), "Mood (optional): ") {
+		t.Fatalf("expected prompts on stderr, got %q", errOut.String())
+	}
+
+	err := runCheckin([]string{"--no-confirm"}, svc, adherenceSvc, formatText, newInput(), &bytes.Buffer{}, &bytes.Buffer{})
+	if !errors.Is(err, journal.ErrEmptyEntry) {
+		t.Fatalf("expected ErrEmptyEntry, got %v", err)
+	}
+}
+
+func TestRunCheckinVedana(t *testing.T) {
+	d := newTestData()
+	svc, journalSvc, adherenceSvc := d.checkin(), d.journal, d.adherence
+	var out bytes.Buffer
+	input := newInput("", "", "", "", "", "", "v", "p", "2", "shoulders", "sunlight", "", "", "", "", "", "walked outside", "y")
+	if err := runCheckin(nil, svc, adherenceSvc, formatText, input, &out, &bytes.Buffer{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out.String(), "Feeling tone: pleasant, intensity 2/5, in shoulders, after sunlight") {
+		t.Fatalf("expected the feeling tone in the summary, got %q", out.String())
+	}
+	entry, err := journalSvc.LatestEntry(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if entry.Vedana == nil || entry.Vedana.Tone != journal.TonePleasant || entry.Vedana.Trigger != "sunlight" {
+		t.Fatalf("unexpected entry: %+v", entry)
+	}
+}
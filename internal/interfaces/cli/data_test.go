@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	adherenceapp "github.com/thatnerdjosh/mindfulness/internal/application/adherence"
+	analyticsapp "github.com/thatnerdjosh/mindfulness/internal/application/analytics"
+	checkinapp "github.com/thatnerdjosh/mindfulness/internal/application/checkin"
+	journalapp "github.com/thatnerdjosh/mindfulness/internal/application/journal"
+	reviewapp "github.com/thatnerdjosh/mindfulness/internal/application/review"
+	"github.com/thatnerdjosh/mindfulness/internal/application/unitofwork"
+	"github.com/thatnerdjosh/mindfulness/internal/domain/adherence"
+	"github.com/thatnerdjosh/mindfulness/internal/domain/journal"
+	"github.com/thatnerdjosh/mindfulness/internal/infrastructure/persistence/memory"
+)
+
+// testData wires in-memory repositories into the services commands run on,
+// the way Run wires the flat-file ones.
+type testData struct {
+	journalRepo   *memory.JournalRepository
+	adherenceRepo *memory.AdherenceRepository
+	renewals      *memory.BeginningAnewRepository
+	revisits      *memory.RevisitRepository
+	journal       *journalapp.Service
+	adherence     *adherenceapp.Service
+	analytics     *analyticsapp.Service
+	adherenceOpts []adherenceapp.Option
+}
+
+func newTestData() *testData {
+	d := &testData{
+		journalRepo:   memory.NewJournalRepository(),
+		adherenceRepo: memory.NewAdherenceRepository(),
+		renewals:      memory.NewBeginningAnewRepository(),
+		revisits:      memory.NewRevisitRepository(),
+	}
+	d.journal = journalapp.NewService(d.journalRepo, journalapp.WithRevisits(d.revisits))
+	d.adherence = adherenceapp.NewService(d.adherenceRepo)
+	d.analytics = analyticsapp.NewService(d.journalRepo)
+	return d
+}
+
+// offerBeginningAnew has the adherence service offer Beginning Anew when a
+// precept is broken, keeping the records in d.renewals.
+func (d *testData) offerBeginningAnew() *testData {
+	return d.withAdherence(adherenceapp.WithBeginningAnew(d.renewals))
+}
+
+// journalAdherenceChanges has adherence changes journaled in the same unit
+// of work, as Run does when the config links them.
+func (d *testData) journalAdherenceChanges() *testData {
+	return d.withAdherence(
+		adherenceapp.WithUnitOfWork(unitofwork.Direct(d.journalRepo, d.adherenceRepo, d.renewals)),
+		adherenceapp.WithListener(d.journal.JournalAdherenceChanges),
+	)
+}
+
+func (d *testData) withAdherence(opts ...adherenceapp.Option) *testData {
+	d.adherenceOpts = append(d.adherenceOpts, opts...)
+	d.adherence = adherenceapp.NewService(d.adherenceRepo, d.adherenceOpts...)
+	return d
+}
+
+func (d *testData) checkin() *checkinapp.Service {
+	return checkinapp.NewService(unitofwork.Direct(d.journalRepo, d.adherenceRepo, d.renewals), d.journal, d.adherence)
+}
+
+func (d *testData) review() *reviewapp.Service {
+	return reviewapp.NewService(d.journal, d.adherence, d.analytics)
+}
+
+// testEntry is a journal entry to seed. Entries without a time are recorded
+// through the journal service, as a command would.
+type testEntry struct {
+	date        string
+	at          time.Time
+	reflections map[journal.Precept]string
+	note        string
+	mood        string
+	foundation  journal.Foundation
+	opts        []journal.EntryOption
+}
+
+// seed stores entries in order, then appends changes to the adherence log.
+func (d *testData) seed(t *testing.T, entries []testEntry, changes ...adherence.AdherenceLogEntry) *testData {
+	t.Helper()
+	for _, seeded := range entries {
+		day, err := time.Parse("2006-01-02", seeded.date)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if seeded.at.IsZero() {
+			_, err = d.journal.RecordEntry(context.Background(), day, seeded.reflections, seeded.note, seeded.mood, seeded.foundation, seeded.opts...)
+		} else {
+			var entry journal.Entry
+			entry, err = journal.NewEntry(day, seeded.reflections, seeded.note, seeded.mood, seeded.foundation, seeded.at, seeded.opts...)
+			if err == nil {
+				err = d.journalRepo.Save(context.Background(), entry)
+			}
+		}
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	for _, change := range changes {
+		if err := d.adherenceRepo.AppendLog(context.Background(), change); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	return d
+}
@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/thatnerdjosh/mindfulness/internal/infrastructure/persistence/flatfile"
+)
+
+type doctorProblem struct {
+	File    string `json:"file"`
+	Line    int    `json:"line,omitempty"`
+	Record  int    `json:"record,omitempty"`
+	Message string `json:"message"`
+	Fix     string `json:"fix,omitempty"`
+}
+
+type doctorReport struct {
+	Problems    []doctorProblem `json:"problems"`
+	Repaired    bool            `json:"repaired"`
+	Backups     []string        `json:"backups,omitempty"`
+	Quarantines []string        `json:"quarantines,omitempty"`
+	Removed     []string        `json:"removed,omitempty"`
+}
+
+func runDoctor(args []string, files dataFiles, format outputFormat, out io.Writer, errOut io.Writer) error {
+	fs := flag.NewFlagSet("doctor", flag.ContinueOnError)
+	fs.SetOutput(errOut)
+	repair := fs.Bool("repair", false, "quarantine bad records and rebuild derived files, keeping backups")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	doctor := flatfile.NewDoctor(files.journal, files.adherence, files.adherenceLog)
+	doctor.BeginningAnewPath = files.beginningAnew
+	doctor.RevisitPath = files.revisits
+	doctor.WALPath = files.wal
+	var problems []flatfile.Problem
+	var result flatfile.RepairResult
+	var err error
+	if *repair {
+		problems, result, err = doctor.Repair()
+	} else {
+		problems, err = doctor.Check()
+	}
+	if err != nil {
+		return err
+	}
+
+	report := doctorReport{Problems: make([]doctorProblem, 0, len(problems)), Repaired: *repair && len(problems) > 0}
+	for _, problem := range problems {
+		report.Problems = append(report.Problems, doctorProblem(problem))
+	}
+	report.Backups = result.Backups
+	report.Quarantines = result.Quarantines
+	report.Removed = result.Removed
+
+	if format != formatText {
+		if err := writeObject(out, format, report); err != nil {
+			return err
+		}
+	} else {
+		writeDoctorReport(out, report)
+	}
+
+	if len(problems) > 0 && !*repair {
+		return fmt.Errorf("%d problem(s) found", len(problems))
+	}
+	return nil
+}
+
+func writeDoctorReport(out io.Writer, report doctorReport) {
+	if len(report.Problems) == 0 {
+		fmt.Fprintln(out, "no problems found")
+		return
+	}
+	for _, problem := range report.Problems {
+		location := filepath.Base(problem.File)
+		if problem.Line > 0 {
+			location += fmt.Sprintf(":%d", problem.Line)
+		}
+		if problem.Record > 0 {
+			location += fmt.Sprintf(" (record %d)", problem.Record)
+		}
+		fmt.Fprintf(out, "%s: %s\n", location, problem.Message)
+		if problem.Fix == "" {
+			fmt.Fprintln(out, "  fix by hand")
+		} else if !report.Repaired {
+			fmt.Fprintf(out, "  --repair will %s\n", problem.Fix)
+		}
+	}
+	if !report.Repaired {
+		return
+	}
+	for _, backup := range report.Backups {
+		fmt.Fprintf(out, "backup: %s\n", backup)
+	}
+	for _, quarantine := range report.Quarantines {
+		fmt.Fprintf(out, "quarantined records: %s\n", quarantine)
+	}
+	for _, removed := range report.Removed {
+		fmt.Fprintf(out, "removed: %s\n", removed)
+	}
+}
+
+// withDoctorHint points at mt doctor when a data file cannot be loaded.
+func withDoctorHint(err error) error {
+	return fmt.Errorf("%w\nrun `mt doctor` to find the bad records and `mt doctor --repair` to set them aside", err)
+}
+
+// warnSkippedRecords prints one line summarizing the records a lenient load
+// skipped, so commands keep working while the files wait for mt doctor.
+func warnSkippedRecords(errOut io.Writer, warnings []flatfile.LoadWarning) {
+	if len(warnings) == 0 {
+		return
+	}
+	counts := make(map[string]int)
+	var files []string
+	for _, warning := range warnings {
+		name := filepath.Base(warning.File)
+		if counts[name] == 0 {
+			files = append(files, name)
+		}
+		counts[name]++
+	}
+	for i, name := range files {
+		files[i] = fmt.Sprintf("%s (%d)", name, counts[name])
+	}
+	fmt.Fprintf(errOut, "warning: skipped %d unreadable record(s) in %s; run `mt doctor` for details\n", len(warnings), strings.Join(files, ", "))
+}
@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunDoctor(t *testing.T) {
+	dataHome := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dataHome)
+	journalPath := filepath.Join(dataHome, "mt", "journal.json")
+	if err := os.MkdirAll(filepath.Dir(journalPath), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	data := "[\n  {\"date\": \"2024-01-01\", \"note\": \"good\"},\n  {\"date\": \"2024-01-02\", \"foundation\": \"mind\", \"note\": \"bad\"}\n]\n"
+	if err := os.WriteFile(journalPath, []byte(data), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	var out, errOut bytes.Buffer
+	if err := Run([]string{"mt", "journal", "list"}, &out, &errOut); err != nil {
+		t.Fatalf("expected journal to load around the bad record: %v", err)
+	}
+	if !strings.Contains(errOut.String(), "warning: skipped 1 unreadable record(s) in journal.json (1); run `mt doctor` for details") {
+		t.Fatalf("expected skipped-record warning, got %q", errOut.String())
+	}
+	if !strings.Contains(out.String(), "2024-01-01") || strings.Contains(out.String(), "2024-01-02") {
+		t.Fatalf("expected only the good entry, got %q", out.String())
+	}
+
+	out.Reset()
+	err := Run([]string{"mt", "doctor"}, &out, &bytes.Buffer{})
+	if err == nil || !strings.Contains(err.Error(), "1 problem(s) found") {
+		t.Fatalf("expected problem count error, got %v", err)
+	}
+	if !strings.Contains(out.String(), `journal.json:3 (record 2): unknown foundation "mind"`) {
+		t.Fatalf("unexpected doctor output:\n%s", out.String())
+	}
+
+	out.Reset()
+	if err := Run([]string{"mt", "doctor", "--repair"}, &out, &bytes.Buffer{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out.String(), "backup: ") || !strings.Contains(out.String(), "quarantined records: ") {
+		t.Fatalf("unexpected repair output:\n%s", out.String())
+	}
+
+	out.Reset()
+	errOut.Reset()
+	if err := Run([]string{"mt", "journal", "list"}, &out, &errOut); err != nil {
+		t.Fatalf("expected journal to load after repair: %v", err)
+	}
+	if errOut.Len() != 0 {
+		t.Fatalf("expected no warnings after repair, got %q", errOut.String())
+	}
+	if !strings.Contains(out.String(), "2024-01-01") || strings.Contains(out.String(), "2024-01-02") {
+		t.Fatalf("expected the good entry to survive, got %q", out.String())
+	}
+}
+
+func TestRunDoctorRemovesUnreadableWriteAheadRecord(t *testing.T) {
+	dataHome := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dataHome)
+	walPath := filepath.Join(dataHome, "mt", "pending.wal.json")
+	if err := os.MkdirAll(filepath.Dir(walPath), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(walPath, []byte(`{"journal_append": [`), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := Run([]string{"mt", "journal", "list"}, &bytes.Buffer{}, &bytes.Buffer{}); err == nil {
+		t.Fatal("expected an unreadable write-ahead record to stop the command")
+	}
+
+	var out bytes.Buffer
+	if err := Run([]string{"mt", "doctor"}, &out, &bytes.Buffer{}); err == nil {
+		t.Fatal("expected the doctor to report the write-ahead record")
+	}
+	if !strings.Contains(out.String(), "pending.wal.json:1: interrupted write cannot be completed") {
+		t.Fatalf("unexpected doctor output:\n%s", out.String())
+	}
+
+	out.Reset()
+	if err := Run([]string{"mt", "doctor", "--repair"}, &out, &bytes.Buffer{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out.String(), "removed: "+walPath) {
+		t.Fatalf("unexpected repair output:\n%s", out.String())
+	}
+	if err := Run([]string{"mt", "journal", "list"}, &bytes.Buffer{}, &bytes.Buffer{}); err != nil {
+		t.Fatalf("expected commands to work after repair: %v", err)
+	}
+}
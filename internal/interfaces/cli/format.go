@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+type outputFormat string
+
+const (
+	formatText  outputFormat = "text"
+	formatJSON  outputFormat = "json"
+	formatJSONL outputFormat = "jsonl"
+)
+
+func parseOutputFormat(input string) (outputFormat, error) {
+	switch outputFormat(strings.ToLower(strings.TrimSpace(input))) {
+	case "", formatText:
+		return formatText, nil
+	case formatJSON:
+		return formatJSON, nil
+	case formatJSONL:
+		return formatJSONL, nil
+	default:
+		return "", fmt.Errorf("unknown format: %s (expected text, json or jsonl)", input)
+	}
+}
+
+// parseGlobalFlags consumes the flags placed before the command name and
+// returns the remaining arguments, starting with the command.
+func parseGlobalFlags(args []string) (outputFormat, []string, error) {
+	format := formatText
+	for len(args) > 0 {
+		name, value, hasValue := strings.Cut(args[0], "=")
+		if name != "--format" && name != "-format" {
+			break
+		}
+		if !hasValue {
+			if len(args) < 2 {
+				return "", nil, fmt.Errorf("flag needs an argument: %s", name)
+			}
+			value = args[1]
+			args = args[1:]
+		}
+		parsed, err := parseOutputFormat(value)
+		if err != nil {
+			return "", nil, err
+		}
+		format = parsed
+		args = args[1:]
+	}
+	return format, args, nil
+}
+
+// promptWriter keeps stdout machine-readable by sending interactive prompts
+// to errOut when a structured format is selected.
+func promptWriter(format outputFormat, out io.Writer, errOut io.Writer) io.Writer {
+	if format == formatText {
+		return out
+	}
+	return errOut
+}
+
+// writeObject encodes a single value as indented JSON or as one JSONL line.
+func writeObject(out io.Writer, format outputFormat, value any) error {
+	encoder := json.NewEncoder(out)
+	if format == formatJSON {
+		encoder.SetIndent("", "  ")
+	}
+	if err := encoder.Encode(value); err != nil {
+		return fmt.Errorf("encode output: %w", err)
+	}
+	return nil
+}
+
+// writeList encodes items as one JSON array or as one JSONL line per item.
+func writeList[T any](out io.Writer, format outputFormat, items []T) error {
+	if format == formatJSON {
+		if items == nil {
+			items = []T{}
+		}
+		return writeObject(out, format, items)
+	}
+	for _, item := range items {
+		if err := writeObject(out, format, item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,331 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	adherenceapp "github.com/thatnerdjosh/mindfulness/internal/application/adherence"
+	journalapp "github.com/thatnerdjosh/mindfulness/internal/application/journal"
+	adherencedomain "github.com/thatnerdjosh/mindfulness/internal/domain/adherence"
+	"github.com/thatnerdjosh/mindfulness/internal/domain/journal"
+	"github.com/thatnerdjosh/mindfulness/internal/infrastructure/persistence/flatfile"
+	"github.com/thatnerdjosh/mindfulness/internal/infrastructure/persistence/memory"
+	"github.com/thatnerdjosh/mindfulness/internal/interfaces/schema"
+)
+
+func TestParseGlobalFlags(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		wantFormat outputFormat
+		wantArgs   []string
+		wantErr    bool
+	}{
+		{
+			name:       "defaults to text",
+			args:       []string{"journal", "list"},
+			wantFormat: formatText,
+			wantArgs:   []string{"journal", "list"},
+		},
+		{
+			name:       "equals form",
+			args:       []string{"--format=json", "journal", "list"},
+			wantFormat: formatJSON,
+			wantArgs:   []string{"journal", "list"},
+		},
+		{
+			name:       "separate value",
+			args:       []string{"--format", "jsonl", "journal"},
+			wantFormat: formatJSONL,
+			wantArgs:   []string{"journal"},
+		},
+		{
+			name:       "stops at command",
+			args:       []string{"-v"},
+			wantFormat: formatText,
+			wantArgs:   []string{"-v"},
+		},
+		{
+			name:    "unknown format",
+			args:    []string{"--format=xml", "journal"},
+			wantErr: true,
+		},
+		{
+			name:    "missing value",
+			args:    []string{"--format"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			format, args, err := parseGlobalFlags(tt.args)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if format != tt.wantFormat {
+				t.Fatalf("expected format %q, got %q", tt.wantFormat, format)
+			}
+			if strings.Join(args, " ") != strings.Join(tt.wantArgs, " ") {
+				t.Fatalf("expected args %v, got %v", tt.wantArgs, args)
+			}
+		})
+	}
+}
+
+func TestRunJournalListFormats(t *testing.T) {
+	svc := journalapp.NewService(memory.NewJournalRepository())
+	for _, date := range []string{"2024-01-01", "2024-01-02"} {
+		if err := runJournalAdd([]string{"--date=" + date, "--note=steady"}, svc, formatText, strings.NewReader(""), &bytes.Buffer{}, &bytes.Buffer{}); err != nil {
+			t.Fatalf("unexpected setup error: %v", err)
+		}
+	}
+
+	var out bytes.Buffer
+	if err := runJournalList(svc, formatJSON, &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var list []schema.Entry
+	if err := json.Unmarshal(out.Bytes(), &list); err != nil {
+		t.Fatalf("expected JSON array, got %s", out.String())
+	}
+	if len(list) != 2 || list[0].Date != "2024-01-01" || list[1].Note != "steady" {
+		t.Fatalf("unexpected entries: %+v", list)
+	}
+
+	out.Reset()
+	if err := runJournalList(svc, formatJSONL, &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %q", out.String())
+	}
+	for _, line := range lines {
+		var entry schema.Entry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("expected JSON line, got %q", line)
+		}
+	}
+}
+
+func TestRunJournalListEmptyJSON(t *testing.T) {
+	svc := journalapp.NewService(memory.NewJournalRepository())
+	var out bytes.Buffer
+	if err := runJournalList(svc, formatJSON, &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.TrimSpace(out.String()) != "[]" {
+		t.Fatalf("expected empty array, got %q", out.String())
+	}
+}
+
+func TestRunJournalAddJSON(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		wantErr    error
+		wantErrAny bool
+	}{
+		{
+			name:  "ok",
+			input: `{"date":"2024-03-01","timestamp":"2024-03-01T08:15:00Z","foundation":"vedana","mood":"calm","reflections":{"true-love":"listened"}}`,
+		},
+		{
+			name:    "validates through NewEntry",
+			input:   `{"date":"2024-03-01","reflections":{"other":"x"}}`,
+			wantErr: journal.ErrUnknownPrecept,
+		},
+		{
+			name:       "rejects unknown fields",
+			input:      `{"date":"2024-03-01","note":"x","extra":1}`,
+			wantErrAny: true,
+		},
+		{
+			name:       "rejects malformed JSON",
+			input:      `{`,
+			wantErrAny: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := journalapp.NewService(memory.NewJournalRepository())
+			var out bytes.Buffer
+			err := runJournalAdd([]string{"--json", "-"}, svc, formatJSON, strings.NewReader(tt.input), &out, &bytes.Buffer{})
+			switch {
+			case tt.wantErr != nil:
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr.Error()) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			case tt.wantErrAny:
+				if err == nil {
+					t.Fatalf("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var saved schema.Entry
+			if err := json.Unmarshal(out.Bytes(), &saved); err != nil {
+				t.Fatalf("expected JSON output, got %s", out.String())
+			}
+			if saved.Timestamp != "2024-03-01T08:15:00Z" || saved.Foundation != "vedana" {
+				t.Fatalf("unexpected output: %+v", saved)
+			}
+			latest, err := svc.LatestEntry(context.Background())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if latest.Reflections[journal.TrueLove] != "listened" {
+				t.Fatalf("expected entry to be saved")
+			}
+		})
+	}
+}
+
+func TestRunAdherenceGuidedJSON(t *testing.T) {
+	svc := adherenceapp.NewService(memory.NewAdherenceRepository())
+	var out bytes.Buffer
+	var errOut bytes.Buffer
+
+	err := runAdherenceGuided([]string{"--no-confirm"}, svc, formatJSONL, newInput("y", "n", "slipped", "", "", ""), &out, &errOut)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(errOut.String(), "keep?") {
+		t.Fatalf("expected prompts on error output, got %q", errOut.String())
+	}
+
+	var update schema.AdherenceUpdate
+	if err := json.Unmarshal(out.Bytes(), &update); err != nil {
+		t.Fatalf("expected JSON output, got %q", out.String())
+	}
+	if update.Adherence["true-happiness"] {
+		t.Fatalf("expected true-happiness false")
+	}
+	if len(update.Changes) != 1 || update.Changes[0].Note != "slipped" {
+		t.Fatalf("unexpected changes: %+v", update.Changes)
+	}
+}
+
+func TestRunAdherenceStatus(t *testing.T) {
+	svc := adherenceapp.NewService(memory.NewAdherenceRepository())
+
+	var out bytes.Buffer
+	if err := runAdherenceStatus(svc, formatText, &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out.String(), "True Love: yes") {
+		t.Fatalf("unexpected output: %s", out.String())
+	}
+
+	out.Reset()
+	if err := runAdherenceStatus(svc, formatJSON, &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var state schema.Adherence
+	if err := json.Unmarshal(out.Bytes(), &state); err != nil {
+		t.Fatalf("expected JSON output, got %s", out.String())
+	}
+	if !state["true-love"] {
+		t.Fatalf("expected true-love true")
+	}
+}
+
+func TestRunAdherenceRebuild(t *testing.T) {
+	dataHome := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dataHome)
+	if err := Run([]string{"mt", "adherence", "status"}, &bytes.Buffer{}, &bytes.Buffer{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	statePath := filepath.Join(dataHome, "mt", "adherence.json")
+	if err := os.WriteFile(statePath, []byte(`{"true-love": false}`), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := Run([]string{"mt", "--format", "json", "adherence", "rebuild"}, &out, &bytes.Buffer{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var rebuilt schema.AdherenceRebuild
+	if err := json.Unmarshal(out.Bytes(), &rebuilt); err != nil {
+		t.Fatalf("expected JSON output, got %s", out.String())
+	}
+	if !rebuilt.Adherence["true-love"] || rebuilt.Events != len(journal.AllPrecepts()) {
+		t.Fatalf("expected the default state from the initial events, got %+v", rebuilt)
+	}
+
+	svc := adherenceapp.NewService(memory.NewAdherenceRepository())
+	if err := runAdherenceRebuild(svc, formatText, &out); err == nil {
+		t.Fatal("expected an error for a store that cannot be rebuilt")
+	}
+}
+
+func TestRunAdherenceLog(t *testing.T) {
+	dataHome := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dataHome)
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	var out bytes.Buffer
+	if err := Run([]string{"mt", "adherence", "log", "archive"}, &out, &bytes.Buffer{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out.String(), "nothing to archive") {
+		t.Fatalf("expected nothing to archive, got %q", out.String())
+	}
+
+	svc := adherenceapp.NewService(mustEventSourcedRepo(t, dataHome))
+	next := adherencedomain.DefaultAdherence()
+	next[journal.ReverenceForLife] = false
+	if _, err := svc.Set(context.Background(), next, map[journal.Precept]string{journal.ReverenceForLife: "felt rushed"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	out.Reset()
+	if err := Run([]string{"mt", "--format", "json", "adherence", "log", "archive"}, &out, &bytes.Buffer{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var archive schema.LogArchive
+	if err := json.Unmarshal(out.Bytes(), &archive); err != nil {
+		t.Fatalf("expected JSON output, got %s", out.String())
+	}
+	if !archive.Archived || archive.Segment == "" || archive.Events != len(journal.AllPrecepts())+1 {
+		t.Fatalf("unexpected archive result: %+v", archive)
+	}
+	if _, err := os.Stat(filepath.Join(dataHome, "mt", archive.Segment)); err != nil {
+		t.Fatalf("expected the segment file: %v", err)
+	}
+
+	out.Reset()
+	if err := Run([]string{"mt", "adherence", "log"}, &out, &bytes.Buffer{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out.String(), "Reverence For Life: yes -> no") || !strings.Contains(out.String(), "felt rushed") {
+		t.Fatalf("expected the archived change in the log, got %q", out.String())
+	}
+}
+
+func mustEventSourcedRepo(t *testing.T, dataHome string) *flatfile.EventSourcedAdherenceRepository {
+	t.Helper()
+	dir := filepath.Join(dataHome, "mt")
+	repo, err := flatfile.NewEventSourcedAdherenceRepository(filepath.Join(dir, "adherence.json"), filepath.Join(dir, "adherence.log.jsonl"))
+	if err != nil {
+		t.Fatalf("open adherence: %v", err)
+	}
+	return repo
+}
@@ -0,0 +1,265 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	analyticsapp "github.com/thatnerdjosh/mindfulness/internal/application/analytics"
+	journalapp "github.com/thatnerdjosh/mindfulness/internal/application/journal"
+	"github.com/thatnerdjosh/mindfulness/internal/domain/journal"
+	"github.com/thatnerdjosh/mindfulness/internal/infrastructure/config"
+	"github.com/thatnerdjosh/mindfulness/internal/interfaces/schema"
+)
+
+// sparkBlocks draw a sparkline from the lowest to the highest scale value.
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// moodVocabulary builds the vocabulary from the built-in terms and those in
+// the config file.
+func moodVocabulary(cfg config.Mood) (journal.MoodVocabulary, error) {
+	var terms []journal.MoodTerm
+	custom := make(map[string]bool)
+	for _, term := range cfg.Terms {
+		if (term.Valence == nil) != (term.Energy == nil) {
+			return journal.MoodVocabulary{}, fmt.Errorf("mood %q: set both valence and energy, or neither", term.Name)
+		}
+		converted := journal.MoodTerm{Name: term.Name, Family: term.Family, Synonyms: term.Synonyms}
+		if term.Valence != nil {
+			converted.Scale = &journal.MoodScale{Valence: *term.Valence, Energy: *term.Energy}
+		}
+		terms = append(terms, converted)
+		custom[strings.ToLower(strings.TrimSpace(term.Name))] = true
+	}
+	if !cfg.ReplaceDefaults {
+		for _, term := range journal.DefaultMoodTerms() {
+			if !custom[term.Name] {
+				terms = append(terms, term)
+			}
+		}
+	}
+	vocabulary, err := journal.NewMoodVocabulary(terms)
+	if err != nil {
+		return journal.MoodVocabulary{}, fmt.Errorf("mood config: %w", err)
+	}
+	return vocabulary, nil
+}
+
+// promptMood asks for a mood. Typing a number opens that family of the
+// emotion wheel and picks a term from it; anything else is taken as written.
+func promptMood(reader *bufio.Reader, out io.Writer, vocabulary journal.MoodVocabulary) (string, error) {
+	families := vocabulary.Families()
+	if len(families) > 0 {
+		fmt.Fprintf(out, "Mood wheel: %s (enter a number to pick from it)\n", numbered(families))
+	}
+	for {
+		answer, err := prompt(reader, out, "Mood (optional): ")
+		if err != nil {
+			return "", err
+		}
+		choice, err := strconv.Atoi(answer)
+		if err != nil || len(families) == 0 {
+			return answer, nil
+		}
+		if choice < 1 || choice > len(families) {
+			fmt.Fprintf(out, "Please enter 1-%d or a mood.\n", len(families))
+			continue
+		}
+
+		terms := vocabulary.Terms(families[choice-1])
+		names := make([]string, 0, len(terms))
+		for _, term := range terms {
+			names = append(names, term.Name)
+		}
+		fmt.Fprintf(out, "%s: %s\n", families[choice-1], numbered(names))
+		for {
+			answer, err := prompt(reader, out, "Mood: ")
+			if err != nil {
+				return "", err
+			}
+			choice, err := strconv.Atoi(answer)
+			if err != nil {
+				return answer, nil
+			}
+			if choice >= 1 && choice <= len(names) {
+				return names[choice-1], nil
+			}
+			fmt.Fprintf(out, "Please enter 1-%d or a mood.\n", len(names))
+		}
+	}
+}
+
+func numbered(items []string) string {
+	parts := make([]string, 0, len(items))
+	for i, item := range items {
+		parts = append(parts, fmt.Sprintf("%d) %s", i+1, item))
+	}
+	return strings.Join(parts, " ")
+}
+
+func runMood(args []string, svc *journalapp.Service, analytics *analyticsapp.Service, format outputFormat, out io.Writer, errOut io.Writer) error {
+	if len(args) < 1 {
+		printMoodUsage(errOut)
+		return fmt.Errorf("mood subcommand required")
+	}
+
+	switch args[0] {
+	case "trend":
+		return runMoodTrend(args[1:], analytics, format, out, errOut)
+	case "migrate":
+		return runMoodMigrate(args[1:], svc, format, out, errOut)
+	case "help", "-h", "--help":
+		printMoodUsage(out)
+		return nil
+	default:
+		fmt.Fprintf(errOut, "unknown mood command: %s\n", args[0])
+		printMoodUsage(errOut)
+		return fmt.Errorf("unknown mood command: %s", args[0])
+	}
+}
+
+func runMoodMigrate(args []string, svc *journalapp.Service, format outputFormat, out io.Writer, errOut io.Writer) error {
+	fs := flag.NewFlagSet("mood migrate", flag.ContinueOnError)
+	fs.SetOutput(errOut)
+	dryRun := fs.Bool("dry-run", false, "list the changes without saving them")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	changes, err := svc.MigrateMoods(context.Background(), *dryRun)
+	if err != nil {
+		return err
+	}
+
+	if format != formatText {
+		list := make([]schema.MoodChange, 0, len(changes))
+		for _, change := range changes {
+			list = append(list, schema.MoodChange{
+				Date:      change.Date.Format("2006-01-02"),
+				Timestamp: change.Timestamp.UTC().Format(time.RFC3339Nano),
+				From:      change.From,
+				To:        change.To,
+			})
+		}
+		return writeList(out, format, list)
+	}
+	if len(changes) == 0 {
+		fmt.Fprintln(out, "all moods already match the vocabulary")
+		return nil
+	}
+	for _, change := range changes {
+		fmt.Fprintf(out, "%s  %q -> %q\n", change.Date.Format("2006-01-02"), change.From, change.To)
+	}
+	if *dryRun {
+		fmt.Fprintf(out, "%d mood(s) would be normalized; run without --dry-run to save\n", len(changes))
+	} else {
+		fmt.Fprintf(out, "normalized %d mood(s)\n", len(changes))
+	}
+	return nil
+}
+
+func runMoodTrend(args []string, analytics *analyticsapp.Service, format outputFormat, out io.Writer, errOut io.Writer) error {
+	fs := flag.NewFlagSet("mood trend", flag.ContinueOnError)
+	fs.SetOutput(errOut)
+	sinceStr := fs.String("since", "", "first day to include (YYYY-MM-DD, default 12 weeks or 30 days back)")
+	untilStr := fs.String("until", "", "last day to include (YYYY-MM-DD, default today)")
+	by := fs.String("by", "week", "period to average over: day or week")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	period := analyticsapp.Period(*by)
+
+	until, err := parseDate(*untilStr)
+	if err != nil {
+		return err
+	}
+	var since time.Time
+	switch {
+	case *sinceStr != "":
+		if since, err = parseDate(*sinceStr); err != nil {
+			return err
+		}
+	case period == analyticsapp.Daily:
+		since = until.AddDate(0, 0, -29)
+	default:
+		since = until.AddDate(0, 0, -7*11)
+	}
+
+	points, err := analytics.MoodTrend(context.Background(), since, until, period)
+	if err != nil {
+		return err
+	}
+
+	if format != formatText {
+		return writeList(out, format, fromMoodPoints(points))
+	}
+
+	entries, rated := 0, 0
+	var valence, energy float64
+	for _, point := range points {
+		entries += point.Entries
+		rated += point.Rated
+		valence += point.Valence * float64(point.Rated)
+		energy += point.Energy * float64(point.Rated)
+	}
+	if rated == 0 {
+		fmt.Fprintln(out, "no rated moods in range")
+		return nil
+	}
+
+	// Keep the latest periods that fit beside the labels and averages.
+	shown := points
+	if room := terminalWidth() - 22; room > 0 && len(shown) > room {
+		shown = shown[len(shown)-room:]
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "Mood trend %s to %s, by %s\n", shown[0].Start.Format("2006-01-02"), until.Format("2006-01-02"), period)
+	fmt.Fprintf(&buf, "Valence  %s  avg %+.1f\n", sparkline(shown, func(p analyticsapp.MoodPoint) float64 { return p.Valence }), valence/float64(rated))
+	fmt.Fprintf(&buf, "Energy   %s  avg %+.1f\n", sparkline(shown, func(p analyticsapp.MoodPoint) float64 { return p.Energy }), energy/float64(rated))
+	fmt.Fprintf(&buf, "%d of %d mood(s) on the scale from %d to %+d; blank periods have none\n", rated, entries, journal.MoodScaleMin, journal.MoodScaleMax)
+	_, err = out.Write(buf.Bytes())
+	return err
+}
+
+func fromMoodPoints(points []analyticsapp.MoodPoint) []schema.MoodPoint {
+	list := make([]schema.MoodPoint, 0, len(points))
+	for _, point := range points {
+		item := schema.MoodPoint{Start: point.Start.Format("2006-01-02"), Entries: point.Entries, Rated: point.Rated}
+		if point.Rated > 0 {
+			valence, energy := point.Valence, point.Energy
+			item.Valence, item.Energy = &valence, &energy
+		}
+		list = append(list, item)
+	}
+	return list
+}
+
+// sparkline draws one block per point, scaled over the whole mood scale so
+// lines from different ranges compare. Points without rated moods are blank.
+func sparkline(points []analyticsapp.MoodPoint, value func(analyticsapp.MoodPoint) float64) string {
+	var b strings.Builder
+	span := float64(journal.MoodScaleMax - journal.MoodScaleMin)
+	for _, point := range points {
+		if point.Rated == 0 {
+			b.WriteRune(' ')
+			continue
+		}
+		level := (value(point) - journal.MoodScaleMin) / span * float64(len(sparkBlocks)-1)
+		b.WriteRune(sparkBlocks[int(math.Round(level))])
+	}
+	return b.String()
+}
+
+func printMoodUsage(out io.Writer) {
+	fmt.Fprintln(out, "Usage:")
+	fmt.Fprintln(out, "  mt mood trend [--since YYYY-MM-DD] [--until YYYY-MM-DD] [--by day|week]")
+	fmt.Fprintln(out, "  mt mood migrate [--dry-run]")
+}
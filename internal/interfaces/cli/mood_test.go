@@ -0,0 +1,141 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thatnerdjosh/mindfulness/internal/domain/journal"
+	"github.com/thatnerdjosh/mindfulness/internal/infrastructure/config"
+	"github.com/thatnerdjosh/mindfulness/internal/interfaces/schema"
+)
+
+func TestPromptMood(t *testing.T) {
+	vocabulary := journal.DefaultMoodVocabulary()
+	tests := []struct {
+		name  string
+		input []string
+		want  string
+	}{
+		{name: "typed", input: []string{"Peaceful"}, want: "Peaceful"},
+		{name: "skipped", input: []string{""}, want: ""},
+		{name: "wheel", input: []string{"3", "1"}, want: "calm"},
+		{name: "wheel then typed", input: []string{"4", "wistful"}, want: "wistful"},
+		{name: "out of range", input: []string{"9", "1", "7", "2"}, want: "excited"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			got, err := promptMood(bufio.NewReader(newInput(tt.input...)), &out, vocabulary)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("expected %q, got %q (output %q)", tt.want, got, out.String())
+			}
+		})
+	}
+
+	var out bytes.Buffer
+	if _, err := promptMood(bufio.NewReader(newInput("3", "")), &out, vocabulary); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out.String(), "Mood wheel: 1) joyful 2) powerful 3) peaceful") || !strings.Contains(out.String(), "peaceful: 1) calm 2) content") {
+		t.Fatalf("expected the wheel to be listed, got %q", out.String())
+	}
+}
+
+func TestMoodVocabularyFromConfig(t *testing.T) {
+	two, minusOne := 2, -1
+	vocabulary, err := moodVocabulary(config.Mood{Terms: []config.MoodTerm{
+		{Name: "calm", Family: "peaceful", Synonyms: []string{"still"}},
+		{Name: "wistful", Family: "sad", Synonyms: []string{"nostalgic"}, Valence: &minusOne, Energy: &minusOne},
+		{Name: "elated", Family: "joyful", Valence: &two, Energy: &two},
+	}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := vocabulary.Normalize("Still"); got != "calm" {
+		t.Fatalf("expected the custom synonym, got %q", got)
+	}
+	if term, _ := vocabulary.Lookup("calm"); term.Scale != nil {
+		t.Fatalf("expected the custom calm to replace the built-in one, got %+v", term)
+	}
+	if got := vocabulary.Normalize("exhausted"); got != "tired" {
+		t.Fatalf("expected built-in terms to remain, got %q", got)
+	}
+
+	replaced, err := moodVocabulary(config.Mood{ReplaceDefaults: true, Terms: []config.MoodTerm{{Name: "wistful"}}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := replaced.Lookup("tired"); ok {
+		t.Fatal("expected the built-in terms to be replaced")
+	}
+
+	if _, err := moodVocabulary(config.Mood{Terms: []config.MoodTerm{{Name: "wistful", Valence: &two}}}); err == nil {
+		t.Fatal("expected an error for valence without energy")
+	}
+}
+
+func TestRunMoodTrendAndMigrate(t *testing.T) {
+	var entries []testEntry
+	for _, item := range []struct{ date, mood string }{
+		{"2024-05-01", "Calm."},
+		{"2024-05-02", "excited"},
+		{"2024-05-04", "exhausted"},
+		{"2024-05-05", "wistful"},
+	} {
+		at, _ := time.Parse("2006-01-02", item.date)
+		entries = append(entries, testEntry{date: item.date, at: at, note: "note", mood: item.mood, foundation: journal.FoundationDhamma})
+	}
+	d := newTestData().seed(t, entries)
+	repo, analytics := d.journalRepo, d.analytics
+
+	var out bytes.Buffer
+	args := []string{"trend", "--by", "day", "--since", "2024-05-01", "--until", "2024-05-05"}
+	if err := runMood(args, d.journal, analytics, formatText, &out, &bytes.Buffer{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{"Mood trend 2024-05-01 to 2024-05-05, by day", "Valence  ▆█ ▃   avg +0.7", "Energy   ▃█ ▁   avg -0.3", "3 of 4 mood(s)"} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("expected %q in output, got %q", want, out.String())
+		}
+	}
+
+	out.Reset()
+	if err := runMood(args, d.journal, analytics, formatJSON, &out, &bytes.Buffer{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var points []schema.MoodPoint
+	if err := json.Unmarshal(out.Bytes(), &points); err != nil {
+		t.Fatalf("expected a JSON array, got %q", out.String())
+	}
+	if len(points) != 5 || points[2].Valence != nil || *points[1].Valence != 2 {
+		t.Fatalf("unexpected points: %+v", points)
+	}
+
+	out.Reset()
+	if err := runMood([]string{"migrate", "--dry-run"}, d.journal, analytics, formatText, &out, &bytes.Buffer{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out.String(), `2024-05-01  "Calm." -> "calm"`) || !strings.Contains(out.String(), "2 mood(s) would be normalized") {
+		t.Fatalf("unexpected dry run output: %q", out.String())
+	}
+
+	out.Reset()
+	if err := runMood([]string{"migrate"}, d.journal, analytics, formatText, &out, &bytes.Buffer{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out.String(), "normalized 2 mood(s)") {
+		t.Fatalf("unexpected migrate output: %q", out.String())
+	}
+	latest, _ := repo.Latest(context.Background())
+	if entries, _ := repo.List(context.Background()); entries[0].Mood != "calm" || latest.Mood != "wistful" {
+		t.Fatalf("unexpected moods after migrating: %+v", entries)
+	}
+}
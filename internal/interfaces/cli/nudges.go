@@ -0,0 +1,40 @@
+package cli
+
+import (
+	"fmt"
+
+	journalapp "github.com/thatnerdjosh/mindfulness/internal/application/journal"
+	"github.com/thatnerdjosh/mindfulness/internal/infrastructure/config"
+)
+
+// nudgePolicy converts the nudges config section, keeping the built-in
+// weights for anything unset.
+func nudgePolicy(cfg config.Nudges) (journalapp.NudgePolicy, error) {
+	policy := journalapp.DefaultNudgePolicy()
+	policy.Disabled = cfg.Disabled
+	if cfg.DayWeight != nil {
+		if *cfg.DayWeight < 0 {
+			return journalapp.NudgePolicy{}, fmt.Errorf("nudges.day_weight: must not be negative")
+		}
+		policy.DayWeight = *cfg.DayWeight
+	}
+	if cfg.BreakWeight != nil {
+		if *cfg.BreakWeight < 0 {
+			return journalapp.NudgePolicy{}, fmt.Errorf("nudges.break_weight: must not be negative")
+		}
+		policy.BreakWeight = *cfg.BreakWeight
+	}
+	if cfg.BreakDays != nil {
+		if *cfg.BreakDays < 1 {
+			return journalapp.NudgePolicy{}, fmt.Errorf("nudges.break_days: must be at least 1")
+		}
+		policy.BreakDays = *cfg.BreakDays
+	}
+	if cfg.QuietDays != nil {
+		if *cfg.QuietDays < 1 {
+			return journalapp.NudgePolicy{}, fmt.Errorf("nudges.quiet_days: must be at least 1")
+		}
+		policy.QuietDays = *cfg.QuietDays
+	}
+	return policy, nil
+}
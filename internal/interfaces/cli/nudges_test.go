@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	journalapp "github.com/thatnerdjosh/mindfulness/internal/application/journal"
+	"github.com/thatnerdjosh/mindfulness/internal/domain/adherence"
+	"github.com/thatnerdjosh/mindfulness/internal/domain/journal"
+	"github.com/thatnerdjosh/mindfulness/internal/infrastructure/config"
+)
+
+func TestNudgePolicyFromConfig(t *testing.T) {
+	breakDays := 3
+	policy, err := nudgePolicy(config.Nudges{BreakDays: &breakDays})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := journalapp.DefaultNudgePolicy()
+	want.BreakDays = 3
+	if policy != want {
+		t.Fatalf("expected unset weights to keep the defaults, got %+v", policy)
+	}
+
+	negative := -1.0
+	if _, err := nudgePolicy(config.Nudges{DayWeight: &negative}); err == nil || !strings.Contains(err.Error(), "nudges.day_weight") {
+		t.Fatalf("expected an error naming the setting, got %v", err)
+	}
+	zero := 0
+	if _, err := nudgePolicy(config.Nudges{BreakDays: &zero}); err == nil {
+		t.Fatal("expected an error for zero break days")
+	}
+}
+
+func TestRunJournalGuidedNudges(t *testing.T) {
+	reflections := make(map[journal.Precept]string)
+	for _, info := range journal.AllPrecepts() {
+		if info.ID != journal.TrueLove {
+			reflections[info.ID] = "reflected"
+		}
+	}
+	d := newTestData().seed(t, []testEntry{
+		{date: "2024-04-01", reflections: reflections, foundation: journal.FoundationDhamma},
+		{date: "2024-04-29", reflections: reflections, foundation: journal.FoundationDhamma},
+	}, adherence.AdherenceLogEntry{At: time.Date(2024, 4, 30, 20, 0, 0, 0, time.UTC), Precept: journal.NourishmentAndHealing, From: true, To: false})
+	svc := d.journal
+
+	var out bytes.Buffer
+	input := newInput("2024-05-01", "", "steady", "d", "", "", "", "", "", "", "", "")
+	if err := runJournalGuided([]string{"--no-confirm"}, svc, d.adherence, formatText, input, &out, &bytes.Buffer{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got := out.String()
+	order := []string{
+		"* no reflection on True Love in 30 days of journaling\n",
+		"True Love reflection (optional): ",
+		"* Nourishment and Healing marked broken yesterday\n",
+		"Nourishment and Healing reflection (optional): ",
+		"Reverence For Life reflection (optional): ",
+	}
+	last := -1
+	for _, want := range order {
+		at := strings.Index(got, want)
+		if at <= last {
+			t.Fatalf("expected %q after the earlier prompts, got %q", want, got)
+		}
+		last = at
+	}
+	if strings.Count(got, "* ") != 2 {
+		t.Fatalf("expected only the nudged precepts explained, got %q", got)
+	}
+}
@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+const defaultTerminalHeight = 24
+
+// page writes text through $PAGER when out is a terminal and the text does not
+// fit on one screen. Otherwise, or when the pager cannot start, it writes
+// directly to out.
+func page(out io.Writer, text []byte) error {
+	file, ok := out.(*os.File)
+	if !ok || !isTerminal(file) || bytes.Count(text, []byte("\n")) < envInt("LINES", defaultTerminalHeight) {
+		_, err := out.Write(text)
+		return err
+	}
+
+	pager := strings.TrimSpace(os.Getenv("PAGER"))
+	if pager == "" {
+		if _, err := exec.LookPath("less"); err != nil {
+			_, err := out.Write(text)
+			return err
+		}
+		pager = "less -FRX"
+	}
+
+	cmd := exec.Command("sh", "-c", pager)
+	cmd.Stdin = bytes.NewReader(text)
+	cmd.Stdout = file
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		if _, ok := err.(*exec.ExitError); ok {
+			return nil
+		}
+		_, err := out.Write(text)
+		return err
+	}
+	return nil
+}
+
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
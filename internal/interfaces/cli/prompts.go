@@ -0,0 +1,42 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/thatnerdjosh/mindfulness/internal/domain/journal"
+	"github.com/thatnerdjosh/mindfulness/internal/infrastructure/config"
+)
+
+// promptBank builds the reflection questions from the built-in ones and
+// those in the config file.
+func promptBank(cfg config.Prompts) (journal.PromptBank, error) {
+	var prompts []journal.Prompt
+	if !cfg.ReplaceDefaults {
+		prompts = journal.DefaultPrompts()
+	}
+	for _, question := range cfg.Questions {
+		prompts = append(prompts, journal.Prompt{
+			Precept:    journal.Precept(strings.ToLower(strings.TrimSpace(question.Precept))),
+			Foundation: journal.Foundation(strings.ToLower(strings.TrimSpace(question.Foundation))),
+			Text:       question.Text,
+		})
+	}
+	bank, err := journal.NewPromptBank(prompts)
+	if err != nil {
+		return journal.PromptBank{}, fmt.Errorf("prompts config: %w", err)
+	}
+	return bank, nil
+}
+
+// answeredQuestions keeps the questions of the precepts that were reflected
+// on.
+func answeredQuestions(questions map[journal.Precept]string, reflections map[journal.Precept]string) map[journal.Precept]string {
+	answered := make(map[journal.Precept]string, len(reflections))
+	for precept := range reflections {
+		if question, ok := questions[precept]; ok {
+			answered[precept] = question
+		}
+	}
+	return answered
+}
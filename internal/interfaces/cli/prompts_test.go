@@ -0,0 +1,73 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	adherenceapp "github.com/thatnerdjosh/mindfulness/internal/application/adherence"
+	journalapp "github.com/thatnerdjosh/mindfulness/internal/application/journal"
+	"github.com/thatnerdjosh/mindfulness/internal/domain/journal"
+	"github.com/thatnerdjosh/mindfulness/internal/infrastructure/config"
+	"github.com/thatnerdjosh/mindfulness/internal/infrastructure/persistence/memory"
+)
+
+func TestPromptBankFromConfig(t *testing.T) {
+	bank, err := promptBank(config.Prompts{Questions: []config.Question{
+		{Precept: "True-Love", Text: "Who did you hold today?"},
+		{Foundation: "dhamma.hindrances", Text: "Which hindrance?"},
+	}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := len(bank.Prompts()); got != len(journal.DefaultPrompts())+2 {
+		t.Fatalf("expected the questions to extend the built-in ones, got %d", got)
+	}
+
+	replaced, err := promptBank(config.Prompts{ReplaceDefaults: true, Questions: []config.Question{{Precept: "true-love", Text: "Who?"}}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := replaced.Candidates(journal.TrueLove, journal.FoundationDhamma); len(got) != 1 || got[0] != "Who?" {
+		t.Fatalf("expected only the configured question, got %v", got)
+	}
+
+	if _, err := promptBank(config.Prompts{Questions: []config.Question{{Precept: "kindness", Text: "Who?"}}}); err == nil {
+		t.Fatal("expected an error for an unknown precept")
+	}
+}
+
+func TestRunJournalGuidedAsksQuestions(t *testing.T) {
+	bank, err := journal.NewPromptBank([]journal.Prompt{{Precept: journal.TrueLove, Text: "Who did you hold today?"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	repo := memory.NewJournalRepository()
+	svc := journalapp.NewService(repo, journalapp.WithPromptBank(bank))
+
+	var out bytes.Buffer
+	input := newInput("2024-05-01", "", "", "d", "", "", "called my sister", "", "")
+	if err := runJournalGuided([]string{"--no-confirm"}, svc, adherenceapp.NewService(memory.NewAdherenceRepository()), formatText, input, &out, &bytes.Buffer{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out.String(), "Who did you hold today?\nTrue Love reflection (optional): ") {
+		t.Fatalf("expected the question before the reflection, got %q", out.String())
+	}
+	entry, err := svc.LatestEntry(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(entry.Questions) != 1 || entry.Questions[journal.TrueLove] != "Who did you hold today?" {
+		t.Fatalf("expected the answered question to be recorded, got %+v", entry.Questions)
+	}
+
+	out.Reset()
+	date, _ := time.Parse("2006-01-02", "2024-05-01")
+	entries, _ := repo.List(context.Background())
+	renderDay(&out, date, entries, nil, 80)
+	if !strings.Contains(out.String(), "  True Love\n    Q: Who did you hold today?\n    called my sister") {
+		t.Fatalf("expected the question to be shown with the reflection, got %q", out.String())
+	}
+}
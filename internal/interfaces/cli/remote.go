@@ -0,0 +1,308 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	mergeapp "github.com/thatnerdjosh/mindfulness/internal/application/merge"
+	"github.com/thatnerdjosh/mindfulness/internal/infrastructure/config"
+	"github.com/thatnerdjosh/mindfulness/internal/infrastructure/persistence/flatfile"
+	"github.com/thatnerdjosh/mindfulness/internal/infrastructure/webdav"
+	"github.com/thatnerdjosh/mindfulness/internal/interfaces/schema"
+)
+
+// maxPushAttempts bounds how often a push merges and retries when another
+// device keeps uploading in between.
+const maxPushAttempts = 3
+
+type syncDirection string
+
+const (
+	syncPush syncDirection = "push"
+	syncPull syncDirection = "pull"
+)
+
+// remoteFile is a local data file; its base name is its name on the remote.
+type remoteFile struct {
+	path string
+	kind flatfile.SyncFileKind
+}
+
+func (f remoteFile) name() string {
+	return filepath.Base(f.path)
+}
+
+// remote lists the files in sync order. The adherence state goes last so it
+// is recomputed from the merged log before it is uploaded. Archived log
+// segments are not synced as files: the log is uploaded with its whole
+// history instead, so another device merges and folds it from the start.
+func (f dataFiles) remote() []remoteFile {
+	return []remoteFile{
+		{path: f.journal, kind: flatfile.SyncFileJournal},
+		{path: f.adherenceLog, kind: flatfile.SyncFileAdherenceLog},
+		{path: f.adherence, kind: flatfile.SyncFileAdherenceState},
+	}
+}
+
+// remoteSync carries the client and the ETags known from the last sync.
+type remoteSync struct {
+	svc       *mergeapp.Service
+	client    *webdav.Client
+	statePath string
+	state     webdav.State
+}
+
+func runSyncRemote(direction syncDirection, args []string, svc *mergeapp.Service, files dataFiles, format outputFormat, out io.Writer, errOut io.Writer) error {
+	fs := flag.NewFlagSet("sync "+string(direction), flag.ContinueOnError)
+	fs.SetOutput(errOut)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	configPath, err := config.DefaultPath()
+	if err != nil {
+		return err
+	}
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return err
+	}
+	statePath, err := webdav.DefaultStatePath()
+	if err != nil {
+		return err
+	}
+	return syncWithRemote(direction, cfg.WebDAV, statePath, nil, svc, files, format, out)
+}
+
+func syncWithRemote(direction syncDirection, remoteCfg config.WebDAV, statePath string, httpClient *http.Client, svc *mergeapp.Service, files dataFiles, format outputFormat, out io.Writer) error {
+	if !remoteCfg.Configured() {
+		return errors.New("no WebDAV remote configured (use mt sync remote --url URL)")
+	}
+	client, err := webdav.NewClient(remoteCfg.URL, remoteCfg.Username, remoteCfg.Password, httpClient)
+	if err != nil {
+		return err
+	}
+	state, err := webdav.LoadState(statePath)
+	if err != nil {
+		return err
+	}
+	state.Use(remoteCfg.URL)
+	rs := &remoteSync{svc: svc, client: client, statePath: statePath, state: state}
+
+	results := make([]schema.SyncResult, 0, 3)
+	for _, file := range files.remote() {
+		var result schema.SyncResult
+		if direction == syncPush {
+			result, err = rs.push(context.Background(), file)
+		} else {
+			result, err = rs.pull(context.Background(), file)
+		}
+		// Keep the ETags learned so far even if a later file fails.
+		if saveErr := webdav.SaveState(rs.statePath, rs.state); saveErr != nil && err == nil {
+			err = saveErr
+		}
+		if err != nil {
+			return fmt.Errorf("%s %s: %w", direction, file.name(), err)
+		}
+		results = append(results, result)
+	}
+
+	if format != formatText {
+		return writeList(out, format, results)
+	}
+	for _, result := range results {
+		writeSyncResult(out, result)
+	}
+	return nil
+}
+
+// push uploads the local file. If the remote changed since the last sync, the
+// remote copy is merged into the local data and the upload is retried with
+// the newer ETag.
+func (rs *remoteSync) push(ctx context.Context, file remoteFile) (schema.SyncResult, error) {
+	result := schema.SyncResult{File: file.name(), Action: "unchanged", Conflicts: []schema.MergeConflict{}}
+	for attempt := 0; attempt < maxPushAttempts; attempt++ {
+		data, err := rs.read(ctx, file)
+		if os.IsNotExist(err) {
+			// Nothing local yet: adopt the remote copy, if any, so it is not
+			// lost, and upload whatever that produces.
+			pulled, err := rs.pull(ctx, file)
+			if err != nil || pulled.Action == "missing" {
+				return pulled, err
+			}
+			result.Action = "merged"
+			result.Added += pulled.Added
+			result.Conflicts = append(result.Conflicts, pulled.Conflicts...)
+			if data, err = rs.read(ctx, file); err != nil {
+				return schema.SyncResult{}, fmt.Errorf("read local file: %w", err)
+			}
+		} else if err != nil {
+			return schema.SyncResult{}, fmt.Errorf("read local file: %w", err)
+		}
+
+		etag, err := rs.client.Put(ctx, file.name(), data, rs.state.ETags[file.name()])
+		if err == nil {
+			rs.state.ETags[file.name()] = etag
+			if result.Action == "unchanged" {
+				result.Action = "uploaded"
+			}
+			return result, nil
+		}
+		if !errors.Is(err, webdav.ErrPreconditionFailed) {
+			return schema.SyncResult{}, err
+		}
+
+		remote, remoteETag, err := rs.client.Get(ctx, file.name())
+		if errors.Is(err, webdav.ErrNotFound) {
+			// Deleted remotely since the last sync; upload as a new file.
+			delete(rs.state.ETags, file.name())
+			continue
+		}
+		if err != nil {
+			return schema.SyncResult{}, err
+		}
+		report, err := mergeData(rs.svc, file.name(), file.kind, remote)
+		if err != nil {
+			return schema.SyncResult{}, fmt.Errorf("merge remote copy: %w", err)
+		}
+		rs.state.ETags[file.name()] = remoteETag
+		result.Action = "merged"
+		result.Added += report.Added
+		result.Conflicts = append(result.Conflicts, report.Conflicts...)
+	}
+	return schema.SyncResult{}, fmt.Errorf("remote kept changing; try again")
+}
+
+// read returns what is uploaded for file: its contents, or for the adherence
+// log the whole history including archived segments. It fails with a
+// not-exist error when there is no local file.
+func (rs *remoteSync) read(ctx context.Context, file remoteFile) ([]byte, error) {
+	data, err := os.ReadFile(file.path)
+	if err != nil || file.kind != flatfile.SyncFileAdherenceLog {
+		return data, err
+	}
+	log, err := rs.svc.AdherenceLog(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return flatfile.EncodeAdherenceLog(log)
+}
+
+// pull merges the remote file into the local data when it changed since the
+// last sync.
+func (rs *remoteSync) pull(ctx context.Context, file remoteFile) (schema.SyncResult, error) {
+	result := schema.SyncResult{File: file.name(), Action: "unchanged", Conflicts: []schema.MergeConflict{}}
+	remote, etag, err := rs.client.Get(ctx, file.name())
+	if errors.Is(err, webdav.ErrNotFound) {
+		result.Action = "missing"
+		return result, nil
+	}
+	if err != nil {
+		return schema.SyncResult{}, err
+	}
+	if etag != "" && etag == rs.state.ETags[file.name()] {
+		return result, nil
+	}
+
+	report, err := mergeData(rs.svc, file.name(), file.kind, remote)
+	if err != nil {
+		return schema.SyncResult{}, fmt.Errorf("merge remote copy: %w", err)
+	}
+	rs.state.ETags[file.name()] = etag
+	result.Action = "merged"
+	result.Added = report.Added
+	result.Conflicts = report.Conflicts
+	return result, nil
+}
+
+func writeSyncResult(out io.Writer, result schema.SyncResult) {
+	switch result.Action {
+	case "missing":
+		fmt.Fprintf(out, "%s: nothing to sync\n", result.File)
+	case "merged":
+		fmt.Fprintf(out, "%s: merged remote changes (%d added)\n", result.File, result.Added)
+	default:
+		fmt.Fprintf(out, "%s: %s\n", result.File, result.Action)
+	}
+	writeConflicts(out, result.Conflicts)
+}
+
+func runSyncRemoteConfig(args []string, format outputFormat, in io.Reader, out io.Writer, errOut io.Writer) error {
+	fs := flag.NewFlagSet("sync remote", flag.ContinueOnError)
+	fs.SetOutput(errOut)
+	rawURL := fs.String("url", "", "WebDAV collection URL")
+	username := fs.String("username", "", "WebDAV username")
+	passwordStdin := fs.Bool("password-stdin", false, "read the WebDAV password from the first line of stdin")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	configPath, err := config.DefaultPath()
+	if err != nil {
+		return err
+	}
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return err
+	}
+
+	changed := false
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "url":
+			cfg.WebDAV.URL = strings.TrimSpace(*rawURL)
+		case "username":
+			cfg.WebDAV.Username = strings.TrimSpace(*username)
+		}
+		changed = true
+	})
+	if *passwordStdin {
+		line, err := bufio.NewReader(in).ReadString('\n')
+		if err != nil && err != io.EOF {
+			return fmt.Errorf("read password: %w", err)
+		}
+		cfg.WebDAV.Password = strings.TrimRight(line, "\r\n")
+	}
+	if changed {
+		if cfg.WebDAV.Configured() {
+			if _, err := webdav.NewClient(cfg.WebDAV.URL, "", "", nil); err != nil {
+				return err
+			}
+		}
+		if err := config.Save(configPath, cfg); err != nil {
+			return err
+		}
+	}
+
+	password := ""
+	if cfg.WebDAV.Password != "" {
+		password = "********"
+	}
+	if format != formatText {
+		return writeObject(out, format, map[string]string{
+			"url":      cfg.WebDAV.URL,
+			"username": cfg.WebDAV.Username,
+			"password": password,
+		})
+	}
+	if !cfg.WebDAV.Configured() {
+		fmt.Fprintln(out, "no WebDAV remote configured")
+		return nil
+	}
+	fmt.Fprintf(out, "url: %s\n", cfg.WebDAV.URL)
+	if cfg.WebDAV.Username != "" {
+		fmt.Fprintf(out, "username: %s\n", cfg.WebDAV.Username)
+	}
+	if password != "" {
+		fmt.Fprintln(out, "password: set")
+	}
+	return nil
+}
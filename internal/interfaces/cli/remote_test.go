@@ -0,0 +1,187 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	adherenceapp "github.com/thatnerdjosh/mindfulness/internal/application/adherence"
+	journalapp "github.com/thatnerdjosh/mindfulness/internal/application/journal"
+	mergeapp "github.com/thatnerdjosh/mindfulness/internal/application/merge"
+	"github.com/thatnerdjosh/mindfulness/internal/application/unitofwork"
+	adherencedomain "github.com/thatnerdjosh/mindfulness/internal/domain/adherence"
+	"github.com/thatnerdjosh/mindfulness/internal/domain/journal"
+	"github.com/thatnerdjosh/mindfulness/internal/infrastructure/config"
+	"github.com/thatnerdjosh/mindfulness/internal/infrastructure/persistence/flatfile"
+	"github.com/thatnerdjosh/mindfulness/internal/infrastructure/webdav/webdavtest"
+	"github.com/thatnerdjosh/mindfulness/internal/interfaces/schema"
+)
+
+type testDevice struct {
+	files     dataFiles
+	statePath string
+	journal   *journalapp.Service
+	adherence *adherenceapp.Service
+	merge     *mergeapp.Service
+}
+
+// newTestDevice opens fresh repositories, as a new mt process would.
+func newTestDevice(t *testing.T, dir string) testDevice {
+	t.Helper()
+	files := dataFiles{
+		journal:      filepath.Join(dir, "journal.json"),
+		adherence:    filepath.Join(dir, "adherence.json"),
+		adherenceLog: filepath.Join(dir, "adherence.log.jsonl"),
+	}
+	journalRepo, err := flatfile.NewJournalRepository(files.journal)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	adherenceRepo, err := flatfile.NewAdherenceRepository(files.adherence, files.adherenceLog)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return testDevice{
+		files:     files,
+		statePath: filepath.Join(dir, "state", "webdav.json"),
+		journal:   journalapp.NewService(journalRepo),
+		adherence: adherenceapp.NewService(adherenceRepo),
+		merge:     mergeapp.NewService(unitofwork.Direct(journalRepo, adherenceRepo, nil)),
+	}
+}
+
+func (d testDevice) sync(t *testing.T, server *webdavtest.Server, direction syncDirection) []schema.SyncResult {
+	t.Helper()
+	remote := config.WebDAV{URL: server.CollectionURL(), Username: "alice", Password: "secret"}
+	var out bytes.Buffer
+	if err := syncWithRemote(direction, remote, d.statePath, server.Client(), d.merge, d.files, formatJSON, &out); err != nil {
+		t.Fatalf("%s: unexpected error: %v", direction, err)
+	}
+	var results []schema.SyncResult
+	if err := json.Unmarshal(out.Bytes(), &results); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	return results
+}
+
+func TestSyncPushPullMergesDevices(t *testing.T) {
+	server := webdavtest.NewServer("alice", "secret")
+	defer server.Close()
+
+	laptopDir, desktopDir := t.TempDir(), t.TempDir()
+	laptop := newTestDevice(t, laptopDir)
+	desktop := newTestDevice(t, desktopDir)
+	ctx := context.Background()
+	day := time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC)
+
+	if _, err := laptop.journal.RecordEntry(ctx, day, nil, "from the laptop", "", journal.FoundationDhamma); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	next := adherencedomain.DefaultAdherence()
+	next[journal.TrueLove] = false
+	if _, err := laptop.adherence.Set(ctx, next, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	results := laptop.sync(t, server, syncPush)
+	for _, result := range results {
+		if result.Action != "uploaded" {
+			t.Fatalf("expected every file to be uploaded, got %+v", results)
+		}
+	}
+
+	if _, err := desktop.journal.RecordEntry(ctx, day, nil, "from the desktop", "", journal.FoundationDhamma); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	results = desktop.sync(t, server, syncPush)
+	if results[0].File != "journal.json" || results[0].Action != "merged" || results[0].Added != 1 {
+		t.Fatalf("expected desktop push to merge the laptop entry first, got %+v", results[0])
+	}
+
+	desktop = newTestDevice(t, desktopDir)
+	state, err := desktop.adherence.Current(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if state[journal.TrueLove] {
+		t.Fatalf("expected desktop state to be recomputed from the merged log")
+	}
+
+	results = laptop.sync(t, server, syncPull)
+	if results[0].Action != "merged" || results[0].Added != 1 {
+		t.Fatalf("expected laptop pull to add the desktop entry, got %+v", results[0])
+	}
+	laptop = newTestDevice(t, laptopDir)
+	entries, err := laptop.journal.ListEntries(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries after pull, got %d", len(entries))
+	}
+
+	for _, result := range laptop.sync(t, server, syncPull) {
+		if result.Action != "unchanged" {
+			t.Fatalf("expected nothing new on a repeated pull, got %+v", result)
+		}
+	}
+}
+
+func TestSyncPushIncludesArchivedLog(t *testing.T) {
+	server := webdavtest.NewServer("alice", "secret")
+	defer server.Close()
+
+	laptop := newTestDevice(t, t.TempDir())
+	desktopDir := t.TempDir()
+	desktop := newTestDevice(t, desktopDir)
+	ctx := context.Background()
+
+	if _, err := laptop.adherence.Set(ctx, adherencedomain.Adherence{journal.TrueLove: false}, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, archived, err := laptop.adherence.ArchiveLog(ctx); err != nil || !archived {
+		t.Fatalf("expected the log to be archived, got %v", err)
+	}
+	if _, err := laptop.adherence.Set(ctx, adherencedomain.Adherence{journal.TrueHappiness: false}, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	laptop.sync(t, server, syncPush)
+	desktop.sync(t, server, syncPull)
+
+	desktop = newTestDevice(t, desktopDir)
+	log, err := desktop.adherence.Log(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var precepts []journal.Precept
+	for _, change := range log {
+		precepts = append(precepts, change.Precept)
+	}
+	if len(precepts) != 2 || precepts[0] != journal.TrueLove || precepts[1] != journal.TrueHappiness {
+		t.Fatalf("expected the archived change to reach the other device, got %v", precepts)
+	}
+	state, _ := desktop.adherence.Current(ctx)
+	if state[journal.TrueLove] || state[journal.TrueHappiness] {
+		t.Fatalf("expected the state folded from the whole history, got %v", state)
+	}
+}
+
+func TestSyncRemoteErrors(t *testing.T) {
+	server := webdavtest.NewServer("alice", "secret")
+	defer server.Close()
+	device := newTestDevice(t, t.TempDir())
+
+	err := syncWithRemote(syncPull, config.WebDAV{}, device.statePath, nil, device.merge, device.files, formatText, &bytes.Buffer{})
+	if err == nil || !strings.Contains(err.Error(), "no WebDAV remote") {
+		t.Fatalf("expected missing remote error, got %v", err)
+	}
+
+	bad := config.WebDAV{URL: server.CollectionURL(), Username: "alice", Password: "wrong"}
+	err = syncWithRemote(syncPull, bad, device.statePath, server.Client(), device.merge, device.files, formatText, &bytes.Buffer{})
+	if err == nil || !strings.Contains(err.Error(), "credentials") {
+		t.Fatalf("expected credentials error, got %v", err)
+	}
+}
@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	adherenceapp "github.com/thatnerdjosh/mindfulness/internal/application/adherence"
+	analyticsapp "github.com/thatnerdjosh/mindfulness/internal/application/analytics"
+	journalapp "github.com/thatnerdjosh/mindfulness/internal/application/journal"
+	"github.com/thatnerdjosh/mindfulness/internal/domain/journal"
+	"github.com/thatnerdjosh/mindfulness/internal/interfaces/report"
+)
+
+// runReport executes a report template against the journal. Structured
+// formats print the template's data instead, with the field names a
+// template uses.
+func runReport(args []string, svc *journalapp.Service, adherenceSvc *adherenceapp.Service, analytics *analyticsapp.Service, format outputFormat, out io.Writer, errOut io.Writer) error {
+	fs := flag.NewFlagSet("report", flag.ContinueOnError)
+	fs.SetOutput(errOut)
+	templatePath := fs.String("template", "", "template file to execute")
+	builtin := fs.String("builtin", "", "built-in template to execute: "+strings.Join(report.Builtins(), ", ")+" (default summary)")
+	asHTML := fs.Bool("html", false, "parse the template as html/template regardless of its file name")
+	sinceStr := fs.String("since", "", "first day to include (YYYY-MM-DD, default the first entry)")
+	untilStr := fs.String("until", "", "last day to include (YYYY-MM-DD, default today)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return errors.New("usage: mt report [--template FILE | --builtin NAME] [--html] [--since YYYY-MM-DD] [--until YYYY-MM-DD]")
+	}
+	if *templatePath != "" && *builtin != "" {
+		return errors.New("--template and --builtin cannot be combined")
+	}
+
+	var tmpl report.Template
+	var err error
+	switch {
+	case format != formatText:
+	case *templatePath != "":
+		data, err := os.ReadFile(*templatePath)
+		if err != nil {
+			return fmt.Errorf("read template: %w", err)
+		}
+		if tmpl, err = report.Parse(*templatePath, string(data), *asHTML || report.IsHTML(*templatePath)); err != nil {
+			return err
+		}
+	case *builtin != "":
+		if tmpl, err = report.Builtin(*builtin); err != nil {
+			return err
+		}
+	default:
+		if tmpl, err = report.Builtin("summary"); err != nil {
+			return err
+		}
+	}
+
+	var since, until time.Time
+	if *sinceStr != "" {
+		if since, err = parseDate(*sinceStr); err != nil {
+			return err
+		}
+	}
+	if until, err = parseDate(*untilStr); err != nil {
+		return err
+	}
+	model, err := reportModel(context.Background(), svc, adherenceSvc, analytics, since, until)
+	if err != nil {
+		return err
+	}
+
+	if format != formatText {
+		return writeObject(out, format, model)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, model); err != nil {
+		return err
+	}
+	_, err = out.Write(buf.Bytes())
+	return err
+}
+
+// reportModel gathers the data a report template sees for the days from
+// since, or the first entry, to until.
+func reportModel(ctx context.Context, svc *journalapp.Service, adherenceSvc *adherenceapp.Service, analytics *analyticsapp.Service, since time.Time, until time.Time) (report.Model, error) {
+	stats, err := analytics.Stats(ctx, since, until)
+	if err != nil {
+		return report.Model{}, err
+	}
+	model := report.Model{
+		Since:     stats.Since,
+		Until:     stats.Until,
+		Generated: time.Now(),
+		Stats:     stats,
+		Precepts:  journal.AllPrecepts(),
+	}
+	if model.Since.IsZero() {
+		model.Since = since
+	}
+
+	if model.Entries, err = svc.QueryEntries(ctx, journalapp.Query{Since: since, Until: until}); err != nil {
+		return report.Model{}, err
+	}
+	sort.SliceStable(model.Entries, func(i, j int) bool {
+		return model.Entries[i].Timestamp.Before(model.Entries[j].Timestamp)
+	})
+
+	if model.Adherence, err = adherenceSvc.Current(ctx); err != nil {
+		return report.Model{}, err
+	}
+	log, err := adherenceSvc.Log(ctx)
+	if err != nil {
+		return report.Model{}, err
+	}
+	end := until.AddDate(0, 0, 1)
+	for _, change := range log {
+		if (!since.IsZero() && change.At.Before(since)) || !change.At.Before(end) {
+			continue
+		}
+		model.Log = append(model.Log, change)
+	}
+	sort.SliceStable(model.Log, func(i, j int) bool {
+		return model.Log[i].At.Before(model.Log[j].At)
+	})
+	return model, nil
+}
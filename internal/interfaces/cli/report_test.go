@@ -0,0 +1,98 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thatnerdjosh/mindfulness/internal/domain/journal"
+)
+
+var reportEntries = []testEntry{
+	{date: "2024-04-29", reflections: map[journal.Precept]string{journal.TrueLove: "listened"}, note: "first", foundation: journal.FoundationKaya},
+	{date: "2024-05-02", reflections: map[journal.Precept]string{journal.TrueLove: "listened"}, note: "<second>", foundation: journal.FoundationKaya},
+	{date: "2024-06-01", reflections: map[journal.Precept]string{journal.TrueLove: "listened"}, note: "later", foundation: journal.FoundationKaya},
+}
+
+func TestRunReportTemplateFile(t *testing.T) {
+	d := newTestData().seed(t, reportEntries)
+	svc, adherenceSvc, analytics := d.journal, d.adherence, d.analytics
+	dir := t.TempDir()
+	textPath := filepath.Join(dir, "notes.tmpl")
+	if err := os.WriteFile(textPath, []byte(`{{date .Since}}..{{date .Until}}{{range byWeek .Entries}} [{{date .Start}}{{range .Entries}} {{.Note}}{{end}}]{{end}} {{.Stats.Entries}}`), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	var out bytes.Buffer
+	args := []string{"--template", textPath, "--since", "2024-04-29", "--until", "2024-05-31"}
+	if err := runReport(args, svc, adherenceSvc, analytics, formatText, &out, &bytes.Buffer{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, want := out.String(), "2024-04-29..2024-05-31 [2024-04-29 first <second>] 2"; got != want {
+		t.Fatalf("unexpected output:\n got %q\nwant %q", got, want)
+	}
+
+	htmlPath := filepath.Join(dir, "notes.html.tmpl")
+	if err := os.WriteFile(htmlPath, []byte(`{{range .Entries}}<p>{{.Note}}</p>{{end}}`), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	out.Reset()
+	args = []string{"--template", htmlPath, "--since", "2024-05-01", "--until", "2024-05-31"}
+	if err := runReport(args, svc, adherenceSvc, analytics, formatText, &out, &bytes.Buffer{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := out.String(); got != "<p>&lt;second&gt;</p>" {
+		t.Fatalf("expected an escaped HTML report, got %q", got)
+	}
+
+	if err := os.WriteFile(textPath, []byte(`{{.Missing}}`), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := runReport([]string{"--template", textPath}, svc, adherenceSvc, analytics, formatText, &out, &bytes.Buffer{}); err == nil || !strings.Contains(err.Error(), "Missing") {
+		t.Fatalf("expected the template error, got %v", err)
+	}
+}
+
+func TestRunReportBuiltinAndJSON(t *testing.T) {
+	d := newTestData().seed(t, reportEntries)
+	svc, adherenceSvc, analytics := d.journal, d.adherence, d.analytics
+
+	var out bytes.Buffer
+	if err := runReport([]string{"--since", "2024-04-01", "--until", "2024-06-30"}, svc, adherenceSvc, analytics, formatText, &out, &bytes.Buffer{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(out.String(), "Journal 2024-04-01 to 2024-06-30\n") || !strings.Contains(out.String(), "Sat 2024-06-01") {
+		t.Fatalf("expected the summary template, got %q", out.String())
+	}
+
+	out.Reset()
+	if err := runReport([]string{"--builtin", "weekly", "--until", "2024-06-30"}, svc, adherenceSvc, analytics, formatText, &out, &bytes.Buffer{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out.String(), "## Week of 2024-05-27") {
+		t.Fatalf("expected the weekly template, got %q", out.String())
+	}
+	if err := runReport([]string{"--builtin", "weekly", "--template", "x.tmpl"}, svc, adherenceSvc, analytics, formatText, &out, &bytes.Buffer{}); err == nil {
+		t.Fatal("expected an error combining --builtin and --template")
+	}
+
+	out.Reset()
+	if err := runReport([]string{"--until", "2024-06-30"}, svc, adherenceSvc, analytics, formatJSON, &out, &bytes.Buffer{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var model struct {
+		Since   time.Time
+		Entries []struct{ Note string }
+		Stats   struct{ Entries int }
+	}
+	if err := json.Unmarshal(out.Bytes(), &model); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(model.Entries) != 3 || model.Stats.Entries != 3 || model.Since.Format("2006-01-02") != "2024-04-29" {
+		t.Fatalf("expected the template data with its field names, got %+v", model)
+	}
+}
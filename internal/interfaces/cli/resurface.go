@@ -0,0 +1,120 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+
+	journalapp "github.com/thatnerdjosh/mindfulness/internal/application/journal"
+	"github.com/thatnerdjosh/mindfulness/internal/domain/journal"
+	"github.com/thatnerdjosh/mindfulness/internal/interfaces/schema"
+)
+
+// runJournalResurface shows past entries worth re-reading today and marks
+// them as revisited.
+func runJournalResurface(args []string, svc *journalapp.Service, format outputFormat, out io.Writer, errOut io.Writer) error {
+	fs := flag.NewFlagSet("journal resurface", flag.ContinueOnError)
+	fs.SetOutput(errOut)
+	dateStr := fs.String("date", "", "day to resurface entries for (YYYY-MM-DD, default today)")
+	limit := fs.Int("limit", 3, "how many older entries due for re-reading to show; 0 shows only this day's")
+	peek := fs.Bool("peek", false, "show the entries without marking them as revisited")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *limit < 0 {
+		return fmt.Errorf("--limit must not be negative")
+	}
+
+	date, err := parseDate(*dateStr)
+	if err != nil {
+		return err
+	}
+	resurfacing, err := svc.Resurface(context.Background(), date, *limit)
+	if err != nil {
+		return err
+	}
+	if *limit == 0 {
+		resurfacing.Due = nil
+	}
+
+	if !*peek {
+		var entries []journal.Entry
+		for _, item := range resurfacing.Entries() {
+			entries = append(entries, item.Entry)
+		}
+		if err := svc.MarkRevisited(context.Background(), entries...); err != nil {
+			return err
+		}
+	}
+
+	if format != formatText {
+		result := schema.Resurfacing{OnThisDay: []schema.Resurfaced{}, Due: []schema.Resurfaced{}}
+		for _, item := range resurfacing.OnThisDay {
+			result.OnThisDay = append(result.OnThisDay, schema.Resurfaced{Ago: item.Ago, Entry: schema.FromEntry(item.Entry)})
+		}
+		for _, item := range resurfacing.Due {
+			result.Due = append(result.Due, schema.Resurfaced{Due: item.Due.Format("2006-01-02"), Entry: schema.FromEntry(item.Entry)})
+		}
+		return writeObject(out, format, result)
+	}
+	if len(resurfacing.Entries()) == 0 {
+		fmt.Fprintln(out, "nothing to resurface today")
+		return nil
+	}
+
+	var buf bytes.Buffer
+	width := terminalWidth()
+	if len(resurfacing.OnThisDay) > 0 {
+		fmt.Fprintln(&buf, "On this day")
+		for _, item := range resurfacing.OnThisDay {
+			fmt.Fprintln(&buf, "")
+			renderEntry(&buf, item.Entry, item.Ago+"  "+item.Entry.Timestamp.Local().Format("2006-01-02 15:04"), width)
+		}
+	}
+	if len(resurfacing.Due) > 0 {
+		if len(resurfacing.OnThisDay) > 0 {
+			fmt.Fprintln(&buf, "")
+		}
+		fmt.Fprintln(&buf, "Worth another look")
+		for _, item := range resurfacing.Due {
+			fmt.Fprintln(&buf, "")
+			renderEntry(&buf, item.Entry, item.Entry.Timestamp.Local().Format("2006-01-02 15:04"), width)
+		}
+	}
+	if !*peek {
+		fmt.Fprintf(&buf, "\nmarked %d entry(ies) as revisited\n", len(resurfacing.Entries()))
+	}
+	return page(out, buf.Bytes())
+}
+
+// resurfaceReflection shows a past reflection on precept before it is
+// written about again, preferring one from this day in an earlier month or
+// year. Entries already in shown are skipped; the one shown is added.
+func resurfaceReflection(out io.Writer, resurfacing journalapp.Resurfacing, precept journal.Precept, shown map[string]journal.Entry) {
+	for _, item := range resurfacing.Entries() {
+		reflection, ok := item.Entry.Reflections[precept]
+		fingerprint := item.Entry.Fingerprint()
+		if _, seen := shown[fingerprint]; !ok || seen {
+			continue
+		}
+		shown[fingerprint] = item.Entry
+		when := item.Entry.Date.Format("2006-01-02")
+		if item.Ago != "" {
+			when = item.Ago + ", " + when
+		}
+		writeWrapped(out, fmt.Sprintf("You wrote (%s): %s", when, reflection), "", terminalWidth())
+		return
+	}
+}
+
+// markShown records the entries resurfaced during guided journaling as
+// revisited.
+func markShown(svc *journalapp.Service, shown map[string]journal.Entry) error {
+	entries := make([]journal.Entry, 0, len(shown))
+	for _, entry := range shown {
+		entries = append(entries, entry)
+	}
+	return svc.MarkRevisited(context.Background(), entries...)
+}
@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/thatnerdjosh/mindfulness/internal/domain/journal"
+	"github.com/thatnerdjosh/mindfulness/internal/interfaces/schema"
+)
+
+var resurfaceEntries = []testEntry{
+	{date: "2024-04-01", reflections: map[journal.Precept]string{journal.TrueLove: "listened to my brother"}, foundation: journal.FoundationDhamma},
+	{date: "2023-05-01", reflections: map[journal.Precept]string{journal.TrueLove: "wrote to an old friend"}, foundation: journal.FoundationDhamma},
+	{date: "2023-09-15", reflections: map[journal.Precept]string{journal.TrueLove: "kept a promise"}, foundation: journal.FoundationDhamma},
+}
+
+func TestRunJournalResurface(t *testing.T) {
+	svc := newTestData().seed(t, resurfaceEntries).journal
+
+	var out bytes.Buffer
+	if err := runJournalResurface([]string{"--date", "2024-05-01", "--peek"}, svc, formatText, &out, &bytes.Buffer{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got := out.String()
+	for _, want := range []string{"On this day", "1 month ago", "listened to my brother", "1 year ago", "wrote to an old friend", "Worth another look", "kept a promise"} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected %q in output, got %q", want, got)
+		}
+	}
+	if strings.Contains(got, "marked") {
+		t.Fatalf("expected --peek not to mark entries, got %q", got)
+	}
+
+	out.Reset()
+	if err := runJournalResurface([]string{"--date", "2024-05-01"}, svc, formatJSON, &out, &bytes.Buffer{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var result schema.Resurfacing
+	if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(result.OnThisDay) != 2 || result.OnThisDay[0].Ago != "1 month ago" || len(result.Due) != 1 || result.Due[0].Due != "2023-10-15" {
+		t.Fatalf("unexpected resurfacing: %+v", result)
+	}
+
+	out.Reset()
+	if err := runJournalResurface([]string{"--date", "2024-05-02"}, svc, formatText, &out, &bytes.Buffer{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := out.String(); got != "nothing to resurface today\n" {
+		t.Fatalf("expected revisited entries to rest, got %q", got)
+	}
+}
+
+func TestRunJournalGuidedResurfaces(t *testing.T) {
+	d := newTestData().seed(t, resurfaceEntries)
+	svc, revisits := d.journal, d.revisits
+
+	var out bytes.Buffer
+	input := newInput("2024-05-01", "", "", "d", "", "", "called my sister", "", "")
+	if err := runJournalGuided([]string{"--no-confirm", "--resurface"}, svc, d.adherence, formatText, input, &out, &bytes.Buffer{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got := out.String()
+	shown := strings.Index(got, "You wrote (1 month ago, 2024-04-01): listened to my brother\n")
+	if shown < 0 || shown < strings.Index(got, "True Happiness reflection") || shown > strings.Index(got, "True Love reflection") {
+		t.Fatalf("expected a past reflection before the prompt, got %q", got)
+	}
+
+	recorded, err := revisits.List(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(recorded) != 1 {
+		t.Fatalf("expected only the shown entry to be marked revisited, got %+v", recorded)
+	}
+}
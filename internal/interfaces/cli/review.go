@@ -0,0 +1,482 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	analyticsapp "github.com/thatnerdjosh/mindfulness/internal/application/analytics"
+	reviewapp "github.com/thatnerdjosh/mindfulness/internal/application/review"
+	"github.com/thatnerdjosh/mindfulness/internal/domain/journal"
+	"github.com/thatnerdjosh/mindfulness/internal/interfaces/schema"
+)
+
+func runReview(args []string, svc *reviewapp.Service, format outputFormat, in io.Reader, out io.Writer, errOut io.Writer) error {
+	if len(args) > 0 {
+		switch args[0] {
+		case "help", "-h", "--help":
+			printReviewUsage(out)
+			return nil
+		}
+	}
+	if len(args) < 1 || strings.HasPrefix(args[0], "-") {
+		printReviewUsage(errOut)
+		return errors.New("review period required")
+	}
+	period, err := journal.ParseReviewPeriod(args[0])
+	if err != nil {
+		return err
+	}
+
+	fs := flag.NewFlagSet("review", flag.ContinueOnError)
+	fs.SetOutput(errOut)
+	dateStr := fs.String("date", "", "a day in the period to review (YYYY-MM-DD, default today)")
+	as := fs.String("as", "text", "how to write the review: text, markdown or html")
+	noPrompt := fs.Bool("no-prompt", false, "skip the closing questions and save nothing")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return errors.New("usage: mt review week|month|year [--date YYYY-MM-DD] [--as text|markdown|html] [--no-prompt]")
+	}
+
+	var writer reviewWriter
+	var buf bytes.Buffer
+	switch *as {
+	case "text":
+		writer = &textReview{out: &buf, width: terminalWidth()}
+	case "markdown", "md":
+		writer = &markdownReview{out: &buf}
+	case "html":
+		writer = &htmlReview{out: &buf}
+	default:
+		return fmt.Errorf("invalid --as %q: expected text, markdown or html", *as)
+	}
+
+	date, err := parseDate(*dateStr)
+	if err != nil {
+		return err
+	}
+	report, err := svc.Build(context.Background(), period, date)
+	if err != nil {
+		return err
+	}
+
+	promptOut := out
+	if format != formatText {
+		if err := writeObject(out, format, fromReport(report)); err != nil {
+			return err
+		}
+		promptOut = errOut
+	} else {
+		writeReport(writer, report)
+		if *as != "text" {
+			promptOut = errOut
+		}
+		if err := page(out, buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	if *noPrompt {
+		return nil
+	}
+	return promptReview(bufio.NewReader(in), promptOut, svc, report, date)
+}
+
+// promptReview closes a review by asking what stood out and, for each
+// neglected precept, what to intend for the next period, then saves the
+// answers as a review entry.
+func promptReview(reader *bufio.Reader, out io.Writer, svc *reviewapp.Service, report reviewapp.Report, date time.Time) error {
+	fmt.Fprintln(out)
+	note, err := prompt(reader, out, fmt.Sprintf("What stood out this %s? (optional): ", report.Review.Period))
+	if err != nil {
+		return err
+	}
+	intentions := make(map[journal.Precept]string)
+	for _, neglected := range report.Neglected {
+		fmt.Fprintln(out, reviewapp.IntentionQuestion(neglected.Precept, report.Review.Period))
+		intention, err := prompt(reader, out, fmt.Sprintf("%s intention (optional): ", preceptTitle(neglected.Precept)))
+		if err != nil {
+			return err
+		}
+		if intention != "" {
+			intentions[neglected.Precept] = intention
+		}
+	}
+	if note == "" && len(intentions) == 0 {
+		fmt.Fprintln(out, "review not saved")
+		return nil
+	}
+
+	if _, err := svc.Save(context.Background(), report.Review, date, note, intentions); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "saved %s review %s to %s\n", report.Review.Period, report.Review.Start.Format("2006-01-02"), report.Review.End().Format("2006-01-02"))
+	return nil
+}
+
+// reviewTitle names a review, as in "Week review 2024-04-29 to 2024-05-05".
+func reviewTitle(review journal.Review) string {
+	period := string(review.Period)
+	return fmt.Sprintf("%s%s review %s to %s", strings.ToUpper(period[:1]), period[1:], review.Start.Format("2006-01-02"), review.End().Format("2006-01-02"))
+}
+
+// reviewWriter lays out a review in one output style. Sections are level 2
+// headings and the entries within them level 3.
+type reviewWriter interface {
+	begin(title string)
+	heading(level int, text string)
+	paragraph(text string)
+	list(items []string)
+	table(header []string, rows [][]string)
+	end()
+}
+
+func writeReport(w reviewWriter, report reviewapp.Report) {
+	stats := report.Stats
+	days := int(report.Review.End().Sub(report.Review.Start).Hours()/24) + 1
+	w.begin(reviewTitle(report.Review))
+
+	summary := []string{
+		fmt.Sprintf("%d entries on %d of %d days (%d full, %d quicknotes)", stats.Entries, stats.DaysJournaled, days, stats.FullEntries, stats.Quicknotes),
+		fmt.Sprintf("Longest streak: %d day(s)", stats.LongestStreak),
+	}
+	if stats.AverageWords > 0 {
+		summary = append(summary, fmt.Sprintf("Words per reflection: %.1f", stats.AverageWords))
+	}
+	w.list(summary)
+
+	if previous := report.Previous; previous != nil {
+		w.heading(2, "Carried forward")
+		if previous.Note != "" {
+			w.paragraph(previous.Note)
+		}
+		w.list(reflectionItems(*previous))
+	}
+
+	w.heading(2, "Entries")
+	if len(report.Entries) == 0 {
+		w.paragraph("No entries.")
+	}
+	for _, entry := range report.Entries {
+		header := entry.Date.Format("Mon 2006-01-02") + "  " + journal.FoundationLabel(entry.Foundation)
+		if entry.Mood != "" {
+			header += "  mood: " + entry.Mood
+		}
+		w.heading(3, header)
+		var items []string
+		if entry.Vedana != nil {
+			items = append(items, "Feeling tone: "+vedanaLabel(*entry.Vedana))
+		}
+		if entry.Note != "" {
+			items = append(items, "Note: "+entry.Note)
+		}
+		w.list(append(items, reflectionItems(entry)...))
+	}
+
+	w.heading(2, "Adherence")
+	if len(report.Changes) == 0 {
+		w.paragraph("No adherence changes.")
+	} else {
+		var rows [][]string
+		for _, change := range report.Changes {
+			rows = append(rows, []string{
+				change.At.Local().Format("2006-01-02 15:04"),
+				preceptTitle(change.Precept),
+				yesNoLabel(change.From) + " -> " + yesNoLabel(change.To),
+				change.Note,
+			})
+		}
+		w.table([]string{"When", "Precept", "Keeping", "Note"}, rows)
+	}
+	var state [][]string
+	for _, info := range journal.AllPrecepts() {
+		state = append(state, []string{info.Title, yesNoLabel(report.Adherence[info.ID])})
+	}
+	w.table([]string{"Precept", "Keeping now"}, state)
+
+	w.heading(2, "Foundations")
+	var foundations [][]string
+	for _, share := range stats.Foundations {
+		if share.Entries == 0 {
+			continue
+		}
+		foundations = append(foundations, []string{journal.FoundationLabel(share.Foundation), fmt.Sprint(share.Entries), fmt.Sprintf("%.0f%%", share.Share*100)})
+		for _, object := range share.Objects {
+			if object.Entries > 0 {
+				foundations = append(foundations, []string{journal.FoundationLabel(object.Object), fmt.Sprint(object.Entries), ""})
+			}
+		}
+	}
+	if len(foundations) == 0 {
+		w.paragraph("No entries.")
+	} else {
+		w.table([]string{"Foundation", "Entries", "Share"}, foundations)
+	}
+
+	w.heading(2, "Mood")
+	moods := moodRows(report.Moods)
+	if len(moods) == 0 {
+		w.paragraph("No moods recorded.")
+	} else {
+		heading := "Week of"
+		if report.Review.Period == journal.ReviewWeek {
+			heading = "Day"
+		}
+		w.table([]string{heading, "Moods", "Valence", "Energy"}, moods)
+	}
+	if len(stats.Moods) > 0 {
+		var top []string
+		for _, mood := range stats.Moods {
+			top = append(top, fmt.Sprintf("%s (%d)", mood.Mood, mood.Entries))
+		}
+		w.paragraph("Most often: " + strings.Join(top, ", "))
+	}
+
+	w.heading(2, "Neglected precepts")
+	if len(report.Neglected) == 0 {
+		w.paragraph(fmt.Sprintf("Every precept was reflected on this %s.", report.Review.Period))
+	} else {
+		var items []string
+		for _, neglected := range report.Neglected {
+			item := preceptTitle(neglected.Precept) + ": never reflected on"
+			if !neglected.LastReflected.IsZero() {
+				item = fmt.Sprintf("%s: last reflected on %s", preceptTitle(neglected.Precept), neglected.LastReflected.Format("2006-01-02"))
+			}
+			if neglected.Broken > 0 {
+				item += fmt.Sprintf(", marked broken %d time(s)", neglected.Broken)
+			}
+			items = append(items, item)
+		}
+		w.list(items)
+	}
+	w.end()
+}
+
+// reflectionItems lists an entry's reflections as "Title: reflection".
+func reflectionItems(entry journal.Entry) []string {
+	var items []string
+	for _, info := range journal.AllPrecepts() {
+		if reflection, ok := entry.Reflections[info.ID]; ok {
+			items = append(items, info.Title+": "+reflection)
+		}
+	}
+	return items
+}
+
+// moodRows lists the periods with moods; unrated ones show "-".
+func moodRows(points []analyticsapp.MoodPoint) [][]string {
+	var rows [][]string
+	for _, point := range points {
+		if point.Entries == 0 {
+			continue
+		}
+		valence, energy := "-", "-"
+		if point.Rated > 0 {
+			valence, energy = fmt.Sprintf("%+.1f", point.Valence), fmt.Sprintf("%+.1f", point.Energy)
+		}
+		rows = append(rows, []string{point.Start.Format("2006-01-02"), fmt.Sprint(point.Entries), valence, energy})
+	}
+	return rows
+}
+
+// textReview writes a review for the terminal, indenting what each heading
+// covers. A blank line follows each table.
+type textReview struct {
+	out        io.Writer
+	width      int
+	indent     string
+	afterTable bool
+}
+
+func (t *textReview) gap() {
+	if t.afterTable {
+		fmt.Fprintln(t.out)
+		t.afterTable = false
+	}
+}
+
+func (t *textReview) begin(title string) {
+	fmt.Fprintln(t.out, title)
+	fmt.Fprintln(t.out)
+}
+
+func (t *textReview) heading(level int, text string) {
+	t.afterTable = false
+	if level <= 2 {
+		fmt.Fprintf(t.out, "\n%s\n", text)
+		t.indent = "  "
+		return
+	}
+	fmt.Fprintf(t.out, "  %s\n", text)
+	t.indent = "    "
+}
+
+func (t *textReview) paragraph(text string) {
+	t.gap()
+	writeWrapped(t.out, text, t.indent, t.width)
+}
+
+func (t *textReview) list(items []string) {
+	t.gap()
+	for _, item := range items {
+		for i, line := range wrapText(strings.ReplaceAll(item, "\n", " "), t.width-len(t.indent)-2) {
+			bullet := "- "
+			if i > 0 {
+				bullet = "  "
+			}
+			fmt.Fprintln(t.out, t.indent+bullet+line)
+		}
+	}
+}
+
+func (t *textReview) table(header []string, rows [][]string) {
+	t.gap()
+	w := tabwriter.NewWriter(t.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, t.indent+strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(w, t.indent+strings.TrimRight(strings.Join(row, "\t"), "\t"))
+	}
+	w.Flush()
+	t.afterTable = true
+}
+
+func (t *textReview) end() {}
+
+// markdownReview writes a review as Markdown.
+type markdownReview struct {
+	out io.Writer
+}
+
+func (m *markdownReview) begin(title string) {
+	fmt.Fprintf(m.out, "# %s\n\n", title)
+}
+
+func (m *markdownReview) heading(level int, text string) {
+	fmt.Fprintf(m.out, "%s %s\n\n", strings.Repeat("#", level), text)
+}
+
+func (m *markdownReview) paragraph(text string) {
+	fmt.Fprintf(m.out, "%s\n\n", text)
+}
+
+func (m *markdownReview) list(items []string) {
+	if len(items) == 0 {
+		return
+	}
+	for _, item := range items {
+		fmt.Fprintf(m.out, "- %s\n", strings.ReplaceAll(item, "\n", " "))
+	}
+	fmt.Fprintln(m.out)
+}
+
+func (m *markdownReview) table(header []string, rows [][]string) {
+	cell := strings.NewReplacer("|", `\|`, "\n", " ")
+	fmt.Fprintf(m.out, "| %s |\n", strings.Join(header, " | "))
+	fmt.Fprintf(m.out, "|%s\n", strings.Repeat(" --- |", len(header)))
+	for _, row := range rows {
+		cells := make([]string, len(row))
+		for i, value := range row {
+			cells[i] = cell.Replace(value)
+		}
+		fmt.Fprintf(m.out, "| %s |\n", strings.Join(cells, " | "))
+	}
+	fmt.Fprintln(m.out)
+}
+
+func (m *markdownReview) end() {}
+
+// htmlReview writes a review as a standalone HTML page.
+type htmlReview struct {
+	out io.Writer
+}
+
+func (h *htmlReview) begin(title string) {
+	title = html.EscapeString(title)
+	fmt.Fprintln(h.out, "<!DOCTYPE html>")
+	fmt.Fprintln(h.out, `<html lang="en">`)
+	fmt.Fprintf(h.out, "<head>\n<meta charset=\"utf-8\">\n<title>%s</title>\n", title)
+	fmt.Fprintln(h.out, "<style>body{font-family:sans-serif;max-width:48rem;margin:2rem auto;padding:0 1rem;line-height:1.5}table{border-collapse:collapse;margin:1rem 0}th,td{text-align:left;padding:.25rem .75rem;border-bottom:1px solid #ddd}</style>")
+	fmt.Fprintf(h.out, "</head>\n<body>\n<h1>%s</h1>\n", title)
+}
+
+func (h *htmlReview) heading(level int, text string) {
+	fmt.Fprintf(h.out, "<h%d>%s</h%d>\n", level, html.EscapeString(text), level)
+}
+
+func (h *htmlReview) paragraph(text string) {
+	fmt.Fprintf(h.out, "<p>%s</p>\n", html.EscapeString(text))
+}
+
+func (h *htmlReview) list(items []string) {
+	if len(items) == 0 {
+		return
+	}
+	fmt.Fprintln(h.out, "<ul>")
+	for _, item := range items {
+		fmt.Fprintf(h.out, "<li>%s</li>\n", html.EscapeString(item))
+	}
+	fmt.Fprintln(h.out, "</ul>")
+}
+
+func (h *htmlReview) table(header []string, rows [][]string) {
+	fmt.Fprintln(h.out, "<table>")
+	fmt.Fprint(h.out, "<tr>")
+	for _, name := range header {
+		fmt.Fprintf(h.out, "<th>%s</th>", html.EscapeString(name))
+	}
+	fmt.Fprintln(h.out, "</tr>")
+	for _, row := range rows {
+		fmt.Fprint(h.out, "<tr>")
+		for _, value := range row {
+			fmt.Fprintf(h.out, "<td>%s</td>", html.EscapeString(value))
+		}
+		fmt.Fprintln(h.out, "</tr>")
+	}
+	fmt.Fprintln(h.out, "</table>")
+}
+
+func (h *htmlReview) end() {
+	fmt.Fprintln(h.out, "</body>\n</html>")
+}
+
+func fromReport(report reviewapp.Report) schema.Review {
+	result := schema.Review{
+		ReviewPeriod: schema.ReviewPeriod{
+			Period: string(report.Review.Period),
+			Start:  report.Review.Start.Format("2006-01-02"),
+			End:    report.Review.End().Format("2006-01-02"),
+		},
+		Entries:   schema.FromEntries(report.Entries),
+		Changes:   schema.FromLogEntries(report.Changes),
+		Adherence: schema.FromAdherence(report.Adherence),
+		Stats:     fromStats(report.Stats),
+		Moods:     fromMoodPoints(report.Moods),
+		Neglected: []schema.Neglected{},
+	}
+	for _, neglected := range report.Neglected {
+		item := schema.Neglected{Precept: string(neglected.Precept), Broken: neglected.Broken}
+		if !neglected.LastReflected.IsZero() {
+			item.LastReflected = neglected.LastReflected.Format("2006-01-02")
+		}
+		result.Neglected = append(result.Neglected, item)
+	}
+	if report.Previous != nil {
+		previous := schema.FromEntry(*report.Previous)
+		result.Previous = &previous
+	}
+	return result
+}
+
+func printReviewUsage(out io.Writer) {
+	fmt.Fprintln(out, "Usage:")
+	fmt.Fprintln(out, "  mt review week|month|year [--date YYYY-MM-DD] [--as text|markdown|html] [--no-prompt]")
+}
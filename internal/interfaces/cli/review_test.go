@@ -0,0 +1,143 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thatnerdjosh/mindfulness/internal/domain/adherence"
+	"github.com/thatnerdjosh/mindfulness/internal/domain/journal"
+	"github.com/thatnerdjosh/mindfulness/internal/interfaces/schema"
+)
+
+var (
+	reviewEntries = []testEntry{
+		{date: "2024-04-29", reflections: map[journal.Precept]string{journal.TrueLove: "called my sister | twice"}, mood: "calm", foundation: "dhamma.hindrances"},
+		{date: "2024-05-02", reflections: map[journal.Precept]string{journal.ReverenceForLife: "walked around the ants", journal.TrueHappiness: "shared lunch"}, mood: "calm", foundation: "dhamma.hindrances"},
+	}
+	reviewChange = adherence.AdherenceLogEntry{At: time.Date(2024, 4, 30, 9, 0, 0, 0, time.UTC), Precept: journal.LovingSpeechDeepListening, From: true, To: false, Note: "snapped <at> work"}
+)
+
+func TestRunReviewText(t *testing.T) {
+	d := newTestData().seed(t, reviewEntries, reviewChange)
+	svc, journalSvc := d.review(), d.journal
+
+	var out bytes.Buffer
+	input := newInput("more patience than I expected", "listen before answering", "")
+	if err := runReview([]string{"week", "--date", "2024-05-01"}, svc, formatText, input, &out, &bytes.Buffer{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got := out.String()
+	for _, want := range []string{
+		"Week review 2024-04-29 to 2024-05-05\n",
+		"\n- 2 entries on 2 of 7 days (2 full, 0 quicknotes)",
+		"\nEntries\n",
+		"    - True Love: called my sister | twice",
+		"Loving Speech and Deep Listening  yes -> no  snapped <at> work\n\n  Precept",
+		"Dhamma: Hindrances",
+		"\n  Most often: calm (2)\n",
+		"\nNeglected precepts\n  - Loving Speech and Deep Listening: never reflected on, marked broken 1\n    time(s)\n",
+		"What stood out this week? (optional): ",
+		"What is your intention for Loving Speech and Deep Listening in the week ahead?\nLoving Speech and Deep Listening intention (optional): ",
+		"saved week review 2024-04-29 to 2024-05-05",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected %q in output, got %q", want, got)
+		}
+	}
+
+	entries, err := journalSvc.ListEntries(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var entry *journal.Entry
+	for i := range entries {
+		if entries[i].Review != nil {
+			entry = &entries[i]
+		}
+	}
+	if entry == nil || entry.Note != "more patience than I expected" || entry.Reflections[journal.LovingSpeechDeepListening] != "listen before answering" {
+		t.Fatalf("expected the review to be saved, got %+v", entry)
+	}
+
+	out.Reset()
+	renderDay(&out, entry.Date, []journal.Entry{*entry}, nil, 80)
+	if !strings.Contains(out.String(), "Week review 2024-04-29 to 2024-05-05") {
+		t.Fatalf("expected show to label the review, got %q", out.String())
+	}
+}
+
+func TestRunReviewMarkdownAndHTML(t *testing.T) {
+	svc := newTestData().seed(t, reviewEntries, reviewChange).review()
+
+	var out, errOut bytes.Buffer
+	if err := runReview([]string{"week", "--date", "2024-05-01", "--as", "markdown"}, svc, formatText, newInput("", "", ""), &out, &errOut); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got := out.String()
+	for _, want := range []string{"# Week review 2024-04-29 to 2024-05-05\n", "## Adherence\n", "| Precept | Keeping now |\n| --- | --- |\n", "- True Love: called my sister | twice\n"} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected %q in markdown, got %q", want, got)
+		}
+	}
+	if strings.Contains(got, "What stood out") || !strings.Contains(errOut.String(), "review not saved") {
+		t.Fatalf("expected the prompts on stderr, got %q and %q", got, errOut.String())
+	}
+
+	out.Reset()
+	if err := runReview([]string{"week", "--date", "2024-05-01", "--as", "html", "--no-prompt"}, svc, formatText, newInput(), &out, &bytes.Buffer{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got = out.String()
+	for _, want := range []string{"<!DOCTYPE html>", "<h2>Neglected precepts</h2>", "<td>snapped &lt;at&gt; work</td>", "</html>\n"} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected %q in html, got %q", want, got)
+		}
+	}
+
+	if err := runReview([]string{"week", "--as", "pdf"}, svc, formatText, newInput(), &out, &bytes.Buffer{}); err == nil {
+		t.Fatal("expected an error for an unknown style")
+	}
+	if err := runReview([]string{"fortnight"}, svc, formatText, newInput(), &out, &bytes.Buffer{}); err == nil {
+		t.Fatal("expected an error for an unknown period")
+	}
+}
+
+func TestRunReviewJSON(t *testing.T) {
+	svc := newTestData().seed(t, reviewEntries, reviewChange).review()
+
+	var out bytes.Buffer
+	if err := runReview([]string{"month", "--date", "2024-05-01", "--no-prompt"}, svc, formatJSON, newInput(), &out, &bytes.Buffer{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var result schema.Review
+	if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if result.Period != "month" || result.Start != "2024-05-01" || result.End != "2024-05-31" {
+		t.Fatalf("unexpected period: %+v", result.ReviewPeriod)
+	}
+	if len(result.Entries) != 1 || len(result.Changes) != 0 || result.Stats.Entries != 1 || len(result.Neglected) != 3 {
+		t.Fatalf("unexpected review: %+v", result)
+	}
+}
+
+func TestRunReviewHelp(t *testing.T) {
+	svc := newTestData().review()
+	for _, arg := range []string{"help", "-h", "--help"} {
+		var out bytes.Buffer
+		if err := runReview([]string{arg}, svc, formatText, newInput(), &out, &bytes.Buffer{}); err != nil {
+			t.Fatalf("%s: unexpected error: %v", arg, err)
+		}
+		if !strings.Contains(out.String(), "mt review week|month|year") {
+			t.Fatalf("%s: expected usage, got %q", arg, out.String())
+		}
+	}
+
+	if err := runReview([]string{"--date", "2024-05-01"}, svc, formatText, newInput(), &bytes.Buffer{}, &bytes.Buffer{}); err == nil {
+		t.Fatal("expected an error without a period")
+	}
+}
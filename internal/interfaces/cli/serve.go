@@ -0,0 +1,115 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	adherenceapp "github.com/thatnerdjosh/mindfulness/internal/application/adherence"
+	journalapp "github.com/thatnerdjosh/mindfulness/internal/application/journal"
+	"github.com/thatnerdjosh/mindfulness/internal/domain/journal"
+	"github.com/thatnerdjosh/mindfulness/internal/domain/user"
+	"github.com/thatnerdjosh/mindfulness/internal/infrastructure/config"
+	"github.com/thatnerdjosh/mindfulness/internal/infrastructure/persistence/flatfile"
+	"github.com/thatnerdjosh/mindfulness/internal/interfaces/httpapi"
+)
+
+// runServe serves the API. A single-user server opens the data files like
+// any other command and only listens on loopback; a multi-user server opens
+// each user's files per request instead.
+func runServe(args []string, files dataFiles, out io.Writer, errOut io.Writer) error {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	fs.SetOutput(errOut)
+	listen := fs.String("listen", "127.0.0.1:8080", "address to listen on (loopback unless --multi-user)")
+	multiUser := fs.Bool("multi-user", false, "require bearer tokens and serve each user's own data")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if !*multiUser && !isLoopbackAddr(*listen) {
+		return fmt.Errorf("refusing to serve a single user's data on non-loopback address %s (use --multi-user)", *listen)
+	}
+
+	var handler http.Handler
+	if *multiUser {
+		configPath, err := config.DefaultPath()
+		if err != nil {
+			return err
+		}
+		cfg, err := config.Load(configPath)
+		if err != nil {
+			return err
+		}
+		moods, err := moodVocabulary(cfg.Mood)
+		if err != nil {
+			return err
+		}
+		registryPath, err := flatfile.DefaultUserRegistryPath()
+		if err != nil {
+			return err
+		}
+		registry, err := openUserService()
+		if err != nil {
+			return err
+		}
+		handler = httpapi.NewMultiUserHandler(registry, openUserServices(registryPath, cfg.Journal, moods))
+	} else {
+		data, err := openData(files, errOut)
+		if err != nil {
+			return err
+		}
+		warnSyncConflicts(files.dir(), errOut)
+		handler = httpapi.NewHandler(data.journal, data.adherence)
+	}
+
+	listener, err := net.Listen("tcp", *listen)
+	if err != nil {
+		return fmt.Errorf("listen: %w", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	fmt.Fprintf(out, "serving API on http://%s/api/v1\n", listener.Addr())
+	return httpapi.Serve(ctx, listener, handler)
+}
+
+// openUserServices opens the flat files under each user's own data
+// directory. The repositories load their files when constructed, so building
+// them per request keeps every request consistent with what is on disk.
+func openUserServices(registryPath string, journalCfg config.Journal, moods journal.MoodVocabulary) httpapi.OpenFunc {
+	return func(u user.User) (httpapi.Services, error) {
+		dir := flatfile.UserDataDir(registryPath, u.Name)
+		journalRepo, err := flatfile.NewJournalRepository(filepath.Join(dir, "journal.json"))
+		if err != nil {
+			return httpapi.Services{}, err
+		}
+		adherenceRepo, err := flatfile.NewEventSourcedAdherenceRepository(filepath.Join(dir, "adherence.json"), filepath.Join(dir, "adherence.log.jsonl"))
+		if err != nil {
+			return httpapi.Services{}, err
+		}
+		renewalRepo, err := flatfile.NewBeginningAnewRepository(filepath.Join(dir, "beginning-anew.json"))
+		if err != nil {
+			return httpapi.Services{}, err
+		}
+		uow, err := flatfile.NewUnitOfWork(filepath.Join(dir, "pending.wal.json"), journalRepo, adherenceRepo, renewalRepo)
+		if err != nil {
+			return httpapi.Services{}, err
+		}
+		journalSvc := journalapp.NewService(journalRepo, journalapp.WithMoodVocabulary(moods))
+		opts := []adherenceapp.Option{adherenceapp.WithUnitOfWork(uow), adherenceapp.WithBeginningAnew(renewalRepo)}
+		if journalCfg.LinksAdherence() {
+			opts = append(opts, adherenceapp.WithListener(journalSvc.JournalAdherenceChanges))
+		}
+		return httpapi.Services{
+			Journal:   journalSvc,
+			Adherence: adherenceapp.NewService(adherenceRepo, opts...),
+		}, nil
+	}
+}
@@ -0,0 +1,35 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunServeLeavesSingleUserDataAlone(t *testing.T) {
+	dataHome := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dataHome)
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	dir := filepath.Join(dataHome, "mt")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	walPath := filepath.Join(dir, "pending.wal.json")
+	if err := os.WriteFile(walPath, []byte(`{"journal_append": [`), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	err := Run([]string{"mt", "serve", "--listen", "0.0.0.0:0"}, &bytes.Buffer{}, &bytes.Buffer{})
+	if err == nil || !strings.Contains(err.Error(), "non-loopback") {
+		t.Fatalf("expected a single-user server to refuse a remote address, got %v", err)
+	}
+	err = Run([]string{"mt", "serve", "--multi-user", "--listen", "127.0.0.1:-1"}, &bytes.Buffer{}, &bytes.Buffer{})
+	if err == nil || !strings.HasPrefix(err.Error(), "listen:") {
+		t.Fatalf("expected the multi-user server to get as far as listening, got %v", err)
+	}
+	if data, err := os.ReadFile(walPath); err != nil || string(data) != `{"journal_append": [` {
+		t.Fatalf("expected the write-ahead record to be left alone, got %q, %v", data, err)
+	}
+}
@@ -0,0 +1,199 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	adherenceapp "github.com/thatnerdjosh/mindfulness/internal/application/adherence"
+	journalapp "github.com/thatnerdjosh/mindfulness/internal/application/journal"
+	adherencedomain "github.com/thatnerdjosh/mindfulness/internal/domain/adherence"
+	"github.com/thatnerdjosh/mindfulness/internal/domain/journal"
+	"github.com/thatnerdjosh/mindfulness/internal/interfaces/schema"
+)
+
+const defaultTerminalWidth = 80
+
+func runJournalShow(args []string, svc *journalapp.Service, adherenceSvc *adherenceapp.Service, format outputFormat, out io.Writer) error {
+	if len(args) > 1 {
+		return fmt.Errorf("journal show takes at most one date")
+	}
+	target := "latest"
+	if len(args) == 1 {
+		target = args[0]
+	}
+
+	date, err := resolveShowDate(svc, target)
+	if err != nil {
+		return err
+	}
+
+	entries, err := svc.EntriesOn(context.Background(), date)
+	if err != nil {
+		return err
+	}
+	changes, err := adherenceSvc.LogOn(context.Background(), date)
+	if err != nil {
+		return err
+	}
+
+	if format != formatText {
+		return writeObject(out, format, schema.Day{
+			Date:             date.Format("2006-01-02"),
+			Entries:          schema.FromEntries(entries),
+			AdherenceChanges: schema.FromLogEntries(changes),
+		})
+	}
+
+	var buf bytes.Buffer
+	renderDay(&buf, date, entries, changes, terminalWidth())
+	return page(out, buf.Bytes())
+}
+
+func resolveShowDate(svc *journalapp.Service, target string) (time.Time, error) {
+	switch strings.ToLower(strings.TrimSpace(target)) {
+	case "latest":
+		latest, err := svc.LatestEntry(context.Background())
+		if err != nil {
+			return time.Time{}, err
+		}
+		return latest.Date, nil
+	case "today":
+		return parseDate("")
+	default:
+		return parseDate(target)
+	}
+}
+
+// renderDay writes the entries and adherence changes of one day. Days are
+// UTC days, so times are printed in UTC to match them.
+func renderDay(out io.Writer, date time.Time, entries []journal.Entry, changes []adherencedomain.AdherenceLogEntry, width int) {
+	fmt.Fprintln(out, date.Format("Monday, 2006-01-02"))
+
+	if len(entries) == 0 {
+		fmt.Fprintln(out, "")
+		fmt.Fprintln(out, "no entries")
+	}
+	for _, entry := range entries {
+		fmt.Fprintln(out, "")
+		renderEntry(out, entry, entry.Timestamp.UTC().Format("15:04"), width)
+	}
+
+	if len(changes) == 0 {
+		return
+	}
+	// Notes already shown as the reflection of a linked entry are not
+	// repeated under the change.
+	journaled := make(map[string]bool)
+	for _, entry := range entries {
+		for _, fingerprint := range entry.AdherenceEvents {
+			journaled[fingerprint] = true
+		}
+	}
+	fmt.Fprintln(out, "")
+	fmt.Fprintln(out, "Adherence changes")
+	for _, change := range changes {
+		fmt.Fprintf(out, "  %s  %s: %s -> %s\n",
+			change.At.UTC().Format("15:04"),
+			preceptTitle(change.Precept),
+			yesNoLabel(change.From),
+			yesNoLabel(change.To),
+		)
+		if change.Note != "" && !journaled[change.Fingerprint()] {
+			writeWrapped(out, change.Note, "    ", width)
+		}
+	}
+}
+
+// renderEntry writes one entry under a header line that starts with when,
+// followed by its foundation and mood.
+func renderEntry(out io.Writer, entry journal.Entry, when string, width int) {
+	header := fmt.Sprintf("%s  %s", when, journal.FoundationLabel(entry.Foundation))
+	if entry.Review != nil {
+		header = fmt.Sprintf("%s  %s", when, reviewTitle(*entry.Review))
+	}
+	if entry.Mood != "" {
+		header += "  mood: " + entry.Mood
+	}
+	fmt.Fprintln(out, header)
+	if entry.Vedana != nil {
+		fmt.Fprintf(out, "  Feeling tone: %s\n", vedanaLabel(*entry.Vedana))
+	}
+	if entry.Note != "" {
+		fmt.Fprintln(out, "  Note")
+		writeWrapped(out, entry.Note, "    ", width)
+	}
+	for _, info := range journal.AllPrecepts() {
+		reflection, ok := entry.Reflections[info.ID]
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(out, "  %s\n", info.Title)
+		if question, ok := entry.Questions[info.ID]; ok {
+			writeWrapped(out, "Q: "+question, "    ", width)
+		}
+		writeWrapped(out, reflection, "    ", width)
+	}
+}
+
+func preceptTitle(precept journal.Precept) string {
+	for _, info := range journal.AllPrecepts() {
+		if info.ID == precept {
+			return info.Title
+		}
+	}
+	return string(precept)
+}
+
+// writeWrapped prints text word-wrapped to width, keeping paragraph breaks.
+func writeWrapped(out io.Writer, text string, indent string, width int) {
+	for _, paragraph := range strings.Split(text, "\n") {
+		for _, line := range wrapText(paragraph, width-len(indent)) {
+			fmt.Fprintln(out, indent+line)
+		}
+	}
+}
+
+func wrapText(text string, width int) []string {
+	if width < 20 {
+		width = 20
+	}
+	words := strings.Fields(text)
+	if len(words) == 0 {
+		return []string{""}
+	}
+
+	var lines []string
+	line := words[0]
+	for _, word := range words[1:] {
+		if len([]rune(line))+1+len([]rune(word)) > width {
+			lines = append(lines, line)
+			line = word
+			continue
+		}
+		line += " " + word
+	}
+	return append(lines, line)
+}
+
+// terminalWidth is the width of the terminal on standard output. When output
+// is not a terminal, as when it is piped, $COLUMNS is used instead.
+func terminalWidth() int {
+	if width, ok := ttyWidth(os.Stdout); ok {
+		return width
+	}
+	return envInt("COLUMNS", defaultTerminalWidth)
+}
+
+func envInt(name string, fallback int) int {
+	value, err := strconv.Atoi(strings.TrimSpace(os.Getenv(name)))
+	if err != nil || value <= 0 {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,192 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	adherencedomain "github.com/thatnerdjosh/mindfulness/internal/domain/adherence"
+	"github.com/thatnerdjosh/mindfulness/internal/domain/journal"
+	"github.com/thatnerdjosh/mindfulness/internal/interfaces/schema"
+)
+
+var (
+	showDay     = time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	showEntries = []testEntry{
+		{date: "2024-01-02", at: showDay.Add(18 * time.Hour), reflections: map[journal.Precept]string{
+			journal.TrueLove: "listened to a friend without interrupting",
+		}, foundation: journal.FoundationCit},
+		{date: "2024-01-02", at: showDay.Add(7 * time.Hour), note: "morning sitting", mood: "calm", foundation: journal.FoundationKaya},
+		{date: "2024-01-03", at: showDay.Add(30 * time.Hour), note: "next day", foundation: journal.FoundationDhamma},
+	}
+	showChange = adherencedomain.AdherenceLogEntry{
+		At: showDay.Add(12 * time.Hour), Precept: journal.TrueHappiness, From: true, To: false, Note: "bought on impulse",
+	}
+)
+
+func TestRunJournalShow(t *testing.T) {
+	d := newTestData().seed(t, showEntries, showChange)
+	svc, adherenceSvc := d.journal, d.adherence
+
+	var out bytes.Buffer
+	if err := runJournalShow([]string{"2024-01-02"}, svc, adherenceSvc, formatText, &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text := out.String()
+	for _, want := range []string{
+		"Tuesday, 2024-01-02",
+		"Kaya  mood: calm",
+		"morning sitting",
+		"  True Love\n    listened to a friend",
+		"Adherence changes",
+		"True Happiness: yes -> no",
+		"bought on impulse",
+	} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("expected %q in output:\n%s", want, text)
+		}
+	}
+	if strings.Index(text, "morning sitting") > strings.Index(text, "listened") {
+		t.Fatalf("expected entries in time order:\n%s", text)
+	}
+	if strings.Contains(text, "next day") {
+		t.Fatalf("expected only the requested day:\n%s", text)
+	}
+}
+
+func TestRunJournalShowPrintsTimesInTheDaysZone(t *testing.T) {
+	local := time.Local
+	time.Local = time.FixedZone("UTC+10", 10*60*60)
+	t.Cleanup(func() { time.Local = local })
+	d := newTestData().seed(t, showEntries, showChange)
+	svc, adherenceSvc := d.journal, d.adherence
+
+	var out bytes.Buffer
+	if err := runJournalShow([]string{"2024-01-02"}, svc, adherenceSvc, formatText, &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{"07:00  Kaya", "18:00  Cit", "12:00  True Happiness"} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("expected %q in output:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestRunJournalShowLatestJSON(t *testing.T) {
+	d := newTestData().seed(t, showEntries, showChange)
+	svc, adherenceSvc := d.journal, d.adherence
+
+	var out bytes.Buffer
+	if err := runJournalShow(nil, svc, adherenceSvc, formatJSON, &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var day schema.Day
+	if err := json.Unmarshal(out.Bytes(), &day); err != nil {
+		t.Fatalf("expected JSON output, got %s", out.String())
+	}
+	if day.Date != "2024-01-03" || len(day.Entries) != 1 || len(day.AdherenceChanges) != 0 {
+		t.Fatalf("unexpected day: %+v", day)
+	}
+}
+
+func TestRunJournalShowErrors(t *testing.T) {
+	d := newTestData()
+	svc, adherenceSvc := d.journal, d.adherence
+
+	if err := runJournalShow([]string{"latest"}, svc, adherenceSvc, formatText, &bytes.Buffer{}); !errors.Is(err, journal.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	if err := runJournalShow([]string{"bad-date"}, svc, adherenceSvc, formatText, &bytes.Buffer{}); err == nil {
+		t.Fatalf("expected error for invalid date")
+	}
+	if err := runJournalShow([]string{"a", "b"}, svc, adherenceSvc, formatText, &bytes.Buffer{}); err == nil {
+		t.Fatalf("expected error for extra arguments")
+	}
+
+	var out bytes.Buffer
+	if err := runJournalShow([]string{"today"}, svc, adherenceSvc, formatText, &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out.String(), "no entries") {
+		t.Fatalf("unexpected output: %s", out.String())
+	}
+}
+
+func TestWrapText(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		width int
+		want  []string
+	}{
+		{
+			name:  "empty",
+			text:  "  ",
+			width: 30,
+			want:  []string{""},
+		},
+		{
+			name:  "fits",
+			text:  "breathing in I calm my body",
+			width: 40,
+			want:  []string{"breathing in I calm my body"},
+		},
+		{
+			name:  "wraps on words",
+			text:  "breathing in I calm my body breathing out I smile",
+			width: 20,
+			want:  []string{"breathing in I calm", "my body breathing", "out I smile"},
+		},
+		{
+			name:  "clamps tiny widths",
+			text:  "one two three four five six",
+			width: 3,
+			want:  []string{"one two three four", "five six"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := wrapText(tt.text, tt.width)
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Fatalf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestPageWritesDirectlyWhenNotTerminal(t *testing.T) {
+	t.Setenv("PAGER", "false")
+	var out bytes.Buffer
+	if err := page(&out, []byte(strings.Repeat("line\n", 100))); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Count(out.String(), "line") != 100 {
+		t.Fatalf("expected output written directly")
+	}
+}
+
+func TestRunJournalShowLinkedAdherenceNote(t *testing.T) {
+	d := newTestData().journalAdherenceChanges()
+	svc, adherenceSvc := d.journal, d.adherence
+
+	input := newInput("y", "n", "skipped lunch to keep working", "", "", "")
+	if err := runAdherenceGuided([]string{"--no-confirm"}, adherenceSvc, formatText, input, &bytes.Buffer{}, &bytes.Buffer{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := runJournalShow([]string{"today"}, svc, adherenceSvc, formatText, &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text := out.String()
+	if !strings.Contains(text, "  True Happiness\n    skipped lunch to keep working") || !strings.Contains(text, "True Happiness: yes -> no") {
+		t.Fatalf("expected the note as a reflection and the change, got:\n%s", text)
+	}
+	if strings.Count(text, "skipped lunch") != 1 {
+		t.Fatalf("expected the linked note to be shown once, got:\n%s", text)
+	}
+}
@@ -0,0 +1,142 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	analyticsapp "github.com/thatnerdjosh/mindfulness/internal/application/analytics"
+	"github.com/thatnerdjosh/mindfulness/internal/domain/journal"
+	"github.com/thatnerdjosh/mindfulness/internal/interfaces/schema"
+)
+
+func runStats(args []string, svc *analyticsapp.Service, format outputFormat, out io.Writer, errOut io.Writer) error {
+	fs := flag.NewFlagSet("stats", flag.ContinueOnError)
+	fs.SetOutput(errOut)
+	sinceStr := fs.String("since", "", "first day to include (YYYY-MM-DD, default the first entry)")
+	untilStr := fs.String("until", "", "last day to include (YYYY-MM-DD, default today)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var since, until time.Time
+	var err error
+	if *sinceStr != "" {
+		if since, err = parseDate(*sinceStr); err != nil {
+			return err
+		}
+	}
+	if *untilStr != "" {
+		if until, err = parseDate(*untilStr); err != nil {
+			return err
+		}
+	}
+
+	stats, err := svc.Stats(context.Background(), since, until)
+	if err != nil {
+		return err
+	}
+	if format != formatText {
+		return writeObject(out, format, fromStats(stats))
+	}
+	if stats.Entries == 0 {
+		fmt.Fprintln(out, "no entries in range")
+		return nil
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "Journal stats %s to %s\n\n", stats.Since.Format("2006-01-02"), stats.Until.Format("2006-01-02"))
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Entries\t%d (%d full, %d quicknotes)\n", stats.Entries, stats.FullEntries, stats.Quicknotes)
+	fmt.Fprintf(w, "Days journaled\t%d of %d (%d skipped)\n", stats.DaysJournaled, stats.DaysJournaled+stats.DaysSkipped, stats.DaysSkipped)
+	fmt.Fprintf(w, "Current streak\t%d day(s)\n", stats.CurrentStreak)
+	fmt.Fprintf(w, "Longest streak\t%d day(s)\n", stats.LongestStreak)
+	fmt.Fprintf(w, "Words per reflection\t%.1f\n", stats.AverageWords)
+	fmt.Fprintln(w)
+
+	fmt.Fprintln(w, "Week of\tEntries")
+	for _, week := range stats.Weeks {
+		fmt.Fprintf(w, "%s\t%d\n", week.Start.Format("2006-01-02"), week.Entries)
+	}
+	fmt.Fprintln(w)
+
+	fmt.Fprintln(w, "Precept\tReflections\tAvg words")
+	for _, count := range stats.Precepts {
+		fmt.Fprintf(w, "%s\t%d\t%.1f\n", preceptTitle(count.Precept), count.Reflections, count.AverageWords)
+	}
+	fmt.Fprintln(w)
+
+	fmt.Fprintln(w, "Foundation\tEntries\tShare")
+	for _, share := range stats.Foundations {
+		fmt.Fprintf(w, "%s\t%d\t%.0f%%\n", journal.FoundationLabel(share.Foundation), share.Entries, share.Share*100)
+		for _, object := range share.Objects {
+			if object.Entries > 0 {
+				fmt.Fprintf(w, "  %s\t%d\n", objectLabel(object.Object), object.Entries)
+			}
+		}
+	}
+	if len(stats.Moods) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "Mood\tEntries")
+		for _, mood := range stats.Moods {
+			fmt.Fprintf(w, "%s\t%d\n", mood.Mood, mood.Entries)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return page(out, buf.Bytes())
+}
+
+func fromStats(stats analyticsapp.Stats) schema.Stats {
+	result := schema.Stats{
+		Until:                  stats.Until.Format("2006-01-02"),
+		Entries:                stats.Entries,
+		Quicknotes:             stats.Quicknotes,
+		FullEntries:            stats.FullEntries,
+		Weeks:                  []schema.WeekCount{},
+		DaysJournaled:          stats.DaysJournaled,
+		DaysSkipped:            stats.DaysSkipped,
+		CurrentStreak:          stats.CurrentStreak,
+		LongestStreak:          stats.LongestStreak,
+		Precepts:               []schema.PreceptCount{},
+		Foundations:            []schema.FoundationShare{},
+		Moods:                  []schema.MoodCount{},
+		AverageReflectionWords: stats.AverageWords,
+	}
+	if !stats.Since.IsZero() {
+		result.Since = stats.Since.Format("2006-01-02")
+	}
+	for _, week := range stats.Weeks {
+		result.Weeks = append(result.Weeks, schema.WeekCount{Week: week.Start.Format("2006-01-02"), Entries: week.Entries})
+	}
+	for _, count := range stats.Precepts {
+		result.Precepts = append(result.Precepts, schema.PreceptCount{Precept: string(count.Precept), Reflections: count.Reflections, AverageWords: count.AverageWords})
+	}
+	for _, share := range stats.Foundations {
+		item := schema.FoundationShare{Foundation: string(share.Foundation), Entries: share.Entries, Share: share.Share, Objects: []schema.ObjectCount{}}
+		for _, object := range share.Objects {
+			item.Objects = append(item.Objects, schema.ObjectCount{Object: string(object.Object), Entries: object.Entries})
+		}
+		result.Foundations = append(result.Foundations, item)
+	}
+	for _, mood := range stats.Moods {
+		result.Moods = append(result.Moods, schema.MoodCount{Mood: mood.Mood, Entries: mood.Entries})
+	}
+	return result
+}
+
+// objectLabel names an object without its foundation, which the stats table
+// already shows above it.
+func objectLabel(object journal.Foundation) string {
+	for _, candidate := range journal.FoundationObjects(object) {
+		if candidate.ID == object {
+			return candidate.Label
+		}
+	}
+	return object.Object()
+}
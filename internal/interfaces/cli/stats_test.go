@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thatnerdjosh/mindfulness/internal/domain/journal"
+	"github.com/thatnerdjosh/mindfulness/internal/interfaces/schema"
+)
+
+func TestRunStats(t *testing.T) {
+	var entries []testEntry
+	for _, date := range []string{"2024-05-01", "2024-05-02", "2024-05-04"} {
+		at, _ := time.Parse("2006-01-02", date)
+		entries = append(entries, testEntry{date: date, at: at, reflections: map[journal.Precept]string{journal.TrueLove: "held space"}, mood: "calm", foundation: journal.FoundationKaya})
+	}
+	svc := newTestData().seed(t, entries).analytics
+
+	var out bytes.Buffer
+	if err := runStats([]string{"--since", "2024-05-01", "--until", "2024-05-04"}, svc, formatText, &out, &bytes.Buffer{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{"Journal stats 2024-05-01 to 2024-05-04", "Days journaled        3 of 4 (1 skipped)", "Longest streak        2 day(s)", "True Love", "Kaya        3        100%", "calm"} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("expected %q in output, got %q", want, out.String())
+		}
+	}
+
+	out.Reset()
+	if err := runStats([]string{"--until", "2024-05-04"}, svc, formatJSON, &out, &bytes.Buffer{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var stats schema.Stats
+	if err := json.Unmarshal(out.Bytes(), &stats); err != nil {
+		t.Fatalf("expected JSON output, got %q", out.String())
+	}
+	if stats.Since != "2024-05-01" || stats.Entries != 3 || stats.CurrentStreak != 1 || stats.Moods[0].Mood != "calm" || len(stats.Weeks) != 1 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+
+	out.Reset()
+	if err := runStats([]string{"--until", "2024-04-01"}, svc, formatText, &out, &bytes.Buffer{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out.String(), "no entries in range") {
+		t.Fatalf("expected no entries, got %q", out.String())
+	}
+}
+
+func TestRunStatsFoundationObjects(t *testing.T) {
+	var entries []testEntry
+	for _, foundation := range []journal.Foundation{"dhamma.hindrances", "dhamma.hindrances", journal.FoundationDhamma} {
+		at, _ := time.Parse("2006-01-02", "2024-05-01")
+		entries = append(entries, testEntry{date: "2024-05-01", at: at, note: "noted", foundation: foundation})
+	}
+	svc := newTestData().seed(t, entries).analytics
+
+	var out bytes.Buffer
+	if err := runStats([]string{"--until", "2024-05-01"}, svc, formatText, &out, &bytes.Buffer{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out.String(), "Dhamma        3        100%\n  Hindrances  2\n") || strings.Contains(out.String(), "Aggregates") {
+		t.Fatalf("expected only the used objects under their foundation, got %q", out.String())
+	}
+
+	out.Reset()
+	if err := runStats([]string{"--until", "2024-05-01"}, svc, formatJSON, &out, &bytes.Buffer{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var stats schema.Stats
+	if err := json.Unmarshal(out.Bytes(), &stats); err != nil {
+		t.Fatalf("expected JSON output, got %q", out.String())
+	}
+	if objects := stats.Foundations[3].Objects; len(objects) == 0 || objects[0] != (schema.ObjectCount{Object: "dhamma.hindrances", Entries: 2}) {
+		t.Fatalf("unexpected objects: %+v", stats.Foundations[3])
+	}
+}
+
+func TestPromptFoundationObjects(t *testing.T) {
+	var out bytes.Buffer
+	got, err := promptFoundation(bufio.NewReader(newInput("?", "d.nope", "d.hind")), &out)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "dhamma.hindrances" {
+		t.Fatalf("expected dhamma.hindrances, got %q", got)
+	}
+	for _, want := range []string{"Dhamma (d): hindrances, aggregates", "Kaya (k): breath, postures", "Please enter k, v, c, or d"} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("expected %q in output, got %q", want, out.String())
+		}
+	}
+}
@@ -0,0 +1,259 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	mergeapp "github.com/thatnerdjosh/mindfulness/internal/application/merge"
+	"github.com/thatnerdjosh/mindfulness/internal/domain/journal"
+	"github.com/thatnerdjosh/mindfulness/internal/infrastructure/persistence/flatfile"
+	"github.com/thatnerdjosh/mindfulness/internal/interfaces/schema"
+)
+
+// dataFiles are the local files that sync and doctor operate on. Sync
+// leaves out the Beginning Anew records, the revisits and the write-ahead
+// record, which only doctor checks.
+type dataFiles struct {
+	journal       string
+	adherence     string
+	adherenceLog  string
+	beginningAnew string
+	revisits      string
+	wal           string
+}
+
+// defaultDataFiles returns the data files in the default data directory.
+func defaultDataFiles() (dataFiles, error) {
+	journalPath, err := flatfile.DefaultJournalPath()
+	if err != nil {
+		return dataFiles{}, err
+	}
+	adherencePath, err := flatfile.DefaultAdherencePath()
+	if err != nil {
+		return dataFiles{}, err
+	}
+	adherenceLogPath, err := flatfile.DefaultAdherenceLogPath()
+	if err != nil {
+		return dataFiles{}, err
+	}
+	renewalPath, err := flatfile.DefaultBeginningAnewPath()
+	if err != nil {
+		return dataFiles{}, err
+	}
+	revisitPath, err := flatfile.DefaultRevisitPath()
+	if err != nil {
+		return dataFiles{}, err
+	}
+	walPath, err := flatfile.DefaultWALPath()
+	if err != nil {
+		return dataFiles{}, err
+	}
+	return dataFiles{
+		journal:       journalPath,
+		adherence:     adherencePath,
+		adherenceLog:  adherenceLogPath,
+		beginningAnew: renewalPath,
+		revisits:      revisitPath,
+		wal:           walPath,
+	}, nil
+}
+
+func (f dataFiles) dir() string {
+	return filepath.Dir(f.journal)
+}
+
+func runSync(args []string, svc *mergeapp.Service, files dataFiles, format outputFormat, in io.Reader, out io.Writer, errOut io.Writer) error {
+	if len(args) < 1 {
+		printSyncUsage(errOut)
+		return fmt.Errorf("sync subcommand required")
+	}
+
+	switch args[0] {
+	case "merge":
+		return runSyncMerge(args[1:], svc, files.dir(), format, out)
+	case "push":
+		return runSyncRemote(syncPush, args[1:], svc, files, format, out, errOut)
+	case "pull":
+		return runSyncRemote(syncPull, args[1:], svc, files, format, out, errOut)
+	case "remote":
+		return runSyncRemoteConfig(args[1:], format, in, out, errOut)
+	case "help", "-h", "--help":
+		printSyncUsage(out)
+		return nil
+	default:
+		fmt.Fprintf(errOut, "unknown sync command: %s\n", args[0])
+		printSyncUsage(errOut)
+		return fmt.Errorf("unknown sync command: %s", args[0])
+	}
+}
+
+// runSyncMerge merges the named files, or every sync-conflict copy found in
+// the data directory. Detected conflict copies are removed once merged since
+// all of their content is now in the local files; named files are left alone.
+func runSyncMerge(args []string, svc *mergeapp.Service, dataDir string, format outputFormat, out io.Writer) error {
+	var targets []flatfile.SyncConflict
+	detected := len(args) == 0
+	if detected {
+		found, err := flatfile.FindSyncConflicts(dataDir)
+		if err != nil {
+			return err
+		}
+		targets = found
+	}
+	for _, path := range args {
+		kind, err := flatfile.ClassifySyncFile(path)
+		if err != nil {
+			return err
+		}
+		targets = append(targets, flatfile.SyncConflict{Path: path, Kind: kind})
+	}
+
+	reports := make([]schema.MergeReport, 0, len(targets))
+	for _, target := range targets {
+		report, err := mergeFile(svc, target)
+		if err != nil {
+			return fmt.Errorf("merge %s: %w", target.Path, err)
+		}
+		if detected {
+			if err := os.Remove(target.Path); err != nil {
+				return fmt.Errorf("remove merged conflict file: %w", err)
+			}
+			report.Removed = true
+		}
+		reports = append(reports, report)
+	}
+
+	if format != formatText {
+		return writeList(out, format, reports)
+	}
+	if len(reports) == 0 {
+		fmt.Fprintln(out, "no sync conflicts found")
+		return nil
+	}
+	for _, report := range reports {
+		writeMergeReport(out, report)
+	}
+	return nil
+}
+
+func mergeFile(svc *mergeapp.Service, target flatfile.SyncConflict) (schema.MergeReport, error) {
+	data, err := os.ReadFile(target.Path)
+	if err != nil {
+		return schema.MergeReport{}, fmt.Errorf("read file: %w", err)
+	}
+	return mergeData(svc, target.Path, target.Kind, data)
+}
+
+// mergeData merges the contents of a data file of the given kind into the
+// local stores.
+func mergeData(svc *mergeapp.Service, name string, kind flatfile.SyncFileKind, data []byte) (schema.MergeReport, error) {
+	report := schema.MergeReport{File: name, Kind: string(kind)}
+	switch kind {
+	case flatfile.SyncFileJournal:
+		entries, err := flatfile.DecodeJournal(data)
+		if err != nil {
+			return schema.MergeReport{}, err
+		}
+		result, err := svc.MergeJournal(context.Background(), entries)
+		if err != nil {
+			return schema.MergeReport{}, err
+		}
+		report.Added, report.Total = result.Added, result.Total
+		report.Conflicts = schema.FromJournalConflicts(result.Conflicts)
+	case flatfile.SyncFileAdherenceLog:
+		log, err := flatfile.DecodeAdherenceLog(data)
+		if err != nil {
+			return schema.MergeReport{}, err
+		}
+		result, err := svc.MergeAdherenceLog(context.Background(), log)
+		if err != nil {
+			return schema.MergeReport{}, err
+		}
+		report.Added, report.Total = result.Added, result.Total
+		report.Conflicts = schema.FromLogConflicts(result.Conflicts)
+	case flatfile.SyncFileAdherenceState:
+		// State is derived from the log, so a conflicting copy carries
+		// nothing to merge; recompute it from the local log instead.
+		result, err := svc.MergeAdherenceLog(context.Background(), nil)
+		if err != nil {
+			return schema.MergeReport{}, err
+		}
+		report.Total = result.Total
+		report.Conflicts = []schema.MergeConflict{}
+	}
+	return report, nil
+}
+
+func writeMergeReport(out io.Writer, report schema.MergeReport) {
+	switch flatfile.SyncFileKind(report.Kind) {
+	case flatfile.SyncFileAdherenceState:
+		fmt.Fprintf(out, "%s: adherence state recomputed from the log\n", report.File)
+	case flatfile.SyncFileAdherenceLog:
+		fmt.Fprintf(out, "%s: added %d adherence changes (%d total), state recomputed\n", report.File, report.Added, report.Total)
+	default:
+		fmt.Fprintf(out, "%s: added %d entries (%d total)\n", report.File, report.Added, report.Total)
+	}
+	if report.Removed {
+		fmt.Fprintln(out, "  removed conflict copy")
+	}
+	writeConflicts(out, report.Conflicts)
+}
+
+func writeConflicts(out io.Writer, conflicts []schema.MergeConflict) {
+	if len(conflicts) == 0 {
+		return
+	}
+	fmt.Fprintf(out, "  %d conflict(s); both versions were kept, edit or delete the one you do not want:\n", len(conflicts))
+	for _, conflict := range conflicts {
+		if conflict.Precept != "" {
+			fmt.Fprintf(out, "  %s  %s:", conflict.Timestamp, preceptTitle(journal.Precept(conflict.Precept)))
+			for _, change := range conflict.Changes {
+				fmt.Fprintf(out, " %s -> %s", yesNoLabel(change.From), yesNoLabel(change.To))
+				if change.Note != "" {
+					fmt.Fprintf(out, " (%s)", change.Note)
+				}
+				fmt.Fprint(out, ";")
+			}
+			fmt.Fprintln(out)
+			continue
+		}
+		fmt.Fprintf(out, "  %s  %d versions of the entry for %s\n", conflict.Timestamp, len(conflict.Entries), conflict.Entries[0].Date)
+		for _, entry := range conflict.Entries {
+			fmt.Fprintf(out, "    - %s\n", summarizeEntry(entry))
+		}
+	}
+}
+
+func summarizeEntry(entry schema.Entry) string {
+	text := entry.Note
+	for _, info := range journal.AllPrecepts() {
+		if text != "" {
+			break
+		}
+		text = entry.Reflections[string(info.ID)]
+	}
+	if runes := []rune(text); len(runes) > 60 {
+		text = string(runes[:57]) + "..."
+	}
+	return text
+}
+
+// warnSyncConflicts points at conflicting copies left by a file-sync tool so
+// they are merged before they drift further.
+func warnSyncConflicts(dataDir string, errOut io.Writer) {
+	conflicts, err := flatfile.FindSyncConflicts(dataDir)
+	if err != nil || len(conflicts) == 0 {
+		return
+	}
+	fmt.Fprintf(errOut, "warning: %d sync conflict file(s) in %s; run `mt sync merge` to merge them\n", len(conflicts), dataDir)
+}
+
+func printSyncUsage(out io.Writer) {
+	fmt.Fprintln(out, "Usage:")
+	fmt.Fprintln(out, "  mt sync merge [file...]")
+	fmt.Fprintln(out, "  mt sync push|pull")
+	fmt.Fprintln(out, "  mt sync remote [--url URL] [--username NAME] [--password-stdin]")
+}
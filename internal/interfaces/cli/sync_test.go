@@ -0,0 +1,84 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	mergeapp "github.com/thatnerdjosh/mindfulness/internal/application/merge"
+	"github.com/thatnerdjosh/mindfulness/internal/application/unitofwork"
+	"github.com/thatnerdjosh/mindfulness/internal/domain/journal"
+	"github.com/thatnerdjosh/mindfulness/internal/infrastructure/persistence/flatfile"
+)
+
+func TestRunSyncMergeDetectedConflicts(t *testing.T) {
+	dir := t.TempDir()
+	journalRepo, err := flatfile.NewJournalRepository(filepath.Join(dir, "journal.json"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	adherenceRepo, err := flatfile.NewAdherenceRepository(filepath.Join(dir, "adherence.json"), filepath.Join(dir, "adherence.log.jsonl"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	day := time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC)
+	local, err := journal.NewEntry(day, nil, "written on the laptop", "", journal.FoundationDhamma, day.Add(9*time.Hour))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := journalRepo.Save(context.Background(), local); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	conflictPath := filepath.Join(dir, "journal.sync-conflict-20240105-101500-ABCDEFG.json")
+	conflict := `[{"date":"2024-01-05","timestamp":"2024-01-05T09:00:00Z","note":"written on the desktop","foundation":"dhamma"}]`
+	if err := os.WriteFile(conflictPath, []byte(conflict), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	var warnings bytes.Buffer
+	warnSyncConflicts(dir, &warnings)
+	if !strings.Contains(warnings.String(), "1 sync conflict file") {
+		t.Fatalf("expected conflict warning, got %q", warnings.String())
+	}
+
+	var out bytes.Buffer
+	svc := mergeapp.NewService(unitofwork.Direct(journalRepo, adherenceRepo, nil))
+	files := dataFiles{
+		journal:      filepath.Join(dir, "journal.json"),
+		adherence:    filepath.Join(dir, "adherence.json"),
+		adherenceLog: filepath.Join(dir, "adherence.log.jsonl"),
+	}
+	if err := runSync([]string{"merge"}, svc, files, formatText, strings.NewReader(""), &out, &bytes.Buffer{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{"added 1 entries (2 total)", "removed conflict copy", "1 conflict(s)", "written on the desktop"} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("expected %q in output:\n%s", want, out.String())
+		}
+	}
+	if _, err := os.Stat(conflictPath); !os.IsNotExist(err) {
+		t.Fatalf("expected conflict copy to be removed")
+	}
+
+	entries, err := flatfile.ReadJournalFile(filepath.Join(dir, "journal.json"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected both versions to be kept, got %d", len(entries))
+	}
+
+	out.Reset()
+	if err := runSync([]string{"merge"}, svc, files, formatText, strings.NewReader(""), &out, &bytes.Buffer{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out.String(), "no sync conflicts found") {
+		t.Fatalf("unexpected output: %q", out.String())
+	}
+}
@@ -0,0 +1,11 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+
+package cli
+
+import "os"
+
+// ttyWidth reports no width where the terminal cannot be asked, leaving
+// terminalWidth to $COLUMNS.
+func ttyWidth(_ *os.File) (int, bool) {
+	return 0, false
+}
@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	userapp "github.com/thatnerdjosh/mindfulness/internal/application/user"
+	"github.com/thatnerdjosh/mindfulness/internal/infrastructure/persistence/flatfile"
+	"github.com/thatnerdjosh/mindfulness/internal/interfaces/schema"
+)
+
+func openUserService() (*userapp.Service, error) {
+	registryPath, err := flatfile.DefaultUserRegistryPath()
+	if err != nil {
+		return nil, err
+	}
+	registry, err := flatfile.NewUserRepository(registryPath)
+	if err != nil {
+		return nil, err
+	}
+	return userapp.NewService(registry), nil
+}
+
+func runUser(args []string, svc *userapp.Service, format outputFormat, out io.Writer, errOut io.Writer) error {
+	if len(args) < 1 {
+		printUserUsage(errOut)
+		return fmt.Errorf("user subcommand required")
+	}
+
+	switch args[0] {
+	case "add":
+		if len(args) != 2 {
+			return fmt.Errorf("user add takes exactly one name")
+		}
+		return runUserAdd(args[1], svc, format, out)
+	case "list":
+		return runUserList(svc, format, out)
+	case "revoke":
+		if len(args) != 2 {
+			return fmt.Errorf("user revoke takes exactly one name")
+		}
+		return runUserRevoke(args[1], svc, format, out)
+	case "help", "-h", "--help":
+		printUserUsage(out)
+		return nil
+	default:
+		fmt.Fprintf(errOut, "unknown user command: %s\n", args[0])
+		printUserUsage(errOut)
+		return fmt.Errorf("unknown user command: %s", args[0])
+	}
+}
+
+func runUserAdd(name string, svc *userapp.Service, format outputFormat, out io.Writer) error {
+	created, token, err := svc.Add(context.Background(), name)
+	if err != nil {
+		return err
+	}
+	if format != formatText {
+		record := schema.FromUser(created)
+		record.Token = token
+		return writeObject(out, format, record)
+	}
+	fmt.Fprintf(out, "added user %s\n", created.Name)
+	fmt.Fprintf(out, "token: %s\n", token)
+	fmt.Fprintln(out, "store this token now; it cannot be shown again")
+	return nil
+}
+
+func runUserList(svc *userapp.Service, format outputFormat, out io.Writer) error {
+	users, err := svc.List(context.Background())
+	if err != nil {
+		return err
+	}
+	if format != formatText {
+		records := make([]schema.User, 0, len(users))
+		for _, u := range users {
+			records = append(records, schema.FromUser(u))
+		}
+		return writeList(out, format, records)
+	}
+	if len(users) == 0 {
+		fmt.Fprintln(out, "no users")
+		return nil
+	}
+	for _, u := range users {
+		status := "active"
+		if u.Revoked() {
+			status = "revoked " + u.RevokedAt.Local().Format("2006-01-02")
+		}
+		fmt.Fprintf(out, "%s  created %s  %s\n", u.Name, u.CreatedAt.Local().Format("2006-01-02"), status)
+	}
+	return nil
+}
+
+func runUserRevoke(name string, svc *userapp.Service, format outputFormat, out io.Writer) error {
+	revoked, err := svc.Revoke(context.Background(), name)
+	if err != nil {
+		return err
+	}
+	if format != formatText {
+		return writeObject(out, format, schema.FromUser(revoked))
+	}
+	fmt.Fprintf(out, "revoked user %s\n", revoked.Name)
+	return nil
+}
+
+func printUserUsage(out io.Writer) {
+	fmt.Fprintln(out, "Usage:")
+	fmt.Fprintln(out, "  mt user add <name>")
+	fmt.Fprintln(out, "  mt user list")
+	fmt.Fprintln(out, "  mt user revoke <name>")
+}
@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	userapp "github.com/thatnerdjosh/mindfulness/internal/application/user"
+	"github.com/thatnerdjosh/mindfulness/internal/infrastructure/persistence/flatfile"
+	"github.com/thatnerdjosh/mindfulness/internal/interfaces/schema"
+)
+
+func TestRunUserLifecycle(t *testing.T) {
+	registry, err := flatfile.NewUserRepository(filepath.Join(t.TempDir(), "users.json"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	svc := userapp.NewService(registry)
+
+	var out bytes.Buffer
+	if err := runUser([]string{"add", "alice"}, svc, formatJSON, &out, &bytes.Buffer{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var added schema.User
+	if err := json.Unmarshal(out.Bytes(), &added); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if added.Name != "alice" || !strings.HasPrefix(added.Token, "mt_") {
+		t.Fatalf("unexpected add output: %+v", added)
+	}
+
+	if err := runUser([]string{"add", "alice"}, svc, formatText, &bytes.Buffer{}, &bytes.Buffer{}); err == nil {
+		t.Fatalf("expected duplicate user error")
+	}
+
+	out.Reset()
+	if err := runUser([]string{"revoke", "alice"}, svc, formatText, &out, &bytes.Buffer{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out.String(), "revoked user alice") {
+		t.Fatalf("unexpected revoke output: %q", out.String())
+	}
+
+	out.Reset()
+	if err := runUser([]string{"list"}, svc, formatJSON, &out, &bytes.Buffer{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var users []schema.User
+	if err := json.Unmarshal(out.Bytes(), &users); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(users) != 1 || users[0].RevokedAt == "" || users[0].Token != "" {
+		t.Fatalf("unexpected list output: %+v", users)
+	}
+}
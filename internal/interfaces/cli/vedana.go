@@ -0,0 +1,230 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	analyticsapp "github.com/thatnerdjosh/mindfulness/internal/application/analytics"
+	"github.com/thatnerdjosh/mindfulness/internal/domain/journal"
+	"github.com/thatnerdjosh/mindfulness/internal/interfaces/schema"
+)
+
+// promptVedana asks for the feeling tone of an entry with the vedana
+// foundation. Skipping the tone skips the rest and records none.
+func promptVedana(reader *bufio.Reader, out io.Writer) (*journal.Vedana, error) {
+	var tone journal.Tone
+	for {
+		input, err := prompt(reader, out, "Feeling tone (p/u/n, optional): ")
+		if err != nil {
+			return nil, err
+		}
+		if input == "" {
+			return nil, nil
+		}
+		if tone, err = journal.ParseTone(input); err == nil {
+			break
+		}
+		fmt.Fprintln(out, "Please enter p, u, or n.")
+	}
+
+	var intensity int
+	for {
+		input, err := prompt(reader, out, fmt.Sprintf("Intensity (1-%d, optional): ", journal.MaxVedanaIntensity))
+		if err != nil {
+			return nil, err
+		}
+		if input == "" {
+			break
+		}
+		if intensity, err = strconv.Atoi(input); err == nil && intensity >= 1 && intensity <= journal.MaxVedanaIntensity {
+			break
+		}
+		intensity = 0
+		fmt.Fprintf(out, "Please enter 1-%d.\n", journal.MaxVedanaIntensity)
+	}
+
+	location, err := prompt(reader, out, "Where in the body (optional): ")
+	if err != nil {
+		return nil, err
+	}
+	trigger, err := prompt(reader, out, "Trigger (optional): ")
+	if err != nil {
+		return nil, err
+	}
+
+	vedana, err := journal.NewVedana(tone, intensity, location, trigger)
+	if err != nil {
+		return nil, err
+	}
+	return &vedana, nil
+}
+
+// promptVedanaFor asks for the feeling tone only when foundation is vedana
+// or one of its objects.
+func promptVedanaFor(reader *bufio.Reader, out io.Writer, foundation journal.Foundation) (*journal.Vedana, error) {
+	if foundation.Root() != journal.FoundationVedana {
+		return nil, nil
+	}
+	return promptVedana(reader, out)
+}
+
+// vedanaOptions records vedana on the entry when one was given.
+func vedanaOptions(vedana *journal.Vedana) []journal.EntryOption {
+	if vedana == nil {
+		return nil
+	}
+	return []journal.EntryOption{journal.WithVedana(*vedana)}
+}
+
+// vedanaLabel describes a feeling tone in one line, such as
+// "unpleasant, intensity 4/5, in chest, after email".
+func vedanaLabel(vedana journal.Vedana) string {
+	parts := []string{string(vedana.Tone)}
+	if vedana.Intensity > 0 {
+		parts = append(parts, fmt.Sprintf("intensity %d/%d", vedana.Intensity, journal.MaxVedanaIntensity))
+	}
+	if vedana.Location != "" {
+		parts = append(parts, "in "+vedana.Location)
+	}
+	if vedana.Trigger != "" {
+		parts = append(parts, "after "+vedana.Trigger)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func runVedana(args []string, analytics *analyticsapp.Service, format outputFormat, out io.Writer, errOut io.Writer) error {
+	fs := flag.NewFlagSet("vedana", flag.ContinueOnError)
+	fs.SetOutput(errOut)
+	sinceStr := fs.String("since", "", "first day to include (YYYY-MM-DD, default 12 weeks or 30 days back)")
+	untilStr := fs.String("until", "", "last day to include (YYYY-MM-DD, default today)")
+	by := fs.String("by", "week", "period to count over: day or week")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return errors.New("usage: mt vedana [--since YYYY-MM-DD] [--until YYYY-MM-DD] [--by day|week]")
+	}
+	period := analyticsapp.Period(*by)
+
+	until, err := parseDate(*untilStr)
+	if err != nil {
+		return err
+	}
+	var since time.Time
+	switch {
+	case *sinceStr != "":
+		if since, err = parseDate(*sinceStr); err != nil {
+			return err
+		}
+	case period == analyticsapp.Daily:
+		since = until.AddDate(0, 0, -29)
+	default:
+		since = until.AddDate(0, 0, -7*11)
+	}
+
+	report, err := analytics.FeelingTones(context.Background(), since, until, period)
+	if err != nil {
+		return err
+	}
+	if format != formatText {
+		return writeObject(out, format, fromFeelingTones(report, until))
+	}
+	if report.Total.Total() == 0 {
+		fmt.Fprintln(out, "no feeling tones in range")
+		return nil
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "Feeling tones %s to %s, by %s\n\n", report.Since.Format("2006-01-02"), report.Until.Format("2006-01-02"), period)
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	heading := "Day"
+	if period == analyticsapp.Weekly {
+		heading = "Week of"
+	}
+	fmt.Fprintf(w, "%s\tPleasant\tUnpleasant\tNeutral\tIntensity\n", heading)
+	for _, point := range report.Points {
+		if point.Total() == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%s\n", point.Start.Format("2006-01-02"), toneColumns(point.ToneCount))
+	}
+	fmt.Fprintf(w, "Total\t%s\n", toneColumns(report.Total))
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	total := float64(report.Total.Total())
+	fmt.Fprintf(&buf, "\n%.0f%% pleasant, %.0f%% unpleasant, %.0f%% neutral\n",
+		float64(report.Total.Pleasant)/total*100, float64(report.Total.Unpleasant)/total*100, float64(report.Total.Neutral)/total*100)
+	if len(report.Locations) > 0 {
+		fmt.Fprintf(&buf, "Felt in: %s\n", detailList(report.Locations))
+	}
+	if len(report.Triggers) > 0 {
+		fmt.Fprintf(&buf, "Triggers: %s\n", detailList(report.Triggers))
+	}
+	return page(out, buf.Bytes())
+}
+
+// toneColumns formats a tally for the report table; an unrated period shows
+// its intensity as "-".
+func toneColumns(count analyticsapp.ToneCount) string {
+	intensity := "-"
+	if count.Rated > 0 {
+		intensity = fmt.Sprintf("%.1f", count.Intensity)
+	}
+	return fmt.Sprintf("%d\t%d\t%d\t%s", count.Pleasant, count.Unpleasant, count.Neutral, intensity)
+}
+
+func detailList(details []analyticsapp.DetailCount) string {
+	parts := make([]string, 0, len(details))
+	for _, detail := range details {
+		parts = append(parts, fmt.Sprintf("%s (%d)", detail.Detail, detail.Entries))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func fromFeelingTones(report analyticsapp.FeelingTones, until time.Time) schema.FeelingTones {
+	result := schema.FeelingTones{
+		Until:     until.Format("2006-01-02"),
+		Total:     fromToneCount(report.Total),
+		Periods:   []schema.TonePeriod{},
+		Locations: []schema.DetailCount{},
+		Triggers:  []schema.DetailCount{},
+	}
+	if !report.Since.IsZero() {
+		result.Since = report.Since.Format("2006-01-02")
+	}
+	for _, point := range report.Points {
+		result.Periods = append(result.Periods, schema.TonePeriod{Start: point.Start.Format("2006-01-02"), ToneCount: fromToneCount(point.ToneCount)})
+	}
+	for _, detail := range report.Locations {
+		result.Locations = append(result.Locations, schema.DetailCount{Detail: detail.Detail, Entries: detail.Entries})
+	}
+	for _, detail := range report.Triggers {
+		result.Triggers = append(result.Triggers, schema.DetailCount{Detail: detail.Detail, Entries: detail.Entries})
+	}
+	return result
+}
+
+func fromToneCount(count analyticsapp.ToneCount) schema.ToneCount {
+	result := schema.ToneCount{
+		Pleasant:   count.Pleasant,
+		Unpleasant: count.Unpleasant,
+		Neutral:    count.Neutral,
+		Rated:      count.Rated,
+	}
+	if count.Rated > 0 {
+		intensity := count.Intensity
+		result.Intensity = &intensity
+	}
+	return result
+}
@@ -0,0 +1,125 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	journalapp "github.com/thatnerdjosh/mindfulness/internal/application/journal"
+	"github.com/thatnerdjosh/mindfulness/internal/domain/journal"
+	"github.com/thatnerdjosh/mindfulness/internal/infrastructure/persistence/memory"
+	"github.com/thatnerdjosh/mindfulness/internal/interfaces/schema"
+)
+
+func TestPromptVedana(t *testing.T) {
+	tests := []struct {
+		name  string
+		input []string
+		want  *journal.Vedana
+	}{
+		{name: "skipped", input: []string{""}, want: nil},
+		{name: "tone only", input: []string{"n", "", "", ""}, want: &journal.Vedana{Tone: journal.ToneNeutral}},
+		{
+			name:  "retries",
+			input: []string{"x", "u", "9", "4", "chest", "email"},
+			want:  &journal.Vedana{Tone: journal.ToneUnpleasant, Intensity: 4, Location: "chest", Trigger: "email"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			got, err := promptVedana(bufio.NewReader(newInput(tt.input...)), &out)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Fatalf("expected %+v, got %+v (output %q)", tt.want, got, out.String())
+			}
+		})
+	}
+}
+
+func TestRunQuicknoteVedana(t *testing.T) {
+	repo := memory.NewJournalRepository()
+	svc := journalapp.NewService(repo)
+	var out bytes.Buffer
+	if err := runQuicknote(nil, svc, formatText, newInput("tight jaw in traffic", "v", "u", "3", "jaw", "traffic"), &out, &bytes.Buffer{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	entry, err := svc.LatestEntry(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if entry.Vedana == nil || *entry.Vedana != (journal.Vedana{Tone: journal.ToneUnpleasant, Intensity: 3, Location: "jaw", Trigger: "traffic"}) {
+		t.Fatalf("unexpected entry: %+v", entry)
+	}
+
+	out.Reset()
+	if err := runQuicknote(nil, svc, formatText, newInput("sat", "d"), &out, &bytes.Buffer{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(out.String(), "Feeling tone") {
+		t.Fatalf("expected no feeling tone prompt for dhamma, got %q", out.String())
+	}
+}
+
+func TestRunVedana(t *testing.T) {
+	var entries []testEntry
+	for _, item := range []struct {
+		date   string
+		vedana journal.Vedana
+	}{
+		{"2024-05-01", journal.Vedana{Tone: journal.ToneUnpleasant, Intensity: 4, Location: "chest", Trigger: "email"}},
+		{"2024-05-01", journal.Vedana{Tone: journal.TonePleasant, Intensity: 2}},
+		{"2024-05-03", journal.Vedana{Tone: journal.ToneNeutral, Location: "hands"}},
+	} {
+		at, _ := time.Parse("2006-01-02", item.date)
+		entries = append(entries, testEntry{date: item.date, at: at, note: "noticed", foundation: journal.FoundationVedana, opts: []journal.EntryOption{journal.WithVedana(item.vedana)}})
+	}
+	analytics := newTestData().seed(t, entries).analytics
+
+	var out bytes.Buffer
+	args := []string{"--by", "day", "--since", "2024-05-01", "--until", "2024-05-03"}
+	if err := runVedana(args, analytics, formatText, &out, &bytes.Buffer{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"Feeling tones 2024-05-01 to 2024-05-03, by day",
+		"2024-05-01  1         1           0        3.0",
+		"Total       1         1           1        3.0",
+		"33% pleasant, 33% unpleasant, 33% neutral",
+		"Felt in: chest (1), hands (1)",
+		"Triggers: email (1)",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("expected %q in output, got %q", want, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "2024-05-02") {
+		t.Fatalf("expected the empty day to be skipped, got %q", out.String())
+	}
+
+	out.Reset()
+	if err := runVedana(args, analytics, formatJSON, &out, &bytes.Buffer{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var report schema.FeelingTones
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("expected a JSON object, got %q", out.String())
+	}
+	if len(report.Periods) != 3 || report.Periods[1].Intensity != nil || report.Total.Neutral != 1 || *report.Total.Intensity != 3 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+
+	out.Reset()
+	if err := runVedana([]string{"--since", "2024-06-01", "--until", "2024-06-02"}, analytics, formatText, &out, &bytes.Buffer{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out.String(), "no feeling tones in range") {
+		t.Fatalf("unexpected output: %q", out.String())
+	}
+}
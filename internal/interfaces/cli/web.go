@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+
+	adherenceapp "github.com/thatnerdjosh/mindfulness/internal/application/adherence"
+	journalapp "github.com/thatnerdjosh/mindfulness/internal/application/journal"
+	"github.com/thatnerdjosh/mindfulness/internal/interfaces/httpapi"
+	"github.com/thatnerdjosh/mindfulness/internal/interfaces/web"
+)
+
+func runWeb(args []string, svc *journalapp.Service, adherenceSvc *adherenceapp.Service, out io.Writer, errOut io.Writer) error {
+	fs := flag.NewFlagSet("web", flag.ContinueOnError)
+	fs.SetOutput(errOut)
+	listen := fs.String("listen", "127.0.0.1:8081", "loopback address to listen on")
+	allowRemote := fs.Bool("allow-remote", false, "allow listening on a non-loopback address")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if !*allowRemote && !isLoopbackAddr(*listen) {
+		return fmt.Errorf("refusing to listen on non-loopback address %s (use --allow-remote)", *listen)
+	}
+
+	var opts []web.Option
+	if *allowRemote {
+		opts = append(opts, web.WithAnyHost())
+	}
+	handler, err := web.NewHandler(svc, adherenceSvc, opts...)
+	if err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("tcp", *listen)
+	if err != nil {
+		return fmt.Errorf("listen: %w", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	fmt.Fprintf(out, "web UI on http://%s/\n", listener.Addr())
+	return httpapi.Serve(ctx, listener, handler)
+}
+
+func isLoopbackAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
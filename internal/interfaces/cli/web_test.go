@@ -0,0 +1,41 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	adherenceapp "github.com/thatnerdjosh/mindfulness/internal/application/adherence"
+	journalapp "github.com/thatnerdjosh/mindfulness/internal/application/journal"
+	"github.com/thatnerdjosh/mindfulness/internal/infrastructure/persistence/memory"
+)
+
+func TestIsLoopbackAddr(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"127.0.0.1:8081", true},
+		{"[::1]:8081", true},
+		{"localhost:8081", true},
+		{"0.0.0.0:8081", false},
+		{"192.168.1.5:8081", false},
+		{":8081", false},
+		{"bad", false},
+	}
+
+	for _, tt := range tests {
+		if got := isLoopbackAddr(tt.addr); got != tt.want {
+			t.Errorf("isLoopbackAddr(%q) = %v, want %v", tt.addr, got, tt.want)
+		}
+	}
+}
+
+func TestRunWebRefusesRemoteAddress(t *testing.T) {
+	svc := journalapp.NewService(memory.NewJournalRepository())
+	adherenceSvc := adherenceapp.NewService(memory.NewAdherenceRepository())
+	err := runWeb([]string{"--listen", "0.0.0.0:0"}, svc, adherenceSvc, &bytes.Buffer{}, &bytes.Buffer{})
+	if err == nil || !strings.Contains(err.Error(), "non-loopback") {
+		t.Fatalf("expected loopback error, got %v", err)
+	}
+}
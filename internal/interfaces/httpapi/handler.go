@@ -0,0 +1,265 @@
+// Package httpapi exposes the journal and adherence use cases as a JSON REST
+// API using the payloads defined in package schema.
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	adherenceapp "github.com/thatnerdjosh/mindfulness/internal/application/adherence"
+	journalapp "github.com/thatnerdjosh/mindfulness/internal/application/journal"
+	"github.com/thatnerdjosh/mindfulness/internal/domain/journal"
+	"github.com/thatnerdjosh/mindfulness/internal/interfaces/schema"
+)
+
+const maxBodyBytes = 1 << 20
+
+// Services are the use cases a request operates on.
+type Services struct {
+	Journal   *journalapp.Service
+	Adherence *adherenceapp.Service
+}
+
+type servicesKey struct{}
+
+func withServices(r *http.Request, svcs Services) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), servicesKey{}, svcs))
+}
+
+func servicesFrom(r *http.Request) Services {
+	svcs, _ := r.Context().Value(servicesKey{}).(Services)
+	return svcs
+}
+
+// Handler serves the REST API.
+type Handler struct {
+	mux   *http.ServeMux
+	entry http.Handler
+	now   func() time.Time
+}
+
+// NewHandler serves a single journal and adherence store without
+// authentication, so it only answers requests addressed to a loopback host.
+func NewHandler(journalSvc *journalapp.Service, adherenceSvc *adherenceapp.Service) *Handler {
+	h := newHandler()
+	svcs := Services{Journal: journalSvc, Adherence: adherenceSvc}
+	h.entry = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !LoopbackHost(r) {
+			writeError(w, http.StatusForbidden, errors.New("unexpected Host header"))
+			return
+		}
+		h.mux.ServeHTTP(w, withServices(r, svcs))
+	})
+	return h
+}
+
+func newHandler() *Handler {
+	h := &Handler{
+		mux: http.NewServeMux(),
+		now: time.Now,
+	}
+	h.mux.HandleFunc("GET /api/v1/entries", h.listEntries)
+	h.mux.HandleFunc("POST /api/v1/entries", h.createEntry)
+	h.mux.HandleFunc("GET /api/v1/entries/latest", h.latestEntry)
+	h.mux.HandleFunc("GET /api/v1/entries/{date}", h.entriesOn)
+	h.mux.HandleFunc("GET /api/v1/adherence", h.getAdherence)
+	h.mux.HandleFunc("PUT /api/v1/adherence", h.setAdherence)
+	h.mux.HandleFunc("GET /api/v1/adherence/log", h.adherenceLog)
+	return h
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.entry.ServeHTTP(w, r)
+}
+
+// listEntries lists entries, optionally filtered by the since, until,
+// precept, foundation and q query parameters.
+func (h *Handler) listEntries(w http.ResponseWriter, r *http.Request) {
+	query, err := parseQuery(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	entries, err := servicesFrom(r).Journal.QueryEntries(r.Context(), query)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, schema.FromEntries(entries))
+}
+
+func (h *Handler) createEntry(w http.ResponseWriter, r *http.Request) {
+	var record schema.Entry
+	if err := decodeBody(r, &record); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	entry, err := record.ToEntry(h.now())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	entry, err = servicesFrom(r).Journal.SaveEntry(r.Context(), entry)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, schema.FromEntry(entry))
+}
+
+func (h *Handler) latestEntry(w http.ResponseWriter, r *http.Request) {
+	entry, err := servicesFrom(r).Journal.LatestEntry(r.Context())
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, schema.FromEntry(*entry))
+}
+
+func (h *Handler) entriesOn(w http.ResponseWriter, r *http.Request) {
+	date, err := parseDay(r.PathValue("date"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	svcs := servicesFrom(r)
+	entries, err := svcs.Journal.EntriesOn(r.Context(), date)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	changes, err := svcs.Adherence.LogOn(r.Context(), date)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, schema.Day{
+		Date:             date.Format("2006-01-02"),
+		Entries:          schema.FromEntries(entries),
+		AdherenceChanges: schema.FromLogEntries(changes),
+	})
+}
+
+func (h *Handler) getAdherence(w http.ResponseWriter, r *http.Request) {
+	state, err := servicesFrom(r).Adherence.Current(r.Context())
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, schema.FromAdherence(state))
+}
+
+func (h *Handler) setAdherence(w http.ResponseWriter, r *http.Request) {
+	var request schema.AdherenceRequest
+	if err := decodeBody(r, &request); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	next, notes := request.ToAdherence()
+	svcs := servicesFrom(r)
+	changes, err := svcs.Adherence.Set(r.Context(), next, notes)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	state, err := svcs.Adherence.Current(r.Context())
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, schema.AdherenceUpdate{
+		Adherence: schema.FromAdherence(state),
+		Changes:   schema.FromLogEntries(changes),
+	})
+}
+
+func (h *Handler) adherenceLog(w http.ResponseWriter, r *http.Request) {
+	entries, err := servicesFrom(r).Adherence.Log(r.Context())
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, schema.FromLogEntries(entries))
+}
+
+func parseQuery(r *http.Request) (journalapp.Query, error) {
+	values := r.URL.Query()
+	query := journalapp.Query{
+		Precept:    journal.Precept(strings.TrimSpace(values.Get("precept"))),
+		Foundation: journal.Foundation(strings.ToLower(strings.TrimSpace(values.Get("foundation")))),
+		Text:       values.Get("q"),
+	}
+	var err error
+	if since := values.Get("since"); since != "" {
+		if query.Since, err = parseDay(since); err != nil {
+			return journalapp.Query{}, err
+		}
+	}
+	if until := values.Get("until"); until != "" {
+		if query.Until, err = parseDay(until); err != nil {
+			return journalapp.Query{}, err
+		}
+	}
+	return query, nil
+}
+
+func parseDay(input string) (time.Time, error) {
+	parsed, err := time.Parse("2006-01-02", strings.TrimSpace(input))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q: expected YYYY-MM-DD", input)
+	}
+	return parsed, nil
+}
+
+func decodeBody(r *http.Request, v any) error {
+	decoder := json.NewDecoder(io.LimitReader(r.Body, maxBodyBytes))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return fmt.Errorf("decode request body: %w", err)
+	}
+	return nil
+}
+
+// statusFor maps domain and application errors to HTTP status codes.
+func statusFor(err error) int {
+	switch {
+	case errors.Is(err, journal.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, journal.ErrInvalidDate),
+		errors.Is(err, journal.ErrEmptyEntry),
+		errors.Is(err, journal.ErrUnknownPrecept),
+		errors.Is(err, journal.ErrUnknownFoundation),
+		errors.Is(err, journal.ErrUnknownTone),
+		errors.Is(err, journal.ErrInvalidIntensity),
+		errors.Is(err, journal.ErrVedanaFoundation),
+		errors.Is(err, journal.ErrUnknownReviewPeriod):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func writeServiceError(w http.ResponseWriter, err error) {
+	status := statusFor(err)
+	if status == http.StatusInternalServerError {
+		writeError(w, status, errors.New(http.StatusText(status)))
+		return
+	}
+	writeError(w, status, err)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, schema.Error{Error: err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
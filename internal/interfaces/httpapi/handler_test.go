@@ -0,0 +1,316 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	adherenceapp "github.com/thatnerdjosh/mindfulness/internal/application/adherence"
+	journalapp "github.com/thatnerdjosh/mindfulness/internal/application/journal"
+	"github.com/thatnerdjosh/mindfulness/internal/domain/journal"
+	"github.com/thatnerdjosh/mindfulness/internal/infrastructure/persistence/memory"
+	"github.com/thatnerdjosh/mindfulness/internal/interfaces/schema"
+)
+
+func newTestHandler(t *testing.T) *Handler {
+	t.Helper()
+	repo := memory.NewJournalRepository()
+	for _, day := range []int{1, 2, 3} {
+		date := time.Date(2024, 1, day, 0, 0, 0, 0, time.UTC)
+		reflections := map[journal.Precept]string{journal.TrueLove: "kind words"}
+		if day == 2 {
+			reflections = map[journal.Precept]string{journal.TrueHappiness: "shared a meal"}
+		}
+		entry, err := journal.NewEntry(date, reflections, "", "", journal.FoundationDhamma, date.Add(9*time.Hour))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := repo.Save(context.Background(), entry); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	h := NewHandler(journalapp.NewService(repo), adherenceapp.NewService(memory.NewAdherenceRepository()))
+	h.now = func() time.Time { return time.Date(2024, 1, 4, 8, 0, 0, 0, time.UTC) }
+	return h
+}
+
+func do(t *testing.T, h http.Handler, method, target, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Host = "127.0.0.1:8080"
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestHandlerEntries(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		body       string
+		wantStatus int
+		check      func(t *testing.T, body []byte)
+	}{
+		{
+			name:       "list all",
+			method:     http.MethodGet,
+			target:     "/api/v1/entries",
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				var entries []schema.Entry
+				if err := json.Unmarshal(body, &entries); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if len(entries) != 3 {
+					t.Fatalf("expected 3 entries, got %d", len(entries))
+				}
+			},
+		},
+		{
+			name:       "query by range and precept",
+			method:     http.MethodGet,
+			target:     "/api/v1/entries?since=2024-01-02&until=2024-01-03&precept=true-love",
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				var entries []schema.Entry
+				if err := json.Unmarshal(body, &entries); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if len(entries) != 1 || entries[0].Date != "2024-01-03" {
+					t.Fatalf("unexpected entries: %+v", entries)
+				}
+			},
+		},
+		{
+			name:       "query text",
+			method:     http.MethodGet,
+			target:     "/api/v1/entries?q=MEAL",
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				var entries []schema.Entry
+				if err := json.Unmarshal(body, &entries); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if len(entries) != 1 || entries[0].Date != "2024-01-02" {
+					t.Fatalf("unexpected entries: %+v", entries)
+				}
+			},
+		},
+		{
+			name:       "query invalid date",
+			method:     http.MethodGet,
+			target:     "/api/v1/entries?since=yesterday",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "query unknown precept",
+			method:     http.MethodGet,
+			target:     "/api/v1/entries?precept=other",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "latest",
+			method:     http.MethodGet,
+			target:     "/api/v1/entries/latest",
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				var entry schema.Entry
+				if err := json.Unmarshal(body, &entry); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if entry.Date != "2024-01-03" {
+					t.Fatalf("unexpected entry: %+v", entry)
+				}
+			},
+		},
+		{
+			name:       "day",
+			method:     http.MethodGet,
+			target:     "/api/v1/entries/2024-01-02",
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				var day schema.Day
+				if err := json.Unmarshal(body, &day); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if day.Date != "2024-01-02" || len(day.Entries) != 1 {
+					t.Fatalf("unexpected day: %+v", day)
+				}
+			},
+		},
+		{
+			name:       "day invalid",
+			method:     http.MethodGet,
+			target:     "/api/v1/entries/not-a-date",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "create",
+			method:     http.MethodPost,
+			target:     "/api/v1/entries",
+			body:       `{"date":"2024-01-04","foundation":"kaya","note":"walking meditation"}`,
+			wantStatus: http.StatusCreated,
+			check: func(t *testing.T, body []byte) {
+				var entry schema.Entry
+				if err := json.Unmarshal(body, &entry); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if entry.Timestamp != "2024-01-04T08:00:00Z" || entry.Foundation != "kaya" {
+					t.Fatalf("unexpected entry: %+v", entry)
+				}
+			},
+		},
+		{
+			name:       "create empty entry",
+			method:     http.MethodPost,
+			target:     "/api/v1/entries",
+			body:       `{"date":"2024-01-04"}`,
+			wantStatus: http.StatusBadRequest,
+			check: func(t *testing.T, body []byte) {
+				if !strings.Contains(string(body), journal.ErrEmptyEntry.Error()) {
+					t.Fatalf("expected domain error message, got %s", body)
+				}
+			},
+		},
+		{
+			name:       "create malformed",
+			method:     http.MethodPost,
+			target:     "/api/v1/entries",
+			body:       `{"date":`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "method not allowed",
+			method:     http.MethodDelete,
+			target:     "/api/v1/entries",
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := do(t, newTestHandler(t), tt.method, tt.target, tt.body)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+			if tt.check != nil {
+				tt.check(t, rec.Body.Bytes())
+			}
+		})
+	}
+}
+
+func TestHandlerLatestNotFound(t *testing.T) {
+	h := NewHandler(journalapp.NewService(memory.NewJournalRepository()), adherenceapp.NewService(memory.NewAdherenceRepository()))
+	rec := do(t, h, http.MethodGet, "/api/v1/entries/latest", "")
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", rec.Code)
+	}
+}
+
+func TestHandlerAdherence(t *testing.T) {
+	h := newTestHandler(t)
+
+	rec := do(t, h, http.MethodPut, "/api/v1/adherence", `{"adherence":{"true-love":false},"notes":{"true-love":"harsh words"}}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var update schema.AdherenceUpdate
+	if err := json.Unmarshal(rec.Body.Bytes(), &update); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if update.Adherence["true-love"] || len(update.Changes) != 1 || update.Changes[0].Note != "harsh words" {
+		t.Fatalf("unexpected update: %+v", update)
+	}
+
+	rec = do(t, h, http.MethodGet, "/api/v1/adherence", "")
+	var state schema.Adherence
+	if err := json.Unmarshal(rec.Body.Bytes(), &state); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if state["true-love"] || !state["true-happiness"] {
+		t.Fatalf("unexpected state: %+v", state)
+	}
+
+	rec = do(t, h, http.MethodGet, "/api/v1/adherence/log", "")
+	var log []schema.LogEntry
+	if err := json.Unmarshal(rec.Body.Bytes(), &log); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(log) != 1 || log[0].Precept != "true-love" {
+		t.Fatalf("unexpected log: %+v", log)
+	}
+
+	rec = do(t, h, http.MethodPut, "/api/v1/adherence", `{"adherence":{"other":false}}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for unknown precept, got %d", rec.Code)
+	}
+}
+
+func TestStatusFor(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{journal.ErrNotFound, http.StatusNotFound},
+		{journal.ErrEmptyEntry, http.StatusBadRequest},
+		{journal.ErrInvalidDate, http.StatusBadRequest},
+		{journal.ErrUnknownFoundation, http.StatusBadRequest},
+		{errors.Join(errors.New("wrapped"), journal.ErrUnknownPrecept), http.StatusBadRequest},
+		{errors.New("disk full"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		if got := statusFor(tt.err); got != tt.want {
+			t.Errorf("statusFor(%v) = %d, want %d", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestWriteServiceErrorHidesInternalDetails(t *testing.T) {
+	rec := httptest.NewRecorder()
+	writeServiceError(rec, errors.New("open /home/user/journal.json: permission denied"))
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d", rec.Code)
+	}
+	if strings.Contains(rec.Body.String(), "journal.json") {
+		t.Fatalf("expected internal error details to be hidden, got %s", rec.Body.String())
+	}
+}
+
+func TestHandlerRejectsForeignHost(t *testing.T) {
+	server := httptest.NewServer(newTestHandler(t))
+	t.Cleanup(server.Close)
+	_, port, err := net.SplitHostPort(server.Listener.Addr().String())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for host, want := range map[string]int{
+		"127.0.0.1:" + port:       http.StatusOK,
+		"localhost:" + port:       http.StatusOK,
+		"rebound.example:" + port: http.StatusForbidden,
+		"localhost:1":             http.StatusForbidden,
+	} {
+		req, err := http.NewRequest(http.MethodGet, server.URL+"/api/v1/entries", nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		req.Host = host
+		resp, err := server.Client().Do(req)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		_ = resp.Body.Close()
+		if resp.StatusCode != want {
+			t.Errorf("Host %s: expected %d, got %d", host, want, resp.StatusCode)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package httpapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/thatnerdjosh/mindfulness/internal/domain/user"
+)
+
+// Authenticator resolves an API token to its user.
+type Authenticator interface {
+	Authenticate(ctx context.Context, token string) (user.User, error)
+}
+
+// OpenFunc builds the services backed by a single user's data.
+type OpenFunc func(u user.User) (Services, error)
+
+// NewMultiUserHandler requires a bearer token on every request and serves it
+// from the authenticated user's own stores. Requests from the same user are
+// serialized so that per-request repositories never overwrite each other.
+func NewMultiUserHandler(auth Authenticator, open OpenFunc) *Handler {
+	h := newHandler()
+	var locks sync.Map
+	h.entry = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := bearerToken(r)
+		if !ok {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="mt"`)
+			writeError(w, http.StatusUnauthorized, user.ErrUnauthorized)
+			return
+		}
+		u, err := auth.Authenticate(r.Context(), token)
+		if err != nil {
+			if errors.Is(err, user.ErrUnauthorized) {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="mt", error="invalid_token"`)
+				writeError(w, http.StatusUnauthorized, err)
+				return
+			}
+			writeServiceError(w, err)
+			return
+		}
+
+		lock, _ := locks.LoadOrStore(u.Name, &sync.Mutex{})
+		lock.(*sync.Mutex).Lock()
+		defer lock.(*sync.Mutex).Unlock()
+
+		svcs, err := open(u)
+		if err != nil {
+			writeServiceError(w, err)
+			return
+		}
+		h.mux.ServeHTTP(w, withServices(r, svcs))
+	})
+	return h
+}
+
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+		return "", false
+	}
+	return strings.TrimSpace(token), true
+}
@@ -0,0 +1,147 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	adherenceapp "github.com/thatnerdjosh/mindfulness/internal/application/adherence"
+	journalapp "github.com/thatnerdjosh/mindfulness/internal/application/journal"
+	userapp "github.com/thatnerdjosh/mindfulness/internal/application/user"
+	"github.com/thatnerdjosh/mindfulness/internal/domain/user"
+	"github.com/thatnerdjosh/mindfulness/internal/infrastructure/persistence/flatfile"
+	"github.com/thatnerdjosh/mindfulness/internal/interfaces/schema"
+)
+
+func newMultiUserTestHandler(t *testing.T) (*Handler, *userapp.Service) {
+	t.Helper()
+	registryPath := filepath.Join(t.TempDir(), "users.json")
+	registry, err := flatfile.NewUserRepository(registryPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	users := userapp.NewService(registry)
+
+	open := func(u user.User) (Services, error) {
+		dir := flatfile.UserDataDir(registryPath, u.Name)
+		journalRepo, err := flatfile.NewJournalRepository(filepath.Join(dir, "journal.json"))
+		if err != nil {
+			return Services{}, err
+		}
+		adherenceRepo, err := flatfile.NewAdherenceRepository(filepath.Join(dir, "adherence.json"), filepath.Join(dir, "adherence.log.jsonl"))
+		if err != nil {
+			return Services{}, err
+		}
+		return Services{
+			Journal:   journalapp.NewService(journalRepo),
+			Adherence: adherenceapp.NewService(adherenceRepo),
+		}, nil
+	}
+	return NewMultiUserHandler(users, open), users
+}
+
+func doAs(t *testing.T, h http.Handler, token, method, target, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestMultiUserHandlerIsolatesUsers(t *testing.T) {
+	h, users := newMultiUserTestHandler(t)
+	_, alice, err := users.Add(context.Background(), "alice")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, bob, err := users.Add(context.Background(), "bob")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	rec := doAs(t, h, alice, http.MethodPost, "/api/v1/entries", `{"date":"2024-01-05","note":"alice only"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	rec = doAs(t, h, alice, http.MethodPut, "/api/v1/adherence", `{"adherence":{"true-love":false}}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	rec = doAs(t, h, bob, http.MethodGet, "/api/v1/entries", "")
+	var entries []schema.Entry
+	if err := json.Unmarshal(rec.Body.Bytes(), &entries); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("expected bob to see no entries, got %+v", entries)
+	}
+	if rec := doAs(t, h, bob, http.MethodGet, "/api/v1/entries/latest", ""); rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for bob's latest entry, got %d", rec.Code)
+	}
+	rec = doAs(t, h, bob, http.MethodGet, "/api/v1/adherence/log", "")
+	var log []schema.LogEntry
+	if err := json.Unmarshal(rec.Body.Bytes(), &log); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(log) != 0 {
+		t.Fatalf("expected bob to see no adherence log, got %+v", log)
+	}
+
+	rec = doAs(t, h, alice, http.MethodGet, "/api/v1/entries", "")
+	entries = nil
+	if err := json.Unmarshal(rec.Body.Bytes(), &entries); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Note != "alice only" {
+		t.Fatalf("expected alice's entry, got %+v", entries)
+	}
+}
+
+func TestMultiUserHandlerRejectsBadTokens(t *testing.T) {
+	h, users := newMultiUserTestHandler(t)
+	_, revoked, err := users.Add(context.Background(), "carol")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := users.Revoke(context.Background(), "carol"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		header string
+	}{
+		{name: "missing", header: ""},
+		{name: "wrong scheme", header: "Basic " + revoked},
+		{name: "unknown token", header: "Bearer mt_not-a-real-token"},
+		{name: "revoked token", header: "Bearer " + revoked},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/entries", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			if rec.Code != http.StatusUnauthorized {
+				t.Fatalf("expected 401, got %d", rec.Code)
+			}
+			if rec.Header().Get("WWW-Authenticate") == "" {
+				t.Fatalf("expected WWW-Authenticate header")
+			}
+			var body schema.Error
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil || body.Error == "" {
+				t.Fatalf("expected JSON error body, got %q", rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,204 @@
+// Package report renders journal data through Go templates, either the
+// built-in ones or templates users write themselves. Templates see a Model
+// and the helpers returned by Funcs.
+package report
+
+import (
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	analyticsapp "github.com/thatnerdjosh/mindfulness/internal/application/analytics"
+	"github.com/thatnerdjosh/mindfulness/internal/domain/adherence"
+	"github.com/thatnerdjosh/mindfulness/internal/domain/journal"
+)
+
+//go:embed templates/*
+var builtins embed.FS
+
+// Model is what a report template executes against.
+type Model struct {
+	// Since and Until are the first and last day covered. Since is zero
+	// when the range was open and the journal has no entries in it.
+	Since time.Time
+	Until time.Time
+	// Generated is when the report was made.
+	Generated time.Time
+	// Entries are the journal entries in the range, in time order.
+	Entries []journal.Entry
+	// Adherence is the current adherence state: true for a precept that is
+	// being kept.
+	Adherence adherence.Adherence
+	// Log holds the adherence changes logged in the range, in time order.
+	Log []adherence.AdherenceLogEntry
+	// Stats summarizes the entries in the range, leaving out reviews.
+	Stats analyticsapp.Stats
+	// Precepts lists the five trainings in order.
+	Precepts []journal.PreceptInfo
+}
+
+// Keeping reports whether precept is being kept now, as in
+// {{.Keeping "true-love"}}.
+func (m Model) Keeping(precept journal.Precept) bool {
+	return m.Adherence[precept]
+}
+
+// Group is the entries of one day or of the week starting on Monday Start.
+type Group struct {
+	Start   time.Time
+	Entries []journal.Entry
+}
+
+// Reflection is one reflection of an entry with its precept's title and the
+// question it answered, if any.
+type Reflection struct {
+	Precept  journal.Precept
+	Title    string
+	Question string
+	Text     string
+}
+
+// Template is a parsed report template, text or HTML.
+type Template interface {
+	Execute(out io.Writer, data any) error
+}
+
+// Funcs returns the helpers available to every report template:
+//
+//	precept ID          the precept's title
+//	foundation F        the foundation's label, such as "Dhamma: Hindrances"
+//	date T [LAYOUT]     T formatted with LAYOUT, by default 2006-01-02
+//	reflections ENTRY   the entry's reflections in precept order
+//	byDay ENTRIES       the entries grouped by day
+//	byWeek ENTRIES      the entries grouped by week, starting on Monday
+//	yesno B             "yes" or "no"
+//	percent F           a fraction such as 0.25 as "25%"
+func Funcs() map[string]any {
+	return map[string]any{
+		"precept":     preceptTitle,
+		"foundation":  journal.FoundationLabel,
+		"date":        formatDate,
+		"reflections": reflections,
+		"byDay":       byDay,
+		"byWeek":      byWeek,
+		"yesno": func(value bool) string {
+			if value {
+				return "yes"
+			}
+			return "no"
+		},
+		"percent": func(share float64) string {
+			return fmt.Sprintf("%.0f%%", share*100)
+		},
+	}
+}
+
+// Parse parses text as a report template named name. HTML templates escape
+// what they print for the context it appears in.
+func Parse(name string, text string, html bool) (Template, error) {
+	if html {
+		return htmltemplate.New(name).Funcs(htmltemplate.FuncMap(Funcs())).Parse(text)
+	}
+	return template.New(name).Funcs(template.FuncMap(Funcs())).Parse(text)
+}
+
+// IsHTML reports whether a template file should be parsed as HTML, judging
+// by its name: "report.html", "report.html.tmpl" and "report.gohtml" are.
+func IsHTML(name string) bool {
+	base := strings.ToLower(path.Base(name))
+	base = strings.TrimSuffix(base, ".tmpl")
+	return strings.HasSuffix(base, ".html") || strings.HasSuffix(base, ".htm") || strings.HasSuffix(base, ".gohtml")
+}
+
+// Builtins lists the names of the built-in templates.
+func Builtins() []string {
+	files, _ := builtins.ReadDir("templates")
+	names := make([]string, 0, len(files))
+	for _, file := range files {
+		name, _, _ := strings.Cut(file.Name(), ".")
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Builtin returns the built-in template called name.
+func Builtin(name string) (Template, error) {
+	files, _ := builtins.ReadDir("templates")
+	for _, file := range files {
+		if base, _, _ := strings.Cut(file.Name(), "."); base != name {
+			continue
+		}
+		data, err := builtins.ReadFile("templates/" + file.Name())
+		if err != nil {
+			return nil, err
+		}
+		return Parse(file.Name(), string(data), IsHTML(file.Name()))
+	}
+	return nil, fmt.Errorf("unknown built-in template %q (expected one of %s)", name, strings.Join(Builtins(), ", "))
+}
+
+func preceptTitle(precept journal.Precept) string {
+	for _, info := range journal.AllPrecepts() {
+		if info.ID == precept {
+			return info.Title
+		}
+	}
+	return string(precept)
+}
+
+func formatDate(value time.Time, layout ...string) string {
+	if value.IsZero() {
+		return ""
+	}
+	if len(layout) > 0 {
+		return value.Format(layout[0])
+	}
+	return value.Format("2006-01-02")
+}
+
+func reflections(entry journal.Entry) []Reflection {
+	var list []Reflection
+	for _, info := range journal.AllPrecepts() {
+		if text, ok := entry.Reflections[info.ID]; ok {
+			list = append(list, Reflection{Precept: info.ID, Title: info.Title, Question: entry.Questions[info.ID], Text: text})
+		}
+	}
+	return list
+}
+
+func byDay(entries []journal.Entry) []Group {
+	return group(entries, func(day time.Time) time.Time { return day })
+}
+
+func byWeek(entries []journal.Entry) []Group {
+	return group(entries, func(day time.Time) time.Time {
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	})
+}
+
+// group buckets entries by the start of their period, in date order.
+func group(entries []journal.Entry, start func(day time.Time) time.Time) []Group {
+	sorted := append([]journal.Entry(nil), entries...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if !sorted[i].Date.Equal(sorted[j].Date) {
+			return sorted[i].Date.Before(sorted[j].Date)
+		}
+		return sorted[i].Timestamp.Before(sorted[j].Timestamp)
+	})
+	var groups []Group
+	for _, entry := range sorted {
+		key := start(entry.Date)
+		if len(groups) == 0 || !groups[len(groups)-1].Start.Equal(key) {
+			groups = append(groups, Group{Start: key})
+		}
+		groups[len(groups)-1].Entries = append(groups[len(groups)-1].Entries, entry)
+	}
+	return groups
+}
@@ -0,0 +1,117 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	analyticsapp "github.com/thatnerdjosh/mindfulness/internal/application/analytics"
+	"github.com/thatnerdjosh/mindfulness/internal/domain/adherence"
+	"github.com/thatnerdjosh/mindfulness/internal/domain/journal"
+)
+
+func testModel(t *testing.T) Model {
+	t.Helper()
+	var entries []journal.Entry
+	for _, value := range []struct {
+		date, note  string
+		reflections map[journal.Precept]string
+	}{
+		{"2024-04-30", "<b>rainy</b>", map[journal.Precept]string{journal.TrueLove: "called my sister"}},
+		{"2024-04-29", "", map[journal.Precept]string{journal.ReverenceForLife: "walked around the ants", journal.TrueLove: "listened"}},
+		{"2024-05-06", "new week", nil},
+	} {
+		day, _ := time.Parse("2006-01-02", value.date)
+		entry, err := journal.NewEntry(day, value.reflections, value.note, "calm", "dhamma.hindrances", day)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		entries = append(entries, entry)
+	}
+	since, _ := time.Parse("2006-01-02", "2024-04-29")
+	return Model{
+		Since:     since,
+		Until:     since.AddDate(0, 0, 13),
+		Entries:   entries,
+		Adherence: adherence.Adherence{journal.TrueLove: false},
+		Log:       []adherence.AdherenceLogEntry{{At: since.Add(9 * time.Hour), Precept: journal.TrueLove, From: true, To: false, Note: "snapped"}},
+		Stats: analyticsapp.Stats{
+			Entries:     3,
+			Precepts:    []analyticsapp.PreceptCount{{Precept: journal.TrueLove, Reflections: 2}},
+			Foundations: []analyticsapp.FoundationShare{{Foundation: journal.FoundationDhamma, Entries: 3, Share: 1}},
+		},
+		Precepts: journal.AllPrecepts(),
+	}
+}
+
+func TestParseWithHelpers(t *testing.T) {
+	tmpl, err := Parse("custom", `{{range byWeek .Entries}}{{date .Start "Jan 2"}}:{{range .Entries}} {{date .Date}}{{range reflections .}}/{{.Title}}{{end}}{{end}}
+{{end}}{{foundation (index .Entries 0).Foundation}} {{percent 0.25}} {{yesno (.Keeping "true-love")}}`, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, testModel(t)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "Apr 29: 2024-04-29/Reverence For Life/True Love 2024-04-30/True Love\nMay 6: 2024-05-06\nDhamma: Hindrances 25% no"
+	if got := out.String(); got != want {
+		t.Fatalf("unexpected output:\n got %q\nwant %q", got, want)
+	}
+}
+
+func TestParseHTMLEscapes(t *testing.T) {
+	tmpl, err := Parse("page.html", `{{range .Entries}}<p>{{.Note}}</p>{{end}}`, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, testModel(t)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out.String(), "<p>&lt;b&gt;rainy&lt;/b&gt;</p>") {
+		t.Fatalf("expected the note to be escaped, got %q", out.String())
+	}
+}
+
+func TestBuiltins(t *testing.T) {
+	if got := strings.Join(Builtins(), ","); got != "page,summary,weekly" {
+		t.Fatalf("unexpected built-ins: %s", got)
+	}
+	wants := map[string]string{
+		"summary": "  2024-04-29 09:00  True Love: yes -> no (snapped)",
+		"weekly":  "## Week of 2024-05-06",
+		"page":    "<p>&lt;b&gt;rainy&lt;/b&gt;</p>",
+	}
+	for _, name := range Builtins() {
+		tmpl, err := Builtin(name)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		var out bytes.Buffer
+		if err := tmpl.Execute(&out, testModel(t)); err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		if !strings.Contains(out.String(), wants[name]) {
+			t.Fatalf("%s: expected %q, got %q", name, wants[name], out.String())
+		}
+	}
+	if _, err := Builtin("nope"); err == nil {
+		t.Fatal("expected an error for an unknown built-in")
+	}
+}
+
+func TestIsHTML(t *testing.T) {
+	for name, want := range map[string]bool{
+		"report.html":       true,
+		"Report.HTML.tmpl":  true,
+		"dir/report.gohtml": true,
+		"report.tmpl":       false,
+		"report.md.tmpl":    false,
+	} {
+		if got := IsHTML(name); got != want {
+			t.Fatalf("IsHTML(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,540 @@
+// Package schema defines the stable JSON representation of journal entries,
+// adherence state and adherence log entries shared by every machine-readable
+// interface of mt.
+//
+// Field names and formats in this package are part of the public contract:
+// dates use YYYY-MM-DD, timestamps use RFC 3339 in UTC, precepts and
+// foundations use their domain identifiers. New optional fields may be added,
+// but existing fields are never renamed or repurposed.
+package schema
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	adherencedomain "github.com/thatnerdjosh/mindfulness/internal/domain/adherence"
+	"github.com/thatnerdjosh/mindfulness/internal/domain/journal"
+	"github.com/thatnerdjosh/mindfulness/internal/domain/user"
+)
+
+const dateLayout = "2006-01-02"
+
+// Entry is the JSON form of a journal entry. AdherenceEvents holds the
+// fingerprints of the adherence log events the entry was written about;
+// Vedana is only set on entries with the vedana foundation. Questions holds
+// the question each reflection answered, keyed by precept ID. Review is only
+// set on entries saved as a review of a week, month or year, and Quicknote
+// on entries written with mt quicknote.
+type Entry struct {
+	Date            string            `json:"date"`
+	Timestamp       string            `json:"timestamp,omitempty"`
+	Foundation      string            `json:"foundation,omitempty"`
+	Mood            string            `json:"mood,omitempty"`
+	Note            string            `json:"note,omitempty"`
+	Reflections     map[string]string `json:"reflections,omitempty"`
+	AdherenceEvents []string          `json:"adherence_events,omitempty"`
+	Vedana          *Vedana           `json:"vedana,omitempty"`
+	Questions       map[string]string `json:"questions,omitempty"`
+	Review          *ReviewPeriod     `json:"review,omitempty"`
+	Quicknote       bool              `json:"quicknote,omitempty"`
+}
+
+// ReviewPeriod is the JSON form of the period a review entry looks back on.
+// End is derived from Start and ignored on input.
+type ReviewPeriod struct {
+	Period string `json:"period"`
+	Start  string `json:"start"`
+	End    string `json:"end,omitempty"`
+}
+
+// Vedana is the JSON form of an entry's feeling tone. Intensity is omitted
+// when it was not rated.
+type Vedana struct {
+	Tone      string `json:"tone"`
+	Intensity int    `json:"intensity,omitempty"`
+	Location  string `json:"location,omitempty"`
+	Trigger   string `json:"trigger,omitempty"`
+}
+
+// Adherence is the JSON form of adherence state, keyed by precept ID.
+type Adherence map[string]bool
+
+// LogEntry is the JSON form of an adherence log entry. Kind is omitted for
+// changes and "initial" for the events a precept's history starts from.
+type LogEntry struct {
+	Timestamp string `json:"timestamp"`
+	Kind      string `json:"kind,omitempty"`
+	Precept   string `json:"precept"`
+	From      bool   `json:"from"`
+	To        bool   `json:"to"`
+	Note      string `json:"note,omitempty"`
+}
+
+// AdherenceUpdate is the JSON result of changing adherence. BeginningAnew
+// lists the records started for the breaks among Changes.
+type AdherenceUpdate struct {
+	Adherence     Adherence       `json:"adherence"`
+	Changes       []LogEntry      `json:"changes"`
+	BeginningAnew []BeginningAnew `json:"beginning_anew,omitempty"`
+}
+
+// BeginningAnew is the JSON form of a Beginning Anew record. Break is the
+// fingerprint of the log event that broke the precept; RenewedAt is omitted
+// until the commitment is renewed.
+type BeginningAnew struct {
+	Break           string `json:"break"`
+	Precept         string `json:"precept"`
+	Timestamp       string `json:"timestamp"`
+	Acknowledgement string `json:"acknowledgement,omitempty"`
+	Regret          string `json:"regret,omitempty"`
+	Intention       string `json:"intention,omitempty"`
+	RenewedAt       string `json:"renewed_at,omitempty"`
+}
+
+// OpenBreak is the JSON form of a break that has not been renewed.
+type OpenBreak struct {
+	Event         LogEntry       `json:"event"`
+	BeginningAnew *BeginningAnew `json:"beginning_anew,omitempty"`
+}
+
+// Renewal is the JSON result of renewing a precept.
+type Renewal struct {
+	Adherence     Adherence     `json:"adherence"`
+	BeginningAnew BeginningAnew `json:"beginning_anew"`
+	Changes       []LogEntry    `json:"changes"`
+}
+
+// CheckIn is the JSON result of a daily check-in: the adherence changes and
+// the entry that journals them, stored together.
+type CheckIn struct {
+	Adherence     Adherence       `json:"adherence"`
+	Changes       []LogEntry      `json:"changes"`
+	Entry         Entry           `json:"entry"`
+	BeginningAnew []BeginningAnew `json:"beginning_anew,omitempty"`
+}
+
+// AdherenceRebuild is the JSON result of regenerating adherence state from
+// the log.
+type AdherenceRebuild struct {
+	Adherence Adherence `json:"adherence"`
+	Events    int       `json:"events"`
+}
+
+// LogArchive is the JSON result of archiving the adherence log. The other
+// fields are only set when Archived is true.
+type LogArchive struct {
+	Archived bool   `json:"archived"`
+	Segment  string `json:"segment,omitempty"`
+	First    string `json:"first,omitempty"`
+	Last     string `json:"last,omitempty"`
+	Events   int    `json:"events,omitempty"`
+}
+
+// AdherenceRequest is the JSON body for changing adherence. Precepts missing
+// from Adherence keep their current value.
+type AdherenceRequest struct {
+	Adherence Adherence         `json:"adherence"`
+	Notes     map[string]string `json:"notes,omitempty"`
+}
+
+// Error is the JSON body returned for failed API requests.
+type Error struct {
+	Error string `json:"error"`
+}
+
+// Day is the JSON form of everything recorded on a single day.
+type Day struct {
+	Date             string     `json:"date"`
+	Entries          []Entry    `json:"entries"`
+	AdherenceChanges []LogEntry `json:"adherence_changes"`
+}
+
+// User is the JSON form of a registered user. Token is only present in the
+// response that creates the user.
+type User struct {
+	Name      string `json:"name"`
+	CreatedAt string `json:"created_at"`
+	RevokedAt string `json:"revoked_at,omitempty"`
+	Token     string `json:"token,omitempty"`
+}
+
+// MergeReport is the JSON result of merging one file into the local data.
+type MergeReport struct {
+	File      string          `json:"file"`
+	Kind      string          `json:"kind"`
+	Added     int             `json:"added"`
+	Total     int             `json:"total"`
+	Conflicts []MergeConflict `json:"conflicts"`
+	Removed   bool            `json:"removed,omitempty"`
+}
+
+// MergeConflict lists the journal entries or adherence changes that were
+// recorded at the same moment with different content. All of them are kept.
+type MergeConflict struct {
+	Timestamp string     `json:"timestamp"`
+	Precept   string     `json:"precept,omitempty"`
+	Entries   []Entry    `json:"entries,omitempty"`
+	Changes   []LogEntry `json:"changes,omitempty"`
+}
+
+// SyncResult is the JSON result of pushing or pulling one file. Action is
+// one of uploaded, merged, unchanged or missing.
+type SyncResult struct {
+	File      string          `json:"file"`
+	Action    string          `json:"action"`
+	Added     int             `json:"added"`
+	Conflicts []MergeConflict `json:"conflicts"`
+}
+
+func FromEntry(entry journal.Entry) Entry {
+	reflections := make(map[string]string, len(entry.Reflections))
+	for precept, reflection := range entry.Reflections {
+		reflections[string(precept)] = reflection
+	}
+	record := Entry{
+		Date:            entry.Date.UTC().Format(dateLayout),
+		Timestamp:       entry.Timestamp.UTC().Format(time.RFC3339Nano),
+		Foundation:      string(entry.Foundation),
+		Mood:            entry.Mood,
+		Note:            entry.Note,
+		Reflections:     reflections,
+		AdherenceEvents: entry.AdherenceEvents,
+		Quicknote:       entry.Quicknote,
+	}
+	if v := entry.Vedana; v != nil {
+		record.Vedana = &Vedana{Tone: string(v.Tone), Intensity: v.Intensity, Location: v.Location, Trigger: v.Trigger}
+	}
+	if len(entry.Questions) > 0 {
+		record.Questions = make(map[string]string, len(entry.Questions))
+		for precept, question := range entry.Questions {
+			record.Questions[string(precept)] = question
+		}
+	}
+	if r := entry.Review; r != nil {
+		record.Review = &ReviewPeriod{Period: string(r.Period), Start: r.Start.Format(dateLayout), End: r.End().Format(dateLayout)}
+	}
+	return record
+}
+
+func FromEntries(entries []journal.Entry) []Entry {
+	list := make([]Entry, 0, len(entries))
+	for _, entry := range entries {
+		list = append(list, FromEntry(entry))
+	}
+	return list
+}
+
+// ToEntry validates the record through journal.NewEntry. A missing timestamp
+// falls back to now.
+func (e Entry) ToEntry(now time.Time) (journal.Entry, error) {
+	date, err := time.Parse(dateLayout, strings.TrimSpace(e.Date))
+	if err != nil {
+		if strings.TrimSpace(e.Date) == "" {
+			return journal.Entry{}, journal.ErrInvalidDate
+		}
+		return journal.Entry{}, fmt.Errorf("invalid date %q: %w", e.Date, err)
+	}
+
+	timestamp := now
+	if strings.TrimSpace(e.Timestamp) != "" {
+		timestamp, err = time.Parse(time.RFC3339Nano, strings.TrimSpace(e.Timestamp))
+		if err != nil {
+			return journal.Entry{}, fmt.Errorf("invalid timestamp %q: %w", e.Timestamp, err)
+		}
+	}
+
+	reflections := make(map[journal.Precept]string, len(e.Reflections))
+	for precept, reflection := range e.Reflections {
+		reflections[journal.Precept(precept)] = reflection
+	}
+
+	questions := make(map[journal.Precept]string, len(e.Questions))
+	for precept, question := range e.Questions {
+		questions[journal.Precept(precept)] = question
+	}
+
+	opts := []journal.EntryOption{journal.WithAdherenceEvents(e.AdherenceEvents...), journal.WithQuestions(questions)}
+	if e.Vedana != nil {
+		vedana, err := journal.NewVedana(journal.Tone(e.Vedana.Tone), e.Vedana.Intensity, e.Vedana.Location, e.Vedana.Trigger)
+		if err != nil {
+			return journal.Entry{}, err
+		}
+		opts = append(opts, journal.WithVedana(vedana))
+	}
+	if e.Review != nil {
+		start, err := time.Parse(dateLayout, strings.TrimSpace(e.Review.Start))
+		if err != nil {
+			return journal.Entry{}, fmt.Errorf("invalid review start %q: %w", e.Review.Start, err)
+		}
+		opts = append(opts, journal.WithReview(journal.Review{Period: journal.ReviewPeriod(e.Review.Period), Start: start}))
+	}
+	if e.Quicknote {
+		opts = append(opts, journal.AsQuicknote())
+	}
+
+	foundation := journal.Foundation(strings.ToLower(strings.TrimSpace(e.Foundation)))
+	return journal.NewEntry(date, reflections, e.Note, e.Mood, foundation, timestamp, opts...)
+}
+
+func FromAdherence(state adherencedomain.Adherence) Adherence {
+	record := make(Adherence, len(state))
+	for precept, value := range state {
+		record[string(precept)] = value
+	}
+	return record
+}
+
+// ToAdherence converts the request into domain adherence and notes.
+func (r AdherenceRequest) ToAdherence() (adherencedomain.Adherence, map[journal.Precept]string) {
+	state := make(adherencedomain.Adherence, len(r.Adherence))
+	for precept, value := range r.Adherence {
+		state[journal.Precept(precept)] = value
+	}
+	notes := make(map[journal.Precept]string, len(r.Notes))
+	for precept, note := range r.Notes {
+		notes[journal.Precept(precept)] = note
+	}
+	return state, notes
+}
+
+func FromLogEntry(entry adherencedomain.AdherenceLogEntry) LogEntry {
+	return LogEntry{
+		Timestamp: entry.At.UTC().Format(time.RFC3339Nano),
+		Kind:      string(entry.Kind),
+		Precept:   string(entry.Precept),
+		From:      entry.From,
+		To:        entry.To,
+		Note:      entry.Note,
+	}
+}
+
+func FromBeginningAnew(record adherencedomain.BeginningAnew) BeginningAnew {
+	converted := BeginningAnew{
+		Break:           record.Break,
+		Precept:         string(record.Precept),
+		Timestamp:       record.At.UTC().Format(time.RFC3339Nano),
+		Acknowledgement: record.Acknowledgement,
+		Regret:          record.Regret,
+		Intention:       record.Intention,
+	}
+	if record.Renewed() {
+		converted.RenewedAt = record.RenewedAt.UTC().Format(time.RFC3339Nano)
+	}
+	return converted
+}
+
+func FromOpenBreak(event adherencedomain.AdherenceLogEntry, record *adherencedomain.BeginningAnew) OpenBreak {
+	converted := OpenBreak{Event: FromLogEntry(event)}
+	if record != nil {
+		practice := FromBeginningAnew(*record)
+		converted.BeginningAnew = &practice
+	}
+	return converted
+}
+
+// FromLogEntries converts log entries, ordered by time and then precept.
+func FromLogEntries(entries []adherencedomain.AdherenceLogEntry) []LogEntry {
+	sorted := append([]adherencedomain.AdherenceLogEntry{}, entries...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if !sorted[i].At.Equal(sorted[j].At) {
+			return sorted[i].At.Before(sorted[j].At)
+		}
+		return sorted[i].Precept < sorted[j].Precept
+	})
+	list := make([]LogEntry, 0, len(sorted))
+	for _, entry := range sorted {
+		list = append(list, FromLogEntry(entry))
+	}
+	return list
+}
+
+func FromUser(u user.User) User {
+	record := User{
+		Name:      u.Name,
+		CreatedAt: u.CreatedAt.UTC().Format(time.RFC3339),
+	}
+	if u.Revoked() {
+		record.RevokedAt = u.RevokedAt.UTC().Format(time.RFC3339)
+	}
+	return record
+}
+
+func FromJournalConflicts(conflicts []journal.Conflict) []MergeConflict {
+	list := make([]MergeConflict, 0, len(conflicts))
+	for _, conflict := range conflicts {
+		list = append(list, MergeConflict{
+			Timestamp: conflict.Timestamp.UTC().Format(time.RFC3339Nano),
+			Entries:   FromEntries(conflict.Entries),
+		})
+	}
+	return list
+}
+
+func FromLogConflicts(conflicts []adherencedomain.LogConflict) []MergeConflict {
+	list := make([]MergeConflict, 0, len(conflicts))
+	for _, conflict := range conflicts {
+		list = append(list, MergeConflict{
+			Timestamp: conflict.At.UTC().Format(time.RFC3339Nano),
+			Precept:   string(conflict.Precept),
+			Changes:   FromLogEntries(conflict.Entries),
+		})
+	}
+	return list
+}
+
+// Stats is the JSON form of the journal statistics for a range of days.
+// Since is empty when the journal has no entries in the range; shares are
+// fractions between 0 and 1.
+type Stats struct {
+	Since                  string            `json:"since,omitempty"`
+	Until                  string            `json:"until"`
+	Entries                int               `json:"entries"`
+	Quicknotes             int               `json:"quicknotes"`
+	FullEntries            int               `json:"full_entries"`
+	Weeks                  []WeekCount       `json:"weeks"`
+	DaysJournaled          int               `json:"days_journaled"`
+	DaysSkipped            int               `json:"days_skipped"`
+	CurrentStreak          int               `json:"current_streak"`
+	LongestStreak          int               `json:"longest_streak"`
+	Precepts               []PreceptCount    `json:"precepts"`
+	Foundations            []FoundationShare `json:"foundations"`
+	Moods                  []MoodCount       `json:"moods"`
+	AverageReflectionWords float64           `json:"average_reflection_words"`
+}
+
+// WeekCount is the number of entries in the week starting on Monday Week.
+type WeekCount struct {
+	Week    string `json:"week"`
+	Entries int    `json:"entries"`
+}
+
+// PreceptCount is how often a precept was reflected on.
+type PreceptCount struct {
+	Precept      string  `json:"precept"`
+	Reflections  int     `json:"reflections"`
+	AverageWords float64 `json:"average_words"`
+}
+
+// FoundationShare is the share of entries written with a foundation,
+// including its objects, with the number of entries per object.
+type FoundationShare struct {
+	Foundation string        `json:"foundation"`
+	Entries    int           `json:"entries"`
+	Share      float64       `json:"share"`
+	Objects    []ObjectCount `json:"objects"`
+}
+
+// ObjectCount is how many entries contemplated an object of a foundation,
+// such as "dhamma.hindrances".
+type ObjectCount struct {
+	Object  string `json:"object"`
+	Entries int    `json:"entries"`
+}
+
+// MoodCount is how many entries recorded a mood.
+type MoodCount struct {
+	Mood    string `json:"mood"`
+	Entries int    `json:"entries"`
+}
+
+// CalendarDay is the JSON form of one day of the practice calendar. Streak
+// marks the days of the current journaling streak.
+type CalendarDay struct {
+	Date             string `json:"date"`
+	Entries          int    `json:"entries"`
+	Reflections      int    `json:"reflections"`
+	AdherenceChanges int    `json:"adherence_changes"`
+	Streak           bool   `json:"streak"`
+}
+
+// MoodChange is the JSON form of a mood rewritten by mt mood migrate.
+type MoodChange struct {
+	Date      string `json:"date"`
+	Timestamp string `json:"timestamp"`
+	From      string `json:"from"`
+	To        string `json:"to"`
+}
+
+// MoodPoint is the JSON form of the average mood over one period of a
+// trend. Valence and Energy are null when no mood in the period is on the
+// scale.
+type MoodPoint struct {
+	Start   string   `json:"start"`
+	Entries int      `json:"entries"`
+	Rated   int      `json:"rated"`
+	Valence *float64 `json:"valence"`
+	Energy  *float64 `json:"energy"`
+}
+
+// FeelingTones is the JSON form of the feeling tone report. Since is empty
+// when the journal has no entries in the range.
+type FeelingTones struct {
+	Since     string        `json:"since,omitempty"`
+	Until     string        `json:"until"`
+	Total     ToneCount     `json:"total"`
+	Periods   []TonePeriod  `json:"periods"`
+	Locations []DetailCount `json:"locations"`
+	Triggers  []DetailCount `json:"triggers"`
+}
+
+// ToneCount tallies feeling tones. Intensity is null when none was rated.
+type ToneCount struct {
+	Pleasant   int      `json:"pleasant"`
+	Unpleasant int      `json:"unpleasant"`
+	Neutral    int      `json:"neutral"`
+	Rated      int      `json:"rated"`
+	Intensity  *float64 `json:"intensity"`
+}
+
+// TonePeriod is the feeling tones recorded in the period starting on Start.
+type TonePeriod struct {
+	Start string `json:"start"`
+	ToneCount
+}
+
+// DetailCount is how many feeling tones were noted with a body location or
+// trigger.
+type DetailCount struct {
+	Detail  string `json:"detail"`
+	Entries int    `json:"entries"`
+}
+
+// Resurfacing is the JSON result of mt journal resurface.
+type Resurfacing struct {
+	OnThisDay []Resurfaced `json:"on_this_day"`
+	Due       []Resurfaced `json:"due"`
+}
+
+// Resurfaced is a past entry brought back to be re-read. Ago is set for
+// entries from this day in an earlier month or year and Due, the day the
+// entry became due, for the others.
+type Resurfaced struct {
+	Ago   string `json:"ago,omitempty"`
+	Due   string `json:"due,omitempty"`
+	Entry Entry  `json:"entry"`
+}
+
+// Review is the JSON form of mt review: what a week, month or year of
+// practice left behind. Previous is the latest review saved for the period
+// before, if any.
+type Review struct {
+	ReviewPeriod
+	Entries   []Entry     `json:"entries"`
+	Changes   []LogEntry  `json:"adherence_changes"`
+	Adherence Adherence   `json:"adherence"`
+	Stats     Stats       `json:"stats"`
+	Moods     []MoodPoint `json:"moods"`
+	Neglected []Neglected `json:"neglected"`
+	Previous  *Entry      `json:"previous,omitempty"`
+}
+
+// Neglected is a precept no entry of the reviewed period reflected on.
+// LastReflected is empty when it never was; Broken counts the times it was
+// marked broken during the period.
+type Neglected struct {
+	Precept       string `json:"precept"`
+	LastReflected string `json:"last_reflected,omitempty"`
+	Broken        int    `json:"broken"`
+}
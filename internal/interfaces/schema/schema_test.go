@@ -0,0 +1,191 @@
+package schema
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	adherencedomain "github.com/thatnerdjosh/mindfulness/internal/domain/adherence"
+	"github.com/thatnerdjosh/mindfulness/internal/domain/journal"
+)
+
+func TestEntryRoundTrip(t *testing.T) {
+	entry, err := journal.NewEntry(time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), map[journal.Precept]string{
+		journal.TrueLove: "kindness",
+	}, "note", "calm", journal.FoundationKaya, time.Date(2024, 1, 2, 9, 30, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := json.Marshal(FromEntry(entry))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `{"date":"2024-01-02","timestamp":"2024-01-02T09:30:00Z","foundation":"kaya","mood":"calm","note":"note","reflections":{"true-love":"kindness"}}`
+	if string(data) != want {
+		t.Fatalf("unexpected JSON:\n got %s\nwant %s", data, want)
+	}
+
+	var record Entry
+	if err := json.Unmarshal(data, &record); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	decoded, err := record.ToEntry(time.Now())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !decoded.Timestamp.Equal(entry.Timestamp) || decoded.Reflections[journal.TrueLove] != "kindness" || decoded.Foundation != journal.FoundationKaya {
+		t.Fatalf("unexpected entry: %+v", decoded)
+	}
+}
+
+func TestEntryRoundTripKeepsSubsecondTimestamp(t *testing.T) {
+	entry, err := journal.NewEntry(time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), nil, "note", "", journal.FoundationKaya,
+		time.Date(2024, 1, 2, 9, 30, 0, 123456789, time.UTC))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	record := FromEntry(entry)
+	if record.Timestamp != "2024-01-02T09:30:00.123456789Z" {
+		t.Fatalf("unexpected timestamp %q", record.Timestamp)
+	}
+	decoded, err := record.ToEntry(time.Now())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if decoded.Fingerprint() != entry.Fingerprint() {
+		t.Fatalf("expected the round trip to keep the entry's fingerprint, got %+v", decoded)
+	}
+}
+
+func TestEntryToEntry(t *testing.T) {
+	now := time.Date(2024, 5, 1, 7, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name       string
+		record     Entry
+		wantErr    error
+		wantErrAny bool
+		check      func(t *testing.T, entry journal.Entry)
+	}{
+		{
+			name:    "requires date",
+			record:  Entry{Note: "note"},
+			wantErr: journal.ErrInvalidDate,
+		},
+		{
+			name:       "invalid date",
+			record:     Entry{Date: "bad", Note: "note"},
+			wantErrAny: true,
+		},
+		{
+			name:       "invalid timestamp",
+			record:     Entry{Date: "2024-05-01", Timestamp: "bad", Note: "note"},
+			wantErrAny: true,
+		},
+		{
+			name:    "unknown precept",
+			record:  Entry{Date: "2024-05-01", Reflections: map[string]string{"other": "x"}},
+			wantErr: journal.ErrUnknownPrecept,
+		},
+		{
+			name:    "unknown foundation",
+			record:  Entry{Date: "2024-05-01", Note: "note", Foundation: "other"},
+			wantErr: journal.ErrUnknownFoundation,
+		},
+		{
+			name:    "requires content",
+			record:  Entry{Date: "2024-05-01"},
+			wantErr: journal.ErrEmptyEntry,
+		},
+		{
+			name:   "defaults timestamp to now",
+			record: Entry{Date: "2024-05-01", Note: "note"},
+			check: func(t *testing.T, entry journal.Entry) {
+				if !entry.Timestamp.Equal(now) {
+					t.Fatalf("expected timestamp %v, got %v", now, entry.Timestamp)
+				}
+				if entry.Foundation != journal.FoundationDhamma {
+					t.Fatalf("expected default foundation, got %q", entry.Foundation)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry, err := tt.record.ToEntry(now)
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+			case tt.wantErrAny:
+				if err == nil {
+					t.Fatalf("expected error")
+				}
+			default:
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if tt.check != nil {
+					tt.check(t, entry)
+				}
+			}
+		})
+	}
+}
+
+func TestFromLogEntriesOrdersByTimeThenPrecept(t *testing.T) {
+	at := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	entries := []adherencedomain.AdherenceLogEntry{
+		{At: at.Add(time.Minute), Precept: journal.ReverenceForLife, From: false, To: true},
+		{At: at, Precept: journal.TrueLove, From: true, To: false, Note: "slipped"},
+		{At: at, Precept: journal.TrueHappiness, From: true, To: false},
+	}
+
+	list := FromLogEntries(entries)
+	if len(list) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(list))
+	}
+	if list[0].Precept != "true-happiness" || list[1].Precept != "true-love" || list[2].Precept != "reverence-for-life" {
+		t.Fatalf("unexpected order: %+v", list)
+	}
+	if list[1].Timestamp != "2024-01-01T12:00:00Z" || list[1].Note != "slipped" {
+		t.Fatalf("unexpected log entry: %+v", list[1])
+	}
+}
+
+func TestFromAdherence(t *testing.T) {
+	state := adherencedomain.DefaultAdherence()
+	state[journal.TrueLove] = false
+
+	record := FromAdherence(state)
+	if len(record) != len(journal.AllPrecepts()) {
+		t.Fatalf("expected %d precepts, got %d", len(journal.AllPrecepts()), len(record))
+	}
+	if record["true-love"] {
+		t.Fatalf("expected true-love false")
+	}
+}
+
+func TestFromOpenBreak(t *testing.T) {
+	at := time.Date(2024, 4, 1, 9, 0, 0, 0, time.UTC)
+	event := adherencedomain.AdherenceLogEntry{At: at, Precept: journal.TrueLove, From: true, To: false}
+	record := adherencedomain.BeginningAnew{Break: event.Fingerprint(), Precept: journal.TrueLove, At: at, Intention: "listen fully"}
+
+	open := FromOpenBreak(event, &record)
+	data, err := json.Marshal(open)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `{"event":{"timestamp":"2024-04-01T09:00:00Z","precept":"true-love","from":true,"to":false},` +
+		`"beginning_anew":{"break":"` + event.Fingerprint() + `","precept":"true-love","timestamp":"2024-04-01T09:00:00Z","intention":"listen fully"}}`
+	if string(data) != want {
+		t.Fatalf("unexpected JSON:\n%s\nwant\n%s", data, want)
+	}
+	if FromOpenBreak(event, nil).BeginningAnew != nil {
+		t.Fatal("expected no record for a break without one")
+	}
+}
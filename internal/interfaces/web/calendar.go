@@ -0,0 +1,91 @@
+package web
+
+import (
+	"net/http"
+	"time"
+)
+
+type calendarPage struct {
+	page
+	Month    string
+	Previous string
+	Next     string
+	Weeks    [][]calendarDay
+}
+
+type calendarDay struct {
+	Date    string
+	Day     int
+	InMonth bool
+	Entries int
+	Level   int
+	Toggled bool
+}
+
+func (h *Handler) calendar(w http.ResponseWriter, r *http.Request) {
+	month := h.now().UTC()
+	if input := r.URL.Query().Get("month"); input != "" {
+		parsed, err := time.Parse("2006-01", input)
+		if err != nil {
+			http.Error(w, "month must be YYYY-MM", http.StatusBadRequest)
+			return
+		}
+		month = parsed
+	}
+	first := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	entries, err := h.journal.ListEntries(r.Context())
+	if err != nil {
+		h.serverError(w, err)
+		return
+	}
+	log, err := h.adherence.Log(r.Context())
+	if err != nil {
+		h.serverError(w, err)
+		return
+	}
+
+	counts := make(map[string]int)
+	for _, entry := range entries {
+		counts[entry.Date.Format("2006-01-02")]++
+	}
+	toggled := make(map[string]bool)
+	for _, change := range log {
+		toggled[change.At.UTC().Format("2006-01-02")] = true
+	}
+
+	h.render(w, "calendar", calendarPage{
+		page:     page{Title: first.Format("January 2006")},
+		Month:    first.Format("January 2006"),
+		Previous: first.AddDate(0, -1, 0).Format("2006-01"),
+		Next:     first.AddDate(0, 1, 0).Format("2006-01"),
+		Weeks:    monthGrid(first, counts, toggled),
+	})
+}
+
+// monthGrid lays out the month in Monday-first weeks, padding with days from
+// the neighbouring months.
+func monthGrid(first time.Time, counts map[string]int, toggled map[string]bool) [][]calendarDay {
+	offset := (int(first.Weekday()) + 6) % 7
+	day := first.AddDate(0, 0, -offset)
+
+	var weeks [][]calendarDay
+	for len(weeks) == 0 || day.Month() == first.Month() {
+		week := make([]calendarDay, 0, 7)
+		for i := 0; i < 7; i++ {
+			key := day.Format("2006-01-02")
+			count := counts[key]
+			week = append(week, calendarDay{
+				Date:    key,
+				Day:     day.Day(),
+				InMonth: day.Month() == first.Month(),
+				Entries: count,
+				Level:   min(count, 3),
+				Toggled: toggled[key],
+			})
+			day = day.AddDate(0, 0, 1)
+		}
+		weeks = append(weeks, week)
+	}
+	return weeks
+}
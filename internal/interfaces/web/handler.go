@@ -0,0 +1,344 @@
+// Package web serves a small browser UI for journaling and adherence. All
+// templates and styles are embedded in the binary.
+package web
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"html/template"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	adherenceapp "github.com/thatnerdjosh/mindfulness/internal/application/adherence"
+	journalapp "github.com/thatnerdjosh/mindfulness/internal/application/journal"
+	adherencedomain "github.com/thatnerdjosh/mindfulness/internal/domain/adherence"
+	"github.com/thatnerdjosh/mindfulness/internal/domain/journal"
+	"github.com/thatnerdjosh/mindfulness/internal/interfaces/httpapi"
+)
+
+//go:embed templates/*.html static/*
+var assets embed.FS
+
+var pages = parsePages("index", "calendar", "day", "timeline")
+
+func parsePages(names ...string) map[string]*template.Template {
+	parsed := make(map[string]*template.Template, len(names))
+	for _, name := range names {
+		parsed[name] = template.Must(template.ParseFS(assets, "templates/layout.html", "templates/"+name+".html"))
+	}
+	return parsed
+}
+
+// Handler serves the web UI.
+type Handler struct {
+	journal   *journalapp.Service
+	adherence *adherenceapp.Service
+	csrf      *csrfGuard
+	mux       *http.ServeMux
+	anyHost   bool
+	now       func() time.Time
+}
+
+// Option configures a Handler.
+type Option func(*Handler)
+
+// WithAnyHost accepts requests for any Host, for a UI reached from other
+// machines under names the handler cannot know.
+func WithAnyHost() Option {
+	return func(h *Handler) {
+		h.anyHost = true
+	}
+}
+
+func NewHandler(journalSvc *journalapp.Service, adherenceSvc *adherenceapp.Service, opts ...Option) (*Handler, error) {
+	guard, err := newCSRFGuard()
+	if err != nil {
+		return nil, err
+	}
+
+	static, err := fs.Sub(assets, "static")
+	if err != nil {
+		return nil, err
+	}
+
+	h := &Handler{
+		journal:   journalSvc,
+		adherence: adherenceSvc,
+		csrf:      guard,
+		mux:       http.NewServeMux(),
+		now:       time.Now,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	h.mux.Handle("GET /static/", http.StripPrefix("/static/", http.FileServerFS(static)))
+	h.mux.HandleFunc("GET /{$}", h.index)
+	h.mux.HandleFunc("POST /entries", h.csrf.protect(h.createEntry))
+	h.mux.HandleFunc("POST /adherence", h.csrf.protect(h.setAdherence))
+	h.mux.HandleFunc("GET /calendar", h.calendar)
+	h.mux.HandleFunc("GET /day/{date}", h.day)
+	h.mux.HandleFunc("GET /timeline", h.timeline)
+	return h, nil
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Security-Policy", "default-src 'self'; form-action 'self'; frame-ancestors 'none'")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	if !h.anyHost && !httpapi.LoopbackHost(r) {
+		http.Error(w, "unexpected Host header", http.StatusForbidden)
+		return
+	}
+	h.mux.ServeHTTP(w, r)
+}
+
+type page struct {
+	Title     string
+	Flash     string
+	Error     string
+	CSRFToken string
+}
+
+type indexPage struct {
+	page
+	Date        string
+	Foundations []foundationOption
+	Precepts    []journal.PreceptInfo
+	Adherence   []adherenceRow
+}
+
+type foundationOption struct {
+	ID       journal.Foundation
+	Label    string
+	Selected bool
+}
+
+type adherenceRow struct {
+	ID    journal.Precept
+	Title string
+	Kept  bool
+}
+
+func (h *Handler) index(w http.ResponseWriter, r *http.Request) {
+	state, err := h.adherence.Current(r.Context())
+	if err != nil {
+		h.serverError(w, err)
+		return
+	}
+
+	data := indexPage{
+		page: page{
+			Title:     "Today",
+			Flash:     r.URL.Query().Get("saved"),
+			Error:     r.URL.Query().Get("error"),
+			CSRFToken: h.csrf.token(w, r),
+		},
+		Date:     h.now().Format("2006-01-02"),
+		Precepts: journal.AllPrecepts(),
+	}
+	for _, foundation := range journal.Foundations() {
+		data.Foundations = append(data.Foundations, foundationOption{
+			ID:       foundation,
+			Label:    journal.FoundationLabel(foundation),
+			Selected: foundation == journal.FoundationDhamma,
+		})
+		for _, object := range journal.FoundationObjects(foundation) {
+			data.Foundations = append(data.Foundations, foundationOption{ID: object.ID, Label: journal.FoundationLabel(object.ID)})
+		}
+	}
+	for _, info := range journal.AllPrecepts() {
+		data.Adherence = append(data.Adherence, adherenceRow{ID: info.ID, Title: info.Title, Kept: state[info.ID]})
+	}
+	h.render(w, "index", data)
+}
+
+func (h *Handler) createEntry(w http.ResponseWriter, r *http.Request) {
+	date, err := time.Parse("2006-01-02", strings.TrimSpace(r.PostFormValue("date")))
+	if err != nil {
+		redirectWithError(w, r, errors.New("enter a date as YYYY-MM-DD"))
+		return
+	}
+	foundation, err := journal.ParseFoundation(r.PostFormValue("foundation"))
+	if err != nil {
+		redirectWithError(w, r, err)
+		return
+	}
+
+	reflections := make(map[journal.Precept]string)
+	for _, info := range journal.AllPrecepts() {
+		reflections[info.ID] = r.PostFormValue("reflection-" + string(info.ID))
+	}
+
+	entry, err := h.journal.RecordEntry(r.Context(), date, reflections, r.PostFormValue("note"), r.PostFormValue("mood"), foundation)
+	if err != nil {
+		if isValidationError(err) {
+			redirectWithError(w, r, err)
+			return
+		}
+		h.serverError(w, err)
+		return
+	}
+	redirectWithFlash(w, r, "Journaled "+entry.Date.Format("2006-01-02"))
+}
+
+func (h *Handler) setAdherence(w http.ResponseWriter, r *http.Request) {
+	next := make(adherencedomain.Adherence)
+	notes := make(map[journal.Precept]string)
+	for _, info := range journal.AllPrecepts() {
+		next[info.ID] = r.PostFormValue("keep-"+string(info.ID)) == "yes"
+		notes[info.ID] = r.PostFormValue("note-" + string(info.ID))
+	}
+
+	changes, err := h.adherence.Set(r.Context(), next, notes)
+	if err != nil {
+		if isValidationError(err) {
+			redirectWithError(w, r, err)
+			return
+		}
+		h.serverError(w, err)
+		return
+	}
+	if len(changes) == 0 {
+		redirectWithFlash(w, r, "Adherence unchanged")
+		return
+	}
+	redirectWithFlash(w, r, "Adherence updated")
+}
+
+type dayPage struct {
+	page
+	Date    string
+	Entries []entryView
+	Changes []changeView
+}
+
+type entryView struct {
+	Time        string
+	Foundation  string
+	Mood        string
+	Note        string
+	Reflections []reflectionView
+}
+
+type reflectionView struct {
+	Title    string
+	Question string
+	Text     string
+}
+
+type changeView struct {
+	At    string
+	Title string
+	To    bool
+	Note  string
+}
+
+func (h *Handler) day(w http.ResponseWriter, r *http.Request) {
+	date, err := time.Parse("2006-01-02", r.PathValue("date"))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	entries, err := h.journal.EntriesOn(r.Context(), date)
+	if err != nil {
+		h.serverError(w, err)
+		return
+	}
+	changes, err := h.adherence.LogOn(r.Context(), date)
+	if err != nil {
+		h.serverError(w, err)
+		return
+	}
+
+	data := dayPage{page: page{Title: date.Format("2006-01-02")}, Date: date.Format("Monday, 2006-01-02")}
+	for _, entry := range entries {
+		view := entryView{
+			Time:       entry.Timestamp.UTC().Format("15:04"),
+			Foundation: journal.FoundationLabel(entry.Foundation),
+			Mood:       entry.Mood,
+			Note:       entry.Note,
+		}
+		for _, info := range journal.AllPrecepts() {
+			if reflection, ok := entry.Reflections[info.ID]; ok {
+				view.Reflections = append(view.Reflections, reflectionView{Title: info.Title, Question: entry.Questions[info.ID], Text: reflection})
+			}
+		}
+		data.Entries = append(data.Entries, view)
+	}
+	data.Changes = changeViews(changes, "15:04")
+	h.render(w, "day", data)
+}
+
+type timelinePage struct {
+	page
+	Changes []changeView
+}
+
+func (h *Handler) timeline(w http.ResponseWriter, r *http.Request) {
+	log, err := h.adherence.Log(r.Context())
+	if err != nil {
+		h.serverError(w, err)
+		return
+	}
+	// Newest first reads naturally as a timeline.
+	reversed := make([]adherencedomain.AdherenceLogEntry, 0, len(log))
+	for i := len(log) - 1; i >= 0; i-- {
+		reversed = append(reversed, log[i])
+	}
+	h.render(w, "timeline", timelinePage{page: page{Title: "Timeline"}, Changes: changeViews(reversed, "2006-01-02 15:04")})
+}
+
+// changeViews formats times in UTC, the zone days are grouped by.
+func changeViews(entries []adherencedomain.AdherenceLogEntry, layout string) []changeView {
+	views := make([]changeView, 0, len(entries))
+	for _, entry := range entries {
+		views = append(views, changeView{
+			At:    entry.At.UTC().Format(layout),
+			Title: preceptTitle(entry.Precept),
+			To:    entry.To,
+			Note:  entry.Note,
+		})
+	}
+	return views
+}
+
+func preceptTitle(precept journal.Precept) string {
+	for _, info := range journal.AllPrecepts() {
+		if info.ID == precept {
+			return info.Title
+		}
+	}
+	return string(precept)
+}
+
+func (h *Handler) render(w http.ResponseWriter, name string, data any) {
+	var buf bytes.Buffer
+	if err := pages[name].ExecuteTemplate(&buf, "layout", data); err != nil {
+		h.serverError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = buf.WriteTo(w)
+}
+
+func (h *Handler) serverError(w http.ResponseWriter, _ error) {
+	http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+}
+
+func isValidationError(err error) bool {
+	return errors.Is(err, journal.ErrInvalidDate) ||
+		errors.Is(err, journal.ErrEmptyEntry) ||
+		errors.Is(err, journal.ErrUnknownPrecept) ||
+		errors.Is(err, journal.ErrUnknownFoundation)
+}
+
+func redirectWithFlash(w http.ResponseWriter, r *http.Request, message string) {
+	http.Redirect(w, r, "/?saved="+url.QueryEscape(message), http.StatusSeeOther)
+}
+
+func redirectWithError(w http.ResponseWriter, r *http.Request, err error) {
+	http.Redirect(w, r, "/?error="+url.QueryEscape(err.Error()), http.StatusSeeOther)
+}
@@ -0,0 +1,304 @@
+package web
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	adherenceapp "github.com/thatnerdjosh/mindfulness/internal/application/adherence"
+	journalapp "github.com/thatnerdjosh/mindfulness/internal/application/journal"
+	"github.com/thatnerdjosh/mindfulness/internal/domain/journal"
+	"github.com/thatnerdjosh/mindfulness/internal/infrastructure/persistence/memory"
+)
+
+var tokenPattern = regexp.MustCompile(`name="csrf_token" value="([^"]+)"`)
+
+type testClient struct {
+	t       *testing.T
+	handler *Handler
+	cookies []*http.Cookie
+}
+
+func newTestClient(t *testing.T) (*testClient, *journalapp.Service, *adherenceapp.Service) {
+	t.Helper()
+	journalSvc := journalapp.NewService(memory.NewJournalRepository())
+	adherenceSvc := adherenceapp.NewService(memory.NewAdherenceRepository())
+	handler, err := NewHandler(journalSvc, adherenceSvc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	handler.now = func() time.Time { return time.Date(2024, 3, 14, 9, 0, 0, 0, time.UTC) }
+	return &testClient{t: t, handler: handler}, journalSvc, adherenceSvc
+}
+
+func (c *testClient) do(req *http.Request) *httptest.ResponseRecorder {
+	req.Host = "127.0.0.1:8081"
+	for _, cookie := range c.cookies {
+		req.AddCookie(cookie)
+	}
+	rec := httptest.NewRecorder()
+	c.handler.ServeHTTP(rec, req)
+	c.cookies = append(c.cookies, rec.Result().Cookies()...)
+	return rec
+}
+
+func (c *testClient) get(target string) *httptest.ResponseRecorder {
+	return c.do(httptest.NewRequest(http.MethodGet, target, nil))
+}
+
+func (c *testClient) post(target string, form url.Values) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return c.do(req)
+}
+
+func (c *testClient) csrfToken() string {
+	c.t.Helper()
+	rec := c.get("/")
+	match := tokenPattern.FindStringSubmatch(rec.Body.String())
+	if match == nil {
+		c.t.Fatalf("expected csrf token in page")
+	}
+	return match[1]
+}
+
+func TestIndexRendersForms(t *testing.T) {
+	client, _, _ := newTestClient(t)
+	rec := client.get("/")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	body := rec.Body.String()
+	for _, want := range []string{"2024-03-14", "Loving Speech and Deep Listening", `value="dhamma" selected`, `name="keep-true-love" value="yes" checked`} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("expected %q in page", want)
+		}
+	}
+	if rec.Header().Get("Content-Security-Policy") == "" {
+		t.Fatalf("expected content security policy header")
+	}
+}
+
+func TestCreateEntry(t *testing.T) {
+	client, journalSvc, _ := newTestClient(t)
+	token := client.csrfToken()
+
+	rec := client.post("/entries", url.Values{
+		"csrf_token":                {token},
+		"date":                      {"2024-03-14"},
+		"mood":                      {"calm"},
+		"foundation":                {"kaya"},
+		"reflection-true-love":      {"listened deeply"},
+		"reflection-true-happiness": {""},
+	})
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("expected redirect, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if !strings.Contains(rec.Header().Get("Location"), "saved=") {
+		t.Fatalf("expected success redirect, got %s", rec.Header().Get("Location"))
+	}
+
+	latest, err := journalSvc.LatestEntry(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if latest.Foundation != journal.FoundationKaya || latest.Reflections[journal.TrueLove] != "listened deeply" {
+		t.Fatalf("unexpected entry: %+v", latest)
+	}
+
+	rec = client.post("/entries", url.Values{"csrf_token": {token}, "date": {"2024-03-14"}})
+	if !strings.Contains(rec.Header().Get("Location"), "error=") {
+		t.Fatalf("expected validation error redirect, got %s", rec.Header().Get("Location"))
+	}
+}
+
+func TestCSRFProtection(t *testing.T) {
+	tests := []struct {
+		name   string
+		form   func(token string) url.Values
+		origin string
+	}{
+		{
+			name: "missing token",
+			form: func(string) url.Values { return url.Values{"date": {"2024-03-14"}, "note": {"x"}} },
+		},
+		{
+			name: "wrong token",
+			form: func(string) url.Values {
+				return url.Values{"csrf_token": {"forged"}, "date": {"2024-03-14"}, "note": {"x"}}
+			},
+		},
+		{
+			name: "cross origin",
+			form: func(token string) url.Values {
+				return url.Values{"csrf_token": {token}, "date": {"2024-03-14"}, "note": {"x"}}
+			},
+			origin: "http://evil.example",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, journalSvc, _ := newTestClient(t)
+			token := client.csrfToken()
+			req := httptest.NewRequest(http.MethodPost, "/entries", strings.NewReader(tt.form(token).Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			rec := client.do(req)
+			if rec.Code != http.StatusForbidden {
+				t.Fatalf("expected 403, got %d", rec.Code)
+			}
+			entries, err := journalSvc.ListEntries(context.Background())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(entries) != 0 {
+				t.Fatalf("expected nothing saved")
+			}
+		})
+	}
+
+	t.Run("token from another session", func(t *testing.T) {
+		first, _, _ := newTestClient(t)
+		token := first.csrfToken()
+		second := &testClient{t: t, handler: first.handler}
+		second.csrfToken()
+		rec := second.post("/entries", url.Values{"csrf_token": {token}, "date": {"2024-03-14"}, "note": {"x"}})
+		if rec.Code != http.StatusForbidden {
+			t.Fatalf("expected 403, got %d", rec.Code)
+		}
+	})
+}
+
+func TestSetAdherenceAndTimeline(t *testing.T) {
+	client, _, adherenceSvc := newTestClient(t)
+	form := url.Values{"csrf_token": {client.csrfToken()}, "note-true-love": {"spoke harshly"}}
+	for _, info := range journal.AllPrecepts() {
+		if info.ID != journal.TrueLove {
+			form.Set("keep-"+string(info.ID), "yes")
+		}
+	}
+
+	rec := client.post("/adherence", form)
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("expected redirect, got %d", rec.Code)
+	}
+	state, err := adherenceSvc.Current(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if state[journal.TrueLove] || !state[journal.TrueHappiness] {
+		t.Fatalf("unexpected state: %+v", state)
+	}
+
+	body := client.get("/timeline").Body.String()
+	if !strings.Contains(body, "True Love") || !strings.Contains(body, "spoke harshly") || !strings.Contains(body, "not kept") {
+		t.Fatalf("expected change in timeline: %s", body)
+	}
+}
+
+func TestCalendarAndDay(t *testing.T) {
+	client, journalSvc, _ := newTestClient(t)
+	for i := 0; i < 2; i++ {
+		if _, err := journalSvc.RecordEntry(context.Background(), time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC), nil, "sat quietly", "", journal.FoundationDhamma); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	body := client.get("/calendar").Body.String()
+	if !strings.Contains(body, "March 2024") || !strings.Contains(body, `href="/day/2024-03-05"`) || !strings.Contains(body, "2 entries") {
+		t.Fatalf("unexpected calendar: %s", body)
+	}
+	if rec := client.get("/calendar?month=bad"); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for bad month, got %d", rec.Code)
+	}
+
+	body = client.get("/day/2024-03-05").Body.String()
+	if strings.Count(body, "sat quietly") != 2 {
+		t.Fatalf("expected both entries on day page: %s", body)
+	}
+	if rec := client.get("/day/nope"); rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", rec.Code)
+	}
+}
+
+func TestStaticAssetsAreEmbedded(t *testing.T) {
+	client, _, _ := newTestClient(t)
+	rec := client.get("/static/style.css")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "table.calendar") {
+		t.Fatalf("expected embedded stylesheet, got %d", rec.Code)
+	}
+}
+
+func TestMonthGrid(t *testing.T) {
+	first := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	weeks := monthGrid(first, map[string]int{"2024-02-14": 5}, map[string]bool{"2024-02-29": true})
+	if len(weeks) != 5 {
+		t.Fatalf("expected 5 weeks, got %d", len(weeks))
+	}
+	if weeks[0][0].Date != "2024-01-29" || weeks[0][0].InMonth {
+		t.Fatalf("expected padding from previous month, got %+v", weeks[0][0])
+	}
+	if weeks[0][3].Date != "2024-02-01" || !weeks[0][3].InMonth {
+		t.Fatalf("expected February to start on Thursday, got %+v", weeks[0][3])
+	}
+	if day := weeks[2][2]; day.Date != "2024-02-14" || day.Entries != 5 || day.Level != 3 {
+		t.Fatalf("unexpected day: %+v", day)
+	}
+	if day := weeks[4][3]; day.Date != "2024-02-29" || !day.Toggled {
+		t.Fatalf("unexpected day: %+v", day)
+	}
+}
+
+func TestRejectsForeignHost(t *testing.T) {
+	client, _, _ := newTestClient(t)
+	server := httptest.NewServer(client.handler)
+	t.Cleanup(server.Close)
+	_, port, err := net.SplitHostPort(server.Listener.Addr().String())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for host, want := range map[string]int{
+		"127.0.0.1:" + port:       http.StatusOK,
+		"localhost:" + port:       http.StatusOK,
+		"[::1]:" + port:           http.StatusOK,
+		"rebound.example:" + port: http.StatusForbidden,
+		"localhost:1":             http.StatusForbidden,
+		"localhost":               http.StatusForbidden,
+	} {
+		req, err := http.NewRequest(http.MethodGet, server.URL+"/", nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		req.Host = host
+		resp, err := server.Client().Do(req)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		_ = resp.Body.Close()
+		if resp.StatusCode != want {
+			t.Errorf("Host %s: expected %d, got %d", host, want, resp.StatusCode)
+		}
+	}
+
+	anyHost, err := NewHandler(client.handler.journal, client.handler.adherence, WithAnyHost())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Host = "mt.example:8081"
+	rec := httptest.NewRecorder()
+	anyHost.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected any host to be accepted, got %d", rec.Code)
+	}
+}